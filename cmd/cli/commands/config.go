@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/cmd/cli/config"
+)
+
+var (
+	// set-context flags; --output and --user-id are taken by the global flags
+	contextServer        string
+	contextUserID        string
+	contextCert          string
+	contextKey           string
+	contextCA            string
+	contextDefaultOutput string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the contexts of the taskman config file",
+	Long: `Manage named contexts in the taskman config file. A context holds a server address, the client
+certificate, key and CA paths and a default output format.
+
+The config file is ~/.config/taskman/config.yaml unless TASKMAN_CONFIG is set. The context is selected with
+--context, then TASKMAN_CONTEXT, then the current context. Flags and the TASKMAN_SERVER_ADDRESS, TASKMAN_USER_ID,
+TASKMAN_CERT, TASKMAN_KEY, TASKMAN_CA and TASKMAN_OUTPUT environment variables override the context.`,
+	Example: `  $ taskman config set-context prod --server taskman.example.com:50051 --cert ~/certs/me.crt --key ~/certs/me.key --ca ~/certs/ca.crt
+  $ taskman config use-context prod
+  $ taskman config get-contexts`,
+	// config commands do not connect to a server so only the output format is resolved
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		p, err := newPrinter(settingValue(cmd, "output", outputFormat, config.EnvOutput, ""))
+		if err != nil {
+			return err
+		}
+		out = p
+		return nil
+	},
+}
+
+var useContextCmd = &cobra.Command{
+	Use:           "use-context <name>",
+	Short:         "Set the current context",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := cfg.UseContext(args[0]); err != nil {
+			return err
+		}
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+		return out.print(cmd.OutOrStdout(), currentContextOutput{CurrentContext: args[0]}, func() string {
+			return fmt.Sprintf("Switched to context %q.\n", args[0])
+		})
+	},
+}
+
+var getContextsCmd = &cobra.Command{
+	Use:           "get-contexts",
+	Short:         "List the contexts of the config file",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		contexts := make([]contextOutput, 0, len(cfg.Contexts))
+		for _, ctx := range cfg.Contexts {
+			contexts = append(contexts, newContextOutput(ctx, ctx.Name == cfg.CurrentContext))
+		}
+		return out.print(cmd.OutOrStdout(), contexts, func() string {
+			return printContexts(contexts)
+		})
+	},
+}
+
+var setContextCmd = &cobra.Command{
+	Use:   "set-context <name>",
+	Short: "Create or update a context",
+	Long: `Create a context or update the given fields of an existing context. The first context created
+becomes the current context.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		ctx := cfg.Context(args[0])
+		if ctx == nil {
+			ctx = &config.Context{Name: args[0]}
+		}
+		flags := cmd.Flags()
+		if flags.Changed("server") {
+			ctx.Server = contextServer
+		}
+		if flags.Changed("user") {
+			ctx.UserID = contextUserID
+		}
+		if flags.Changed("cert") {
+			ctx.Cert = contextCert
+		}
+		if flags.Changed("key") {
+			ctx.Key = contextKey
+		}
+		if flags.Changed("ca") {
+			ctx.CA = contextCA
+		}
+		if flags.Changed("default-output") {
+			if _, err := newPrinter(contextDefaultOutput); err != nil {
+				return err
+			}
+			ctx.Output = contextDefaultOutput
+		}
+
+		cfg.SetContext(ctx)
+		if cfg.CurrentContext == "" {
+			cfg.CurrentContext = ctx.Name
+		}
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+		return out.print(cmd.OutOrStdout(), newContextOutput(ctx, ctx.Name == cfg.CurrentContext), func() string {
+			return fmt.Sprintf("Context %q saved to %s.\n", ctx.Name, path)
+		})
+	},
+}
+
+// loadConfig loads the config file and returns its path
+func loadConfig() (string, *config.Config, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return "", nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, cfg, nil
+}
+
+// currentContextOutput is the output of the use-context command
+type currentContextOutput struct {
+	CurrentContext string `json:"current_context" yaml:"current_context"`
+}
+
+// contextOutput is the output of the get-contexts and set-context commands
+type contextOutput struct {
+	Name    string `json:"name" yaml:"name"`
+	Current bool   `json:"current" yaml:"current"`
+	Server  string `json:"server,omitempty" yaml:"server,omitempty"`
+	UserID  string `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	Cert    string `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key     string `json:"key,omitempty" yaml:"key,omitempty"`
+	CA      string `json:"ca,omitempty" yaml:"ca,omitempty"`
+	Output  string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+func newContextOutput(ctx *config.Context, current bool) contextOutput {
+	return contextOutput{
+		Name:    ctx.Name,
+		Current: current,
+		Server:  ctx.Server,
+		UserID:  ctx.UserID,
+		Cert:    ctx.Cert,
+		Key:     ctx.Key,
+		CA:      ctx.CA,
+		Output:  ctx.Output,
+	}
+}
+
+// printContexts renders the contexts as a table marking the current context with a *
+func printContexts(contexts []contextOutput) string {
+	var buf bytes.Buffer
+
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"CURRENT", "NAME", "SERVER", "USER ID", "CERT", "CA", "OUTPUT"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	for _, ctx := range contexts {
+		current := ""
+		if ctx.Current {
+			current = "*"
+		}
+		table.Append([]string{
+			current, ctx.Name, formatField(ctx.Server), formatField(ctx.UserID), formatField(ctx.Cert),
+			formatField(ctx.CA), formatField(ctx.Output),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+// formatField shows empty fields as "-" like the task status table
+func formatField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	setContextCmd.Flags().StringVar(&contextServer, "server", "", "The gRPC server address of the context.")
+	setContextCmd.Flags().StringVar(&contextUserID, "user", "",
+		"The name of an embedded client certificate, as for --user-id. Used if --cert and --key are not set.")
+	setContextCmd.Flags().StringVar(&contextCert, "cert", "", "The path of the PEM client certificate.")
+	setContextCmd.Flags().StringVar(&contextKey, "key", "", "The path of the PEM client key.")
+	setContextCmd.Flags().StringVar(&contextCA, "ca", "", "The path of the PEM CA certificate. The embedded CA is used if not set.")
+	setContextCmd.Flags().StringVar(&contextDefaultOutput, "default-output", "", "The default output format of the context.")
+
+	configCmd.AddCommand(useContextCmd)
+	configCmd.AddCommand(getContextsCmd)
+	configCmd.AddCommand(setContextCmd)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   `delete <task-id> [--user-id <user-id>] [--server-address <host:port>] [--help]`,
+	Short: "Delete a finished task and its output by its task ID",
+	Long: `Delete a finished task and its output before the server's retention policy evicts it. Running tasks must
+be stopped first. The task ID is unknown to the server afterwards.
+
+Arguments:
+  <task-id>
+        The unique identifier (UUID) of the task to delete.
+        Example: a7da14c7-b47a-4535-a263-5bb26e503002
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --help
+      Display help information for the delete command.`,
+	Example:       `$ taskman --user-id client001 delete a7da14c7-b47a-4535-a263-5bb26e503002`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		taskID := args[0]
+		if taskID == "" {
+			if err := cmd.Usage(); err != nil {
+				return fmt.Errorf("failed to display usage: %w", err)
+			}
+			return errors.New("task ID is required")
+		}
+
+		manager, err := client.NewManager(creds, serverAddr)
+		if err != nil {
+			return fmt.Errorf("failed to set up gRPC client: %w", err)
+		}
+		defer func() {
+			if closeErr := manager.Close(); closeErr != nil {
+				if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+					// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+					fmt.Printf("failed to log close error: %v\n", logErr)
+				}
+			}
+		}()
+
+		if err := manager.DeleteTask(cmd.Context(), taskID); err != nil {
+			return err
+		}
+		return out.print(cmd.OutOrStdout(), deleteOutput{TaskID: taskID, Deleted: true}, func() string {
+			return fmt.Sprintf("Task %s deleted.\n", taskID)
+		})
+	},
+}
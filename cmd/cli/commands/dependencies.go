@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// dependencyConditions maps the conditions of --depends-on to the conditions of a dependency
+var dependencyConditions = map[string]pb.DependencyCondition{
+	"success":    pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS,
+	"completion": pb.DependencyCondition_DEPENDENCY_CONDITION_COMPLETION,
+	"failure":    pb.DependencyCondition_DEPENDENCY_CONDITION_FAILURE,
+}
+
+// conditionName returns the --depends-on name of a condition
+func conditionName(condition pb.DependencyCondition) string {
+	for name, c := range dependencyConditions {
+		if c == condition {
+			return name
+		}
+	}
+	return condition.String()
+}
+
+// parseDependencies parses repeated --depends-on <task-id>[:<condition>] flags; the condition defaults to success
+func parseDependencies(values []string) ([]client.Dependency, error) {
+	deps := make([]client.Dependency, 0, len(values))
+	for _, value := range values {
+		taskID, name, hasCondition := strings.Cut(value, ":")
+		if taskID == "" {
+			return nil, fmt.Errorf("invalid dependency %q: must be <task-id>[:<condition>]", value)
+		}
+		condition := pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS
+		if hasCondition {
+			var ok bool
+			if condition, ok = dependencyConditions[strings.ToLower(name)]; !ok {
+				return nil, fmt.Errorf("invalid condition %q of dependency %s: must be success, completion or failure", name, taskID)
+			}
+		}
+		deps = append(deps, client.Dependency{TaskID: taskID, Condition: condition})
+	}
+	return deps, nil
+}
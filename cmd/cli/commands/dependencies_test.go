@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+func TestParseDependencies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		values      []string
+		expected    []client.Dependency
+		expectedErr bool
+	}{
+		{desc: "none", values: nil, expected: []client.Dependency{}},
+		{
+			desc:   "default condition",
+			values: []string{"a7da14c7-b47a-4535-a263-5bb26e503002"},
+			expected: []client.Dependency{
+				{TaskID: "a7da14c7-b47a-4535-a263-5bb26e503002", Condition: pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS},
+			},
+		},
+		{
+			desc:   "conditions",
+			values: []string{"task1:completion", "task2:Failure"},
+			expected: []client.Dependency{
+				{TaskID: "task1", Condition: pb.DependencyCondition_DEPENDENCY_CONDITION_COMPLETION},
+				{TaskID: "task2", Condition: pb.DependencyCondition_DEPENDENCY_CONDITION_FAILURE},
+			},
+		},
+		{desc: "missing task ID", values: []string{":success"}, expectedErr: true},
+		{desc: "unknown condition", values: []string{"task1:done"}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			deps, err := parseDependencies(tt.values)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, deps)
+		})
+	}
+}
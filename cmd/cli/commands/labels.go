@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseLabels parses repeated --label key=value flags into a map
+func parseLabels(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q: must be key=value", pair)
+		}
+		if _, exists := labels[key]; exists {
+			return nil, fmt.Errorf("label %q is set more than once", key)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var listSelector string
+
+var listCmd = &cobra.Command{
+	Use:     `list [--user-id <user-id>] [--server-address <host:port>] [-l <selector>] [--help]`,
+	Aliases: []string{"ls"},
+	Short:   "List tasks, optionally selected by label",
+	Long: `List the statuses of your tasks ordered by start time. The admin client lists the tasks of all clients.
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  -l, --selector <selector>
+      A comma-separated label selector (e.g., team=infra,env!=prod). Requirements are key=value, key!=value,
+      key (the label is set) or !key (the label is not set).
+  --help
+      Display help information for the list command.`,
+	Example:       `$ taskman --user-id client001 list -l team=infra`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listTasks(cmd, listSelector)
+	},
+}
+
+// listTasks prints the statuses of the tasks matching the label selector
+func listTasks(cmd *cobra.Command, selector string) error {
+	manager, err := client.NewManager(creds, serverAddr)
+	if err != nil {
+		return fmt.Errorf("failed to set up gRPC client: %w", err)
+	}
+	defer func() {
+		if closeErr := manager.Close(); closeErr != nil {
+			if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+				// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+				fmt.Printf("failed to log close error: %v\n", logErr)
+			}
+		}
+	}()
+
+	statuses, err := manager.ListTasks(cmd.Context(), selector)
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	tasks := make([]taskStatusOutput, 0, len(statuses))
+	for _, status := range statuses {
+		tasks = append(tasks, newTaskStatusOutput(status))
+	}
+	return out.print(cmd.OutOrStdout(), tasks, func() string {
+		return client.FormatTaskStatuses(statuses) + "\n"
+	})
+}
+
+func init() {
+	listCmd.Flags().StringVarP(&listSelector, "selector", "l", "", "A comma-separated label selector, e.g. team=infra,env!=prod.")
+}
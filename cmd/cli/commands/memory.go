@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var watchMemoryCmd = &cobra.Command{
+	Use:   `watch-memory <task-id> [--user-id <user-id>] [--server-address <host:port>] [--help]`,
+	Short: "Watch the memory events of a task by its task ID",
+	Long: `Print the memory events of a task as they occur until the task finishes, starting with the last events the
+server kept. The server records an event when the processes of the task are throttled above memory.high (high),
+reach memory.max (max) or are OOM killed (oom_kill), when they stall on memory (pressure) and when the server
+stops the task under memory pressure (stop). Each event shows the percentage of time some and all processes of
+the task stalled on memory over the last 10 seconds. get-status shows the same events.
+
+Arguments:
+  <task-id>
+        The unique identifier (UUID) of the task.
+        Example: a7da14c7-b47a-4535-a263-5bb26e503002
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --help
+      Display help information for the watch-memory command.`,
+	Example:       `$ taskman --user-id client001 watch-memory a7da14c7-b47a-4535-a263-5bb26e503002`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskID := args[0]
+		return withManager(cmd, func(manager *client.Manager) error {
+			return manager.WatchMemoryEvents(cmd.Context(), taskID, func(event client.MemoryEvent) error {
+				return out.print(cmd.OutOrStdout(), newMemoryEventOutput(event), func() string {
+					return event.String() + "\n"
+				})
+			})
+		})
+	},
+}
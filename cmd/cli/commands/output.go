@@ -0,0 +1,451 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// Supported values of the --output flag
+const (
+	outputTable      = "table"
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputGoTemplate = "go-template"
+)
+
+// out renders command results in the format selected with --output; set before any command runs
+var out = &printer{format: outputTable}
+
+// printer renders command results as a table, JSON, YAML or a Go template.
+// The JSON field names are the stable schema used by all machine-readable formats including templates.
+type printer struct {
+	format string
+	tmpl   *template.Template
+}
+
+// newPrinter parses an --output value such as "json" or "go-template={{.task_id}}"
+func newPrinter(output string) (*printer, error) {
+	format, arg, hasArg := strings.Cut(output, "=")
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		if hasArg {
+			return nil, fmt.Errorf("output format %q does not take an argument", format)
+		}
+		return &printer{format: format}, nil
+	case outputGoTemplate:
+		if arg == "" {
+			return nil, errors.New("output format go-template requires a template, e.g. go-template={{.task_id}}")
+		}
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return &printer{format: format, tmpl: tmpl}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q: must be one of table, json, yaml or go-template=<template>", output)
+	}
+}
+
+// print writes v in the selected format; table renders v for the table format
+func (p *printer) print(w io.Writer, v any, table func() string) error {
+	var data []byte
+	switch p.format {
+	case outputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		data = append(b, '\n')
+	case outputYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		data = buf.Bytes()
+	case outputGoTemplate:
+		// the template is executed on the JSON representation so that it uses the same field names
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+		var generic any
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return fmt.Errorf("failed to unmarshal output: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := p.tmpl.Execute(&buf, generic); err != nil {
+			return fmt.Errorf("failed to execute go-template: %w", err)
+		}
+		data = buf.Bytes()
+	default:
+		data = []byte(table())
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to print output: %w", err)
+	}
+	return nil
+}
+
+// taskIDOutput is the output of commands that create a task
+type taskIDOutput struct {
+	TaskID string `json:"task_id" yaml:"task_id"`
+}
+
+// stopOutput is the output of the stop command
+type stopOutput struct {
+	TaskID  string `json:"task_id" yaml:"task_id"`
+	Stopped bool   `json:"stopped" yaml:"stopped"`
+}
+
+// deleteOutput is the output of the delete command
+type deleteOutput struct {
+	TaskID  string `json:"task_id" yaml:"task_id"`
+	Deleted bool   `json:"deleted" yaml:"deleted"`
+}
+
+// pauseOutput is the output of the pause and resume commands
+type pauseOutput struct {
+	TaskID string `json:"task_id" yaml:"task_id"`
+	Paused bool   `json:"paused" yaml:"paused"`
+}
+
+// resourceLimitsOutput are the cgroup limits of a task; a limit of 0 is unlimited
+type resourceLimitsOutput struct {
+	CPUMillis       int64  `json:"cpu_millis" yaml:"cpu_millis"`
+	MemoryMaxBytes  int64  `json:"memory_max_bytes" yaml:"memory_max_bytes"`
+	MemoryHighBytes int64  `json:"memory_high_bytes" yaml:"memory_high_bytes"`
+	IOReadBPS       int64  `json:"io_read_bps" yaml:"io_read_bps"`
+	IOWriteBPS      int64  `json:"io_write_bps" yaml:"io_write_bps"`
+	PidsMax         int64  `json:"pids_max" yaml:"pids_max"`
+	CpusetCPUs      string `json:"cpuset_cpus,omitempty" yaml:"cpuset_cpus,omitempty"`
+	CpusetMems      string `json:"cpuset_mems,omitempty" yaml:"cpuset_mems,omitempty"`
+}
+
+func newResourceLimitsOutput(l client.ResourceLimits) resourceLimitsOutput {
+	return resourceLimitsOutput{
+		CPUMillis:       l.CPUMillis,
+		MemoryMaxBytes:  l.MemoryMax,
+		MemoryHighBytes: l.MemoryHigh,
+		IOReadBPS:       l.IOReadBPS,
+		IOWriteBPS:      l.IOWriteBPS,
+		PidsMax:         l.PidsMax,
+		CpusetCPUs:      l.CPUs,
+		CpusetMems:      l.Mems,
+	}
+}
+
+// resourceChangeOutput is a change of the limits of a task in the output of get-status
+type resourceChangeOutput struct {
+	Time     time.Time            `json:"time" yaml:"time"`
+	ClientID string               `json:"client_id" yaml:"client_id"`
+	Previous resourceLimitsOutput `json:"previous" yaml:"previous"`
+	Limits   resourceLimitsOutput `json:"limits" yaml:"limits"`
+}
+
+// updateResourcesOutput is the output of the update-resources command
+type updateResourcesOutput struct {
+	TaskID string               `json:"task_id" yaml:"task_id"`
+	Limits resourceLimitsOutput `json:"limits" yaml:"limits"`
+}
+
+// memoryStatusOutput is the memory state of the process of a task in the output of get-status
+type memoryStatusOutput struct {
+	HighEvents    int64   `json:"high_events" yaml:"high_events"`
+	MaxEvents     int64   `json:"max_events" yaml:"max_events"`
+	OOMKillEvents int64   `json:"oom_kill_events" yaml:"oom_kill_events"`
+	SomePressure  float64 `json:"some_pressure" yaml:"some_pressure"`
+	FullPressure  float64 `json:"full_pressure" yaml:"full_pressure"`
+}
+
+// memoryEventOutput is a memory event of a task in the output of get-status and watch-memory
+type memoryEventOutput struct {
+	Time         time.Time `json:"time" yaml:"time"`
+	Attempt      int32     `json:"attempt" yaml:"attempt"`
+	Kind         string    `json:"kind" yaml:"kind"`
+	Count        int64     `json:"count,omitempty" yaml:"count,omitempty"`
+	SomePressure float64   `json:"some_pressure" yaml:"some_pressure"`
+	FullPressure float64   `json:"full_pressure" yaml:"full_pressure"`
+}
+
+func newMemoryEventOutput(e client.MemoryEvent) memoryEventOutput {
+	return memoryEventOutput{
+		Time:         e.Time,
+		Attempt:      e.Attempt,
+		Kind:         e.Kind,
+		Count:        e.Count,
+		SomePressure: e.SomePressure,
+		FullPressure: e.FullPressure,
+	}
+}
+
+// quotaUsageOutput is the usage of a single quota; a limit of 0 is unlimited
+type quotaUsageOutput struct {
+	Used  int64 `json:"used" yaml:"used"`
+	Limit int64 `json:"limit" yaml:"limit"`
+}
+
+// quotaOutput is the output of the quota command
+type quotaOutput struct {
+	ClientID           string           `json:"client_id" yaml:"client_id"`
+	RunningTasks       quotaUsageOutput `json:"running_tasks" yaml:"running_tasks"`
+	GlobalRunningTasks quotaUsageOutput `json:"global_running_tasks" yaml:"global_running_tasks"`
+	StartsPerMinute    quotaUsageOutput `json:"starts_per_minute" yaml:"starts_per_minute"`
+	QueuedTasks        quotaUsageOutput `json:"queued_tasks" yaml:"queued_tasks"`
+}
+
+func newQuotaOutput(quota *client.Quota) quotaOutput {
+	return quotaOutput{
+		ClientID:           quota.ClientID,
+		RunningTasks:       quotaUsageOutput(quota.RunningTasks),
+		GlobalRunningTasks: quotaUsageOutput(quota.GlobalRunningTasks),
+		StartsPerMinute:    quotaUsageOutput(quota.StartsPerMinute),
+		QueuedTasks:        quotaUsageOutput(quota.QueuedTasks),
+	}
+}
+
+// scheduleOutput is the output of the schedule commands
+type scheduleOutput struct {
+	ScheduleID    string            `json:"schedule_id" yaml:"schedule_id"`
+	ClientID      string            `json:"client_id" yaml:"client_id"`
+	Cron          string            `json:"cron_expression" yaml:"cron_expression"`
+	Command       string            `json:"command" yaml:"command"`
+	Args          []string          `json:"args" yaml:"args"`
+	Labels        map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Priority      int32             `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Concurrency   string            `json:"concurrency_policy" yaml:"concurrency_policy"`
+	Paused        bool              `json:"paused" yaml:"paused"`
+	CreateTime    *time.Time        `json:"create_time,omitempty" yaml:"create_time,omitempty"`
+	NextRunTime   *time.Time        `json:"next_run_time,omitempty" yaml:"next_run_time,omitempty"`
+	LastRunTime   *time.Time        `json:"last_run_time,omitempty" yaml:"last_run_time,omitempty"`
+	LastTaskID    string            `json:"last_task_id,omitempty" yaml:"last_task_id,omitempty"`
+	ActiveTaskIDs []string          `json:"active_task_ids" yaml:"active_task_ids"`
+}
+
+func newScheduleOutput(s *client.Schedule) scheduleOutput {
+	output := scheduleOutput{
+		ScheduleID:    s.ScheduleID,
+		ClientID:      s.ClientID,
+		Cron:          s.Cron,
+		Command:       s.Command,
+		Args:          s.Args,
+		Labels:        s.Labels,
+		Priority:      s.Priority,
+		Concurrency:   concurrencyName(s.Concurrency),
+		Paused:        s.Paused,
+		CreateTime:    optionalTime(s.CreateTime),
+		NextRunTime:   optionalTime(s.NextRunTime),
+		LastRunTime:   optionalTime(s.LastRunTime),
+		LastTaskID:    s.LastTaskID,
+		ActiveTaskIDs: s.ActiveTaskIDs,
+	}
+	if output.Args == nil {
+		output.Args = []string{}
+	}
+	if output.ActiveTaskIDs == nil {
+		output.ActiveTaskIDs = []string{}
+	}
+	return output
+}
+
+// scheduleDeleteOutput is the output of the schedule delete command
+type scheduleDeleteOutput struct {
+	ScheduleID string `json:"schedule_id" yaml:"schedule_id"`
+	Deleted    bool   `json:"deleted" yaml:"deleted"`
+}
+
+// bulkOutput is the output of the stop command with a selector
+type bulkOutput struct {
+	DryRun  bool               `json:"dry_run" yaml:"dry_run"`
+	Results []taskResultOutput `json:"results" yaml:"results"`
+}
+
+// taskResultOutput is the result of a bulk operation on a single task
+type taskResultOutput struct {
+	TaskID  string `json:"task_id" yaml:"task_id"`
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// taskStatusOutput is the output of the get-status and list commands
+type taskStatusOutput struct {
+	TaskID            string                 `json:"task_id" yaml:"task_id"`
+	Status            string                 `json:"status" yaml:"status"`
+	ProcessID         int32                  `json:"process_id" yaml:"process_id"`
+	ExitCode          *int32                 `json:"exit_code" yaml:"exit_code"`
+	TerminationSignal string                 `json:"termination_signal,omitempty" yaml:"termination_signal,omitempty"`
+	TerminationSource string                 `json:"termination_source,omitempty" yaml:"termination_source,omitempty"`
+	StartTime         *time.Time             `json:"start_time,omitempty" yaml:"start_time,omitempty"`
+	EndTime           *time.Time             `json:"end_time,omitempty" yaml:"end_time,omitempty"`
+	Labels            map[string]string      `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Priority          int32                  `json:"priority,omitempty" yaml:"priority,omitempty"`
+	QueuePosition     int32                  `json:"queue_position,omitempty" yaml:"queue_position,omitempty"`
+	MaxAttempts       int32                  `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	Attempts          []attemptOutput        `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	DependsOn         []dependencyOutput     `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	ScheduleID        string                 `json:"schedule_id,omitempty" yaml:"schedule_id,omitempty"`
+	RestartCount      int32                  `json:"restart_count,omitempty" yaml:"restart_count,omitempty"`
+	LastExitReason    string                 `json:"last_exit_reason,omitempty" yaml:"last_exit_reason,omitempty"`
+	PausedDuration    string                 `json:"paused_duration,omitempty" yaml:"paused_duration,omitempty"`
+	Limits            *resourceLimitsOutput  `json:"limits,omitempty" yaml:"limits,omitempty"`
+	ResourceChanges   []resourceChangeOutput `json:"resource_changes,omitempty" yaml:"resource_changes,omitempty"`
+	PidsMaxEvents     int64                  `json:"pids_max_events,omitempty" yaml:"pids_max_events,omitempty"`
+	Memory            *memoryStatusOutput    `json:"memory,omitempty" yaml:"memory,omitempty"`
+	MemoryEvents      []memoryEventOutput    `json:"memory_events,omitempty" yaml:"memory_events,omitempty"`
+}
+
+// dependencyOutput is a task the task depends on in the output of get-status
+type dependencyOutput struct {
+	TaskID    string `json:"task_id" yaml:"task_id"`
+	Condition string `json:"condition" yaml:"condition"`
+}
+
+// attemptOutput is a single run of a retried task in the output of get-status
+type attemptOutput struct {
+	Attempt           int32      `json:"attempt" yaml:"attempt"`
+	ProcessID         int32      `json:"process_id" yaml:"process_id"`
+	Status            string     `json:"status" yaml:"status"`
+	ExitCode          *int32     `json:"exit_code" yaml:"exit_code"`
+	TerminationSignal string     `json:"termination_signal,omitempty" yaml:"termination_signal,omitempty"`
+	TerminationSource string     `json:"termination_source,omitempty" yaml:"termination_source,omitempty"`
+	StartTime         *time.Time `json:"start_time,omitempty" yaml:"start_time,omitempty"`
+	EndTime           *time.Time `json:"end_time,omitempty" yaml:"end_time,omitempty"`
+	OutputOffset      int64      `json:"output_offset" yaml:"output_offset"`
+	PidsMaxEvents     int64      `json:"pids_max_events,omitempty" yaml:"pids_max_events,omitempty"`
+}
+
+func newTaskStatusOutput(s *client.TaskStatus) taskStatusOutput {
+	output := taskStatusOutput{
+		TaskID:            s.TaskID,
+		Status:            s.Status,
+		ProcessID:         s.ProcessID,
+		ExitCode:          s.ExitCode,
+		TerminationSignal: s.TerminationSignal,
+		TerminationSource: s.TerminationSource,
+		StartTime:         optionalTime(s.StartTime),
+		EndTime:           optionalTime(s.EndTime),
+		Labels:            s.Labels,
+		Priority:          s.Priority,
+		QueuePosition:     s.QueuePosition,
+		ScheduleID:        s.ScheduleID,
+		RestartCount:      s.RestartCount,
+		LastExitReason:    s.LastExitReason,
+		PidsMaxEvents:     s.PidsMaxEvents,
+	}
+	if s.PausedDuration > 0 {
+		output.PausedDuration = s.PausedDuration.String()
+	}
+	if s.Limits != nil {
+		limits := newResourceLimitsOutput(*s.Limits)
+		output.Limits = &limits
+	}
+	for _, change := range s.ResourceChanges {
+		output.ResourceChanges = append(output.ResourceChanges, resourceChangeOutput{
+			Time:     change.Time,
+			ClientID: change.ClientID,
+			Previous: newResourceLimitsOutput(change.Previous),
+			Limits:   newResourceLimitsOutput(change.Limits),
+		})
+	}
+	// the memory state is only shown once the task was throttled, killed or stalled for its memory
+	if s.Memory != nil && *s.Memory != (client.MemoryStatus{}) {
+		output.Memory = &memoryStatusOutput{
+			HighEvents:    s.Memory.HighEvents,
+			MaxEvents:     s.Memory.MaxEvents,
+			OOMKillEvents: s.Memory.OOMKillEvents,
+			SomePressure:  s.Memory.SomePressure,
+			FullPressure:  s.Memory.FullPressure,
+		}
+	}
+	for _, event := range s.MemoryEvents {
+		output.MemoryEvents = append(output.MemoryEvents, newMemoryEventOutput(event))
+	}
+	for _, dep := range s.DependsOn {
+		output.DependsOn = append(output.DependsOn, dependencyOutput{TaskID: dep.TaskID, Condition: conditionName(dep.Condition)})
+	}
+	// attempts are only shown for retried and restarted tasks so that the output of other tasks stays unchanged
+	if s.MaxAttempts > 1 || len(s.Attempts) > 1 {
+		output.MaxAttempts = s.MaxAttempts
+		for _, a := range s.Attempts {
+			output.Attempts = append(output.Attempts, attemptOutput{
+				Attempt:           a.Attempt,
+				ProcessID:         a.ProcessID,
+				Status:            a.Status,
+				ExitCode:          a.ExitCode,
+				TerminationSignal: a.TerminationSignal,
+				TerminationSource: a.TerminationSource,
+				StartTime:         optionalTime(a.StartTime),
+				EndTime:           optionalTime(a.EndTime),
+				OutputOffset:      a.OutputOffset,
+				PidsMaxEvents:     a.PidsMaxEvents,
+			})
+		}
+	}
+	return output
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// errorOutput is written to stderr when a command fails with a machine-readable output format
+type errorOutput struct {
+	Error errorDetail `json:"error" yaml:"error"`
+}
+
+type errorDetail struct {
+	// Code is the gRPC status code name such as "NotFound", or "Unknown" for local errors
+	Code      string `json:"code" yaml:"code"`
+	Message   string `json:"message" yaml:"message"`
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+}
+
+func newErrorOutput(err error) errorOutput {
+	detail := errorDetail{
+		Code:    codes.Unknown.String(),
+		Message: err.Error(),
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		st := grpcErr.GRPCStatus()
+		detail.Code = st.Code().String()
+		detail.Message = st.Message()
+	}
+	var reqErr *client.RequestError
+	if errors.As(err, &reqErr) {
+		detail.RequestID = reqErr.RequestID
+	}
+	return errorOutput{Error: detail}
+}
+
+// PrintError writes err to w as an "Error:" line or, with the JSON and YAML output formats,
+// in the stable error schema
+func PrintError(w io.Writer, err error) error {
+	switch out.format {
+	case outputJSON, outputYAML:
+		return out.print(w, newErrorOutput(err), nil)
+	default:
+		_, printErr := fmt.Fprintf(w, "Error: %v\n", err)
+		return printErr
+	}
+}
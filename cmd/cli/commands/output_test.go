@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+func TestPrinter(t *testing.T) {
+	t.Parallel()
+
+	exitCode := int32(0)
+	startTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	taskStatus := newTaskStatusOutput(&client.TaskStatus{
+		TaskID:    "a7da14c7-b47a-4535-a263-5bb26e503002",
+		Status:    "JOB_STATUS_EXITED_OK",
+		StartTime: startTime,
+		ExitCode:  &exitCode,
+		ProcessID: 42,
+	})
+
+	tests := []struct {
+		desc        string
+		output      string
+		value       any
+		expected    string
+		expectedErr string
+	}{
+		{
+			desc:     "table",
+			output:   "table",
+			value:    taskIDOutput{TaskID: "abc"},
+			expected: "table output",
+		},
+		{
+			desc:   "json task status",
+			output: "json",
+			value:  taskStatus,
+			expected: `{
+  "task_id": "a7da14c7-b47a-4535-a263-5bb26e503002",
+  "status": "JOB_STATUS_EXITED_OK",
+  "process_id": 42,
+  "exit_code": 0,
+  "start_time": "2025-01-02T03:04:05Z"
+}
+`,
+		},
+		{
+			desc:     "yaml task id",
+			output:   "yaml",
+			value:    taskIDOutput{TaskID: "abc"},
+			expected: "task_id: abc\n",
+		},
+		{
+			desc:     "go-template uses json field names",
+			output:   "go-template={{.task_id}} {{.exit_code}}",
+			value:    taskStatus,
+			expected: "a7da14c7-b47a-4535-a263-5bb26e503002 0",
+		},
+		{
+			desc:        "go-template missing field",
+			output:      "go-template={{.TaskID}}",
+			value:       taskStatus,
+			expectedErr: "failed to execute go-template",
+		},
+		{
+			desc:        "unknown format",
+			output:      "xml",
+			expectedErr: "unknown output format",
+		},
+		{
+			desc:        "go-template without template",
+			output:      "go-template",
+			expectedErr: "requires a template",
+		},
+		{
+			desc:        "json with argument",
+			output:      "json=x",
+			expectedErr: "does not take an argument",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := newPrinter(tt.output)
+			if err == nil {
+				var buf bytes.Buffer
+				err = p.print(&buf, tt.value, func() string {
+					return "table output"
+				})
+				if err == nil {
+					assert.Equal(t, tt.expected, buf.String())
+				}
+			}
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewErrorOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		err      error
+		expected errorDetail
+	}{
+		{
+			desc: "local error",
+			err:  errors.New("--user-id is required"),
+			expected: errorDetail{
+				Code:    "Unknown",
+				Message: "--user-id is required",
+			},
+		},
+		{
+			desc: "wrapped gRPC error with request ID",
+			err: fmt.Errorf("failed to get task status: %w", &client.RequestError{
+				Err:       fmt.Errorf("error getting task status: %w", status.Error(codes.NotFound, "task not found")),
+				RequestID: "req-1",
+			}),
+			expected: errorDetail{
+				Code:      "NotFound",
+				Message:   "task not found",
+				RequestID: "req-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, newErrorOutput(tt.err).Error)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// newPauseCmd returns the pause command of a task, or the resume command if paused is false
+func newPauseCmd(paused bool) *cobra.Command {
+	use, short, long := "pause", "Pause a running task by its task ID",
+		`Pause a running task by freezing all processes in its cgroup. The processes keep their memory and open files
+and stay in the running task quota of the client; they continue where they stopped once the task is resumed. A
+paused task can still be stopped, but other signals are rejected until it is resumed. The time a task spends
+paused is reported by get-status and counts toward "taskman wait --timeout".`
+	if !paused {
+		use, short, long = "resume", "Resume a paused task by its task ID",
+			`Resume a paused task by thawing all processes in its cgroup.`
+	}
+	return &cobra.Command{
+		Use:   use + ` <task-id> [--user-id <user-id>] [--server-address <host:port>] [--help]`,
+		Short: short,
+		Long: long + `
+
+Arguments:
+  <task-id>
+        The unique identifier (UUID) of the task.
+        Example: a7da14c7-b47a-4535-a263-5bb26e503002
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --help
+      Display help information for the ` + use + ` command.`,
+		Example:       fmt.Sprintf("$ taskman --user-id client001 %s a7da14c7-b47a-4535-a263-5bb26e503002", use),
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := args[0]
+			return withManager(cmd, func(manager *client.Manager) error {
+				verb := "paused"
+				if paused {
+					if err := manager.PauseTask(cmd.Context(), taskID); err != nil {
+						return err
+					}
+				} else {
+					verb = "resumed"
+					if err := manager.ResumeTask(cmd.Context(), taskID); err != nil {
+						return err
+					}
+				}
+				return out.print(cmd.OutOrStdout(), pauseOutput{TaskID: taskID, Paused: paused}, func() string {
+					return fmt.Sprintf("Task %s %s.\n", taskID, verb)
+				})
+			})
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var quotaClientID string
+
+var quotaCmd = &cobra.Command{
+	Use:   `quota [--user-id <user-id>] [--server-address <host:port>] [--client <client-id>] [--help]`,
+	Short: "Show the usage of the task quotas",
+	Long: `Show how many tasks the client is running and has started in the last minute against the quotas of the server.
+Starts beyond a running task quota are queued until the server's queue is full; starts beyond the other quotas are
+rejected with RESOURCE_EXHAUSTED. A limit of "-" is unlimited.
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --client <client-id>
+      Show the usage of another client. Only allowed for admin. Defaults to the caller.
+  --help
+      Display help information for the quota command.`,
+	Example:       `$ taskman --user-id client001 quota`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := client.NewManager(creds, serverAddr)
+		if err != nil {
+			return fmt.Errorf("failed to set up gRPC client: %w", err)
+		}
+		defer func() {
+			if closeErr := manager.Close(); closeErr != nil {
+				if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+					// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+					fmt.Printf("failed to log close error: %v\n", logErr)
+				}
+			}
+		}()
+
+		quota, err := manager.GetQuota(cmd.Context(), quotaClientID)
+		if err != nil {
+			return err
+		}
+		return out.print(cmd.OutOrStdout(), newQuotaOutput(quota), func() string {
+			return formatQuota(quota)
+		})
+	},
+}
+
+// formatQuota renders the usage of every quota as a table
+func formatQuota(quota *client.Quota) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Quota usage of client %s:\n", quota.ClientID)
+
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"QUOTA", "USED", "LIMIT"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	for _, row := range []struct {
+		name  string
+		usage client.QuotaUsage
+	}{
+		{"running tasks", quota.RunningTasks},
+		{"running tasks (all clients)", quota.GlobalRunningTasks},
+		{"starts per minute", quota.StartsPerMinute},
+		{"queued tasks (all clients)", quota.QueuedTasks},
+	} {
+		limit := "-"
+		if row.usage.Limit > 0 {
+			limit = strconv.FormatInt(row.usage.Limit, 10)
+		}
+		table.Append([]string{row.name, strconv.FormatInt(row.usage.Used, 10), limit})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func init() {
+	quotaCmd.Flags().StringVar(&quotaClientID, "client", "", "Show the usage of another client. Only allowed for admin.")
+}
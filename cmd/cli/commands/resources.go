@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// resourceFlags are the cgroup limit flags of update-resources, start, run and schedule create
+type resourceFlags struct {
+	cpuMillis  string
+	memory     string
+	memoryHigh string
+	ioReadBPS  string
+	ioWriteBPS string
+	pidsMax    string
+	cpus       string
+	mems       string
+}
+
+var updateResources resourceFlags
+
+// register adds the resource limit flags to cmd
+func (f *resourceFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.cpuMillis, "cpu-millis", "", "The CPU limit in thousandths of a CPU, or max.")
+	cmd.Flags().StringVar(&f.memory, "memory", "", "The memory.max limit in bytes with an optional K, M, G or T suffix, or max.")
+	cmd.Flags().StringVar(&f.memoryHigh, "memory-high", "", "The memory.high limit in bytes with an optional K, M, G or T suffix, or max.")
+	cmd.Flags().StringVar(&f.ioReadBPS, "io-read-bps", "", "The io.max read limit in bytes per second, or max.")
+	cmd.Flags().StringVar(&f.ioWriteBPS, "io-write-bps", "", "The io.max write limit in bytes per second, or max.")
+	cmd.Flags().StringVar(&f.pidsMax, "pids-max", "", "The pids.max limit of processes and threads, or max.")
+	cmd.Flags().StringVar(&f.cpus, "cpus", "", "The CPUs to pin the task to such as 0-3,8; empty to unpin it.")
+	cmd.Flags().StringVar(&f.mems, "mems", "", "The NUMA memory nodes to pin the task to such as 0; empty to unpin it.")
+}
+
+// update returns the limits set by the flags; limits whose flag is not set are nil
+func (f *resourceFlags) update(cmd *cobra.Command) (client.ResourceUpdate, error) {
+	var update client.ResourceUpdate
+	for _, l := range []struct {
+		flag   string
+		value  string
+		sizes  bool
+		target **int64
+	}{
+		{"cpu-millis", f.cpuMillis, false, &update.CPUMillis},
+		{"memory", f.memory, true, &update.MemoryMax},
+		{"memory-high", f.memoryHigh, true, &update.MemoryHigh},
+		{"io-read-bps", f.ioReadBPS, true, &update.IOReadBPS},
+		{"io-write-bps", f.ioWriteBPS, true, &update.IOWriteBPS},
+		{"pids-max", f.pidsMax, false, &update.PidsMax},
+	} {
+		if !cmd.Flags().Changed(l.flag) {
+			continue
+		}
+		limit, err := parseLimit(l.value, l.sizes)
+		if err != nil {
+			return client.ResourceUpdate{}, fmt.Errorf("--%s: %w", l.flag, err)
+		}
+		*l.target = &limit
+	}
+	if cmd.Flags().Changed("cpus") {
+		update.CPUs = &f.cpus
+	}
+	if cmd.Flags().Changed("mems") {
+		update.Mems = &f.mems
+	}
+	return update, nil
+}
+
+// sizeSuffixes are the binary size suffixes accepted by parseLimit, as for cgroup interface files
+var sizeSuffixes = map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+// parseLimit parses a resource limit such as "512M", "1048576" or "max"; "max" is unlimited and returned as 0.
+// Sizes take an optional K, M, G or T suffix of powers of 1024.
+func parseLimit(value string, sizes bool) (int64, error) {
+	if strings.EqualFold(value, "max") {
+		return 0, nil
+	}
+	number, multiplier := value, int64(1)
+	if sizes && value != "" {
+		if m, ok := sizeSuffixes[strings.ToUpper(value[len(value)-1:])]; ok {
+			number, multiplier = value[:len(value)-1], m
+		}
+	}
+	limit, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || limit <= 0 || limit > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("invalid limit %q: must be a positive number or max", value)
+	}
+	return limit * multiplier, nil
+}
+
+var updateResourcesCmd = &cobra.Command{
+	Use: `update-resources <task-id> [--cpu-millis <n>] [--memory <bytes>] [--memory-high <bytes>] [--io-read-bps <n>]
+  [--io-write-bps <n>] [--pids-max <n>] [--cpus <list>] [--mems <list>] [--user-id <user-id>] [--server-address <host:port>] [--help]`,
+	Short: "Change the cgroup limits of a task by its task ID",
+	Long: `Change the cgroup limits of a running, queued or waiting task. The limits of a running process apply right away
+and later processes of a restarted or retried task start with them. Limits that are not given are kept and "max" removes
+a limit. The server rejects limits above its ceilings, and owners may not exceed their resource quotas; admins may
+change the limits of any task. The changes are shown by get-status.
+
+Arguments:
+  <task-id>
+        The unique identifier (UUID) of the task.
+        Example: a7da14c7-b47a-4535-a263-5bb26e503002
+
+Options:
+  --cpu-millis <n>
+      The CPU time in thousandths of a CPU (e.g., 500 for half a CPU), written to cpu.max.
+  --memory <bytes>
+      The memory above which the processes are OOM killed (e.g., 512M), written to memory.max.
+  --memory-high <bytes>
+      The memory above which the processes are throttled and reclaimed (e.g., 256M), written to memory.high.
+  --io-read-bps <n>, --io-write-bps <n>
+      The bytes per second read from and written to the block device (e.g., 10M), written to io.max.
+  --pids-max <n>
+      The number of processes and threads above which forks fail (e.g., 100), written to pids.max.
+  --cpus <list>, --mems <list>
+      Pin the task to CPUs and NUMA memory nodes given as a list (e.g., 0-3,8), written to cpuset.cpus and cpuset.mems.
+      An empty list ("") unpins the task. Requires the cpuset controller on the server.
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --help
+      Display help information for the update-resources command.`,
+	Example:       `$ taskman --user-id client001 update-resources a7da14c7-b47a-4535-a263-5bb26e503002 --memory 256M --memory-high 200M`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskID := args[0]
+		update, err := updateResources.update(cmd)
+		if err != nil {
+			return err
+		}
+		if update == (client.ResourceUpdate{}) {
+			return errors.New("at least one limit is required")
+		}
+
+		return withManager(cmd, func(manager *client.Manager) error {
+			limits, err := manager.UpdateTaskResources(cmd.Context(), taskID, update)
+			if err != nil {
+				return err
+			}
+			return out.print(cmd.OutOrStdout(), updateResourcesOutput{TaskID: taskID, Limits: newResourceLimitsOutput(limits)}, func() string {
+				return fmt.Sprintf("Task %s limits: %s\n", taskID, limits)
+			})
+		})
+	},
+}
+
+func init() {
+	updateResources.register(updateResourcesCmd)
+}
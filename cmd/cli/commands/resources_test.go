@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		value       string
+		sizes       bool
+		expected    int64
+		expectedErr bool
+	}{
+		{desc: "number", value: "500", expected: 500},
+		{desc: "max", value: "max", expected: 0},
+		{desc: "max is case insensitive", value: "MAX", sizes: true, expected: 0},
+		{desc: "size suffix", value: "512M", sizes: true, expected: 512 << 20},
+		{desc: "lower case size suffix", value: "2g", sizes: true, expected: 2 << 30},
+		{desc: "size suffix without sizes", value: "512M", expectedErr: true},
+		{desc: "zero", value: "0", expectedErr: true},
+		{desc: "negative", value: "-1", expectedErr: true},
+		{desc: "empty", value: "", sizes: true, expectedErr: true},
+		{desc: "overflow", value: "9223372036854775807K", sizes: true, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			limit, err := parseLimit(tt.value, tt.sizes)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, limit)
+		})
+	}
+}
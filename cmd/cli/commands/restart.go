@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// restartModes maps the values of --restart to the restart modes of a task
+var restartModes = map[string]pb.RestartMode{
+	"never":      pb.RestartMode_RESTART_MODE_NEVER,
+	"on-failure": pb.RestartMode_RESTART_MODE_ON_FAILURE,
+	"always":     pb.RestartMode_RESTART_MODE_ALWAYS,
+}
+
+// restartFlags are the restart policy flags of the start command
+type restartFlags struct {
+	mode        string
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	maxRestarts int32
+	window      time.Duration
+}
+
+var startRestart restartFlags
+
+// register adds the restart flags to cmd
+func (f *restartFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.mode, "restart", "", "Restart the process when it exits: never, on-failure or always. Defaults to never.")
+	cmd.Flags().DurationVar(&f.backoff, "restart-backoff", 0,
+		"The delay before the first restart; it doubles on every restart. Defaults to 1s.")
+	cmd.Flags().DurationVar(&f.maxBackoff, "restart-max-backoff", 0, "The maximum delay between restarts. Defaults to 5m.")
+	cmd.Flags().Int32Var(&f.maxRestarts, "max-restarts", 0,
+		"The maximum number of restarts within --restart-window before the task gives up. Defaults to 10.")
+	cmd.Flags().DurationVar(&f.window, "restart-window", 0, "The window of --max-restarts. Defaults to 10m.")
+}
+
+// policy returns the restart policy of the flags; nil if the task is not restarted
+func (f *restartFlags) policy(cmd *cobra.Command) (*client.RestartPolicy, error) {
+	flags := cmd.Flags()
+	if !flags.Changed("restart") {
+		if flags.Changed("restart-backoff") || flags.Changed("restart-max-backoff") || flags.Changed("max-restarts") ||
+			flags.Changed("restart-window") {
+			return nil, fmt.Errorf("--restart-backoff, --restart-max-backoff, --max-restarts and --restart-window require --restart")
+		}
+		return nil, nil
+	}
+	mode, ok := restartModes[strings.ToLower(f.mode)]
+	if !ok {
+		return nil, fmt.Errorf("invalid --restart %q: must be never, on-failure or always", f.mode)
+	}
+	if f.maxRestarts < 0 {
+		return nil, fmt.Errorf("--max-restarts must not be negative")
+	}
+	return &client.RestartPolicy{
+		Mode:           mode,
+		InitialBackoff: f.backoff,
+		MaxBackoff:     f.maxBackoff,
+		MaxRestarts:    f.maxRestarts,
+		Window:         f.window,
+	}, nil
+}
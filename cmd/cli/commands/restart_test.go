@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+func TestRestartFlagsPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		args        []string
+		expected    *client.RestartPolicy
+		expectedErr bool
+	}{
+		{desc: "not restarted", args: nil, expected: nil},
+		{
+			desc:     "mode only",
+			args:     []string{"--restart", "Always"},
+			expected: &client.RestartPolicy{Mode: pb.RestartMode_RESTART_MODE_ALWAYS},
+		},
+		{
+			desc: "all flags",
+			args: []string{"--restart", "on-failure", "--restart-backoff", "2s", "--restart-max-backoff", "1m",
+				"--max-restarts", "3", "--restart-window", "5m"},
+			expected: &client.RestartPolicy{
+				Mode:           pb.RestartMode_RESTART_MODE_ON_FAILURE,
+				InitialBackoff: 2 * time.Second,
+				MaxBackoff:     time.Minute,
+				MaxRestarts:    3,
+				Window:         5 * time.Minute,
+			},
+		},
+		{desc: "unknown mode", args: []string{"--restart", "sometimes"}, expectedErr: true},
+		{desc: "negative max restarts", args: []string{"--restart", "always", "--max-restarts", "-1"}, expectedErr: true},
+		{desc: "backoff without mode", args: []string{"--restart-backoff", "2s"}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var flags restartFlags
+			cmd := &cobra.Command{}
+			flags.register(cmd)
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			policy, err := flags.policy(cmd)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// retryOutcomes maps the values of --retry-on to the outcomes of an attempt
+var retryOutcomes = map[string]pb.RetryOutcome{
+	"exit-error": pb.RetryOutcome_RETRY_OUTCOME_EXIT_ERROR,
+	"oom":        pb.RetryOutcome_RETRY_OUTCOME_OOM,
+	"signal":     pb.RetryOutcome_RETRY_OUTCOME_SIGNAL,
+}
+
+// retryFlags are the retry policy flags shared by the start and run commands
+type retryFlags struct {
+	maxAttempts int32
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	retryOn     []string
+}
+
+var (
+	startRetry retryFlags
+	runRetry   retryFlags
+)
+
+// register adds the retry flags to cmd
+func (f *retryFlags) register(cmd *cobra.Command) {
+	cmd.Flags().Int32Var(&f.maxAttempts, "max-attempts", 0,
+		"Retry the task until it succeeds or it ran this many times, including the first attempt.")
+	cmd.Flags().DurationVar(&f.backoff, "retry-backoff", 0,
+		"The delay before the first retry; it doubles on every retry. Defaults to 1s.")
+	cmd.Flags().DurationVar(&f.maxBackoff, "retry-max-backoff", 0, "The maximum delay between attempts. Defaults to 1m.")
+	cmd.Flags().StringSliceVar(&f.retryOn, "retry-on", nil,
+		"The outcomes of an attempt that are retried: exit-error, oom or signal. Defaults to exit-error.")
+}
+
+// policy returns the retry policy of the flags; nil if the task is not retried
+func (f *retryFlags) policy(cmd *cobra.Command) (*client.RetryPolicy, error) {
+	flags := cmd.Flags()
+	if !flags.Changed("max-attempts") {
+		if flags.Changed("retry-backoff") || flags.Changed("retry-max-backoff") || flags.Changed("retry-on") {
+			return nil, fmt.Errorf("--retry-backoff, --retry-max-backoff and --retry-on require --max-attempts")
+		}
+		return nil, nil
+	}
+	if f.maxAttempts < 1 {
+		return nil, fmt.Errorf("--max-attempts must be at least 1")
+	}
+	retryOn, err := parseRetryOn(f.retryOn)
+	if err != nil {
+		return nil, err
+	}
+	return &client.RetryPolicy{
+		MaxAttempts:    f.maxAttempts,
+		InitialBackoff: f.backoff,
+		MaxBackoff:     f.maxBackoff,
+		RetryOn:        retryOn,
+	}, nil
+}
+
+// parseRetryOn parses the --retry-on values such as "exit-error" or "oom"
+func parseRetryOn(values []string) ([]pb.RetryOutcome, error) {
+	outcomes := make([]pb.RetryOutcome, 0, len(values))
+	for _, value := range values {
+		outcome, ok := retryOutcomes[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("invalid --retry-on %q: must be exit-error, oom or signal", value)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestParseRetryOn(t *testing.T) {
+	t.Parallel()
+
+	outcomes, err := parseRetryOn([]string{"exit-error", "OOM", "signal"})
+	require.NoError(t, err)
+	assert.Equal(t, []pb.RetryOutcome{
+		pb.RetryOutcome_RETRY_OUTCOME_EXIT_ERROR,
+		pb.RetryOutcome_RETRY_OUTCOME_OOM,
+		pb.RetryOutcome_RETRY_OUTCOME_SIGNAL,
+	}, outcomes)
+
+	outcomes, err = parseRetryOn(nil)
+	require.NoError(t, err)
+	assert.Empty(t, outcomes)
+
+	_, err = parseRetryOn([]string{"timeout"})
+	require.Error(t, err)
+}
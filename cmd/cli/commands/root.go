@@ -1,16 +1,23 @@
 package commands
 
 import (
-	"errors"
-	"log"
+	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/cmd/cli/config"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
 )
 
 var (
 	// Shared flags across commands
-	userID     string
-	serverAddr string
+	userID       string
+	serverAddr   string
+	outputFormat string
+	contextName  string
+
+	// creds are the client credentials resolved from the flags, environment and selected context
+	creds client.Credentials
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -18,33 +25,113 @@ var RootCmd = &cobra.Command{
 	Use:   "taskman",
 	Short: "Taskman is a client for managing tasks via a gRPC server",
 	Long: `A CLI tool to start, check the status, stream output, and stop tasks executed by a remote gRPC server.
-This client connects to a taskman-server instance over a secure mTLS connection.`,
+This client connects to a taskman-server instance over a secure mTLS connection.
+
+Settings are taken from the flags, then the TASKMAN_* environment variables, then the selected context of
+the config file (~/.config/taskman/config.yaml, see "taskman config").`,
 	Example: `  $ taskman --user-id client001 start -- /bin/ls /myFolder
   $ taskman --user-id client001 --server-address localhost:50051 get-status 123e4567-e89b-12d3-a456-426614174000
   $ taskman --user-id client001 --server-address localhost:50051 stream 123e4567-e89b-12d3-a456-426614174000
-  $ taskman --user-id client001 --server-address localhost:50053 stop 123e4567-e89b-12d3-a456-426614174000`,
+  $ taskman --user-id client001 --server-address localhost:50053 stop 123e4567-e89b-12d3-a456-426614174000
+  $ taskman --user-id client001 run -- /bin/ls /myFolder
+  $ taskman --user-id client001 list -l team=infra
+  $ taskman --user-id client001 -o json get-status 123e4567-e89b-12d3-a456-426614174000
+  $ taskman --context prod get-status 123e4567-e89b-12d3-a456-426614174000`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if userID == "" {
-			return errors.New("--user-id is required")
-		}
-		return nil
+		return resolveSettings(cmd)
 	},
 }
 
+// resolveSettings resolves the server address, credentials and output format. A flag set on the
+// command line takes precedence over its environment variable, which takes precedence over the context.
+func resolveSettings(cmd *cobra.Command) error {
+	// the output format is set first so that errors loading the config are printed in it
+	p, err := newPrinter(settingValue(cmd, "output", outputFormat, config.EnvOutput, ""))
+	if err != nil {
+		return err
+	}
+	out = p
+
+	path, err := config.DefaultPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	ctx, err := cfg.Resolve(contextName)
+	if err != nil {
+		return err
+	}
+
+	if p, err = newPrinter(settingValue(cmd, "output", outputFormat, config.EnvOutput, ctx.Output)); err != nil {
+		return err
+	}
+	out = p
+	serverAddr = settingValue(cmd, "server-address", serverAddr, config.EnvServerAddress, ctx.Server)
+
+	// an explicit user ID selects an embedded certificate over the certificate files of the context
+	explicitUser := cmd.Flags().Changed("user-id") || os.Getenv(config.EnvUserID) != ""
+	creds = client.Credentials{
+		UserID: settingValue(cmd, "user-id", userID, config.EnvUserID, ctx.UserID),
+		CAFile: envOr(config.EnvCA, ctx.CA),
+	}
+	if explicitUser {
+		creds.CertFile = os.Getenv(config.EnvCert)
+		creds.KeyFile = os.Getenv(config.EnvKey)
+	} else {
+		creds.CertFile = envOr(config.EnvCert, ctx.Cert)
+		creds.KeyFile = envOr(config.EnvKey, ctx.Key)
+	}
+	return nil
+}
+
+// settingValue returns the flag value if it was set, otherwise the environment variable,
+// the context value or the flag default in this order
+func settingValue(cmd *cobra.Command, flag, flagValue, env, contextValue string) string {
+	if cmd.Flags().Changed(flag) {
+		return flagValue
+	}
+	if v := envOr(env, contextValue); v != "" {
+		return v
+	}
+	return flagValue
+}
+
+// envOr returns the environment variable if set, otherwise the fallback
+func envOr(env, fallback string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return fallback
+}
+
 func init() {
 
 	RootCmd.PersistentFlags().StringVar(&userID,
-		"user-id", "", "The user or client ID issuing the request (e.g., client001)")
+		"user-id", "", "The user or client ID issuing the request (e.g., client001). Selects the embedded client certificate; "+
+			"not needed if the context has a certificate and key.")
 	RootCmd.PersistentFlags().StringVar(&serverAddr,
 		"server-address", "localhost:50051", "The gRPC server address to connect to.Defaults to localhost:50051 if not set.")
-
-	err := RootCmd.MarkPersistentFlagRequired("user-id")
-	if err != nil {
-		log.Fatalf("failed to mark --user-id as required: %v", err)
-	}
+	RootCmd.PersistentFlags().StringVarP(&outputFormat,
+		"output", "o", outputTable, "The output format: table, json, yaml or go-template=<template> using the JSON field names.")
+	RootCmd.PersistentFlags().StringVar(&contextName,
+		"context", "", "The config file context to use instead of the current context.")
 
 	RootCmd.AddCommand(startCmd)
 	RootCmd.AddCommand(statusCmd)
 	RootCmd.AddCommand(streamCmd)
 	RootCmd.AddCommand(stopCmd)
+	RootCmd.AddCommand(newPauseCmd(true))
+	RootCmd.AddCommand(newPauseCmd(false))
+	RootCmd.AddCommand(updateResourcesCmd)
+	RootCmd.AddCommand(watchMemoryCmd)
+	RootCmd.AddCommand(runCmd)
+	RootCmd.AddCommand(waitCmd)
+	RootCmd.AddCommand(listCmd)
+	RootCmd.AddCommand(deleteCmd)
+	RootCmd.AddCommand(quotaCmd)
+	RootCmd.AddCommand(scheduleCmd)
+	RootCmd.AddCommand(configCmd)
 }
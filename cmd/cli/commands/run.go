@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var (
+	stopSignal   string
+	runLabels    []string
+	runPriority  int32
+	runDependsOn []string
+	runResources resourceFlags
+)
+
+// ExitCodeError makes the CLI exit with Code without printing an error message.
+// It is returned by commands that propagate the exit code of a remote task.
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+var runCmd = &cobra.Command{
+	Use: `run [--user-id <user-id>] [--server-address <host:port>] [--stop-signal <signal>] [--label <key=value>]... [--priority <n>]
+  [--depends-on <task-id>[:<condition>]]... [--max-attempts <n> [--retry-backoff <duration>] [--retry-max-backoff <duration>] [--retry-on <outcome>]...]
+  [--cpu-millis <n>] [--memory <bytes>] [--memory-high <bytes>] [--io-read-bps <n>] [--io-write-bps <n>] [--pids-max <n>]
+  [--cpus <list>] [--mems <list>] [--help] -- <command> [args...]`,
+	Short: "Start a task, stream its output and exit with the task's exit code",
+	Long: `Start a new task, stream its output until it completes and exit with the exit code of the task.
+If the task was killed by a signal the exit code is 128 plus the signal number, like in a shell.
+
+Pressing Ctrl-C stops the remote task. With --stop-signal the given signal is sent instead so the task
+can shut down gracefully; pressing Ctrl-C again stops the task. A Ctrl-C while the task is being started
+stops the task once the server returns its ID; pressing Ctrl-C again cancels the start.
+
+Arguments:
+  <command> [args...]
+        The command to execute, followed by any optional arguments.
+        The binary can be a full path or must exist in the system's PATH.
+        Example: "ls"
+
+Options:
+  --user-id <user-id>
+        The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+        The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --stop-signal <signal>
+        The signal to forward to the task on the first Ctrl-C (e.g., SIGTERM). The task is stopped if not set.
+  --label <key=value>
+        A label to attach to the task, e.g. team=infra. May be repeated.
+  --priority <n>
+        The priority of the task if the server queues it; higher priorities start first. Defaults to 0.
+  --depends-on <task-id>[:<condition>]
+        Start the task only after the given task finished with the condition: success (the default), completion or
+        failure. May be repeated. The output is streamed once the task starts.
+  --max-attempts <n>
+        Retry the task until an attempt succeeds or it ran n times. The output of all attempts is streamed and the
+        exit code is the one of the last attempt. See the start command for --retry-backoff, --retry-max-backoff and --retry-on.
+  --cpu-millis, --memory, --memory-high, --io-read-bps, --io-write-bps, --pids-max, --cpus, --mems
+        The cgroup limits the task starts with, as for "taskman update-resources". Limits that are not given are the
+        server defaults.
+  --help
+        Display help information for the run command.`,
+	Example:       `$ taskman --user-id client001 run --stop-signal SIGTERM -- make test`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		command := args[0]
+		if command == "" {
+			if err := cmd.Usage(); err != nil {
+				return fmt.Errorf("failed to display usage: %w", err)
+			}
+			return errors.New("command is required")
+		}
+		var cmdArgs []string
+		if len(args) > 1 {
+			cmdArgs = args[1:]
+		}
+		labels, err := parseLabels(runLabels)
+		if err != nil {
+			return err
+		}
+		retry, err := runRetry.policy(cmd)
+		if err != nil {
+			return err
+		}
+		dependsOn, err := parseDependencies(runDependsOn)
+		if err != nil {
+			return err
+		}
+		limits, err := runResources.update(cmd)
+		if err != nil {
+			return err
+		}
+
+		manager, err := client.NewManager(creds, serverAddr)
+		if err != nil {
+			return fmt.Errorf("failed to set up gRPC client: %w", err)
+		}
+		defer func() {
+			if closeErr := manager.Close(); closeErr != nil {
+				if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+					// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+					fmt.Printf("failed to log close error: %v\n", logErr)
+				}
+			}
+		}()
+
+		// the command context is canceled on Ctrl-C but the calls must continue so that the stop is
+		// forwarded and the remaining output and the final status are still received
+		ctx := context.WithoutCancel(cmd.Context())
+
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupts)
+
+		taskID, interrupted, err := startInterruptibly(ctx, cmd, interrupts, func(ctx context.Context) (string, error) {
+			return manager.StartTask(ctx, command, cmdArgs, client.StartOptions{
+				Labels:    labels,
+				Priority:  runPriority,
+				Retry:     retry,
+				DependsOn: dependsOn,
+				Limits:    limits,
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start task: %w", err)
+		}
+		// the task was started although the caller interrupted the start, so it is stopped right away
+		if interrupted {
+			printRunMessage(cmd, "stopping task %s", taskID)
+			if err := manager.StopTask(ctx, taskID); err != nil {
+				printRunMessage(cmd, "failed to forward interrupt: %v", err)
+			}
+		}
+
+		streamDone := make(chan error, 1)
+		go func() {
+			streamDone <- manager.StreamTaskOutput(ctx, taskID)
+		}()
+
+		for streaming := true; streaming; {
+			select {
+			case err := <-streamDone:
+				if err != nil {
+					return fmt.Errorf("failed to stream task output: %w", err)
+				}
+				streaming = false
+			case <-interrupts:
+				forwardInterrupt(ctx, cmd, manager, taskID, interrupted)
+				interrupted = true
+			}
+		}
+
+		// the output stream ends once the task has exited so the status is final
+		status, err := manager.GetTaskStatus(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to get task status: %w", err)
+		}
+		if code := status.ShellExitCode(); code != 0 {
+			return &ExitCodeError{Code: code}
+		}
+		return nil
+	},
+}
+
+// startInterruptibly runs start while handling interrupts. The start keeps running on the first interrupt
+// since the server may already have started the task; it reports whether it was interrupted so that the
+// task is stopped once its ID is known. A second interrupt cancels the start.
+func startInterruptibly(ctx context.Context, cmd *cobra.Command, interrupts <-chan os.Signal,
+	start func(ctx context.Context) (string, error)) (string, bool, error) {
+	startCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		taskID string
+		err    error
+	}
+	startDone := make(chan result, 1)
+	go func() {
+		taskID, err := start(startCtx)
+		startDone <- result{taskID: taskID, err: err}
+	}()
+
+	interrupted := false
+	for {
+		select {
+		case res := <-startDone:
+			if res.err != nil && startCtx.Err() != nil {
+				return "", interrupted, fmt.Errorf("start canceled, the task may have started: %w", res.err)
+			}
+			return res.taskID, interrupted, res.err
+		case <-interrupts:
+			if interrupted {
+				printRunMessage(cmd, "canceling the start")
+				cancel()
+				continue
+			}
+			printRunMessage(cmd, "the task is stopped once it has started (press Ctrl-C again to cancel the start)")
+			interrupted = true
+		}
+	}
+}
+
+// forwardInterrupt sends the stop signal to the task on the first interrupt and stops it on any later one
+func forwardInterrupt(ctx context.Context, cmd *cobra.Command, manager *client.Manager, taskID string, interrupted bool) {
+	var err error
+	if stopSignal != "" && !interrupted {
+		printRunMessage(cmd, "sending %s to task %s (press Ctrl-C again to stop it)", stopSignal, taskID)
+		err = manager.SignalTask(ctx, taskID, stopSignal)
+	} else {
+		printRunMessage(cmd, "stopping task %s", taskID)
+		err = manager.StopTask(ctx, taskID)
+	}
+	if err != nil {
+		printRunMessage(cmd, "failed to forward interrupt: %v", err)
+	}
+}
+
+// printRunMessage prints to stderr so that messages are not mixed with the task output
+func printRunMessage(cmd *cobra.Command, format string, args ...any) {
+	if _, err := fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", args...); err != nil {
+		// Fallback to fmt.Printf output if logging to cmd.ErrOrStderr fails.
+		fmt.Printf("failed to log message: %v\n", err)
+	}
+}
+
+func init() {
+	runCmd.Flags().StringVar(&stopSignal, "stop-signal", "",
+		"The signal to forward to the task on the first Ctrl-C, e.g. SIGTERM. The task is stopped if not set.")
+	runCmd.Flags().StringArrayVar(&runLabels, "label", nil, "A label key=value to attach to the task. May be repeated.")
+	runCmd.Flags().Int32Var(&runPriority, "priority", 0, "The priority of the task if it is queued; higher priorities start first.")
+	runCmd.Flags().StringArrayVar(&runDependsOn, "depends-on", nil,
+		"Start the task after the task <task-id>[:<condition>] finished with the condition success, completion or failure. May be repeated.")
+	runRetry.register(runCmd)
+	runResources.register(runCmd)
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartInterruptibly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		interrupts  int
+		expectedID  string
+		interrupted bool
+		expectedErr bool
+	}{
+		{desc: "not interrupted", expectedID: "task"},
+		{desc: "interrupted once", interrupts: 1, expectedID: "task", interrupted: true},
+		{desc: "interrupted twice", interrupts: 2, interrupted: true, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{}
+			cmd.SetErr(&bytes.Buffer{})
+			interrupts := make(chan os.Signal)
+			release := make(chan struct{})
+			go func() {
+				for range tt.interrupts {
+					interrupts <- os.Interrupt
+				}
+				if !tt.expectedErr {
+					close(release)
+				}
+			}()
+
+			// the start returns once all interrupts were received unless it is canceled
+			taskID, interrupted, err := startInterruptibly(context.Background(), cmd, interrupts,
+				func(ctx context.Context) (string, error) {
+					select {
+					case <-release:
+						return "task", nil
+					case <-ctx.Done():
+						return "", ctx.Err()
+					}
+				})
+			if tt.expectedErr {
+				require.ErrorIs(t, err, context.Canceled)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedID, taskID)
+			assert.Equal(t, tt.interrupted, interrupted)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+var (
+	scheduleCron        string
+	scheduleConcurrency string
+	schedulePaused      bool
+	scheduleLabels      []string
+	schedulePriority    int32
+	scheduleRetry       retryFlags
+	scheduleRestart     restartFlags
+	scheduleResources   resourceFlags
+)
+
+// concurrencyPolicies maps the values of --concurrency to the concurrency policies of a schedule
+var concurrencyPolicies = map[string]pb.ConcurrencyPolicy{
+	"allow":   pb.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW,
+	"forbid":  pb.ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID,
+	"replace": pb.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE,
+}
+
+// parseConcurrency parses the value of --concurrency; an empty value is allow
+func parseConcurrency(value string) (pb.ConcurrencyPolicy, error) {
+	if value == "" {
+		return pb.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW, nil
+	}
+	policy, ok := concurrencyPolicies[strings.ToLower(value)]
+	if !ok {
+		return pb.ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED,
+			fmt.Errorf("invalid concurrency policy %q: must be allow, forbid or replace", value)
+	}
+	return policy, nil
+}
+
+// concurrencyName returns the --concurrency name of a concurrency policy
+func concurrencyName(policy pb.ConcurrencyPolicy) string {
+	for name, p := range concurrencyPolicies {
+		if p == policy {
+			return name
+		}
+	}
+	return "allow"
+}
+
+// withManager connects to the server, calls fn and closes the connection
+func withManager(cmd *cobra.Command, fn func(manager *client.Manager) error) error {
+	manager, err := client.NewManager(creds, serverAddr)
+	if err != nil {
+		return fmt.Errorf("failed to set up gRPC client: %w", err)
+	}
+	defer func() {
+		if closeErr := manager.Close(); closeErr != nil {
+			if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+				// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+				fmt.Printf("failed to log close error: %v\n", logErr)
+			}
+		}
+	}()
+	return fn(manager)
+}
+
+// printSchedule prints a created, paused or resumed schedule in the selected output format
+func printSchedule(cmd *cobra.Command, schedule *client.Schedule) error {
+	return out.print(cmd.OutOrStdout(), newScheduleOutput(schedule), func() string {
+		return client.FormatSchedules([]*client.Schedule{schedule})
+	})
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage cron schedules that start tasks",
+	Long: `Manage cron schedules that start a task from a template on every run. The tasks are owned by the client that
+created the schedule and carry its schedule ID in their status. Schedules of other clients are only visible to admin.
+
+A schedule is kept in memory by the server and is lost when the server restarts.`,
+	Example: `  $ taskman --user-id client001 schedule create --cron "*/5 * * * *" --concurrency forbid -- ./backup.sh
+  $ taskman --user-id client001 schedule list
+  $ taskman --user-id client001 schedule pause 2f0b8a51-7d0f-4d4e-9d0c-1c6f3f8f1b2a`,
+}
+
+var scheduleCreateCmd = &cobra.Command{
+	Use: `create --cron <expression> [--concurrency <policy>] [--paused] [--label <key=value>]... [--priority <n>]
+  [--max-attempts <n> [--retry-backoff <duration>] [--retry-max-backoff <duration>] [--retry-on <outcome>]...]
+  [--restart <mode> [--restart-backoff <duration>] [--restart-max-backoff <duration>] [--max-restarts <n>] [--restart-window <duration>]]
+  [--cpu-millis <n>] [--memory <bytes>] [--memory-high <bytes>] [--io-read-bps <n>] [--io-write-bps <n>] [--pids-max <n>]
+  [--cpus <list>] [--mems <list>] [--help] -- <command> [args...]`,
+	Short: "Create a schedule that starts the command on every run",
+	Long: `Create a schedule that starts the command on every run of the cron expression. The tasks are started like
+"taskman start" with the labels, priority, retry or restart policy and cgroup limits of the schedule. Limits that are
+not given are the defaults of the server, and limits above the server ceilings are rejected.
+
+Arguments:
+  <command> [args...]
+        The command to execute on every run, followed by any optional arguments.
+
+Options:
+  --cron <expression>
+        A standard 5 field cron expression in the time zone of the server (e.g., "0 3 * * *") or a descriptor such
+        as @hourly, @daily or "@every 10m". Required.
+  --concurrency <policy>
+        What a run does while a task started by an earlier run is still unfinished: allow starts another task,
+        forbid skips the run and replace stops the unfinished tasks with the termination source "schedule" before
+        starting a new one. Defaults to allow.
+  --paused
+        Create the schedule paused; it does not run until it is resumed.
+  --label <key=value>
+        A label to attach to every task of the schedule. May be repeated.
+  --priority <n>
+        The priority of the tasks if the server queues them. Defaults to 0.
+  --max-attempts, --retry-backoff, --retry-max-backoff, --retry-on
+        The retry policy of the tasks, as for "taskman start".
+  --restart, --restart-backoff, --restart-max-backoff, --max-restarts, --restart-window
+        The restart policy of the tasks, as for "taskman start". May not be combined with --max-attempts.
+  --cpu-millis, --memory, --memory-high, --io-read-bps, --io-write-bps, --pids-max, --cpus, --mems
+        The cgroup limits of the tasks, as for "taskman update-resources".
+  --help
+        Display help information for the schedule create command.`,
+	Example: `  $ taskman --user-id client001 schedule create --cron "@every 1h" --label team=infra -- ./sync.sh
+  $ taskman --user-id client001 schedule create --cron "0 3 * * *" --concurrency replace -- make nightly
+  $ taskman --user-id client001 schedule create --cron "@daily" --memory 512M --pids-max 100 -- ./report.sh`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] == "" {
+			return errors.New("command is required")
+		}
+		if scheduleCron == "" {
+			return errors.New("--cron is required")
+		}
+		concurrency, err := parseConcurrency(scheduleConcurrency)
+		if err != nil {
+			return err
+		}
+		labels, err := parseLabels(scheduleLabels)
+		if err != nil {
+			return err
+		}
+		retry, err := scheduleRetry.policy(cmd)
+		if err != nil {
+			return err
+		}
+		restart, err := scheduleRestart.policy(cmd)
+		if err != nil {
+			return err
+		}
+		limits, err := scheduleResources.update(cmd)
+		if err != nil {
+			return err
+		}
+
+		return withManager(cmd, func(manager *client.Manager) error {
+			schedule, err := manager.CreateSchedule(cmd.Context(), args[0], args[1:], client.ScheduleOptions{
+				Cron:        scheduleCron,
+				Labels:      labels,
+				Priority:    schedulePriority,
+				Retry:       retry,
+				Restart:     restart,
+				Limits:      limits,
+				Concurrency: concurrency,
+				Paused:      schedulePaused,
+			})
+			if err != nil {
+				return err
+			}
+			return printSchedule(cmd, schedule)
+		})
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:           "list [--help]",
+	Short:         "List the schedules of the client",
+	Long:          `List the schedules of the client with their next and last run. Admin lists the schedules of all clients.`,
+	Example:       `  $ taskman --user-id client001 schedule list -o json`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withManager(cmd, func(manager *client.Manager) error {
+			schedules, err := manager.ListSchedules(cmd.Context())
+			if err != nil {
+				return err
+			}
+			output := make([]scheduleOutput, 0, len(schedules))
+			for _, s := range schedules {
+				output = append(output, newScheduleOutput(s))
+			}
+			return out.print(cmd.OutOrStdout(), output, func() string {
+				return client.FormatSchedules(schedules)
+			})
+		})
+	},
+}
+
+var scheduleDeleteCmd = &cobra.Command{
+	Use:   "delete <schedule-id> [--help]",
+	Short: "Delete a schedule by its ID",
+	Long: `Delete a schedule so that it does not run again. Tasks it already started are not stopped; stop them with
+"taskman stop" if needed.`,
+	Example:       `  $ taskman --user-id client001 schedule delete 2f0b8a51-7d0f-4d4e-9d0c-1c6f3f8f1b2a`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withManager(cmd, func(manager *client.Manager) error {
+			if err := manager.DeleteSchedule(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			return out.print(cmd.OutOrStdout(), scheduleDeleteOutput{ScheduleID: args[0], Deleted: true}, func() string {
+				return fmt.Sprintf("Schedule %s deleted\n", args[0])
+			})
+		})
+	},
+}
+
+// newSchedulePauseCmd returns the pause or resume command of a schedule
+func newSchedulePauseCmd(paused bool) *cobra.Command {
+	use, short, long := "pause", "Pause a schedule by its ID",
+		`Pause a schedule so that it does not run until it is resumed. Tasks it already started keep running.`
+	if !paused {
+		use, short, long = "resume", "Resume a paused schedule by its ID",
+			`Resume a paused schedule. Runs missed while it was paused are not made up; the next run is computed from now.`
+	}
+	return &cobra.Command{
+		Use:           use + " <schedule-id> [--help]",
+		Short:         short,
+		Long:          long,
+		Example:       fmt.Sprintf("  $ taskman --user-id client001 schedule %s 2f0b8a51-7d0f-4d4e-9d0c-1c6f3f8f1b2a", use),
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withManager(cmd, func(manager *client.Manager) error {
+				schedule, err := manager.PauseSchedule(cmd.Context(), args[0], paused)
+				if err != nil {
+					return err
+				}
+				return printSchedule(cmd, schedule)
+			})
+		},
+	}
+}
+
+func init() {
+	scheduleCreateCmd.Flags().StringVar(&scheduleCron, "cron", "", "The cron expression or descriptor of the schedule.")
+	scheduleCreateCmd.Flags().StringVar(&scheduleConcurrency, "concurrency", "",
+		"What a run does while an earlier task is unfinished: allow, forbid or replace. Defaults to allow.")
+	scheduleCreateCmd.Flags().BoolVar(&schedulePaused, "paused", false, "Create the schedule paused.")
+	scheduleCreateCmd.Flags().StringArrayVar(&scheduleLabels, "label", nil, "A label key=value to attach to every task. May be repeated.")
+	scheduleCreateCmd.Flags().Int32Var(&schedulePriority, "priority", 0, "The priority of the tasks if they are queued.")
+	scheduleRetry.register(scheduleCreateCmd)
+	scheduleRestart.register(scheduleCreateCmd)
+	scheduleResources.register(scheduleCreateCmd)
+
+	scheduleCmd.AddCommand(scheduleCreateCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleDeleteCmd)
+	scheduleCmd.AddCommand(newSchedulePauseCmd(true))
+	scheduleCmd.AddCommand(newSchedulePauseCmd(false))
+}
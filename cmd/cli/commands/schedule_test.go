@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestParseConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		value       string
+		expected    pb.ConcurrencyPolicy
+		expectedErr bool
+	}{
+		{desc: "default", value: "", expected: pb.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW},
+		{desc: "forbid", value: "forbid", expected: pb.ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID},
+		{desc: "case insensitive", value: "Replace", expected: pb.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE},
+		{desc: "unknown", value: "queue", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := parseConcurrency(tt.value)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+			assert.Equal(t, tt.value != "", concurrencyName(policy) == strings.ToLower(tt.value))
+		})
+	}
+}
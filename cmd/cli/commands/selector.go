@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// statusNames maps the --status values to task statuses
+var statusNames = map[string]pb.JobStatus{
+	"running":      pb.JobStatus_JOB_STATUS_STARTED,
+	"queued":       pb.JobStatus_JOB_STATUS_QUEUED,
+	"waiting":      pb.JobStatus_JOB_STATUS_WAITING,
+	"paused":       pb.JobStatus_JOB_STATUS_PAUSED,
+	"canceled":     pb.JobStatus_JOB_STATUS_CANCELED,
+	"failed":       pb.JobStatus_JOB_STATUS_FAILED,
+	"signaled":     pb.JobStatus_JOB_STATUS_SIGNALED,
+	"exited-ok":    pb.JobStatus_JOB_STATUS_EXITED_OK,
+	"exited-error": pb.JobStatus_JOB_STATUS_EXITED_ERROR,
+}
+
+// parseStatuses converts --status values such as "running" or "JOB_STATUS_STARTED" to task statuses
+func parseStatuses(names []string) ([]pb.JobStatus, error) {
+	statuses := make([]pb.JobStatus, 0, len(names))
+	for _, name := range names {
+		if status, ok := statusNames[strings.ToLower(name)]; ok {
+			statuses = append(statuses, status)
+			continue
+		}
+		if value, ok := pb.JobStatus_value[strings.ToUpper(name)]; ok {
+			statuses = append(statuses, pb.JobStatus(value))
+			continue
+		}
+		return nil, fmt.Errorf("invalid status %q: must be one of running, queued, waiting, paused, canceled, failed, signaled, exited-ok or exited-error", name)
+	}
+	return statuses, nil
+}
+
+// parseStartedBefore parses --started-before as either a duration before now such as "1h" or an RFC 3339 time
+func parseStartedBefore(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid started-before %q: duration must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid started-before %q: must be a duration such as 1h or an RFC 3339 time", value)
+	}
+	return t, nil
+}
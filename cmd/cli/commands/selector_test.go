@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestParseStatuses(t *testing.T) {
+	t.Parallel()
+
+	statuses, err := parseStatuses([]string{"running", "Exited-Error", "JOB_STATUS_SIGNALED", "job_status_exited_ok"})
+	require.NoError(t, err)
+	assert.Equal(t, []pb.JobStatus{
+		pb.JobStatus_JOB_STATUS_STARTED,
+		pb.JobStatus_JOB_STATUS_EXITED_ERROR,
+		pb.JobStatus_JOB_STATUS_SIGNALED,
+		pb.JobStatus_JOB_STATUS_EXITED_OK,
+	}, statuses)
+
+	_, err = parseStatuses([]string{"stopped"})
+	require.Error(t, err)
+}
+
+func TestParseStartedBefore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc        string
+		value       string
+		expected    time.Time
+		expectedErr bool
+	}{
+		{desc: "empty", value: "", expected: time.Time{}},
+		{desc: "duration", value: "90m", expected: now.Add(-90 * time.Minute)},
+		{desc: "rfc3339", value: "2025-03-31T08:30:00Z", expected: time.Date(2025, 3, 31, 8, 30, 0, 0, time.UTC)},
+		{desc: "negative duration", value: "-1h", expectedErr: true},
+		{desc: "invalid", value: "yesterday", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseStartedBefore(tt.value, now)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "expected %v, got %v", tt.expected, got)
+		})
+	}
+}
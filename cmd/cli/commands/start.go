@@ -11,10 +11,23 @@ import (
 	"github.com/mikewurtz/taskman/internal/grpc/client"
 )
 
+var (
+	startQuiet          bool
+	startLabels         []string
+	startIdempotencyKey string
+	startPriority       int32
+	startDependsOn      []string
+	startResources      resourceFlags
+)
+
 var startCmd = &cobra.Command{
-	Use:   `start --user-id <user-id> [--server-address <host:port>] [--help] -- <command> [args...]`,
+	Use: `start [--user-id <user-id>] [--server-address <host:port>] [--label <key=value>]... [--idempotency-key <key>] [--priority <n>]
+  [--depends-on <task-id>[:<condition>]]... [--max-attempts <n> [--retry-backoff <duration>] [--retry-max-backoff <duration>] [--retry-on <outcome>]...]
+  [--restart <mode> [--restart-backoff <duration>] [--restart-max-backoff <duration>] [--max-restarts <n>] [--restart-window <duration>]]
+  [--cpu-millis <n>] [--memory <bytes>] [--memory-high <bytes>] [--io-read-bps <n>] [--io-write-bps <n>] [--pids-max <n>]
+  [--cpus <list>] [--mems <list>] [--quiet] [--help] -- <command> [args...]`,
 	Short: "Start a new task by executing the specified command",
-	Long: `Start a new task by executing the specified command. The --user-id flag is required to identify the client initiating the request.
+	Long: `Start a new task by executing the specified command. The client is identified by the --user-id flag or the certificate of the current context.
 
 Arguments:
   <command> [args...]
@@ -25,12 +38,57 @@ Arguments:
 
 Options:
   --user-id <user-id>
-        The user or client ID issuing the request (e.g., client001). This flag is required.
+        The user or client ID issuing the request (e.g., client001). Required unless set by the context.
   --server-address <host:port>
         The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --label <key=value>
+        A label to attach to the task, e.g. team=infra. May be repeated. Keys are a name with an optional
+        DNS prefix (e.g., example.com/build); tasks can be selected by label with -l on the read commands.
+  --idempotency-key <key>
+        A key identifying this start, e.g. a CI job ID. Starting again with the same key and parameters returns the
+        task of the first start instead of a new one. A random key is used for the automatic retries if not set.
+  --priority <n>
+        The priority of the task if the server queues it because a running task quota is reached. Queued tasks with a
+        higher priority start first; tasks of the same priority are shared fairly between clients. Defaults to 0.
+  --depends-on <task-id>[:<condition>]
+        Start the task only after the given task finished with the condition: success (the default), completion or
+        failure. May be repeated. The task waits with the status JOB_STATUS_WAITING and fails with the termination
+        source "dependency" if a condition is not met.
+  --max-attempts <n>
+        Retry the task until an attempt succeeds or it ran n times, including the first attempt. Retries keep the task
+        ID and append to its output after a "--- taskman: attempt N of M ---" line. Not retried if not set.
+  --retry-backoff <duration>
+        The delay before the first retry, doubling on every retry up to --retry-max-backoff. Defaults to 1s.
+  --retry-max-backoff <duration>
+        The maximum delay between attempts. Defaults to 1m.
+  --retry-on <outcome>
+        The outcomes of an attempt that are retried: exit-error, oom or signal. May be repeated or comma-separated.
+        Defaults to exit-error. Tasks stopped by a user are never retried.
+  --restart <mode>
+        Keep a long-running service up by restarting its process when it exits: never (the default), on-failure or
+        always. Restarts keep the task ID and append to its output after a "--- taskman: restart N after <reason> ---"
+        line; stop disables restarts before it kills the process. May not be combined with --max-attempts.
+  --restart-backoff <duration>
+        The delay before the first restart, doubling on every restart up to --restart-max-backoff. The delay resets once
+        a process ran for longer than --restart-max-backoff. Defaults to 1s.
+  --restart-max-backoff <duration>
+        The maximum delay between restarts. Defaults to 5m.
+  --max-restarts <n>, --restart-window <duration>
+        The task finishes with the result of its last process once it was restarted n times within the window.
+        Default to 10 restarts within 10m.
+  --cpu-millis, --memory, --memory-high, --io-read-bps, --io-write-bps, --pids-max, --cpus, --mems
+        The cgroup limits the task starts with, as for "taskman update-resources". Limits that are not given are the
+        server defaults; "max" removes a limit.
+  --quiet, -q
+        Only print the task ID, e.g. for use in scripts as TASK_ID=$(taskman start -q -- ls).
   --help
         Display help information for the start command.`,
-	Example:       `$ taskman start --user-id client001 -- ls /myFolder`,
+	Example: `  $ taskman start --user-id client001 -- ls /myFolder
+  $ taskman start --user-id client001 --label team=infra --label build=1234 -- make test
+  $ taskman start --user-id client001 --depends-on a7da14c7-b47a-4535-a263-5bb26e503002:success -- make deploy
+  $ taskman start --user-id client001 --max-attempts 3 --retry-on exit-error,oom -- ./flaky-test.sh
+  $ taskman start --user-id client001 --restart always --max-restarts 5 --restart-window 1m -- ./my-daemon
+  $ taskman start --user-id client001 --memory 512M --memory-high 400M --pids-max 100 --cpus 0-1 -- ./build.sh`,
 	Args:          cobra.MinimumNArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -47,8 +105,28 @@ Options:
 		if len(args) > 1 {
 			cmdArgs = args[1:]
 		}
+		labels, err := parseLabels(startLabels)
+		if err != nil {
+			return err
+		}
+		retry, err := startRetry.policy(cmd)
+		if err != nil {
+			return err
+		}
+		restart, err := startRestart.policy(cmd)
+		if err != nil {
+			return err
+		}
+		dependsOn, err := parseDependencies(startDependsOn)
+		if err != nil {
+			return err
+		}
+		limits, err := startResources.update(cmd)
+		if err != nil {
+			return err
+		}
 
-		manager, err := client.NewManager(userID, serverAddr)
+		manager, err := client.NewManager(creds, serverAddr)
 		if err != nil {
 			return fmt.Errorf("failed to set up gRPC client: %w", err)
 		}
@@ -61,19 +139,45 @@ Options:
 			}
 		}()
 
-		taskID, err := manager.StartTask(cmd.Context(), command, cmdArgs)
+		taskID, err := manager.StartTask(cmd.Context(), command, cmdArgs, client.StartOptions{
+			Labels:         labels,
+			IdempotencyKey: startIdempotencyKey,
+			Priority:       startPriority,
+			Retry:          retry,
+			Restart:        restart,
+			DependsOn:      dependsOn,
+			Limits:         limits,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to start task: %w", err)
 		}
 
-		output := printTaskID(taskID)
-		if _, err = fmt.Fprint(cmd.OutOrStdout(), output); err != nil {
-			return fmt.Errorf("failed to print output: %w", err)
+		if startQuiet {
+			if _, err = fmt.Fprintln(cmd.OutOrStdout(), taskID); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			return nil
 		}
-		return nil
+		return out.print(cmd.OutOrStdout(), taskIDOutput{TaskID: taskID}, func() string {
+			return printTaskID(taskID)
+		})
 	},
 }
 
+func init() {
+	startCmd.Flags().BoolVarP(&startQuiet, "quiet", "q", false,
+		"Only print the task ID, ignoring the output format.")
+	startCmd.Flags().StringArrayVar(&startLabels, "label", nil, "A label key=value to attach to the task. May be repeated.")
+	startCmd.Flags().StringVar(&startIdempotencyKey, "idempotency-key", "",
+		"A key identifying this start; repeating it with the same parameters returns the same task.")
+	startCmd.Flags().Int32Var(&startPriority, "priority", 0, "The priority of the task if it is queued; higher priorities start first.")
+	startCmd.Flags().StringArrayVar(&startDependsOn, "depends-on", nil,
+		"Start the task after the task <task-id>[:<condition>] finished with the condition success, completion or failure. May be repeated.")
+	startRetry.register(startCmd)
+	startRestart.register(startCmd)
+	startResources.register(startCmd)
+}
+
 // printTaskID is a helper function to print the task ID in a table format
 func printTaskID(taskID string) string {
 	var buf bytes.Buffer
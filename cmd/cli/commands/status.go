@@ -9,12 +9,15 @@ import (
 	"github.com/mikewurtz/taskman/internal/grpc/client"
 )
 
+var statusSelector string
+
 var statusCmd = &cobra.Command{
-	Use:   `get-status <task-id> --user-id <user-id> [--server-address <host:port>] [--help]`,
+	Use:   `get-status (<task-id> | -l <selector>) [--user-id <user-id>] [--server-address <host:port>] [--help]`,
 	Short: "Get the status of a task by its task ID",
 	Long: `Retrieve the status of a task using its unique task ID. The command displays details such as 
 the task status, start time, process ID. If the task has ended, this command will display end time, exit code,
-termination signal and termination source if applicable.
+termination signal and termination source if applicable. With -l the statuses of all tasks matching the label
+selector are displayed instead.
 
 Arguments:
   <task-id>             
@@ -22,18 +25,31 @@ Arguments:
 
 Options:
   --user-id <user-id>   
-      The user or client ID issuing the request (e.g., client001). This flag is required.
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
   --server-address <host:port>
       The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  -l, --selector <selector>
+      Show the tasks matching a comma-separated label selector (e.g., team=infra,env!=prod) instead of one task.
   --help
       Display help information for the get-status command.`,
-	Example:       `$ taskman --user-id client001 get-status a7da14c7-b47a-4535-a263-5bb26e503002`,
-	Args:          cobra.ExactArgs(1),
+	Example: `  $ taskman --user-id client001 get-status a7da14c7-b47a-4535-a263-5bb26e503002
+  $ taskman --user-id client001 get-status -l team=infra,build=1234`,
+	Args:          cobra.MaximumNArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		taskID := args[0]
+		if cmd.Flags().Changed("selector") {
+			if len(args) > 0 {
+				return errors.New("a task ID and a label selector cannot be used together")
+			}
+			return listTasks(cmd, statusSelector)
+		}
+
+		var taskID string
+		if len(args) > 0 {
+			taskID = args[0]
+		}
 		if taskID == "" {
 			if err := cmd.Usage(); err != nil {
 				return fmt.Errorf("failed to display usage: %w", err)
@@ -41,7 +57,7 @@ Options:
 			return errors.New("task ID is required")
 		}
 
-		manager, err := client.NewManager(userID, serverAddr)
+		manager, err := client.NewManager(creds, serverAddr)
 		if err != nil {
 			return fmt.Errorf("failed to set up gRPC client: %w", err)
 		}
@@ -59,10 +75,13 @@ Options:
 			return fmt.Errorf("failed to get task status: %w", err)
 		}
 
-		if _, logErr := fmt.Fprintf(cmd.OutOrStdout(), "%s\n", status.String()); logErr != nil {
-			// fall back to fmt.Println if logging to cmd.OutOrStdout fails
-			return fmt.Errorf("failed to print task status: %w", logErr)
-		}
-		return nil
+		return out.print(cmd.OutOrStdout(), newTaskStatusOutput(status), func() string {
+			return status.String() + "\n"
+		})
 	},
 }
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusSelector, "selector", "l", "",
+		"Show the tasks matching a comma-separated label selector, e.g. team=infra, instead of one task.")
+}
@@ -1,18 +1,34 @@
 package commands
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
 
 	"github.com/mikewurtz/taskman/internal/grpc/client"
 )
 
+var (
+	stopSelector      string
+	stopStatuses      []string
+	stopOwner         string
+	stopStartedBefore string
+	stopDryRun        bool
+	stopSignalName    string
+)
+
 var stopCmd = &cobra.Command{
-	Use:   `stop <task-id> --user-id <user-id> [--server-address <host:port>] [--help]`,
-	Short: "Stop a running task by its task ID",
-	Long: `Stop a running task identified by its unique task ID.
+	Use: `stop (<task-id> | [-l <selector>] [--status <status>]... [--owner <client-id>] [--started-before <time>])
+  [--dry-run] [--signal <signal>] [--user-id <user-id>] [--server-address <host:port>] [--help]`,
+	Short: "Stop a running task by its task ID or all tasks matching a selector",
+	Long: `Stop a running task identified by its unique task ID, or all tasks matching a selector. A selector is made of
+a label selector, statuses, an owner and a start time; all given parts must match. Only running, queued and waiting tasks,
+or only running tasks with --signal, are selected unless --status is set. Authorization is checked for every task and the result of each task is printed.
 
 Arguments:
   <task-id>
@@ -21,26 +37,72 @@ Arguments:
 
 Options:
   --user-id <user-id>
-      The user or client ID issuing the request (e.g., client001). This flag is required.
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
   --server-address <host:port>
       The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  -l, --selector <selector>
+      Stop the tasks matching a comma-separated label selector (e.g., team=infra,env!=prod).
+  --status <status>
+      Stop the tasks with this status: running, queued, waiting, paused, canceled, failed, signaled, exited-ok
+      or exited-error. May be repeated.
+      Running, queued and waiting tasks are stopped if not set; queued and waiting tasks are never started.
+  --owner <client-id>
+      Stop the tasks of this client. Only the admin client can select the tasks of other clients.
+  --started-before <time>
+      Stop the tasks started before a time, given as a duration ago (e.g., 1h) or in RFC 3339 format.
+  --dry-run
+      Only print the tasks that would be stopped or signaled.
+  --signal <signal>
+      Send this signal (e.g., SIGTERM) instead of stopping the tasks with SIGKILL.
   --help
       Display help information for the stop command.`,
-	Example:       `$ taskman --user-id client001 stop a7da14c7-b47a-4535-a263-5bb26e503002`,
-	Args:          cobra.ExactArgs(1),
+	Example: `  $ taskman --user-id client001 stop a7da14c7-b47a-4535-a263-5bb26e503002
+  $ taskman --user-id client001 stop --selector team=infra --dry-run
+  $ taskman --user-id admin stop --owner client001 --started-before 2h --signal SIGTERM`,
+	Args:          cobra.MaximumNArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		taskID := args[0]
-		if taskID == "" {
+		flags := cmd.Flags()
+		bySelector := flags.Changed("selector") || flags.Changed("status") || flags.Changed("owner") ||
+			flags.Changed("started-before")
+		if bySelector && len(args) > 0 {
+			return errors.New("a task ID and a selector cannot be used together")
+		}
+		var taskID string
+		if len(args) > 0 {
+			taskID = args[0]
+		}
+		if taskID == "" && !bySelector {
 			if err := cmd.Usage(); err != nil {
 				return fmt.Errorf("failed to display usage: %w", err)
 			}
 			return errors.New("task ID is required")
 		}
+		if stopDryRun && !bySelector {
+			return errors.New("--dry-run requires a selector")
+		}
 
-		manager, err := client.NewManager(userID, serverAddr)
+		var selector client.TaskSelector
+		if bySelector {
+			statuses, err := parseStatuses(stopStatuses)
+			if err != nil {
+				return err
+			}
+			startedBefore, err := parseStartedBefore(stopStartedBefore, time.Now())
+			if err != nil {
+				return err
+			}
+			selector = client.TaskSelector{
+				LabelSelector: stopSelector,
+				Statuses:      statuses,
+				Owner:         stopOwner,
+				StartedBefore: startedBefore,
+			}
+		}
+
+		manager, err := client.NewManager(creds, serverAddr)
 		if err != nil {
 			return fmt.Errorf("failed to set up gRPC client: %w", err)
 		}
@@ -53,6 +115,105 @@ Options:
 			}
 		}()
 
-		return manager.StopTask(cmd.Context(), taskID)
+		if bySelector {
+			return stopTasksBySelector(cmd, manager, selector)
+		}
+
+		if stopSignalName != "" {
+			if err := manager.SignalTask(cmd.Context(), taskID, stopSignalName); err != nil {
+				return err
+			}
+			return out.print(cmd.OutOrStdout(), stopOutput{TaskID: taskID, Stopped: true}, func() string {
+				return fmt.Sprintf("Sent %s to task %s.\n", stopSignalName, taskID)
+			})
+		}
+
+		if err := manager.StopTask(cmd.Context(), taskID); err != nil {
+			return err
+		}
+		return out.print(cmd.OutOrStdout(), stopOutput{TaskID: taskID, Stopped: true}, func() string {
+			return fmt.Sprintf("Task %s stopped successfully.\n", taskID)
+		})
 	},
 }
+
+// stopTasksBySelector stops or signals every task matching the selector and prints one result per task
+func stopTasksBySelector(cmd *cobra.Command, manager *client.Manager, selector client.TaskSelector) error {
+	var (
+		results []client.TaskResult
+		err     error
+	)
+	if stopSignalName != "" {
+		results, err = manager.SignalTasks(cmd.Context(), selector, stopSignalName, stopDryRun)
+	} else {
+		results, err = manager.StopTasks(cmd.Context(), selector, stopDryRun)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stop tasks: %w", err)
+	}
+
+	output := bulkOutput{DryRun: stopDryRun, Results: make([]taskResultOutput, 0, len(results))}
+	failed := 0
+	for _, result := range results {
+		if result.Code != codes.OK {
+			failed++
+		}
+		output.Results = append(output.Results, taskResultOutput{
+			TaskID:  result.TaskID,
+			Code:    result.Code.String(),
+			Message: result.Message,
+		})
+	}
+
+	if err := out.print(cmd.OutOrStdout(), output, func() string {
+		return printStopResults(results)
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to stop %d of %d tasks", failed, len(results))
+	}
+	return nil
+}
+
+// printStopResults renders the result of stopping each task as a table
+func printStopResults(results []client.TaskResult) string {
+	done := "stopped"
+	if stopSignalName != "" {
+		done = "signaled"
+	}
+	if stopDryRun {
+		done = "would be " + done
+	}
+
+	var buf bytes.Buffer
+
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"TASK ID", "RESULT", "MESSAGE"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	for _, result := range results {
+		outcome := done
+		if result.Code != codes.OK {
+			outcome = result.Code.String()
+		}
+		table.Append([]string{result.TaskID, outcome, formatField(result.Message)})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func init() {
+	stopCmd.Flags().StringVarP(&stopSelector, "selector", "l", "",
+		"Stop the tasks matching a comma-separated label selector, e.g. team=infra.")
+	stopCmd.Flags().StringArrayVar(&stopStatuses, "status", nil,
+		"Stop the tasks with this status: running, queued, waiting, paused, canceled, failed, signaled, exited-ok or "+
+			"exited-error. May be repeated.")
+	stopCmd.Flags().StringVar(&stopOwner, "owner", "", "Stop the tasks of this client. Only admin can select other clients.")
+	stopCmd.Flags().StringVar(&stopStartedBefore, "started-before", "",
+		"Stop the tasks started before a duration ago, e.g. 1h, or an RFC 3339 time.")
+	stopCmd.Flags().BoolVar(&stopDryRun, "dry-run", false, "Only print the tasks that would be stopped.")
+	stopCmd.Flags().StringVar(&stopSignalName, "signal", "", "Send this signal, e.g. SIGTERM, instead of stopping with SIGKILL.")
+}
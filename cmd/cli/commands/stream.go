@@ -9,7 +9,7 @@ import (
 )
 
 var streamCmd = &cobra.Command{
-	Use:   `stream <task-id> --user-id <user-id> [--server-address <host:port>] [--help]`,
+	Use:   `stream <task-id> [--user-id <user-id>] [--server-address <host:port>] [--help]`,
 	Short: "Stream the output of a task by its task ID",
 	Long: `Stream real-time output from a running task identified by its unique task ID.
 This command continuously sends the task's stdout and stderr output to your terminal.
@@ -21,7 +21,7 @@ Arguments:
 
 Options:
   --user-id <user-id>
-      The user or client ID issuing the request (e.g., client001). This flag is required.
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
   --server-address <host:port>
       The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
   --help
@@ -39,7 +39,7 @@ Options:
 			return fmt.Errorf("task ID is required")
 		}
 
-		manager, err := client.NewManager(userID, serverAddr)
+		manager, err := client.NewManager(creds, serverAddr)
 		if err != nil {
 			return fmt.Errorf("failed to set up gRPC client: %w", err)
 		}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+// waitTimeoutExitCode is the exit code when the tasks did not complete in time, as used by timeout(1)
+const waitTimeoutExitCode = 124
+
+var (
+	waitAny     bool
+	waitAll     bool
+	waitTimeout time.Duration
+)
+
+var waitCmd = &cobra.Command{
+	Use:   `wait <task-id>... [--user-id <user-id>] [--server-address <host:port>] [--any|--all] [--timeout <duration>] [--help]`,
+	Short: "Wait for one or many tasks to complete",
+	Long: `Block until all of the tasks, or any of them with --any, have completed and print their final statuses.
+
+The exit code is 0 if all tasks exited successfully. Otherwise it is the exit code of the first failed task in the
+order given, or with --any the exit code of the first task to complete. Tasks killed by a signal exit with 128 plus
+the signal number. If the timeout expires first the current statuses are printed and the exit code is 124.
+
+Arguments:
+  <task-id>...
+      The UUIDs of the tasks to wait for (e.g., a7da14c7-b47a-4535-a263-5bb26e503002)
+
+Options:
+  --user-id <user-id>
+      The user or client ID issuing the request (e.g., client001). Required unless set by the context.
+  --server-address <host:port>
+      The gRPC server address to connect to (e.g., localhost:50051). Defaults to localhost:50051 if not set.
+  --any
+      Return as soon as any of the tasks has completed.
+  --all
+      Return once all of the tasks have completed. This is the default.
+  --timeout <duration>
+      The maximum time to wait (e.g., 30s, 5m). Waits until completion if not set. Time a task spends paused
+      counts toward the timeout.
+  --help
+      Display help information for the wait command.`,
+	Example:       `$ taskman --user-id client001 wait --timeout 10m a7da14c7-b47a-4535-a263-5bb26e503002 0b5e1e8e-0f4c-4d0e-9a57-2f6c7b3f54a1`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if waitTimeout < 0 {
+			return fmt.Errorf("timeout must not be negative")
+		}
+
+		manager, err := client.NewManager(creds, serverAddr)
+		if err != nil {
+			return fmt.Errorf("failed to set up gRPC client: %w", err)
+		}
+		defer func() {
+			if closeErr := manager.Close(); closeErr != nil {
+				if _, logErr := fmt.Fprintf(cmd.OutOrStderr(), "failed to close manager: %v\n", closeErr); logErr != nil {
+					// Fallback to fmt.Printf output if logging to cmd.OutOrStderr fails.
+					fmt.Printf("failed to log close error: %v\n", logErr)
+				}
+			}
+		}()
+
+		statuses, completed, err := manager.WaitTasks(cmd.Context(), args, waitAny, waitTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to wait for tasks: %w", err)
+		}
+
+		result := waitOutput{
+			Tasks:     make([]taskStatusOutput, 0, len(statuses)),
+			Completed: completed,
+			ExitCode:  combinedExitCode(statuses, completed, waitAny),
+		}
+		for _, status := range statuses {
+			result.Tasks = append(result.Tasks, newTaskStatusOutput(status))
+		}
+		if err := out.print(cmd.OutOrStdout(), result, func() string {
+			return client.FormatTaskStatuses(statuses) + "\n"
+		}); err != nil {
+			return err
+		}
+
+		if result.ExitCode != 0 {
+			return &ExitCodeError{Code: result.ExitCode}
+		}
+		return nil
+	},
+}
+
+// waitOutput is the output of the wait command
+type waitOutput struct {
+	Tasks     []taskStatusOutput `json:"tasks" yaml:"tasks"`
+	Completed bool               `json:"completed" yaml:"completed"`
+	ExitCode  int                `json:"exit_code" yaml:"exit_code"`
+}
+
+// combinedExitCode returns the exit code of the wait command for the final statuses
+func combinedExitCode(statuses []*client.TaskStatus, completed, waitAny bool) int {
+	if !completed {
+		return waitTimeoutExitCode
+	}
+
+	if waitAny {
+		var first *client.TaskStatus
+		for _, status := range statuses {
+			if status.EndTime.IsZero() {
+				continue
+			}
+			if first == nil || status.EndTime.Before(first.EndTime) {
+				first = status
+			}
+		}
+		if first == nil {
+			return waitTimeoutExitCode
+		}
+		return first.ShellExitCode()
+	}
+
+	for _, status := range statuses {
+		if code := status.ShellExitCode(); code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+func init() {
+	waitCmd.Flags().BoolVar(&waitAny, "any", false, "Return as soon as any of the tasks has completed.")
+	waitCmd.Flags().BoolVar(&waitAll, "all", false, "Return once all of the tasks have completed (default).")
+	waitCmd.MarkFlagsMutuallyExclusive("any", "all")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "The maximum time to wait, e.g. 30s or 5m. Waits until completion if not set.")
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mikewurtz/taskman/internal/grpc/client"
+)
+
+func TestCombinedExitCode(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	exited := func(code int32, endTime time.Time) *client.TaskStatus {
+		return &client.TaskStatus{ExitCode: &code, EndTime: endTime}
+	}
+	running := &client.TaskStatus{}
+
+	tests := []struct {
+		desc      string
+		statuses  []*client.TaskStatus
+		completed bool
+		waitAny   bool
+		expected  int
+	}{
+		{
+			desc:      "all succeeded",
+			statuses:  []*client.TaskStatus{exited(0, now), exited(0, now)},
+			completed: true,
+			expected:  0,
+		},
+		{
+			desc:      "first failure in order",
+			statuses:  []*client.TaskStatus{exited(0, now), exited(3, now), exited(2, now.Add(-time.Second))},
+			completed: true,
+			expected:  3,
+		},
+		{
+			desc: "signaled task",
+			statuses: []*client.TaskStatus{
+				exited(0, now),
+				{TerminationSignal: "SIGKILL", EndTime: now},
+			},
+			completed: true,
+			expected:  137,
+		},
+		{
+			desc:      "any uses the first task to complete",
+			statuses:  []*client.TaskStatus{running, exited(5, now), exited(0, now.Add(-time.Second))},
+			completed: true,
+			waitAny:   true,
+			expected:  0,
+		},
+		{
+			desc:      "timeout",
+			statuses:  []*client.TaskStatus{running, exited(0, now)},
+			completed: false,
+			expected:  waitTimeoutExitCode,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, combinedExitCode(tt.statuses, tt.completed, tt.waitAny))
+		})
+	}
+}
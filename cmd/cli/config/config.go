@@ -0,0 +1,164 @@
+// Package config manages the taskman CLI configuration file holding named contexts.
+// A context bundles the server address, the client credentials and the default output format.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables overriding the configuration file and the current context
+const (
+	EnvConfig        = "TASKMAN_CONFIG"
+	EnvContext       = "TASKMAN_CONTEXT"
+	EnvServerAddress = "TASKMAN_SERVER_ADDRESS"
+	EnvUserID        = "TASKMAN_USER_ID"
+	EnvCert          = "TASKMAN_CERT"
+	EnvKey           = "TASKMAN_KEY"
+	EnvCA            = "TASKMAN_CA"
+	EnvOutput        = "TASKMAN_OUTPUT"
+)
+
+// Config is the content of the configuration file
+type Config struct {
+	CurrentContext string     `yaml:"current-context,omitempty"`
+	Contexts       []*Context `yaml:"contexts,omitempty"`
+}
+
+// Context holds the connection settings for one server and identity
+type Context struct {
+	Name string `yaml:"name"`
+	// Server is the gRPC server address e.g. localhost:50051
+	Server string `yaml:"server,omitempty"`
+	// UserID is the name of an embedded client certificate, used if Cert and Key are not set
+	UserID string `yaml:"user-id,omitempty"`
+	// Cert and Key are paths of the PEM client certificate and key
+	Cert string `yaml:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+	// CA is the path of the PEM CA certificate; the embedded CA is used if not set
+	CA string `yaml:"ca,omitempty"`
+	// Output is the default output format of commands
+	Output string `yaml:"output,omitempty"`
+}
+
+// DefaultPath returns the configuration file path: $TASKMAN_CONFIG if set, otherwise
+// taskman/config.yaml in the user configuration directory e.g. ~/.config/taskman/config.yaml
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "taskman", "config.yaml"), nil
+}
+
+// Load reads the configuration file; a missing file is an empty configuration
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the configuration file readable only by the user, creating its directory if needed
+func (c *Config) Save(path string) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	data := buf.Bytes()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// write to a temporary file first so that a failed write does not corrupt the config
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// Context returns the context with the given name or nil if there is none
+func (c *Config) Context(name string) *Context {
+	i := slices.IndexFunc(c.Contexts, func(ctx *Context) bool {
+		return ctx.Name == name
+	})
+	if i < 0 {
+		return nil
+	}
+	return c.Contexts[i]
+}
+
+// SetContext adds the context or replaces the existing context with the same name
+func (c *Config) SetContext(ctx *Context) {
+	if i := slices.IndexFunc(c.Contexts, func(existing *Context) bool {
+		return existing.Name == ctx.Name
+	}); i >= 0 {
+		c.Contexts[i] = ctx
+		return
+	}
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// UseContext makes the named context the current context
+func (c *Config) UseContext(name string) error {
+	if c.Context(name) == nil {
+		return fmt.Errorf("context %q not found", name)
+	}
+	c.CurrentContext = name
+	return nil
+}
+
+// Resolve returns the selected context: name if set, otherwise $TASKMAN_CONTEXT or the current context.
+// An empty context is returned if no context is selected.
+func (c *Config) Resolve(name string) (*Context, error) {
+	if name == "" {
+		name = os.Getenv(EnvContext)
+	}
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return &Context{}, nil
+	}
+	ctx := c.Context(name)
+	if ctx == nil {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return ctx, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Contexts)
+	assert.Empty(t, cfg.CurrentContext)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "taskman", "config.yaml")
+	cfg := &Config{}
+	cfg.SetContext(&Context{Name: "dev", Server: "localhost:50051", UserID: "client001"})
+	cfg.SetContext(&Context{Name: "prod", Server: "prod:50051", Cert: "/certs/me.crt", Key: "/certs/me.key"})
+	// replacing a context keeps its position
+	cfg.SetContext(&Context{Name: "dev", Server: "localhost:50052", Output: "json"})
+	require.NoError(t, cfg.UseContext("prod"))
+	require.Error(t, cfg.UseContext("unknown"))
+	require.NoError(t, cfg.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+	require.Len(t, loaded.Contexts, 2)
+	assert.Equal(t, "dev", loaded.Contexts[0].Name)
+	assert.Equal(t, "json", loaded.Contexts[0].Output)
+}
+
+func TestResolve(t *testing.T) {
+	cfg := &Config{
+		CurrentContext: "dev",
+		Contexts: []*Context{
+			{Name: "dev", Server: "dev:50051"},
+			{Name: "prod", Server: "prod:50051"},
+		},
+	}
+
+	tests := []struct {
+		desc           string
+		cfg            *Config
+		name           string
+		env            string
+		expectedServer string
+		expectedErr    string
+	}{
+		{
+			desc:           "current context",
+			cfg:            cfg,
+			expectedServer: "dev:50051",
+		},
+		{
+			desc:           "environment overrides current context",
+			cfg:            cfg,
+			env:            "prod",
+			expectedServer: "prod:50051",
+		},
+		{
+			desc:           "name overrides environment",
+			cfg:            cfg,
+			name:           "dev",
+			env:            "prod",
+			expectedServer: "dev:50051",
+		},
+		{
+			desc:           "no context selected",
+			cfg:            &Config{},
+			expectedServer: "",
+		},
+		{
+			desc:        "unknown context",
+			cfg:         cfg,
+			name:        "staging",
+			expectedErr: `context "staging" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Setenv(EnvContext, tt.env)
+
+			ctx, err := tt.cfg.Resolve(tt.name)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedServer, ctx.Server)
+		})
+	}
+}
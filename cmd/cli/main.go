@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -14,8 +15,13 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 	if err := commands.RootCmd.ExecuteContext(ctx); err != nil {
-		if _, logErr := fmt.Fprintf(commands.RootCmd.ErrOrStderr(), "Error: %v\n", err); logErr != nil {
-			// fall back to fmt.Print output if fmt.FPrintf fails
+		// propagate the exit code of a remote task as is
+		var exitErr *commands.ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if logErr := commands.PrintError(commands.RootCmd.ErrOrStderr(), err); logErr != nil {
+			// fall back to fmt.Print output if printing the error fails
 			fmt.Printf("failed to log error: %v\n", logErr)
 		}
 		os.Exit(1)
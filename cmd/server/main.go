@@ -3,17 +3,63 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"regexp"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/mikewurtz/taskman/internal/audit"
 	"github.com/mikewurtz/taskman/internal/grpc/server"
+	"github.com/mikewurtz/taskman/internal/logging"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
 )
 
-var serverAddr string
+var (
+	serverAddr     string
+	metricsAddress string
+	gatewayAddress string
+	logFormat      string
+	logLevel       string
+
+	enableReflection bool
+	idempotencyTTL   time.Duration
+
+	retentionMaxAge            time.Duration
+	retentionMaxTasksPerClient int
+	retentionMaxOutputBytes    int64
+
+	maxRunningTasksPerClient int
+	maxRunningTasks          int
+	maxStartsPerMinute       int
+	maxQueuedTasks           int
+	maxCPUMillisPerClient    int64
+	maxMemoryPerClient       int64
+
+	maxTaskCPUMillis int64
+	maxTaskMemory    int64
+	maxTaskIOBPS     int64
+	maxTaskPids      int64
+	ioDeviceSpecs    []string
+
+	cgroupParent    string
+	clientCPUMillis int64
+	clientMemory    int64
+	clientPidsMax   int64
+
+	memoryPressureStop  float64
+	memoryPressureGrace time.Duration
+
+	auditLogPath        string
+	auditMaxSizeMB      int
+	auditMaxBackups     int
+	auditRedactArgs     bool
+	auditRedactPatterns []string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "taskman-server",
@@ -23,8 +69,102 @@ over a secure mTLS connection.`,
 	Example:       `$ taskman-server --server-address localhost:50051`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logger, err := logging.New(os.Stderr, logFormat, logLevel)
+		if err != nil {
+			return err
+		}
+		slog.SetDefault(logger)
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		server, err := server.New(cmd.Context(), serverAddr)
+		if idempotencyTTL <= 0 {
+			return fmt.Errorf("idempotency TTL must be positive")
+		}
+		if retentionMaxAge < 0 || retentionMaxTasksPerClient < 0 || retentionMaxOutputBytes < 0 {
+			return fmt.Errorf("retention limits must not be negative")
+		}
+		if maxRunningTasksPerClient < 0 || maxRunningTasks < 0 || maxStartsPerMinute < 0 || maxQueuedTasks < 0 ||
+			maxCPUMillisPerClient < 0 || maxMemoryPerClient < 0 {
+			return fmt.Errorf("quotas must not be negative")
+		}
+		if maxTaskCPUMillis < 0 || maxTaskMemory < 0 || maxTaskIOBPS < 0 || maxTaskPids < 0 {
+			return fmt.Errorf("resource ceilings must not be negative")
+		}
+		if clientCPUMillis < 0 || clientMemory < 0 || clientPidsMax < 0 {
+			return fmt.Errorf("client cgroup limits must not be negative")
+		}
+		if memoryPressureStop < 0 || memoryPressureStop > 100 {
+			return fmt.Errorf("memory pressure stop threshold must be between 0 and 100 percent")
+		}
+		if memoryPressureGrace < 0 {
+			return fmt.Errorf("memory pressure grace period must not be negative")
+		}
+
+		auditLog, err := newAuditLogger()
+		if err != nil {
+			return fmt.Errorf("failed to set up audit log: %w", err)
+		}
+		defer func() {
+			if err := auditLog.Close(); err != nil {
+				slog.Error("failed to close audit log", "error", err)
+			}
+		}()
+
+		serverOpts := []server.Option{
+			server.WithAuditLogger(auditLog),
+			server.WithMetricsAddress(metricsAddress),
+		}
+		if gatewayAddress != "" {
+			serverOpts = append(serverOpts, server.WithGatewayAddress(gatewayAddress))
+		}
+		if enableReflection {
+			serverOpts = append(serverOpts, server.WithReflection())
+		}
+		serverOpts = append(serverOpts, server.WithIdempotencyTTL(idempotencyTTL))
+		serverOpts = append(serverOpts, server.WithRetentionPolicy(taskmanager.RetentionPolicy{
+			MaxAge:            retentionMaxAge,
+			MaxTasksPerClient: retentionMaxTasksPerClient,
+			MaxOutputBytes:    retentionMaxOutputBytes,
+		}))
+		serverOpts = append(serverOpts, server.WithQuotaPolicy(taskmanager.QuotaPolicy{
+			MaxRunningPerClient:   maxRunningTasksPerClient,
+			MaxRunning:            maxRunningTasks,
+			MaxStartsPerMinute:    maxStartsPerMinute,
+			MaxQueued:             maxQueuedTasks,
+			MaxCPUMillisPerClient: maxCPUMillisPerClient,
+			MaxMemoryPerClient:    maxMemoryPerClient,
+		}))
+		// memory.high has no ceiling of its own as it only matters below memory.max
+		serverOpts = append(serverOpts, server.WithResourceCeilings(cgroups.Limits{
+			CPUMillis:  maxTaskCPUMillis,
+			MemoryMax:  maxTaskMemory,
+			IOReadBPS:  maxTaskIOBPS,
+			IOWriteBPS: maxTaskIOBPS,
+			PidsMax:    maxTaskPids,
+		}))
+
+		ioDevices, err := resolveIODevices(cmd.Flags().Changed("io-device"))
+		if err != nil {
+			return err
+		}
+		serverOpts = append(serverOpts, server.WithIODevices(ioDevices))
+
+		hierarchy, err := cgroups.NewHierarchy(cgroupParent, cgroups.Limits{
+			CPUMillis: clientCPUMillis,
+			MemoryMax: clientMemory,
+			PidsMax:   clientPidsMax,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid --cgroup-parent: %w", err)
+		}
+		serverOpts = append(serverOpts, server.WithCgroupHierarchy(hierarchy))
+		serverOpts = append(serverOpts, server.WithMemoryPressurePolicy(taskmanager.MemoryPressurePolicy{
+			StopAbove:   memoryPressureStop,
+			GracePeriod: memoryPressureGrace,
+		}))
+
+		server, err := server.New(cmd.Context(), serverAddr, serverOpts...)
 		if err != nil {
 			return fmt.Errorf("failed to initialize server: %w", err)
 		}
@@ -42,17 +182,17 @@ over a secure mTLS connection.`,
 		}()
 		select {
 		case <-cmd.Context().Done():
-			log.Println("Shutdown signal received. Stopping server...")
+			slog.Info("shutdown signal received, stopping server")
 			server.Shutdown()
-			log.Println("Server stopped")
+			slog.Info("server stopped")
 			return nil
 
 		case err := <-startErrCh:
 			if err != nil {
-				log.Printf("Server exited with error: %v", err)
+				slog.Error("server exited with error", "error", err)
 				return err
 			}
-			log.Println("Server exited cleanly before signal")
+			slog.Info("server exited cleanly before signal")
 			return nil
 		}
 	},
@@ -61,14 +201,128 @@ over a secure mTLS connection.`,
 func init() {
 	rootCmd.Flags().StringVar(&serverAddr, "server-address", "localhost:50051",
 		"The gRPC server address to expose the server on. Defaults to localhost:50051 if not set.")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", logging.FormatText,
+		"The server log output format, either text or json.")
+	rootCmd.Flags().StringVar(&logLevel, "log-level", "info",
+		"The minimum level of server logs: debug, info, warn or error.")
+	rootCmd.Flags().StringVar(&metricsAddress, "metrics-address", "",
+		"The plain HTTP address, e.g. localhost:9100, to expose Prometheus metrics (/metrics) and the liveness (/healthz) and "+
+			"readiness (/readyz) probes on without authentication. Disabled if not set.")
+	rootCmd.Flags().StringVar(&gatewayAddress, "gateway-address", "",
+		"The HTTPS address to expose the HTTP/JSON gateway on. Clients authenticate with the same certificates as for gRPC. "+
+			"Disabled if not set.")
+	rootCmd.Flags().BoolVar(&enableReflection, "enable-reflection", false,
+		"Register the gRPC reflection service so tools such as grpcurl can discover the API.")
+	rootCmd.Flags().DurationVar(&idempotencyTTL, "idempotency-ttl", taskmanager.DefaultIdempotencyTTL,
+		"How long the idempotency key of a start request is remembered to deduplicate retries of the same client.")
+	rootCmd.Flags().DurationVar(&retentionMaxAge, "retention-max-age", 0,
+		"Evict finished tasks and their output this long after they finished. Disabled if 0.")
+	rootCmd.Flags().IntVar(&retentionMaxTasksPerClient, "retention-max-tasks-per-client", 0,
+		"Keep at most this many finished tasks per client, evicting the oldest first. Disabled if 0.")
+	rootCmd.Flags().Int64Var(&retentionMaxOutputBytes, "retention-max-output-bytes", 0,
+		"Evict the oldest finished tasks while the output of all tasks exceeds this many bytes. Disabled if 0.")
+	rootCmd.Flags().IntVar(&maxRunningTasksPerClient, "max-running-tasks-per-client", 0,
+		"Reject starts while the client already runs this many tasks. Unlimited if 0.")
+	rootCmd.Flags().IntVar(&maxRunningTasks, "max-running-tasks", 0,
+		"Reject starts while this many tasks of all clients are running. Unlimited if 0.")
+	rootCmd.Flags().IntVar(&maxStartsPerMinute, "max-starts-per-minute", 0,
+		"Reject starts once the client has started this many tasks in the last minute. Unlimited if 0.")
+	rootCmd.Flags().IntVar(&maxQueuedTasks, "max-queued-tasks", 0,
+		"Queue up to this many starts while a running task quota is reached instead of rejecting them. Starts are rejected if 0.")
+	rootCmd.Flags().Int64Var(&maxCPUMillisPerClient, "max-cpu-millis-per-client", 0,
+		"Reject resource updates by the owner while the CPU limits of its running tasks would exceed this many thousandths of a CPU. "+
+			"Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&maxMemoryPerClient, "max-memory-per-client", 0,
+		"Reject resource updates by the owner while the memory.max limits of its running tasks would exceed this many bytes. "+
+			"Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&maxTaskCPUMillis, "max-task-cpu-millis", 2000,
+		"The highest CPU limit in thousandths of a CPU a resource update may set on a task. Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&maxTaskMemory, "max-task-memory", 1<<30,
+		"The highest memory.max in bytes a resource update may set on a task. Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&maxTaskIOBPS, "max-task-io-bps", 100<<20,
+		"The highest io.max read and write bandwidth in bytes per second a resource update may set on a task. Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&maxTaskPids, "max-task-pids", 4096,
+		fmt.Sprintf("The highest pids.max a resource update may set on a task. Tasks start with a pids.max of %d when the pids controller is available. Unlimited if 0.",
+			cgroups.DefaultLimits.PidsMax))
+	rootCmd.Flags().StringArrayVar(&ioDeviceSpecs, "io-device", []string{cgroups.IODeviceWorkDir},
+		"A block device the IO limits of the tasks apply to (repeatable): the path of a block device such as /dev/nvme0n1, "+
+			"the path of a mount point or other file whose file system is on the block device, "+
+			"or \"workdir\" for the block device of the server's working directory.")
+	rootCmd.Flags().StringVar(&cgroupParent, "cgroup-parent", "",
+		"The cgroup the task cgroups are created in, as an absolute path or relative to /sys/fs/cgroup, e.g. taskman.slice. "+
+			"Every client gets a cgroup of its own below it that holds its tasks. Defaults to a tasks cgroup below the server's own "+
+			"cgroup if systemd delegated it, e.g. with Delegate=yes, and to "+cgroups.DefaultParent+" otherwise.")
+	rootCmd.Flags().Int64Var(&clientCPUMillis, "client-cpu-millis", 0,
+		"The CPU limit in thousandths of a CPU of the cgroup of every client, which all tasks of the client share. Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&clientMemory, "client-memory", 0,
+		"The memory.max in bytes of the cgroup of every client, which all tasks of the client share. Unlimited if 0.")
+	rootCmd.Flags().Int64Var(&clientPidsMax, "client-pids-max", 0,
+		"The pids.max of the cgroup of every client, which all tasks of the client share. Unlimited if 0.")
+	rootCmd.Flags().Float64Var(&memoryPressureStop, "memory-pressure-stop", 0,
+		"Stop a task with SIGTERM once all its processes stalled on memory for this percentage of the last 10 seconds "+
+			"(full avg10 of memory.pressure), before the OOM killer kills it. Disabled if 0.")
+	rootCmd.Flags().DurationVar(&memoryPressureGrace, "memory-pressure-grace", 10*time.Second,
+		"How long a task stopped under memory pressure may take to exit after SIGTERM before it is killed with SIGKILL.")
+	rootCmd.Flags().StringVar(&auditLogPath, "audit-log", "",
+		"Path of the JSON audit log file, or \"-\" to write audit events to stdout. Auditing is disabled if not set.")
+	rootCmd.Flags().IntVar(&auditMaxSizeMB, "audit-max-size-mb", 100,
+		"Size in megabytes at which the audit log file is rotated.")
+	rootCmd.Flags().IntVar(&auditMaxBackups, "audit-max-backups", 5,
+		"Number of rotated audit log files to keep.")
+	rootCmd.Flags().BoolVar(&auditRedactArgs, "audit-redact-args", false,
+		"Replace every task argument with [REDACTED] in audit events.")
+	rootCmd.Flags().StringArrayVar(&auditRedactPatterns, "audit-redact-pattern", nil,
+		"Regular expression whose matches are replaced with [REDACTED] in task arguments of audit events (repeatable).")
+}
+
+// resolveIODevices resolves the --io-device flags to major:minor numbers. The default only warns if no
+// block device backs the working directory, e.g. on tmpfs or overlay, and the IO limits are not enforced.
+func resolveIODevices(explicit bool) ([]string, error) {
+	ioDevices, err := cgroups.ResolveIODevices(ioDeviceSpecs)
+	if err != nil {
+		if explicit {
+			return nil, fmt.Errorf("invalid --io-device: %w", err)
+		}
+		slog.Warn("no IO device detected: IO limits are not enforced", "error", err)
+		return nil, nil
+	}
+	slog.Info("IO limits apply to block devices", "devices", ioDevices)
+	return ioDevices, nil
+}
+
+// newAuditLogger builds the audit logger from the audit flags; returns nil if auditing is disabled
+func newAuditLogger() (*audit.Logger, error) {
+	if auditLogPath == "" {
+		return nil, nil
+	}
+
+	var opts []audit.Option
+	if auditRedactArgs {
+		opts = append(opts, audit.WithRedactArgs())
+	}
+	for _, pattern := range auditRedactPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --audit-redact-pattern %q: %w", pattern, err)
+		}
+		opts = append(opts, audit.WithRedactPatterns(re))
+	}
 
+	if auditLogPath == "-" {
+		return audit.New(audit.NewWriterSink(os.Stdout), opts...), nil
+	}
+	sink, err := audit.NewFileSink(auditLogPath, int64(auditMaxSizeMB)*1024*1024, auditMaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return audit.New(sink, opts...), nil
 }
 
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		log.Println(err)
+		slog.Error(err.Error())
 		// Call stop directly to ensure the context is stopped before we exit
 		stop()
 		os.Exit(1)
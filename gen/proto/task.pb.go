@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -36,6 +37,16 @@ const (
 	JobStatus_JOB_STATUS_EXITED_OK JobStatus = 3
 	// job exited with a non-zero status and was not stopped
 	JobStatus_JOB_STATUS_EXITED_ERROR JobStatus = 4
+	// job is waiting in the queue for a running task quota to free up
+	JobStatus_JOB_STATUS_QUEUED JobStatus = 5
+	// job is waiting for the tasks it depends on to finish
+	JobStatus_JOB_STATUS_WAITING JobStatus = 6
+	// job processes are frozen by PauseTask until ResumeTask
+	JobStatus_JOB_STATUS_PAUSED JobStatus = 7
+	// job was stopped while queued or waiting, before its process started
+	JobStatus_JOB_STATUS_CANCELED JobStatus = 8
+	// job was not started because a dependency did not meet its condition
+	JobStatus_JOB_STATUS_FAILED JobStatus = 9
 )
 
 // Enum value maps for JobStatus.
@@ -46,6 +57,11 @@ var (
 		2: "JOB_STATUS_SIGNALED",
 		3: "JOB_STATUS_EXITED_OK",
 		4: "JOB_STATUS_EXITED_ERROR",
+		5: "JOB_STATUS_QUEUED",
+		6: "JOB_STATUS_WAITING",
+		7: "JOB_STATUS_PAUSED",
+		8: "JOB_STATUS_CANCELED",
+		9: "JOB_STATUS_FAILED",
 	}
 	JobStatus_value = map[string]int32{
 		"JOB_STATUS_UNKNOWN":      0,
@@ -53,6 +69,11 @@ var (
 		"JOB_STATUS_SIGNALED":     2,
 		"JOB_STATUS_EXITED_OK":    3,
 		"JOB_STATUS_EXITED_ERROR": 4,
+		"JOB_STATUS_QUEUED":       5,
+		"JOB_STATUS_WAITING":      6,
+		"JOB_STATUS_PAUSED":       7,
+		"JOB_STATUS_CANCELED":     8,
+		"JOB_STATUS_FAILED":       9,
 	}
 )
 
@@ -83,13 +104,308 @@ func (JobStatus) EnumDescriptor() ([]byte, []int) {
 	return file_proto_task_proto_rawDescGZIP(), []int{0}
 }
 
+// RestartMode selects the exits of a process that are restarted
+type RestartMode int32
+
+const (
+	// same as RESTART_MODE_NEVER
+	RestartMode_RESTART_MODE_UNSPECIFIED RestartMode = 0
+	// the process is never restarted
+	RestartMode_RESTART_MODE_NEVER RestartMode = 1
+	// the process is restarted if it exits with a non-zero exit code, is OOM killed or is killed by a signal that
+	// was not sent through taskman
+	RestartMode_RESTART_MODE_ON_FAILURE RestartMode = 2
+	// the process is restarted whenever it exits unless it was stopped or signaled through taskman
+	RestartMode_RESTART_MODE_ALWAYS RestartMode = 3
+)
+
+// Enum value maps for RestartMode.
+var (
+	RestartMode_name = map[int32]string{
+		0: "RESTART_MODE_UNSPECIFIED",
+		1: "RESTART_MODE_NEVER",
+		2: "RESTART_MODE_ON_FAILURE",
+		3: "RESTART_MODE_ALWAYS",
+	}
+	RestartMode_value = map[string]int32{
+		"RESTART_MODE_UNSPECIFIED": 0,
+		"RESTART_MODE_NEVER":       1,
+		"RESTART_MODE_ON_FAILURE":  2,
+		"RESTART_MODE_ALWAYS":      3,
+	}
+)
+
+func (x RestartMode) Enum() *RestartMode {
+	p := new(RestartMode)
+	*p = x
+	return p
+}
+
+func (x RestartMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RestartMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_task_proto_enumTypes[1].Descriptor()
+}
+
+func (RestartMode) Type() protoreflect.EnumType {
+	return &file_proto_task_proto_enumTypes[1]
+}
+
+func (x RestartMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RestartMode.Descriptor instead.
+func (RestartMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{1}
+}
+
+// DependencyCondition is the outcome of a dependency that lets the dependent task start
+type DependencyCondition int32
+
+const (
+	// same as DEPENDENCY_CONDITION_SUCCESS
+	DependencyCondition_DEPENDENCY_CONDITION_UNSPECIFIED DependencyCondition = 0
+	// the dependency exited with exit code 0
+	DependencyCondition_DEPENDENCY_CONDITION_SUCCESS DependencyCondition = 1
+	// the dependency finished with any outcome
+	DependencyCondition_DEPENDENCY_CONDITION_COMPLETION DependencyCondition = 2
+	// the dependency finished with any outcome other than exit code 0
+	DependencyCondition_DEPENDENCY_CONDITION_FAILURE DependencyCondition = 3
+)
+
+// Enum value maps for DependencyCondition.
+var (
+	DependencyCondition_name = map[int32]string{
+		0: "DEPENDENCY_CONDITION_UNSPECIFIED",
+		1: "DEPENDENCY_CONDITION_SUCCESS",
+		2: "DEPENDENCY_CONDITION_COMPLETION",
+		3: "DEPENDENCY_CONDITION_FAILURE",
+	}
+	DependencyCondition_value = map[string]int32{
+		"DEPENDENCY_CONDITION_UNSPECIFIED": 0,
+		"DEPENDENCY_CONDITION_SUCCESS":     1,
+		"DEPENDENCY_CONDITION_COMPLETION":  2,
+		"DEPENDENCY_CONDITION_FAILURE":     3,
+	}
+)
+
+func (x DependencyCondition) Enum() *DependencyCondition {
+	p := new(DependencyCondition)
+	*p = x
+	return p
+}
+
+func (x DependencyCondition) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DependencyCondition) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_task_proto_enumTypes[2].Descriptor()
+}
+
+func (DependencyCondition) Type() protoreflect.EnumType {
+	return &file_proto_task_proto_enumTypes[2]
+}
+
+func (x DependencyCondition) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DependencyCondition.Descriptor instead.
+func (DependencyCondition) EnumDescriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{2}
+}
+
+// RetryOutcome is an outcome of an attempt that is retried
+type RetryOutcome int32
+
+const (
+	RetryOutcome_RETRY_OUTCOME_UNSPECIFIED RetryOutcome = 0
+	// the process exited with a non-zero exit code
+	RetryOutcome_RETRY_OUTCOME_EXIT_ERROR RetryOutcome = 1
+	// the process was killed by the kernel OOM killer
+	RetryOutcome_RETRY_OUTCOME_OOM RetryOutcome = 2
+	// the process was killed by a signal that was not sent through taskman
+	RetryOutcome_RETRY_OUTCOME_SIGNAL RetryOutcome = 3
+)
+
+// Enum value maps for RetryOutcome.
+var (
+	RetryOutcome_name = map[int32]string{
+		0: "RETRY_OUTCOME_UNSPECIFIED",
+		1: "RETRY_OUTCOME_EXIT_ERROR",
+		2: "RETRY_OUTCOME_OOM",
+		3: "RETRY_OUTCOME_SIGNAL",
+	}
+	RetryOutcome_value = map[string]int32{
+		"RETRY_OUTCOME_UNSPECIFIED": 0,
+		"RETRY_OUTCOME_EXIT_ERROR":  1,
+		"RETRY_OUTCOME_OOM":         2,
+		"RETRY_OUTCOME_SIGNAL":      3,
+	}
+)
+
+func (x RetryOutcome) Enum() *RetryOutcome {
+	p := new(RetryOutcome)
+	*p = x
+	return p
+}
+
+func (x RetryOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RetryOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_task_proto_enumTypes[3].Descriptor()
+}
+
+func (RetryOutcome) Type() protoreflect.EnumType {
+	return &file_proto_task_proto_enumTypes[3]
+}
+
+func (x RetryOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RetryOutcome.Descriptor instead.
+func (RetryOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{3}
+}
+
+// WaitMode selects when WaitTasks returns
+type WaitMode int32
+
+const (
+	// wait until all tasks have completed
+	WaitMode_WAIT_MODE_ALL WaitMode = 0
+	// wait until any task has completed
+	WaitMode_WAIT_MODE_ANY WaitMode = 1
+)
+
+// Enum value maps for WaitMode.
+var (
+	WaitMode_name = map[int32]string{
+		0: "WAIT_MODE_ALL",
+		1: "WAIT_MODE_ANY",
+	}
+	WaitMode_value = map[string]int32{
+		"WAIT_MODE_ALL": 0,
+		"WAIT_MODE_ANY": 1,
+	}
+)
+
+func (x WaitMode) Enum() *WaitMode {
+	p := new(WaitMode)
+	*p = x
+	return p
+}
+
+func (x WaitMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WaitMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_task_proto_enumTypes[4].Descriptor()
+}
+
+func (WaitMode) Type() protoreflect.EnumType {
+	return &file_proto_task_proto_enumTypes[4]
+}
+
+func (x WaitMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WaitMode.Descriptor instead.
+func (WaitMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{4}
+}
+
+// ConcurrencyPolicy decides what a schedule does when a run is due while a task of an earlier run is unfinished
+type ConcurrencyPolicy int32
+
+const (
+	// same as CONCURRENCY_POLICY_ALLOW
+	ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED ConcurrencyPolicy = 0
+	// start a new task next to the unfinished tasks
+	ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW ConcurrencyPolicy = 1
+	// skip the run
+	ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID ConcurrencyPolicy = 2
+	// stop the unfinished tasks with the termination source "schedule" and start a new task
+	ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE ConcurrencyPolicy = 3
+)
+
+// Enum value maps for ConcurrencyPolicy.
+var (
+	ConcurrencyPolicy_name = map[int32]string{
+		0: "CONCURRENCY_POLICY_UNSPECIFIED",
+		1: "CONCURRENCY_POLICY_ALLOW",
+		2: "CONCURRENCY_POLICY_FORBID",
+		3: "CONCURRENCY_POLICY_REPLACE",
+	}
+	ConcurrencyPolicy_value = map[string]int32{
+		"CONCURRENCY_POLICY_UNSPECIFIED": 0,
+		"CONCURRENCY_POLICY_ALLOW":       1,
+		"CONCURRENCY_POLICY_FORBID":      2,
+		"CONCURRENCY_POLICY_REPLACE":     3,
+	}
+)
+
+func (x ConcurrencyPolicy) Enum() *ConcurrencyPolicy {
+	p := new(ConcurrencyPolicy)
+	*p = x
+	return p
+}
+
+func (x ConcurrencyPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConcurrencyPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_task_proto_enumTypes[5].Descriptor()
+}
+
+func (ConcurrencyPolicy) Type() protoreflect.EnumType {
+	return &file_proto_task_proto_enumTypes[5]
+}
+
+func (x ConcurrencyPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConcurrencyPolicy.Descriptor instead.
+func (ConcurrencyPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{5}
+}
+
 // StartTaskRequest contains the command and arguments to start a new task
 type StartTaskRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The command to execute, either a full path (e.g. "/bin/ls") or a binary available in the system's PATH.
 	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
 	// arguments to pass to the task e.g. ["-l", "-a"]
-	Args          []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// user-supplied labels e.g. {"team": "infra", "build": "1234"}; keys are a name with an optional DNS prefix
+	Labels map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// optional key making retries safe: a repeated key of the same client returns the task started by the first
+	// request if the parameters are identical, or fails with ALREADY_EXISTS if they differ. Keys expire after a TTL.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// queued tasks with a higher priority start first; defaults to 0 and may be negative
+	Priority int32 `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	// optional policy restarting the process when an attempt fails; the task is not retried if unset
+	RetryPolicy *RetryPolicy `protobuf:"bytes,6,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	// tasks that must finish before this task starts; the task fails with the termination source "dependency"
+	// if the condition of a dependency is not met. Dependencies must be existing tasks visible to the client.
+	DependsOn []*TaskDependency `protobuf:"bytes,7,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// optional policy restarting the process of a long-running service when it exits; may not be combined with a
+	// retry policy. The task is not restarted if unset.
+	RestartPolicy *RestartPolicy `protobuf:"bytes,8,opt,name=restart_policy,json=restartPolicy,proto3" json:"restart_policy,omitempty"`
+	// cgroup limits the task starts with; unset limits are the server defaults. Limits above the server ceilings
+	// are rejected with INVALID_ARGUMENT and raised limits count against the resource quotas of the client.
+	Limits        *ResourceLimits `protobuf:"bytes,9,opt,name=limits,proto3" json:"limits,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -101,14 +417,2676 @@ func (x *StartTaskRequest) Reset() {
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartTaskRequest) String() string {
+func (x *StartTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTaskRequest) ProtoMessage() {}
+
+func (x *StartTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTaskRequest.ProtoReflect.Descriptor instead.
+func (*StartTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartTaskRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *StartTaskRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *StartTaskRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *StartTaskRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *StartTaskRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *StartTaskRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *StartTaskRequest) GetDependsOn() []*TaskDependency {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *StartTaskRequest) GetRestartPolicy() *RestartPolicy {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return nil
+}
+
+func (x *StartTaskRequest) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+// RestartPolicy keeps the process of a long-running service up. Restarts keep the task ID and output and wait for a
+// crash-loop backoff that doubles on every restart and resets once a process has run for longer than max_backoff.
+type RestartPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mode  RestartMode            `protobuf:"varint,1,opt,name=mode,proto3,enum=task_manager.RestartMode" json:"mode,omitempty"`
+	// delay before the first restart; defaults to 1s
+	InitialBackoff *durationpb.Duration `protobuf:"bytes,2,opt,name=initial_backoff,json=initialBackoff,proto3" json:"initial_backoff,omitempty"`
+	// upper bound of the delay between restarts; defaults to 5m
+	MaxBackoff *durationpb.Duration `protobuf:"bytes,3,opt,name=max_backoff,json=maxBackoff,proto3" json:"max_backoff,omitempty"`
+	// maximum number of restarts within restart_window; the task finishes with the result of its last process
+	// once it is reached. Defaults to 10.
+	MaxRestarts int32 `protobuf:"varint,4,opt,name=max_restarts,json=maxRestarts,proto3" json:"max_restarts,omitempty"`
+	// window of the restart rate; defaults to 10m
+	RestartWindow *durationpb.Duration `protobuf:"bytes,5,opt,name=restart_window,json=restartWindow,proto3" json:"restart_window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartPolicy) Reset() {
+	*x = RestartPolicy{}
+	mi := &file_proto_task_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartPolicy) ProtoMessage() {}
+
+func (x *RestartPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartPolicy.ProtoReflect.Descriptor instead.
+func (*RestartPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RestartPolicy) GetMode() RestartMode {
+	if x != nil {
+		return x.Mode
+	}
+	return RestartMode_RESTART_MODE_UNSPECIFIED
+}
+
+func (x *RestartPolicy) GetInitialBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.InitialBackoff
+	}
+	return nil
+}
+
+func (x *RestartPolicy) GetMaxBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return nil
+}
+
+func (x *RestartPolicy) GetMaxRestarts() int32 {
+	if x != nil {
+		return x.MaxRestarts
+	}
+	return 0
+}
+
+func (x *RestartPolicy) GetRestartWindow() *durationpb.Duration {
+	if x != nil {
+		return x.RestartWindow
+	}
+	return nil
+}
+
+// TaskDependency is a task that must finish with the condition before the dependent task starts
+type TaskDependency struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task depended on
+	TaskId        string              `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Condition     DependencyCondition `protobuf:"varint,2,opt,name=condition,proto3,enum=task_manager.DependencyCondition" json:"condition,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskDependency) Reset() {
+	*x = TaskDependency{}
+	mi := &file_proto_task_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskDependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskDependency) ProtoMessage() {}
+
+func (x *TaskDependency) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskDependency.ProtoReflect.Descriptor instead.
+func (*TaskDependency) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TaskDependency) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskDependency) GetCondition() DependencyCondition {
+	if x != nil {
+		return x.Condition
+	}
+	return DependencyCondition_DEPENDENCY_CONDITION_UNSPECIFIED
+}
+
+// RetryPolicy restarts the process of a failed task. Tasks stopped or signaled through taskman are never retried.
+type RetryPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// maximum number of attempts including the first one; 0 and 1 disable retries
+	MaxAttempts int32 `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// delay before the first retry; defaults to 1s
+	InitialBackoff *durationpb.Duration `protobuf:"bytes,2,opt,name=initial_backoff,json=initialBackoff,proto3" json:"initial_backoff,omitempty"`
+	// upper bound of the delay between attempts; defaults to 1m
+	MaxBackoff *durationpb.Duration `protobuf:"bytes,3,opt,name=max_backoff,json=maxBackoff,proto3" json:"max_backoff,omitempty"`
+	// factor the delay grows by after every retry; defaults to 2
+	BackoffMultiplier float64 `protobuf:"fixed64,4,opt,name=backoff_multiplier,json=backoffMultiplier,proto3" json:"backoff_multiplier,omitempty"`
+	// outcomes to retry; defaults to RETRY_OUTCOME_EXIT_ERROR
+	RetryOn       []RetryOutcome `protobuf:"varint,5,rep,packed,name=retry_on,json=retryOn,proto3,enum=task_manager.RetryOutcome" json:"retry_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_proto_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetInitialBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.InitialBackoff
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetMaxBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetBackoffMultiplier() float64 {
+	if x != nil {
+		return x.BackoffMultiplier
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryOn() []RetryOutcome {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+type StartTaskResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTaskResponse) Reset() {
+	*x = StartTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTaskResponse) ProtoMessage() {}
+
+func (x *StartTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTaskResponse.ProtoReflect.Descriptor instead.
+func (*StartTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartTaskResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type StopTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTaskRequest) Reset() {
+	*x = StopTaskRequest{}
+	mi := &file_proto_task_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTaskRequest) ProtoMessage() {}
+
+func (x *StopTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTaskRequest.ProtoReflect.Descriptor instead.
+func (*StopTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StopTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type StopTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTaskResponse) Reset() {
+	*x = StopTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTaskResponse) ProtoMessage() {}
+
+func (x *StopTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTaskResponse.ProtoReflect.Descriptor instead.
+func (*StopTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{6}
+}
+
+type DeleteTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTaskRequest) Reset() {
+	*x = DeleteTaskRequest{}
+	mi := &file_proto_task_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTaskRequest) ProtoMessage() {}
+
+func (x *DeleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type DeleteTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTaskResponse) Reset() {
+	*x = DeleteTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTaskResponse) ProtoMessage() {}
+
+func (x *DeleteTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTaskResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{8}
+}
+
+type SignalTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// name of the signal to send e.g. "SIGTERM" or "TERM"
+	Signal        string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalTaskRequest) Reset() {
+	*x = SignalTaskRequest{}
+	mi := &file_proto_task_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalTaskRequest) ProtoMessage() {}
+
+func (x *SignalTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalTaskRequest.ProtoReflect.Descriptor instead.
+func (*SignalTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SignalTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SignalTaskRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+type SignalTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalTaskResponse) Reset() {
+	*x = SignalTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalTaskResponse) ProtoMessage() {}
+
+func (x *SignalTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalTaskResponse.ProtoReflect.Descriptor instead.
+func (*SignalTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{10}
+}
+
+type PauseTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseTaskRequest) Reset() {
+	*x = PauseTaskRequest{}
+	mi := &file_proto_task_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseTaskRequest) ProtoMessage() {}
+
+func (x *PauseTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseTaskRequest.ProtoReflect.Descriptor instead.
+func (*PauseTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PauseTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type PauseTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseTaskResponse) Reset() {
+	*x = PauseTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseTaskResponse) ProtoMessage() {}
+
+func (x *PauseTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseTaskResponse.ProtoReflect.Descriptor instead.
+func (*PauseTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{12}
+}
+
+type ResumeTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeTaskRequest) Reset() {
+	*x = ResumeTaskRequest{}
+	mi := &file_proto_task_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeTaskRequest) ProtoMessage() {}
+
+func (x *ResumeTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeTaskRequest.ProtoReflect.Descriptor instead.
+func (*ResumeTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResumeTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type ResumeTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeTaskResponse) Reset() {
+	*x = ResumeTaskResponse{}
+	mi := &file_proto_task_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeTaskResponse) ProtoMessage() {}
+
+func (x *ResumeTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeTaskResponse.ProtoReflect.Descriptor instead.
+func (*ResumeTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{14}
+}
+
+// ResourceLimits are the cgroup limits of a task; a limit of 0 is unlimited ("max")
+type ResourceLimits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CPU time in thousandths of a CPU written to cpu.max, e.g. 500 for half a CPU
+	CpuMillis *int64 `protobuf:"varint,1,opt,name=cpu_millis,json=cpuMillis,proto3,oneof" json:"cpu_millis,omitempty"`
+	// memory in bytes above which the processes are OOM killed, written to memory.max
+	MemoryMaxBytes *int64 `protobuf:"varint,2,opt,name=memory_max_bytes,json=memoryMaxBytes,proto3,oneof" json:"memory_max_bytes,omitempty"`
+	// memory in bytes above which the processes are throttled and reclaimed, written to memory.high
+	MemoryHighBytes *int64 `protobuf:"varint,3,opt,name=memory_high_bytes,json=memoryHighBytes,proto3,oneof" json:"memory_high_bytes,omitempty"`
+	// bytes per second read from and written to the block device, written to io.max
+	IoReadBps  *int64 `protobuf:"varint,4,opt,name=io_read_bps,json=ioReadBps,proto3,oneof" json:"io_read_bps,omitempty"`
+	IoWriteBps *int64 `protobuf:"varint,5,opt,name=io_write_bps,json=ioWriteBps,proto3,oneof" json:"io_write_bps,omitempty"`
+	// number of processes and threads above which forks fail, written to pids.max
+	PidsMax *int64 `protobuf:"varint,6,opt,name=pids_max,json=pidsMax,proto3,oneof" json:"pids_max,omitempty"`
+	// CPUs and NUMA memory nodes the processes are pinned to as a list such as "0-3,8", written to cpuset.cpus and
+	// cpuset.mems; an empty list uses those of the parent cgroup
+	CpusetCpus    *string `protobuf:"bytes,7,opt,name=cpuset_cpus,json=cpusetCpus,proto3,oneof" json:"cpuset_cpus,omitempty"`
+	CpusetMems    *string `protobuf:"bytes,8,opt,name=cpuset_mems,json=cpusetMems,proto3,oneof" json:"cpuset_mems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceLimits) Reset() {
+	*x = ResourceLimits{}
+	mi := &file_proto_task_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceLimits) ProtoMessage() {}
+
+func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceLimits.ProtoReflect.Descriptor instead.
+func (*ResourceLimits) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResourceLimits) GetCpuMillis() int64 {
+	if x != nil && x.CpuMillis != nil {
+		return *x.CpuMillis
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetMemoryMaxBytes() int64 {
+	if x != nil && x.MemoryMaxBytes != nil {
+		return *x.MemoryMaxBytes
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetMemoryHighBytes() int64 {
+	if x != nil && x.MemoryHighBytes != nil {
+		return *x.MemoryHighBytes
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoReadBps() int64 {
+	if x != nil && x.IoReadBps != nil {
+		return *x.IoReadBps
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIoWriteBps() int64 {
+	if x != nil && x.IoWriteBps != nil {
+		return *x.IoWriteBps
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetPidsMax() int64 {
+	if x != nil && x.PidsMax != nil {
+		return *x.PidsMax
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetCpusetCpus() string {
+	if x != nil && x.CpusetCpus != nil {
+		return *x.CpusetCpus
+	}
+	return ""
+}
+
+func (x *ResourceLimits) GetCpusetMems() string {
+	if x != nil && x.CpusetMems != nil {
+		return *x.CpusetMems
+	}
+	return ""
+}
+
+// ResourceChange is a change of the limits of a task by UpdateTaskResources
+type ResourceChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// client that changed the limits
+	ClientId      string          `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Previous      *ResourceLimits `protobuf:"bytes,3,opt,name=previous,proto3" json:"previous,omitempty"`
+	Limits        *ResourceLimits `protobuf:"bytes,4,opt,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceChange) Reset() {
+	*x = ResourceChange{}
+	mi := &file_proto_task_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceChange) ProtoMessage() {}
+
+func (x *ResourceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceChange.ProtoReflect.Descriptor instead.
+func (*ResourceChange) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ResourceChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ResourceChange) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ResourceChange) GetPrevious() *ResourceLimits {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *ResourceChange) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type UpdateTaskResourcesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// limits to change; unset limits are kept. Limits above the server ceilings are rejected and owners may
+	// not exceed their resource quotas.
+	Limits        *ResourceLimits `protobuf:"bytes,2,opt,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskResourcesRequest) Reset() {
+	*x = UpdateTaskResourcesRequest{}
+	mi := &file_proto_task_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskResourcesRequest) ProtoMessage() {}
+
+func (x *UpdateTaskResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskResourcesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateTaskResourcesRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *UpdateTaskResourcesRequest) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type UpdateTaskResourcesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// effective limits of the task after the update
+	Limits        *ResourceLimits `protobuf:"bytes,1,opt,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskResourcesResponse) Reset() {
+	*x = UpdateTaskResourcesResponse{}
+	mi := &file_proto_task_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskResourcesResponse) ProtoMessage() {}
+
+func (x *UpdateTaskResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskResourcesResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTaskResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateTaskResourcesResponse) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type TaskStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskStatusRequest) Reset() {
+	*x = TaskStatusRequest{}
+	mi := &file_proto_task_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatusRequest) ProtoMessage() {}
+
+func (x *TaskStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatusRequest.ProtoReflect.Descriptor instead.
+func (*TaskStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TaskStatusRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type TaskStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// Exit code of the task; only set if task is not running
+	ExitCode *int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// PID of the process picked by the server
+	ProcessId int32 `protobuf:"varint,3,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	// job status tracks status of job
+	Status JobStatus `protobuf:"varint,4,opt,name=status,proto3,enum=task_manager.JobStatus" json:"status,omitempty"`
+	// type of signal used to kill process such as SIGTERM, SIGKILL;
+	TerminationSignal string `protobuf:"bytes,5,opt,name=termination_signal,json=terminationSignal,proto3" json:"termination_signal,omitempty"`
+	// user, system, oom, pids (the process failed after forks failed at pids.max), memory_pressure (stopped by the
+	// memory pressure policy), etc
+	TerminationSource string `protobuf:"bytes,6,opt,name=termination_source,json=terminationSource,proto3" json:"termination_source,omitempty"`
+	// Timestamp when the task started
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Timestamp when the task ended; only set if task is not running
+	EndTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// labels given when the task was started
+	Labels map[string]string `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// priority given when the task was started
+	Priority int32 `protobuf:"varint,10,opt,name=priority,proto3" json:"priority,omitempty"`
+	// 1-based position in the queue while the status is JOB_STATUS_QUEUED; 0 otherwise
+	QueuePosition int32 `protobuf:"varint,11,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+	// attempts of the task in order; the last one is the current attempt
+	Attempts []*TaskAttempt `protobuf:"bytes,12,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	// maximum number of attempts of the retry policy; 1 if the task is not retried and 0 if it has a restart policy
+	MaxAttempts int32 `protobuf:"varint,13,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// tasks the task depends on as given when the task was started
+	DependsOn []*TaskDependency `protobuf:"bytes,14,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// ID of the schedule that started the task; empty if the task was started by a client
+	ScheduleId string `protobuf:"bytes,15,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// number of times the process was restarted by the restart policy
+	RestartCount int32 `protobuf:"varint,16,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	// how the last process exited, e.g. "exit code 1" or "signal SIGKILL (oom)"; empty until a process exited
+	LastExitReason string `protobuf:"bytes,17,opt,name=last_exit_reason,json=lastExitReason,proto3" json:"last_exit_reason,omitempty"`
+	// total time the task has been paused, including the current pause while JOB_STATUS_PAUSED
+	PausedDuration *durationpb.Duration `protobuf:"bytes,18,opt,name=paused_duration,json=pausedDuration,proto3" json:"paused_duration,omitempty"`
+	// effective cgroup limits of the task
+	Limits *ResourceLimits `protobuf:"bytes,19,opt,name=limits,proto3" json:"limits,omitempty"`
+	// last changes of the limits, oldest first
+	ResourceChanges []*ResourceChange `protobuf:"bytes,20,rep,name=resource_changes,json=resourceChanges,proto3" json:"resource_changes,omitempty"`
+	// number of forks of the current or last process that failed because the task reached pids.max; read from
+	// pids.events while the process runs
+	PidsMaxEvents int64 `protobuf:"varint,21,opt,name=pids_max_events,json=pidsMaxEvents,proto3" json:"pids_max_events,omitempty"`
+	// memory.events counters of the current or last process and its memory pressure while it runs
+	Memory *MemoryStatus `protobuf:"bytes,22,opt,name=memory,proto3" json:"memory,omitempty"`
+	// last memory events of the task, oldest first
+	MemoryEvents  []*MemoryEvent `protobuf:"bytes,23,rep,name=memory_events,json=memoryEvents,proto3" json:"memory_events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskStatusResponse) Reset() {
+	*x = TaskStatusResponse{}
+	mi := &file_proto_task_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatusResponse) ProtoMessage() {}
+
+func (x *TaskStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatusResponse.ProtoReflect.Descriptor instead.
+func (*TaskStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TaskStatusResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskStatusResponse) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetProcessId() int32 {
+	if x != nil {
+		return x.ProcessId
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNKNOWN
+}
+
+func (x *TaskStatusResponse) GetTerminationSignal() string {
+	if x != nil {
+		return x.TerminationSignal
+	}
+	return ""
+}
+
+func (x *TaskStatusResponse) GetTerminationSource() string {
+	if x != nil {
+		return x.TerminationSource
+	}
+	return ""
+}
+
+func (x *TaskStatusResponse) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetAttempts() []*TaskAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetDependsOn() []*TaskDependency {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *TaskStatusResponse) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetLastExitReason() string {
+	if x != nil {
+		return x.LastExitReason
+	}
+	return ""
+}
+
+func (x *TaskStatusResponse) GetPausedDuration() *durationpb.Duration {
+	if x != nil {
+		return x.PausedDuration
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetResourceChanges() []*ResourceChange {
+	if x != nil {
+		return x.ResourceChanges
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetPidsMaxEvents() int64 {
+	if x != nil {
+		return x.PidsMaxEvents
+	}
+	return 0
+}
+
+func (x *TaskStatusResponse) GetMemory() *MemoryStatus {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+func (x *TaskStatusResponse) GetMemoryEvents() []*MemoryEvent {
+	if x != nil {
+		return x.MemoryEvents
+	}
+	return nil
+}
+
+// TaskAttempt is a single run of the process of a task
+type TaskAttempt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 1-based number of the attempt
+	Attempt   int32 `protobuf:"varint,1,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	ProcessId int32 `protobuf:"varint,2,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	// JOB_STATUS_STARTED while the attempt is running
+	Status    JobStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=task_manager.JobStatus" json:"status,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// only set once the attempt has ended
+	EndTime           *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	ExitCode          *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	TerminationSignal string                 `protobuf:"bytes,7,opt,name=termination_signal,json=terminationSignal,proto3" json:"termination_signal,omitempty"`
+	TerminationSource string                 `protobuf:"bytes,8,opt,name=termination_source,json=terminationSource,proto3" json:"termination_source,omitempty"`
+	// offset of the first byte of the attempt in the output stream of the task
+	OutputOffset int64 `protobuf:"varint,9,opt,name=output_offset,json=outputOffset,proto3" json:"output_offset,omitempty"`
+	// number of forks of the attempt that failed because it reached pids.max
+	PidsMaxEvents int64 `protobuf:"varint,10,opt,name=pids_max_events,json=pidsMaxEvents,proto3" json:"pids_max_events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskAttempt) Reset() {
+	*x = TaskAttempt{}
+	mi := &file_proto_task_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskAttempt) ProtoMessage() {}
+
+func (x *TaskAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskAttempt.ProtoReflect.Descriptor instead.
+func (*TaskAttempt) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TaskAttempt) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *TaskAttempt) GetProcessId() int32 {
+	if x != nil {
+		return x.ProcessId
+	}
+	return 0
+}
+
+func (x *TaskAttempt) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNKNOWN
+}
+
+func (x *TaskAttempt) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TaskAttempt) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *TaskAttempt) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *TaskAttempt) GetTerminationSignal() string {
+	if x != nil {
+		return x.TerminationSignal
+	}
+	return ""
+}
+
+func (x *TaskAttempt) GetTerminationSource() string {
+	if x != nil {
+		return x.TerminationSource
+	}
+	return ""
+}
+
+func (x *TaskAttempt) GetOutputOffset() int64 {
+	if x != nil {
+		return x.OutputOffset
+	}
+	return 0
+}
+
+func (x *TaskAttempt) GetPidsMaxEvents() int64 {
+	if x != nil {
+		return x.PidsMaxEvents
+	}
+	return 0
+}
+
+// MemoryStatus is the memory state of the process of a task
+type MemoryStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// how often the processes were throttled above memory.high and the usage reached memory.max
+	HighEvents int64 `protobuf:"varint,1,opt,name=high_events,json=highEvents,proto3" json:"high_events,omitempty"`
+	MaxEvents  int64 `protobuf:"varint,2,opt,name=max_events,json=maxEvents,proto3" json:"max_events,omitempty"`
+	// number of processes killed by the OOM killer
+	OomKillEvents int64 `protobuf:"varint,3,opt,name=oom_kill_events,json=oomKillEvents,proto3" json:"oom_kill_events,omitempty"`
+	// percentage of time in which some or all processes stalled on memory over the last 10 seconds, read from
+	// memory.pressure (PSI); only set while the process runs
+	SomePressure  float64 `protobuf:"fixed64,4,opt,name=some_pressure,json=somePressure,proto3" json:"some_pressure,omitempty"`
+	FullPressure  float64 `protobuf:"fixed64,5,opt,name=full_pressure,json=fullPressure,proto3" json:"full_pressure,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryStatus) Reset() {
+	*x = MemoryStatus{}
+	mi := &file_proto_task_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryStatus) ProtoMessage() {}
+
+func (x *MemoryStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryStatus.ProtoReflect.Descriptor instead.
+func (*MemoryStatus) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *MemoryStatus) GetHighEvents() int64 {
+	if x != nil {
+		return x.HighEvents
+	}
+	return 0
+}
+
+func (x *MemoryStatus) GetMaxEvents() int64 {
+	if x != nil {
+		return x.MaxEvents
+	}
+	return 0
+}
+
+func (x *MemoryStatus) GetOomKillEvents() int64 {
+	if x != nil {
+		return x.OomKillEvents
+	}
+	return 0
+}
+
+func (x *MemoryStatus) GetSomePressure() float64 {
+	if x != nil {
+		return x.SomePressure
+	}
+	return 0
+}
+
+func (x *MemoryStatus) GetFullPressure() float64 {
+	if x != nil {
+		return x.FullPressure
+	}
+	return 0
+}
+
+// MemoryEvent is a memory event of the process of a task
+type MemoryEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// attempt whose process the event occurred in
+	Attempt int32 `protobuf:"varint,2,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// high (throttled above memory.high), max (reached memory.max), oom_kill, pressure (processes stalled on
+	// memory) or stop (the memory pressure policy stopped the task)
+	Kind string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	// memory.events counter of the kind after the event; 0 for pressure and stop events
+	Count int64 `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// memory pressure when the event was recorded as in MemoryStatus
+	SomePressure  float64 `protobuf:"fixed64,5,opt,name=some_pressure,json=somePressure,proto3" json:"some_pressure,omitempty"`
+	FullPressure  float64 `protobuf:"fixed64,6,opt,name=full_pressure,json=fullPressure,proto3" json:"full_pressure,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryEvent) Reset() {
+	*x = MemoryEvent{}
+	mi := &file_proto_task_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryEvent) ProtoMessage() {}
+
+func (x *MemoryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryEvent.ProtoReflect.Descriptor instead.
+func (*MemoryEvent) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MemoryEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *MemoryEvent) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *MemoryEvent) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *MemoryEvent) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *MemoryEvent) GetSomePressure() float64 {
+	if x != nil {
+		return x.SomePressure
+	}
+	return 0
+}
+
+func (x *MemoryEvent) GetFullPressure() float64 {
+	if x != nil {
+		return x.FullPressure
+	}
+	return 0
+}
+
+type WatchMemoryEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMemoryEventsRequest) Reset() {
+	*x = WatchMemoryEventsRequest{}
+	mi := &file_proto_task_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMemoryEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMemoryEventsRequest) ProtoMessage() {}
+
+func (x *WatchMemoryEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMemoryEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchMemoryEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WatchMemoryEventsRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+// WatchMemoryEventsResponse contains one memory event; the kept events of the task are sent first
+type WatchMemoryEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *MemoryEvent           `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMemoryEventsResponse) Reset() {
+	*x = WatchMemoryEventsResponse{}
+	mi := &file_proto_task_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMemoryEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMemoryEventsResponse) ProtoMessage() {}
+
+func (x *WatchMemoryEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMemoryEventsResponse.ProtoReflect.Descriptor instead.
+func (*WatchMemoryEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WatchMemoryEventsResponse) GetEvent() *MemoryEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type StreamTaskOutputRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTaskOutputRequest) Reset() {
+	*x = StreamTaskOutputRequest{}
+	mi := &file_proto_task_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTaskOutputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTaskOutputRequest) ProtoMessage() {}
+
+func (x *StreamTaskOutputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTaskOutputRequest.ProtoReflect.Descriptor instead.
+func (*StreamTaskOutputRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StreamTaskOutputRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+// StreamTaskOutputResponse contains stdout and stderr output from the task
+// Each output line is sent as a separate message
+type StreamTaskOutputResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTaskOutputResponse) Reset() {
+	*x = StreamTaskOutputResponse{}
+	mi := &file_proto_task_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTaskOutputResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTaskOutputResponse) ProtoMessage() {}
+
+func (x *StreamTaskOutputResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTaskOutputResponse.ProtoReflect.Descriptor instead.
+func (*StreamTaskOutputResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *StreamTaskOutputResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type WaitTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 IDs of the tasks to wait for
+	TaskIds []string `protobuf:"bytes,1,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"`
+	// whether to wait for all or any of the tasks
+	Mode WaitMode `protobuf:"varint,2,opt,name=mode,proto3,enum=task_manager.WaitMode" json:"mode,omitempty"`
+	// maximum time to wait; the current statuses are returned when it expires. Waits until completion if not set
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitTasksRequest) Reset() {
+	*x = WaitTasksRequest{}
+	mi := &file_proto_task_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitTasksRequest) ProtoMessage() {}
+
+func (x *WaitTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitTasksRequest.ProtoReflect.Descriptor instead.
+func (*WaitTasksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *WaitTasksRequest) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+func (x *WaitTasksRequest) GetMode() WaitMode {
+	if x != nil {
+		return x.Mode
+	}
+	return WaitMode_WAIT_MODE_ALL
+}
+
+func (x *WaitTasksRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type WaitTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// statuses of the tasks in the order of the request
+	Statuses []*TaskStatusResponse `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	// false if the timeout expired before the tasks completed
+	Completed     bool `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitTasksResponse) Reset() {
+	*x = WaitTasksResponse{}
+	mi := &file_proto_task_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitTasksResponse) ProtoMessage() {}
+
+func (x *WaitTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitTasksResponse.ProtoReflect.Descriptor instead.
+func (*WaitTasksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *WaitTasksResponse) GetStatuses() []*TaskStatusResponse {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *WaitTasksResponse) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// comma-separated label selector e.g. "team=infra,env!=prod"; all tasks are listed if empty
+	LabelSelector string `protobuf:"bytes,1,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_proto_task_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListTasksRequest) GetLabelSelector() string {
+	if x != nil {
+		return x.LabelSelector
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// statuses of the matching tasks ordered by start time
+	Tasks         []*TaskStatusResponse `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_proto_task_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListTasksResponse) GetTasks() []*TaskStatusResponse {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+// TaskSelector selects tasks for bulk operations. All set fields must match and at least one must be set.
+type TaskSelector struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// comma-separated label selector e.g. "team=infra,env!=prod"
+	LabelSelector string `protobuf:"bytes,1,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+	// statuses of the tasks to select; only running tasks, and queued tasks when stopping, are selected if empty
+	Statuses []JobStatus `protobuf:"varint,2,rep,packed,name=statuses,proto3,enum=task_manager.JobStatus" json:"statuses,omitempty"`
+	// client ID owning the tasks; clients other than admin only ever select their own tasks
+	Owner string `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	// only select tasks started before this time; queued tasks have not started
+	StartedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_before,json=startedBefore,proto3" json:"started_before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskSelector) Reset() {
+	*x = TaskSelector{}
+	mi := &file_proto_task_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskSelector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskSelector) ProtoMessage() {}
+
+func (x *TaskSelector) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskSelector.ProtoReflect.Descriptor instead.
+func (*TaskSelector) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TaskSelector) GetLabelSelector() string {
+	if x != nil {
+		return x.LabelSelector
+	}
+	return ""
+}
+
+func (x *TaskSelector) GetStatuses() []JobStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *TaskSelector) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TaskSelector) GetStartedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedBefore
+	}
+	return nil
+}
+
+// TaskResult is the outcome of a bulk operation on a single task
+type TaskResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the task generated by the server
+	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// google.rpc.Code of the operation on the task; 0 (OK) on success
+	Code int32 `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	// error message if the operation failed
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskResult) Reset() {
+	*x = TaskResult{}
+	mi := &file_proto_task_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResult) ProtoMessage() {}
+
+func (x *TaskResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TaskResult) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *TaskResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StopTasksRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Selector *TaskSelector          `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	// only report the tasks that would be stopped
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTasksRequest) Reset() {
+	*x = StopTasksRequest{}
+	mi := &file_proto_task_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTasksRequest) ProtoMessage() {}
+
+func (x *StopTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTasksRequest.ProtoReflect.Descriptor instead.
+func (*StopTasksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *StopTasksRequest) GetSelector() *TaskSelector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *StopTasksRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type StopTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// results ordered by task start time
+	Results       []*TaskResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTasksResponse) Reset() {
+	*x = StopTasksResponse{}
+	mi := &file_proto_task_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTasksResponse) ProtoMessage() {}
+
+func (x *StopTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTasksResponse.ProtoReflect.Descriptor instead.
+func (*StopTasksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *StopTasksResponse) GetResults() []*TaskResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SignalTasksRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Selector *TaskSelector          `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	// name of the signal to send e.g. "SIGTERM" or "TERM"
+	Signal string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	// only report the tasks that would be signaled
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalTasksRequest) Reset() {
+	*x = SignalTasksRequest{}
+	mi := &file_proto_task_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalTasksRequest) ProtoMessage() {}
+
+func (x *SignalTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalTasksRequest.ProtoReflect.Descriptor instead.
+func (*SignalTasksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SignalTasksRequest) GetSelector() *TaskSelector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *SignalTasksRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *SignalTasksRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SignalTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// results ordered by task start time
+	Results       []*TaskResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalTasksResponse) Reset() {
+	*x = SignalTasksResponse{}
+	mi := &file_proto_task_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalTasksResponse) ProtoMessage() {}
+
+func (x *SignalTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalTasksResponse.ProtoReflect.Descriptor instead.
+func (*SignalTasksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SignalTasksResponse) GetResults() []*TaskResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetQuotaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// client to get the usage of; defaults to the caller and only admins may set another client
+	ClientId      string `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaRequest) Reset() {
+	*x = GetQuotaRequest{}
+	mi := &file_proto_task_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaRequest) ProtoMessage() {}
+
+func (x *GetQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetQuotaRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+// QuotaUsage is the usage of a single quota
+type QuotaUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Used  int64                  `protobuf:"varint,1,opt,name=used,proto3" json:"used,omitempty"`
+	// 0 if the quota is unlimited
+	Limit         int64 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_proto_task_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *QuotaUsage) GetUsed() int64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetQuotaResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ClientId string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// running tasks of the client
+	RunningTasks *QuotaUsage `protobuf:"bytes,2,opt,name=running_tasks,json=runningTasks,proto3" json:"running_tasks,omitempty"`
+	// running tasks of all clients
+	GlobalRunningTasks *QuotaUsage `protobuf:"bytes,3,opt,name=global_running_tasks,json=globalRunningTasks,proto3" json:"global_running_tasks,omitempty"`
+	// tasks started by the client in the last minute
+	StartsPerMinute *QuotaUsage `protobuf:"bytes,4,opt,name=starts_per_minute,json=startsPerMinute,proto3" json:"starts_per_minute,omitempty"`
+	// tasks of all clients waiting for a running task quota to free up
+	QueuedTasks   *QuotaUsage `protobuf:"bytes,5,opt,name=queued_tasks,json=queuedTasks,proto3" json:"queued_tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaResponse) Reset() {
+	*x = GetQuotaResponse{}
+	mi := &file_proto_task_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaResponse) ProtoMessage() {}
+
+func (x *GetQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetQuotaResponse) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *GetQuotaResponse) GetRunningTasks() *QuotaUsage {
+	if x != nil {
+		return x.RunningTasks
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetGlobalRunningTasks() *QuotaUsage {
+	if x != nil {
+		return x.GlobalRunningTasks
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetStartsPerMinute() *QuotaUsage {
+	if x != nil {
+		return x.StartsPerMinute
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetQueuedTasks() *QuotaUsage {
+	if x != nil {
+		return x.QueuedTasks
+	}
+	return nil
+}
+
+// TaskTemplate describes the tasks a schedule starts; the fields are those of StartTaskRequest and the limits of
+// UpdateTaskResources
+type TaskTemplate struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Command     string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Args        []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	Labels      map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Priority    int32                  `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	RetryPolicy *RetryPolicy           `protobuf:"bytes,5,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	// optional policy restarting the process of every task; may not be combined with a retry policy
+	RestartPolicy *RestartPolicy `protobuf:"bytes,6,opt,name=restart_policy,json=restartPolicy,proto3" json:"restart_policy,omitempty"`
+	// cgroup limits of every task; unset limits are the server defaults. Limits above the server ceilings are
+	// rejected when the schedule is created.
+	Limits        *ResourceLimits `protobuf:"bytes,7,opt,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskTemplate) Reset() {
+	*x = TaskTemplate{}
+	mi := &file_proto_task_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskTemplate) ProtoMessage() {}
+
+func (x *TaskTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskTemplate.ProtoReflect.Descriptor instead.
+func (*TaskTemplate) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *TaskTemplate) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *TaskTemplate) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *TaskTemplate) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TaskTemplate) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *TaskTemplate) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *TaskTemplate) GetRestartPolicy() *RestartPolicy {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return nil
+}
+
+func (x *TaskTemplate) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type Schedule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID v4 ID of the schedule generated by the server
+	ScheduleId string `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// client that created the schedule and owns the tasks it starts
+	ClientId string `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// standard 5 field cron expression (e.g. "*/5 * * * *") or a descriptor such as "@hourly" or "@every 10m"
+	CronExpression    string                 `protobuf:"bytes,3,opt,name=cron_expression,json=cronExpression,proto3" json:"cron_expression,omitempty"`
+	Template          *TaskTemplate          `protobuf:"bytes,4,opt,name=template,proto3" json:"template,omitempty"`
+	ConcurrencyPolicy ConcurrencyPolicy      `protobuf:"varint,5,opt,name=concurrency_policy,json=concurrencyPolicy,proto3,enum=task_manager.ConcurrencyPolicy" json:"concurrency_policy,omitempty"`
+	Paused            bool                   `protobuf:"varint,6,opt,name=paused,proto3" json:"paused,omitempty"`
+	CreateTime        *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// time of the next run; unset while the schedule is paused
+	NextRunTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=next_run_time,json=nextRunTime,proto3" json:"next_run_time,omitempty"`
+	// time of the last run; unset if the schedule has not run yet
+	LastRunTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_run_time,json=lastRunTime,proto3" json:"last_run_time,omitempty"`
+	// task started by the last run; empty if the run was skipped or failed to start a task
+	LastTaskId string `protobuf:"bytes,10,opt,name=last_task_id,json=lastTaskId,proto3" json:"last_task_id,omitempty"`
+	// unfinished tasks started by the schedule
+	ActiveTaskIds []string `protobuf:"bytes,11,rep,name=active_task_ids,json=activeTaskIds,proto3" json:"active_task_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_proto_task_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartTaskRequest) ProtoMessage() {}
+func (*Schedule) ProtoMessage() {}
 
-func (x *StartTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[0]
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -119,48 +3097,114 @@ func (x *StartTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartTaskRequest.ProtoReflect.Descriptor instead.
-func (*StartTaskRequest) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *StartTaskRequest) GetCommand() string {
+func (x *Schedule) GetScheduleId() string {
 	if x != nil {
-		return x.Command
+		return x.ScheduleId
 	}
 	return ""
 }
 
-func (x *StartTaskRequest) GetArgs() []string {
+func (x *Schedule) GetClientId() string {
 	if x != nil {
-		return x.Args
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *Schedule) GetCronExpression() string {
+	if x != nil {
+		return x.CronExpression
+	}
+	return ""
+}
+
+func (x *Schedule) GetTemplate() *TaskTemplate {
+	if x != nil {
+		return x.Template
 	}
 	return nil
 }
 
-type StartTaskResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// UUID v4 ID of the task generated by the server
-	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+func (x *Schedule) GetConcurrencyPolicy() ConcurrencyPolicy {
+	if x != nil {
+		return x.ConcurrencyPolicy
+	}
+	return ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED
+}
+
+func (x *Schedule) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Schedule) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Schedule) GetNextRunTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRunTime
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastRunTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRunTime
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastTaskId() string {
+	if x != nil {
+		return x.LastTaskId
+	}
+	return ""
+}
+
+func (x *Schedule) GetActiveTaskIds() []string {
+	if x != nil {
+		return x.ActiveTaskIds
+	}
+	return nil
+}
+
+type CreateScheduleRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	CronExpression    string                 `protobuf:"bytes,1,opt,name=cron_expression,json=cronExpression,proto3" json:"cron_expression,omitempty"`
+	Template          *TaskTemplate          `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	ConcurrencyPolicy ConcurrencyPolicy      `protobuf:"varint,3,opt,name=concurrency_policy,json=concurrencyPolicy,proto3,enum=task_manager.ConcurrencyPolicy" json:"concurrency_policy,omitempty"`
+	// create the schedule paused
+	Paused        bool `protobuf:"varint,4,opt,name=paused,proto3" json:"paused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StartTaskResponse) Reset() {
-	*x = StartTaskResponse{}
-	mi := &file_proto_task_proto_msgTypes[1]
+func (x *CreateScheduleRequest) Reset() {
+	*x = CreateScheduleRequest{}
+	mi := &file_proto_task_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartTaskResponse) String() string {
+func (x *CreateScheduleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartTaskResponse) ProtoMessage() {}
+func (*CreateScheduleRequest) ProtoMessage() {}
 
-func (x *StartTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[1]
+func (x *CreateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -171,41 +3215,61 @@ func (x *StartTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartTaskResponse.ProtoReflect.Descriptor instead.
-func (*StartTaskResponse) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use CreateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *StartTaskResponse) GetTaskId() string {
+func (x *CreateScheduleRequest) GetCronExpression() string {
 	if x != nil {
-		return x.TaskId
+		return x.CronExpression
 	}
 	return ""
 }
 
-type StopTaskRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// UUID v4 ID of the task generated by the server
-	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+func (x *CreateScheduleRequest) GetTemplate() *TaskTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+func (x *CreateScheduleRequest) GetConcurrencyPolicy() ConcurrencyPolicy {
+	if x != nil {
+		return x.ConcurrencyPolicy
+	}
+	return ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED
+}
+
+func (x *CreateScheduleRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type CreateScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopTaskRequest) Reset() {
-	*x = StopTaskRequest{}
-	mi := &file_proto_task_proto_msgTypes[2]
+func (x *CreateScheduleResponse) Reset() {
+	*x = CreateScheduleResponse{}
+	mi := &file_proto_task_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopTaskRequest) String() string {
+func (x *CreateScheduleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopTaskRequest) ProtoMessage() {}
+func (*CreateScheduleResponse) ProtoMessage() {}
 
-func (x *StopTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[2]
+func (x *CreateScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -216,39 +3280,39 @@ func (x *StopTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopTaskRequest.ProtoReflect.Descriptor instead.
-func (*StopTaskRequest) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use CreateScheduleResponse.ProtoReflect.Descriptor instead.
+func (*CreateScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *StopTaskRequest) GetTaskId() string {
+func (x *CreateScheduleResponse) GetSchedule() *Schedule {
 	if x != nil {
-		return x.TaskId
+		return x.Schedule
 	}
-	return ""
+	return nil
 }
 
-type StopTaskResponse struct {
+type ListSchedulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopTaskResponse) Reset() {
-	*x = StopTaskResponse{}
-	mi := &file_proto_task_proto_msgTypes[3]
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_proto_task_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopTaskResponse) String() string {
+func (x *ListSchedulesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopTaskResponse) ProtoMessage() {}
+func (*ListSchedulesRequest) ProtoMessage() {}
 
-func (x *StopTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[3]
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -259,34 +3323,33 @@ func (x *StopTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopTaskResponse.ProtoReflect.Descriptor instead.
-func (*StopTaskResponse) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{45}
 }
 
-type TaskStatusRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// UUID v4 ID of the task generated by the server
-	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskStatusRequest) Reset() {
-	*x = TaskStatusRequest{}
-	mi := &file_proto_task_proto_msgTypes[4]
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_proto_task_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatusRequest) String() string {
+func (x *ListSchedulesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskStatusRequest) ProtoMessage() {}
+func (*ListSchedulesResponse) ProtoMessage() {}
 
-func (x *TaskStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[4]
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -297,55 +3360,41 @@ func (x *TaskStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskStatusRequest.ProtoReflect.Descriptor instead.
-func (*TaskStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *TaskStatusRequest) GetTaskId() string {
+func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
 	if x != nil {
-		return x.TaskId
+		return x.Schedules
 	}
-	return ""
+	return nil
 }
 
-type TaskStatusResponse struct {
+type DeleteScheduleRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// UUID v4 ID of the task generated by the server
-	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	// Exit code of the task; only set if task is not running
-	ExitCode *int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
-	// PID of the process picked by the server
-	ProcessId int32 `protobuf:"varint,3,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
-	// job status tracks status of job
-	Status JobStatus `protobuf:"varint,4,opt,name=status,proto3,enum=task_manager.JobStatus" json:"status,omitempty"`
-	// type of signal used to kill process such as SIGTERM, SIGKILL;
-	TerminationSignal string `protobuf:"bytes,5,opt,name=termination_signal,json=terminationSignal,proto3" json:"termination_signal,omitempty"`
-	// user, system, oom, etc
-	TerminationSource string `protobuf:"bytes,6,opt,name=termination_source,json=terminationSource,proto3" json:"termination_source,omitempty"`
-	// Timestamp when the task started
-	StartTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	// Timestamp when the task ended; only set if task is not running
-	EndTime       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// UUID v4 ID of the schedule generated by the server
+	ScheduleId    string `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskStatusResponse) Reset() {
-	*x = TaskStatusResponse{}
-	mi := &file_proto_task_proto_msgTypes[5]
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_proto_task_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatusResponse) String() string {
+func (x *DeleteScheduleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskStatusResponse) ProtoMessage() {}
+func (*DeleteScheduleRequest) ProtoMessage() {}
 
-func (x *TaskStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[5]
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -356,90 +3405,79 @@ func (x *TaskStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskStatusResponse.ProtoReflect.Descriptor instead.
-func (*TaskStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *TaskStatusResponse) GetTaskId() string {
+func (x *DeleteScheduleRequest) GetScheduleId() string {
 	if x != nil {
-		return x.TaskId
+		return x.ScheduleId
 	}
 	return ""
 }
 
-func (x *TaskStatusResponse) GetExitCode() int32 {
-	if x != nil && x.ExitCode != nil {
-		return *x.ExitCode
-	}
-	return 0
-}
-
-func (x *TaskStatusResponse) GetProcessId() int32 {
-	if x != nil {
-		return x.ProcessId
-	}
-	return 0
+type DeleteScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskStatusResponse) GetStatus() JobStatus {
-	if x != nil {
-		return x.Status
-	}
-	return JobStatus_JOB_STATUS_UNKNOWN
+func (x *DeleteScheduleResponse) Reset() {
+	*x = DeleteScheduleResponse{}
+	mi := &file_proto_task_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatusResponse) GetTerminationSignal() string {
-	if x != nil {
-		return x.TerminationSignal
-	}
-	return ""
+func (x *DeleteScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *TaskStatusResponse) GetTerminationSource() string {
-	if x != nil {
-		return x.TerminationSource
-	}
-	return ""
-}
+func (*DeleteScheduleResponse) ProtoMessage() {}
 
-func (x *TaskStatusResponse) GetStartTime() *timestamppb.Timestamp {
+func (x *DeleteScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[48]
 	if x != nil {
-		return x.StartTime
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *TaskStatusResponse) GetEndTime() *timestamppb.Timestamp {
-	if x != nil {
-		return x.EndTime
-	}
-	return nil
+// Deprecated: Use DeleteScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{48}
 }
 
-type StreamTaskOutputRequest struct {
+type PauseScheduleRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// UUID v4 ID of the task generated by the server
-	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// UUID v4 ID of the schedule generated by the server
+	ScheduleId string `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// true pauses the schedule and false resumes it
+	Paused        bool `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamTaskOutputRequest) Reset() {
-	*x = StreamTaskOutputRequest{}
-	mi := &file_proto_task_proto_msgTypes[6]
+func (x *PauseScheduleRequest) Reset() {
+	*x = PauseScheduleRequest{}
+	mi := &file_proto_task_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamTaskOutputRequest) String() string {
+func (x *PauseScheduleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamTaskOutputRequest) ProtoMessage() {}
+func (*PauseScheduleRequest) ProtoMessage() {}
 
-func (x *StreamTaskOutputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[6]
+func (x *PauseScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,42 +3488,47 @@ func (x *StreamTaskOutputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamTaskOutputRequest.ProtoReflect.Descriptor instead.
-func (*StreamTaskOutputRequest) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use PauseScheduleRequest.ProtoReflect.Descriptor instead.
+func (*PauseScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *StreamTaskOutputRequest) GetTaskId() string {
+func (x *PauseScheduleRequest) GetScheduleId() string {
 	if x != nil {
-		return x.TaskId
+		return x.ScheduleId
 	}
 	return ""
 }
 
-// StreamTaskOutputResponse contains stdout and stderr output from the task
-// Each output line is sent as a separate message
-type StreamTaskOutputResponse struct {
+func (x *PauseScheduleRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type PauseScheduleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StreamTaskOutputResponse) Reset() {
-	*x = StreamTaskOutputResponse{}
-	mi := &file_proto_task_proto_msgTypes[7]
+func (x *PauseScheduleResponse) Reset() {
+	*x = PauseScheduleResponse{}
+	mi := &file_proto_task_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamTaskOutputResponse) String() string {
+func (x *PauseScheduleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamTaskOutputResponse) ProtoMessage() {}
+func (*PauseScheduleResponse) ProtoMessage() {}
 
-func (x *StreamTaskOutputResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_task_proto_msgTypes[7]
+func (x *PauseScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_task_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -496,14 +3539,14 @@ func (x *StreamTaskOutputResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamTaskOutputResponse.ProtoReflect.Descriptor instead.
-func (*StreamTaskOutputResponse) Descriptor() ([]byte, []int) {
-	return file_proto_task_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use PauseScheduleResponse.ProtoReflect.Descriptor instead.
+func (*PauseScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_task_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *StreamTaskOutputResponse) GetOutput() []byte {
+func (x *PauseScheduleResponse) GetSchedule() *Schedule {
 	if x != nil {
-		return x.Output
+		return x.Schedule
 	}
 	return nil
 }
@@ -512,17 +3555,89 @@ var File_proto_task_proto protoreflect.FileDescriptor
 
 const file_proto_task_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/task.proto\x12\ftask_manager\x1a\x1fgoogle/protobuf/timestamp.proto\"@\n" +
+	"\x10proto/task.proto\x12\ftask_manager\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf9\x03\n" +
 	"\x10StartTaskRequest\x12\x18\n" +
 	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
-	"\x04args\x18\x02 \x03(\tR\x04args\",\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12B\n" +
+	"\x06labels\x18\x03 \x03(\v2*.task_manager.StartTaskRequest.LabelsEntryR\x06labels\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12<\n" +
+	"\fretry_policy\x18\x06 \x01(\v2\x19.task_manager.RetryPolicyR\vretryPolicy\x12;\n" +
+	"\n" +
+	"depends_on\x18\a \x03(\v2\x1c.task_manager.TaskDependencyR\tdependsOn\x12B\n" +
+	"\x0erestart_policy\x18\b \x01(\v2\x1b.task_manager.RestartPolicyR\rrestartPolicy\x124\n" +
+	"\x06limits\x18\t \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x02\n" +
+	"\rRestartPolicy\x12-\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x19.task_manager.RestartModeR\x04mode\x12B\n" +
+	"\x0finitial_backoff\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x0einitialBackoff\x12:\n" +
+	"\vmax_backoff\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxBackoff\x12!\n" +
+	"\fmax_restarts\x18\x04 \x01(\x05R\vmaxRestarts\x12@\n" +
+	"\x0erestart_window\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\rrestartWindow\"j\n" +
+	"\x0eTaskDependency\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12?\n" +
+	"\tcondition\x18\x02 \x01(\x0e2!.task_manager.DependencyConditionR\tcondition\"\x96\x02\n" +
+	"\vRetryPolicy\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x12B\n" +
+	"\x0finitial_backoff\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x0einitialBackoff\x12:\n" +
+	"\vmax_backoff\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxBackoff\x12-\n" +
+	"\x12backoff_multiplier\x18\x04 \x01(\x01R\x11backoffMultiplier\x125\n" +
+	"\bretry_on\x18\x05 \x03(\x0e2\x1a.task_manager.RetryOutcomeR\aretryOn\",\n" +
 	"\x11StartTaskResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\"*\n" +
 	"\x0fStopTaskRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x12\n" +
 	"\x10StopTaskResponse\",\n" +
+	"\x11DeleteTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x14\n" +
+	"\x12DeleteTaskResponse\"D\n" +
+	"\x11SignalTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06signal\x18\x02 \x01(\tR\x06signal\"\x14\n" +
+	"\x12SignalTaskResponse\"+\n" +
+	"\x10PauseTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x13\n" +
+	"\x11PauseTaskResponse\",\n" +
+	"\x11ResumeTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x14\n" +
+	"\x12ResumeTaskResponse\"\xd4\x03\n" +
+	"\x0eResourceLimits\x12\"\n" +
+	"\n" +
+	"cpu_millis\x18\x01 \x01(\x03H\x00R\tcpuMillis\x88\x01\x01\x12-\n" +
+	"\x10memory_max_bytes\x18\x02 \x01(\x03H\x01R\x0ememoryMaxBytes\x88\x01\x01\x12/\n" +
+	"\x11memory_high_bytes\x18\x03 \x01(\x03H\x02R\x0fmemoryHighBytes\x88\x01\x01\x12#\n" +
+	"\vio_read_bps\x18\x04 \x01(\x03H\x03R\tioReadBps\x88\x01\x01\x12%\n" +
+	"\fio_write_bps\x18\x05 \x01(\x03H\x04R\n" +
+	"ioWriteBps\x88\x01\x01\x12\x1e\n" +
+	"\bpids_max\x18\x06 \x01(\x03H\x05R\apidsMax\x88\x01\x01\x12$\n" +
+	"\vcpuset_cpus\x18\a \x01(\tH\x06R\n" +
+	"cpusetCpus\x88\x01\x01\x12$\n" +
+	"\vcpuset_mems\x18\b \x01(\tH\aR\n" +
+	"cpusetMems\x88\x01\x01B\r\n" +
+	"\v_cpu_millisB\x13\n" +
+	"\x11_memory_max_bytesB\x14\n" +
+	"\x12_memory_high_bytesB\x0e\n" +
+	"\f_io_read_bpsB\x0f\n" +
+	"\r_io_write_bpsB\v\n" +
+	"\t_pids_maxB\x0e\n" +
+	"\f_cpuset_cpusB\x0e\n" +
+	"\f_cpuset_mems\"\xcd\x01\n" +
+	"\x0eResourceChange\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x128\n" +
+	"\bprevious\x18\x03 \x01(\v2\x1c.task_manager.ResourceLimitsR\bprevious\x124\n" +
+	"\x06limits\x18\x04 \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\"k\n" +
+	"\x1aUpdateTaskResourcesRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x124\n" +
+	"\x06limits\x18\x02 \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\"S\n" +
+	"\x1bUpdateTaskResourcesResponse\x124\n" +
+	"\x06limits\x18\x01 \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\",\n" +
 	"\x11TaskStatusRequest\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xfd\x02\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xa7\t\n" +
 	"\x12TaskStatusResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12 \n" +
 	"\texit_code\x18\x02 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12\x1d\n" +
@@ -533,24 +3648,217 @@ const file_proto_task_proto_rawDesc = "" +
 	"\x12termination_source\x18\x06 \x01(\tR\x11terminationSource\x129\n" +
 	"\n" +
 	"start_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bend_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\aendTimeB\f\n" +
+	"\bend_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12D\n" +
+	"\x06labels\x18\t \x03(\v2,.task_manager.TaskStatusResponse.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bpriority\x18\n" +
+	" \x01(\x05R\bpriority\x12%\n" +
+	"\x0equeue_position\x18\v \x01(\x05R\rqueuePosition\x125\n" +
+	"\battempts\x18\f \x03(\v2\x19.task_manager.TaskAttemptR\battempts\x12!\n" +
+	"\fmax_attempts\x18\r \x01(\x05R\vmaxAttempts\x12;\n" +
+	"\n" +
+	"depends_on\x18\x0e \x03(\v2\x1c.task_manager.TaskDependencyR\tdependsOn\x12\x1f\n" +
+	"\vschedule_id\x18\x0f \x01(\tR\n" +
+	"scheduleId\x12#\n" +
+	"\rrestart_count\x18\x10 \x01(\x05R\frestartCount\x12(\n" +
+	"\x10last_exit_reason\x18\x11 \x01(\tR\x0elastExitReason\x12B\n" +
+	"\x0fpaused_duration\x18\x12 \x01(\v2\x19.google.protobuf.DurationR\x0epausedDuration\x124\n" +
+	"\x06limits\x18\x13 \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\x12G\n" +
+	"\x10resource_changes\x18\x14 \x03(\v2\x1c.task_manager.ResourceChangeR\x0fresourceChanges\x12&\n" +
+	"\x0fpids_max_events\x18\x15 \x01(\x03R\rpidsMaxEvents\x122\n" +
+	"\x06memory\x18\x16 \x01(\v2\x1a.task_manager.MemoryStatusR\x06memory\x12>\n" +
+	"\rmemory_events\x18\x17 \x03(\v2\x19.task_manager.MemoryEventR\fmemoryEvents\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_exit_code\"\xc4\x03\n" +
+	"\vTaskAttempt\x12\x18\n" +
+	"\aattempt\x18\x01 \x01(\x05R\aattempt\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x02 \x01(\x05R\tprocessId\x12/\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x17.task_manager.JobStatusR\x06status\x129\n" +
 	"\n" +
-	"_exit_code\"2\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12 \n" +
+	"\texit_code\x18\x06 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12-\n" +
+	"\x12termination_signal\x18\a \x01(\tR\x11terminationSignal\x12-\n" +
+	"\x12termination_source\x18\b \x01(\tR\x11terminationSource\x12#\n" +
+	"\routput_offset\x18\t \x01(\x03R\foutputOffset\x12&\n" +
+	"\x0fpids_max_events\x18\n" +
+	" \x01(\x03R\rpidsMaxEventsB\f\n" +
+	"\n" +
+	"_exit_code\"\xc0\x01\n" +
+	"\fMemoryStatus\x12\x1f\n" +
+	"\vhigh_events\x18\x01 \x01(\x03R\n" +
+	"highEvents\x12\x1d\n" +
+	"\n" +
+	"max_events\x18\x02 \x01(\x03R\tmaxEvents\x12&\n" +
+	"\x0foom_kill_events\x18\x03 \x01(\x03R\roomKillEvents\x12#\n" +
+	"\rsome_pressure\x18\x04 \x01(\x01R\fsomePressure\x12#\n" +
+	"\rfull_pressure\x18\x05 \x01(\x01R\ffullPressure\"\xcb\x01\n" +
+	"\vMemoryEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x18\n" +
+	"\aattempt\x18\x02 \x01(\x05R\aattempt\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x03R\x05count\x12#\n" +
+	"\rsome_pressure\x18\x05 \x01(\x01R\fsomePressure\x12#\n" +
+	"\rfull_pressure\x18\x06 \x01(\x01R\ffullPressure\"3\n" +
+	"\x18WatchMemoryEventsRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"L\n" +
+	"\x19WatchMemoryEventsResponse\x12/\n" +
+	"\x05event\x18\x01 \x01(\v2\x19.task_manager.MemoryEventR\x05event\"2\n" +
 	"\x17StreamTaskOutputRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\"2\n" +
 	"\x18StreamTaskOutputResponse\x12\x16\n" +
-	"\x06output\x18\x01 \x01(\fR\x06output*\x8b\x01\n" +
+	"\x06output\x18\x01 \x01(\fR\x06output\"\x8e\x01\n" +
+	"\x10WaitTasksRequest\x12\x19\n" +
+	"\btask_ids\x18\x01 \x03(\tR\ataskIds\x12*\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x16.task_manager.WaitModeR\x04mode\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"o\n" +
+	"\x11WaitTasksResponse\x12<\n" +
+	"\bstatuses\x18\x01 \x03(\v2 .task_manager.TaskStatusResponseR\bstatuses\x12\x1c\n" +
+	"\tcompleted\x18\x02 \x01(\bR\tcompleted\"9\n" +
+	"\x10ListTasksRequest\x12%\n" +
+	"\x0elabel_selector\x18\x01 \x01(\tR\rlabelSelector\"K\n" +
+	"\x11ListTasksResponse\x126\n" +
+	"\x05tasks\x18\x01 \x03(\v2 .task_manager.TaskStatusResponseR\x05tasks\"\xc3\x01\n" +
+	"\fTaskSelector\x12%\n" +
+	"\x0elabel_selector\x18\x01 \x01(\tR\rlabelSelector\x123\n" +
+	"\bstatuses\x18\x02 \x03(\x0e2\x17.task_manager.JobStatusR\bstatuses\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12A\n" +
+	"\x0estarted_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rstartedBefore\"S\n" +
+	"\n" +
+	"TaskResult\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"c\n" +
+	"\x10StopTasksRequest\x126\n" +
+	"\bselector\x18\x01 \x01(\v2\x1a.task_manager.TaskSelectorR\bselector\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"G\n" +
+	"\x11StopTasksResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.task_manager.TaskResultR\aresults\"}\n" +
+	"\x12SignalTasksRequest\x126\n" +
+	"\bselector\x18\x01 \x01(\v2\x1a.task_manager.TaskSelectorR\bselector\x12\x16\n" +
+	"\x06signal\x18\x02 \x01(\tR\x06signal\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"I\n" +
+	"\x13SignalTasksResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.task_manager.TaskResultR\aresults\".\n" +
+	"\x0fGetQuotaRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\"6\n" +
+	"\n" +
+	"QuotaUsage\x12\x12\n" +
+	"\x04used\x18\x01 \x01(\x03R\x04used\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"\xbd\x02\n" +
+	"\x10GetQuotaResponse\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12=\n" +
+	"\rrunning_tasks\x18\x02 \x01(\v2\x18.task_manager.QuotaUsageR\frunningTasks\x12J\n" +
+	"\x14global_running_tasks\x18\x03 \x01(\v2\x18.task_manager.QuotaUsageR\x12globalRunningTasks\x12D\n" +
+	"\x11starts_per_minute\x18\x04 \x01(\v2\x18.task_manager.QuotaUsageR\x0fstartsPerMinute\x12;\n" +
+	"\fqueued_tasks\x18\x05 \x01(\v2\x18.task_manager.QuotaUsageR\vqueuedTasks\"\x8b\x03\n" +
+	"\fTaskTemplate\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12>\n" +
+	"\x06labels\x18\x03 \x03(\v2&.task_manager.TaskTemplate.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\x05R\bpriority\x12<\n" +
+	"\fretry_policy\x18\x05 \x01(\v2\x19.task_manager.RetryPolicyR\vretryPolicy\x12B\n" +
+	"\x0erestart_policy\x18\x06 \x01(\v2\x1b.task_manager.RestartPolicyR\rrestartPolicy\x124\n" +
+	"\x06limits\x18\a \x01(\v2\x1c.task_manager.ResourceLimitsR\x06limits\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x98\x04\n" +
+	"\bSchedule\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x12'\n" +
+	"\x0fcron_expression\x18\x03 \x01(\tR\x0ecronExpression\x126\n" +
+	"\btemplate\x18\x04 \x01(\v2\x1a.task_manager.TaskTemplateR\btemplate\x12N\n" +
+	"\x12concurrency_policy\x18\x05 \x01(\x0e2\x1f.task_manager.ConcurrencyPolicyR\x11concurrencyPolicy\x12\x16\n" +
+	"\x06paused\x18\x06 \x01(\bR\x06paused\x12;\n" +
+	"\vcreate_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12>\n" +
+	"\rnext_run_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vnextRunTime\x12>\n" +
+	"\rlast_run_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vlastRunTime\x12 \n" +
+	"\flast_task_id\x18\n" +
+	" \x01(\tR\n" +
+	"lastTaskId\x12&\n" +
+	"\x0factive_task_ids\x18\v \x03(\tR\ractiveTaskIds\"\xe0\x01\n" +
+	"\x15CreateScheduleRequest\x12'\n" +
+	"\x0fcron_expression\x18\x01 \x01(\tR\x0ecronExpression\x126\n" +
+	"\btemplate\x18\x02 \x01(\v2\x1a.task_manager.TaskTemplateR\btemplate\x12N\n" +
+	"\x12concurrency_policy\x18\x03 \x01(\x0e2\x1f.task_manager.ConcurrencyPolicyR\x11concurrencyPolicy\x12\x16\n" +
+	"\x06paused\x18\x04 \x01(\bR\x06paused\"L\n" +
+	"\x16CreateScheduleResponse\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.task_manager.ScheduleR\bschedule\"\x16\n" +
+	"\x14ListSchedulesRequest\"M\n" +
+	"\x15ListSchedulesResponse\x124\n" +
+	"\tschedules\x18\x01 \x03(\v2\x16.task_manager.ScheduleR\tschedules\"8\n" +
+	"\x15DeleteScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\"\x18\n" +
+	"\x16DeleteScheduleResponse\"O\n" +
+	"\x14PauseScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\"K\n" +
+	"\x15PauseScheduleResponse\x122\n" +
+	"\bschedule\x18\x01 \x01(\v2\x16.task_manager.ScheduleR\bschedule*\x81\x02\n" +
 	"\tJobStatus\x12\x16\n" +
 	"\x12JOB_STATUS_UNKNOWN\x10\x00\x12\x16\n" +
 	"\x12JOB_STATUS_STARTED\x10\x01\x12\x17\n" +
 	"\x13JOB_STATUS_SIGNALED\x10\x02\x12\x18\n" +
 	"\x14JOB_STATUS_EXITED_OK\x10\x03\x12\x1b\n" +
-	"\x17JOB_STATUS_EXITED_ERROR\x10\x042\xdf\x02\n" +
+	"\x17JOB_STATUS_EXITED_ERROR\x10\x04\x12\x15\n" +
+	"\x11JOB_STATUS_QUEUED\x10\x05\x12\x16\n" +
+	"\x12JOB_STATUS_WAITING\x10\x06\x12\x15\n" +
+	"\x11JOB_STATUS_PAUSED\x10\a\x12\x17\n" +
+	"\x13JOB_STATUS_CANCELED\x10\b\x12\x15\n" +
+	"\x11JOB_STATUS_FAILED\x10\t*y\n" +
+	"\vRestartMode\x12\x1c\n" +
+	"\x18RESTART_MODE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12RESTART_MODE_NEVER\x10\x01\x12\x1b\n" +
+	"\x17RESTART_MODE_ON_FAILURE\x10\x02\x12\x17\n" +
+	"\x13RESTART_MODE_ALWAYS\x10\x03*\xa4\x01\n" +
+	"\x13DependencyCondition\x12$\n" +
+	" DEPENDENCY_CONDITION_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cDEPENDENCY_CONDITION_SUCCESS\x10\x01\x12#\n" +
+	"\x1fDEPENDENCY_CONDITION_COMPLETION\x10\x02\x12 \n" +
+	"\x1cDEPENDENCY_CONDITION_FAILURE\x10\x03*|\n" +
+	"\fRetryOutcome\x12\x1d\n" +
+	"\x19RETRY_OUTCOME_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18RETRY_OUTCOME_EXIT_ERROR\x10\x01\x12\x15\n" +
+	"\x11RETRY_OUTCOME_OOM\x10\x02\x12\x18\n" +
+	"\x14RETRY_OUTCOME_SIGNAL\x10\x03*0\n" +
+	"\bWaitMode\x12\x11\n" +
+	"\rWAIT_MODE_ALL\x10\x00\x12\x11\n" +
+	"\rWAIT_MODE_ANY\x10\x01*\x94\x01\n" +
+	"\x11ConcurrencyPolicy\x12\"\n" +
+	"\x1eCONCURRENCY_POLICY_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18CONCURRENCY_POLICY_ALLOW\x10\x01\x12\x1d\n" +
+	"\x19CONCURRENCY_POLICY_FORBID\x10\x02\x12\x1e\n" +
+	"\x1aCONCURRENCY_POLICY_REPLACE\x10\x032\xeb\f\n" +
 	"\vTaskManager\x12L\n" +
 	"\tStartTask\x12\x1e.task_manager.StartTaskRequest\x1a\x1f.task_manager.StartTaskResponse\x12I\n" +
-	"\bStopTask\x12\x1d.task_manager.StopTaskRequest\x1a\x1e.task_manager.StopTaskResponse\x12R\n" +
+	"\bStopTask\x12\x1d.task_manager.StopTaskRequest\x1a\x1e.task_manager.StopTaskResponse\x12O\n" +
+	"\n" +
+	"DeleteTask\x12\x1f.task_manager.DeleteTaskRequest\x1a .task_manager.DeleteTaskResponse\x12O\n" +
+	"\n" +
+	"SignalTask\x12\x1f.task_manager.SignalTaskRequest\x1a .task_manager.SignalTaskResponse\x12L\n" +
+	"\tPauseTask\x12\x1e.task_manager.PauseTaskRequest\x1a\x1f.task_manager.PauseTaskResponse\x12O\n" +
+	"\n" +
+	"ResumeTask\x12\x1f.task_manager.ResumeTaskRequest\x1a .task_manager.ResumeTaskResponse\x12j\n" +
+	"\x13UpdateTaskResources\x12(.task_manager.UpdateTaskResourcesRequest\x1a).task_manager.UpdateTaskResourcesResponse\x12R\n" +
 	"\rGetTaskStatus\x12\x1f.task_manager.TaskStatusRequest\x1a .task_manager.TaskStatusResponse\x12c\n" +
-	"\x10StreamTaskOutput\x12%.task_manager.StreamTaskOutputRequest\x1a&.task_manager.StreamTaskOutputResponse0\x01B\bZ\x06proto/b\x06proto3"
+	"\x10StreamTaskOutput\x12%.task_manager.StreamTaskOutputRequest\x1a&.task_manager.StreamTaskOutputResponse0\x01\x12f\n" +
+	"\x11WatchMemoryEvents\x12&.task_manager.WatchMemoryEventsRequest\x1a'.task_manager.WatchMemoryEventsResponse0\x01\x12L\n" +
+	"\tWaitTasks\x12\x1e.task_manager.WaitTasksRequest\x1a\x1f.task_manager.WaitTasksResponse\x12L\n" +
+	"\tListTasks\x12\x1e.task_manager.ListTasksRequest\x1a\x1f.task_manager.ListTasksResponse\x12L\n" +
+	"\tStopTasks\x12\x1e.task_manager.StopTasksRequest\x1a\x1f.task_manager.StopTasksResponse\x12R\n" +
+	"\vSignalTasks\x12 .task_manager.SignalTasksRequest\x1a!.task_manager.SignalTasksResponse\x12I\n" +
+	"\bGetQuota\x12\x1d.task_manager.GetQuotaRequest\x1a\x1e.task_manager.GetQuotaResponse\x12[\n" +
+	"\x0eCreateSchedule\x12#.task_manager.CreateScheduleRequest\x1a$.task_manager.CreateScheduleResponse\x12X\n" +
+	"\rListSchedules\x12\".task_manager.ListSchedulesRequest\x1a#.task_manager.ListSchedulesResponse\x12[\n" +
+	"\x0eDeleteSchedule\x12#.task_manager.DeleteScheduleRequest\x1a$.task_manager.DeleteScheduleResponse\x12X\n" +
+	"\rPauseSchedule\x12\".task_manager.PauseScheduleRequest\x1a#.task_manager.PauseScheduleResponseB\bZ\x06proto/b\x06proto3"
 
 var (
 	file_proto_task_proto_rawDescOnce sync.Once
@@ -564,37 +3872,178 @@ func file_proto_task_proto_rawDescGZIP() []byte {
 	return file_proto_task_proto_rawDescData
 }
 
-var file_proto_task_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_task_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proto_task_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_proto_task_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_proto_task_proto_goTypes = []any{
-	(JobStatus)(0),                   // 0: task_manager.JobStatus
-	(*StartTaskRequest)(nil),         // 1: task_manager.StartTaskRequest
-	(*StartTaskResponse)(nil),        // 2: task_manager.StartTaskResponse
-	(*StopTaskRequest)(nil),          // 3: task_manager.StopTaskRequest
-	(*StopTaskResponse)(nil),         // 4: task_manager.StopTaskResponse
-	(*TaskStatusRequest)(nil),        // 5: task_manager.TaskStatusRequest
-	(*TaskStatusResponse)(nil),       // 6: task_manager.TaskStatusResponse
-	(*StreamTaskOutputRequest)(nil),  // 7: task_manager.StreamTaskOutputRequest
-	(*StreamTaskOutputResponse)(nil), // 8: task_manager.StreamTaskOutputResponse
-	(*timestamppb.Timestamp)(nil),    // 9: google.protobuf.Timestamp
+	(JobStatus)(0),                      // 0: task_manager.JobStatus
+	(RestartMode)(0),                    // 1: task_manager.RestartMode
+	(DependencyCondition)(0),            // 2: task_manager.DependencyCondition
+	(RetryOutcome)(0),                   // 3: task_manager.RetryOutcome
+	(WaitMode)(0),                       // 4: task_manager.WaitMode
+	(ConcurrencyPolicy)(0),              // 5: task_manager.ConcurrencyPolicy
+	(*StartTaskRequest)(nil),            // 6: task_manager.StartTaskRequest
+	(*RestartPolicy)(nil),               // 7: task_manager.RestartPolicy
+	(*TaskDependency)(nil),              // 8: task_manager.TaskDependency
+	(*RetryPolicy)(nil),                 // 9: task_manager.RetryPolicy
+	(*StartTaskResponse)(nil),           // 10: task_manager.StartTaskResponse
+	(*StopTaskRequest)(nil),             // 11: task_manager.StopTaskRequest
+	(*StopTaskResponse)(nil),            // 12: task_manager.StopTaskResponse
+	(*DeleteTaskRequest)(nil),           // 13: task_manager.DeleteTaskRequest
+	(*DeleteTaskResponse)(nil),          // 14: task_manager.DeleteTaskResponse
+	(*SignalTaskRequest)(nil),           // 15: task_manager.SignalTaskRequest
+	(*SignalTaskResponse)(nil),          // 16: task_manager.SignalTaskResponse
+	(*PauseTaskRequest)(nil),            // 17: task_manager.PauseTaskRequest
+	(*PauseTaskResponse)(nil),           // 18: task_manager.PauseTaskResponse
+	(*ResumeTaskRequest)(nil),           // 19: task_manager.ResumeTaskRequest
+	(*ResumeTaskResponse)(nil),          // 20: task_manager.ResumeTaskResponse
+	(*ResourceLimits)(nil),              // 21: task_manager.ResourceLimits
+	(*ResourceChange)(nil),              // 22: task_manager.ResourceChange
+	(*UpdateTaskResourcesRequest)(nil),  // 23: task_manager.UpdateTaskResourcesRequest
+	(*UpdateTaskResourcesResponse)(nil), // 24: task_manager.UpdateTaskResourcesResponse
+	(*TaskStatusRequest)(nil),           // 25: task_manager.TaskStatusRequest
+	(*TaskStatusResponse)(nil),          // 26: task_manager.TaskStatusResponse
+	(*TaskAttempt)(nil),                 // 27: task_manager.TaskAttempt
+	(*MemoryStatus)(nil),                // 28: task_manager.MemoryStatus
+	(*MemoryEvent)(nil),                 // 29: task_manager.MemoryEvent
+	(*WatchMemoryEventsRequest)(nil),    // 30: task_manager.WatchMemoryEventsRequest
+	(*WatchMemoryEventsResponse)(nil),   // 31: task_manager.WatchMemoryEventsResponse
+	(*StreamTaskOutputRequest)(nil),     // 32: task_manager.StreamTaskOutputRequest
+	(*StreamTaskOutputResponse)(nil),    // 33: task_manager.StreamTaskOutputResponse
+	(*WaitTasksRequest)(nil),            // 34: task_manager.WaitTasksRequest
+	(*WaitTasksResponse)(nil),           // 35: task_manager.WaitTasksResponse
+	(*ListTasksRequest)(nil),            // 36: task_manager.ListTasksRequest
+	(*ListTasksResponse)(nil),           // 37: task_manager.ListTasksResponse
+	(*TaskSelector)(nil),                // 38: task_manager.TaskSelector
+	(*TaskResult)(nil),                  // 39: task_manager.TaskResult
+	(*StopTasksRequest)(nil),            // 40: task_manager.StopTasksRequest
+	(*StopTasksResponse)(nil),           // 41: task_manager.StopTasksResponse
+	(*SignalTasksRequest)(nil),          // 42: task_manager.SignalTasksRequest
+	(*SignalTasksResponse)(nil),         // 43: task_manager.SignalTasksResponse
+	(*GetQuotaRequest)(nil),             // 44: task_manager.GetQuotaRequest
+	(*QuotaUsage)(nil),                  // 45: task_manager.QuotaUsage
+	(*GetQuotaResponse)(nil),            // 46: task_manager.GetQuotaResponse
+	(*TaskTemplate)(nil),                // 47: task_manager.TaskTemplate
+	(*Schedule)(nil),                    // 48: task_manager.Schedule
+	(*CreateScheduleRequest)(nil),       // 49: task_manager.CreateScheduleRequest
+	(*CreateScheduleResponse)(nil),      // 50: task_manager.CreateScheduleResponse
+	(*ListSchedulesRequest)(nil),        // 51: task_manager.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),       // 52: task_manager.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),       // 53: task_manager.DeleteScheduleRequest
+	(*DeleteScheduleResponse)(nil),      // 54: task_manager.DeleteScheduleResponse
+	(*PauseScheduleRequest)(nil),        // 55: task_manager.PauseScheduleRequest
+	(*PauseScheduleResponse)(nil),       // 56: task_manager.PauseScheduleResponse
+	nil,                                 // 57: task_manager.StartTaskRequest.LabelsEntry
+	nil,                                 // 58: task_manager.TaskStatusResponse.LabelsEntry
+	nil,                                 // 59: task_manager.TaskTemplate.LabelsEntry
+	(*durationpb.Duration)(nil),         // 60: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),       // 61: google.protobuf.Timestamp
 }
 var file_proto_task_proto_depIdxs = []int32{
-	0, // 0: task_manager.TaskStatusResponse.status:type_name -> task_manager.JobStatus
-	9, // 1: task_manager.TaskStatusResponse.start_time:type_name -> google.protobuf.Timestamp
-	9, // 2: task_manager.TaskStatusResponse.end_time:type_name -> google.protobuf.Timestamp
-	1, // 3: task_manager.TaskManager.StartTask:input_type -> task_manager.StartTaskRequest
-	3, // 4: task_manager.TaskManager.StopTask:input_type -> task_manager.StopTaskRequest
-	5, // 5: task_manager.TaskManager.GetTaskStatus:input_type -> task_manager.TaskStatusRequest
-	7, // 6: task_manager.TaskManager.StreamTaskOutput:input_type -> task_manager.StreamTaskOutputRequest
-	2, // 7: task_manager.TaskManager.StartTask:output_type -> task_manager.StartTaskResponse
-	4, // 8: task_manager.TaskManager.StopTask:output_type -> task_manager.StopTaskResponse
-	6, // 9: task_manager.TaskManager.GetTaskStatus:output_type -> task_manager.TaskStatusResponse
-	8, // 10: task_manager.TaskManager.StreamTaskOutput:output_type -> task_manager.StreamTaskOutputResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	57, // 0: task_manager.StartTaskRequest.labels:type_name -> task_manager.StartTaskRequest.LabelsEntry
+	9,  // 1: task_manager.StartTaskRequest.retry_policy:type_name -> task_manager.RetryPolicy
+	8,  // 2: task_manager.StartTaskRequest.depends_on:type_name -> task_manager.TaskDependency
+	7,  // 3: task_manager.StartTaskRequest.restart_policy:type_name -> task_manager.RestartPolicy
+	21, // 4: task_manager.StartTaskRequest.limits:type_name -> task_manager.ResourceLimits
+	1,  // 5: task_manager.RestartPolicy.mode:type_name -> task_manager.RestartMode
+	60, // 6: task_manager.RestartPolicy.initial_backoff:type_name -> google.protobuf.Duration
+	60, // 7: task_manager.RestartPolicy.max_backoff:type_name -> google.protobuf.Duration
+	60, // 8: task_manager.RestartPolicy.restart_window:type_name -> google.protobuf.Duration
+	2,  // 9: task_manager.TaskDependency.condition:type_name -> task_manager.DependencyCondition
+	60, // 10: task_manager.RetryPolicy.initial_backoff:type_name -> google.protobuf.Duration
+	60, // 11: task_manager.RetryPolicy.max_backoff:type_name -> google.protobuf.Duration
+	3,  // 12: task_manager.RetryPolicy.retry_on:type_name -> task_manager.RetryOutcome
+	61, // 13: task_manager.ResourceChange.time:type_name -> google.protobuf.Timestamp
+	21, // 14: task_manager.ResourceChange.previous:type_name -> task_manager.ResourceLimits
+	21, // 15: task_manager.ResourceChange.limits:type_name -> task_manager.ResourceLimits
+	21, // 16: task_manager.UpdateTaskResourcesRequest.limits:type_name -> task_manager.ResourceLimits
+	21, // 17: task_manager.UpdateTaskResourcesResponse.limits:type_name -> task_manager.ResourceLimits
+	0,  // 18: task_manager.TaskStatusResponse.status:type_name -> task_manager.JobStatus
+	61, // 19: task_manager.TaskStatusResponse.start_time:type_name -> google.protobuf.Timestamp
+	61, // 20: task_manager.TaskStatusResponse.end_time:type_name -> google.protobuf.Timestamp
+	58, // 21: task_manager.TaskStatusResponse.labels:type_name -> task_manager.TaskStatusResponse.LabelsEntry
+	27, // 22: task_manager.TaskStatusResponse.attempts:type_name -> task_manager.TaskAttempt
+	8,  // 23: task_manager.TaskStatusResponse.depends_on:type_name -> task_manager.TaskDependency
+	60, // 24: task_manager.TaskStatusResponse.paused_duration:type_name -> google.protobuf.Duration
+	21, // 25: task_manager.TaskStatusResponse.limits:type_name -> task_manager.ResourceLimits
+	22, // 26: task_manager.TaskStatusResponse.resource_changes:type_name -> task_manager.ResourceChange
+	28, // 27: task_manager.TaskStatusResponse.memory:type_name -> task_manager.MemoryStatus
+	29, // 28: task_manager.TaskStatusResponse.memory_events:type_name -> task_manager.MemoryEvent
+	0,  // 29: task_manager.TaskAttempt.status:type_name -> task_manager.JobStatus
+	61, // 30: task_manager.TaskAttempt.start_time:type_name -> google.protobuf.Timestamp
+	61, // 31: task_manager.TaskAttempt.end_time:type_name -> google.protobuf.Timestamp
+	61, // 32: task_manager.MemoryEvent.time:type_name -> google.protobuf.Timestamp
+	29, // 33: task_manager.WatchMemoryEventsResponse.event:type_name -> task_manager.MemoryEvent
+	4,  // 34: task_manager.WaitTasksRequest.mode:type_name -> task_manager.WaitMode
+	60, // 35: task_manager.WaitTasksRequest.timeout:type_name -> google.protobuf.Duration
+	26, // 36: task_manager.WaitTasksResponse.statuses:type_name -> task_manager.TaskStatusResponse
+	26, // 37: task_manager.ListTasksResponse.tasks:type_name -> task_manager.TaskStatusResponse
+	0,  // 38: task_manager.TaskSelector.statuses:type_name -> task_manager.JobStatus
+	61, // 39: task_manager.TaskSelector.started_before:type_name -> google.protobuf.Timestamp
+	38, // 40: task_manager.StopTasksRequest.selector:type_name -> task_manager.TaskSelector
+	39, // 41: task_manager.StopTasksResponse.results:type_name -> task_manager.TaskResult
+	38, // 42: task_manager.SignalTasksRequest.selector:type_name -> task_manager.TaskSelector
+	39, // 43: task_manager.SignalTasksResponse.results:type_name -> task_manager.TaskResult
+	45, // 44: task_manager.GetQuotaResponse.running_tasks:type_name -> task_manager.QuotaUsage
+	45, // 45: task_manager.GetQuotaResponse.global_running_tasks:type_name -> task_manager.QuotaUsage
+	45, // 46: task_manager.GetQuotaResponse.starts_per_minute:type_name -> task_manager.QuotaUsage
+	45, // 47: task_manager.GetQuotaResponse.queued_tasks:type_name -> task_manager.QuotaUsage
+	59, // 48: task_manager.TaskTemplate.labels:type_name -> task_manager.TaskTemplate.LabelsEntry
+	9,  // 49: task_manager.TaskTemplate.retry_policy:type_name -> task_manager.RetryPolicy
+	7,  // 50: task_manager.TaskTemplate.restart_policy:type_name -> task_manager.RestartPolicy
+	21, // 51: task_manager.TaskTemplate.limits:type_name -> task_manager.ResourceLimits
+	47, // 52: task_manager.Schedule.template:type_name -> task_manager.TaskTemplate
+	5,  // 53: task_manager.Schedule.concurrency_policy:type_name -> task_manager.ConcurrencyPolicy
+	61, // 54: task_manager.Schedule.create_time:type_name -> google.protobuf.Timestamp
+	61, // 55: task_manager.Schedule.next_run_time:type_name -> google.protobuf.Timestamp
+	61, // 56: task_manager.Schedule.last_run_time:type_name -> google.protobuf.Timestamp
+	47, // 57: task_manager.CreateScheduleRequest.template:type_name -> task_manager.TaskTemplate
+	5,  // 58: task_manager.CreateScheduleRequest.concurrency_policy:type_name -> task_manager.ConcurrencyPolicy
+	48, // 59: task_manager.CreateScheduleResponse.schedule:type_name -> task_manager.Schedule
+	48, // 60: task_manager.ListSchedulesResponse.schedules:type_name -> task_manager.Schedule
+	48, // 61: task_manager.PauseScheduleResponse.schedule:type_name -> task_manager.Schedule
+	6,  // 62: task_manager.TaskManager.StartTask:input_type -> task_manager.StartTaskRequest
+	11, // 63: task_manager.TaskManager.StopTask:input_type -> task_manager.StopTaskRequest
+	13, // 64: task_manager.TaskManager.DeleteTask:input_type -> task_manager.DeleteTaskRequest
+	15, // 65: task_manager.TaskManager.SignalTask:input_type -> task_manager.SignalTaskRequest
+	17, // 66: task_manager.TaskManager.PauseTask:input_type -> task_manager.PauseTaskRequest
+	19, // 67: task_manager.TaskManager.ResumeTask:input_type -> task_manager.ResumeTaskRequest
+	23, // 68: task_manager.TaskManager.UpdateTaskResources:input_type -> task_manager.UpdateTaskResourcesRequest
+	25, // 69: task_manager.TaskManager.GetTaskStatus:input_type -> task_manager.TaskStatusRequest
+	32, // 70: task_manager.TaskManager.StreamTaskOutput:input_type -> task_manager.StreamTaskOutputRequest
+	30, // 71: task_manager.TaskManager.WatchMemoryEvents:input_type -> task_manager.WatchMemoryEventsRequest
+	34, // 72: task_manager.TaskManager.WaitTasks:input_type -> task_manager.WaitTasksRequest
+	36, // 73: task_manager.TaskManager.ListTasks:input_type -> task_manager.ListTasksRequest
+	40, // 74: task_manager.TaskManager.StopTasks:input_type -> task_manager.StopTasksRequest
+	42, // 75: task_manager.TaskManager.SignalTasks:input_type -> task_manager.SignalTasksRequest
+	44, // 76: task_manager.TaskManager.GetQuota:input_type -> task_manager.GetQuotaRequest
+	49, // 77: task_manager.TaskManager.CreateSchedule:input_type -> task_manager.CreateScheduleRequest
+	51, // 78: task_manager.TaskManager.ListSchedules:input_type -> task_manager.ListSchedulesRequest
+	53, // 79: task_manager.TaskManager.DeleteSchedule:input_type -> task_manager.DeleteScheduleRequest
+	55, // 80: task_manager.TaskManager.PauseSchedule:input_type -> task_manager.PauseScheduleRequest
+	10, // 81: task_manager.TaskManager.StartTask:output_type -> task_manager.StartTaskResponse
+	12, // 82: task_manager.TaskManager.StopTask:output_type -> task_manager.StopTaskResponse
+	14, // 83: task_manager.TaskManager.DeleteTask:output_type -> task_manager.DeleteTaskResponse
+	16, // 84: task_manager.TaskManager.SignalTask:output_type -> task_manager.SignalTaskResponse
+	18, // 85: task_manager.TaskManager.PauseTask:output_type -> task_manager.PauseTaskResponse
+	20, // 86: task_manager.TaskManager.ResumeTask:output_type -> task_manager.ResumeTaskResponse
+	24, // 87: task_manager.TaskManager.UpdateTaskResources:output_type -> task_manager.UpdateTaskResourcesResponse
+	26, // 88: task_manager.TaskManager.GetTaskStatus:output_type -> task_manager.TaskStatusResponse
+	33, // 89: task_manager.TaskManager.StreamTaskOutput:output_type -> task_manager.StreamTaskOutputResponse
+	31, // 90: task_manager.TaskManager.WatchMemoryEvents:output_type -> task_manager.WatchMemoryEventsResponse
+	35, // 91: task_manager.TaskManager.WaitTasks:output_type -> task_manager.WaitTasksResponse
+	37, // 92: task_manager.TaskManager.ListTasks:output_type -> task_manager.ListTasksResponse
+	41, // 93: task_manager.TaskManager.StopTasks:output_type -> task_manager.StopTasksResponse
+	43, // 94: task_manager.TaskManager.SignalTasks:output_type -> task_manager.SignalTasksResponse
+	46, // 95: task_manager.TaskManager.GetQuota:output_type -> task_manager.GetQuotaResponse
+	50, // 96: task_manager.TaskManager.CreateSchedule:output_type -> task_manager.CreateScheduleResponse
+	52, // 97: task_manager.TaskManager.ListSchedules:output_type -> task_manager.ListSchedulesResponse
+	54, // 98: task_manager.TaskManager.DeleteSchedule:output_type -> task_manager.DeleteScheduleResponse
+	56, // 99: task_manager.TaskManager.PauseSchedule:output_type -> task_manager.PauseScheduleResponse
+	81, // [81:100] is the sub-list for method output_type
+	62, // [62:81] is the sub-list for method input_type
+	62, // [62:62] is the sub-list for extension type_name
+	62, // [62:62] is the sub-list for extension extendee
+	0,  // [0:62] is the sub-list for field type_name
 }
 
 func init() { file_proto_task_proto_init() }
@@ -602,14 +4051,16 @@ func file_proto_task_proto_init() {
 	if File_proto_task_proto != nil {
 		return
 	}
-	file_proto_task_proto_msgTypes[5].OneofWrappers = []any{}
+	file_proto_task_proto_msgTypes[15].OneofWrappers = []any{}
+	file_proto_task_proto_msgTypes[20].OneofWrappers = []any{}
+	file_proto_task_proto_msgTypes[21].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_task_proto_rawDesc), len(file_proto_task_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   8,
+			NumEnums:      6,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,10 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TaskManager_StartTask_FullMethodName        = "/task_manager.TaskManager/StartTask"
-	TaskManager_StopTask_FullMethodName         = "/task_manager.TaskManager/StopTask"
-	TaskManager_GetTaskStatus_FullMethodName    = "/task_manager.TaskManager/GetTaskStatus"
-	TaskManager_StreamTaskOutput_FullMethodName = "/task_manager.TaskManager/StreamTaskOutput"
+	TaskManager_StartTask_FullMethodName           = "/task_manager.TaskManager/StartTask"
+	TaskManager_StopTask_FullMethodName            = "/task_manager.TaskManager/StopTask"
+	TaskManager_DeleteTask_FullMethodName          = "/task_manager.TaskManager/DeleteTask"
+	TaskManager_SignalTask_FullMethodName          = "/task_manager.TaskManager/SignalTask"
+	TaskManager_PauseTask_FullMethodName           = "/task_manager.TaskManager/PauseTask"
+	TaskManager_ResumeTask_FullMethodName          = "/task_manager.TaskManager/ResumeTask"
+	TaskManager_UpdateTaskResources_FullMethodName = "/task_manager.TaskManager/UpdateTaskResources"
+	TaskManager_GetTaskStatus_FullMethodName       = "/task_manager.TaskManager/GetTaskStatus"
+	TaskManager_StreamTaskOutput_FullMethodName    = "/task_manager.TaskManager/StreamTaskOutput"
+	TaskManager_WatchMemoryEvents_FullMethodName   = "/task_manager.TaskManager/WatchMemoryEvents"
+	TaskManager_WaitTasks_FullMethodName           = "/task_manager.TaskManager/WaitTasks"
+	TaskManager_ListTasks_FullMethodName           = "/task_manager.TaskManager/ListTasks"
+	TaskManager_StopTasks_FullMethodName           = "/task_manager.TaskManager/StopTasks"
+	TaskManager_SignalTasks_FullMethodName         = "/task_manager.TaskManager/SignalTasks"
+	TaskManager_GetQuota_FullMethodName            = "/task_manager.TaskManager/GetQuota"
+	TaskManager_CreateSchedule_FullMethodName      = "/task_manager.TaskManager/CreateSchedule"
+	TaskManager_ListSchedules_FullMethodName       = "/task_manager.TaskManager/ListSchedules"
+	TaskManager_DeleteSchedule_FullMethodName      = "/task_manager.TaskManager/DeleteSchedule"
+	TaskManager_PauseSchedule_FullMethodName       = "/task_manager.TaskManager/PauseSchedule"
 )
 
 // TaskManagerClient is the client API for TaskManager service.
@@ -35,10 +50,40 @@ type TaskManagerClient interface {
 	StartTask(ctx context.Context, in *StartTaskRequest, opts ...grpc.CallOption) (*StartTaskResponse, error)
 	// StopTask stops a running task by task ID
 	StopTask(ctx context.Context, in *StopTaskRequest, opts ...grpc.CallOption) (*StopTaskResponse, error)
+	// DeleteTask removes a finished task and its output by task ID; later calls for the task return NOT_FOUND
+	DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error)
+	// SignalTask sends a signal such as SIGTERM to the process group of a running task by task ID
+	SignalTask(ctx context.Context, in *SignalTaskRequest, opts ...grpc.CallOption) (*SignalTaskResponse, error)
+	// PauseTask freezes the processes of a running task by task ID until it is resumed
+	PauseTask(ctx context.Context, in *PauseTaskRequest, opts ...grpc.CallOption) (*PauseTaskResponse, error)
+	// ResumeTask thaws the processes of a paused task by task ID
+	ResumeTask(ctx context.Context, in *ResumeTaskRequest, opts ...grpc.CallOption) (*ResumeTaskResponse, error)
+	// UpdateTaskResources changes the cgroup limits of an unfinished task by task ID
+	UpdateTaskResources(ctx context.Context, in *UpdateTaskResourcesRequest, opts ...grpc.CallOption) (*UpdateTaskResourcesResponse, error)
 	// GetTaskStatus gets the status of a task by task ID
 	GetTaskStatus(ctx context.Context, in *TaskStatusRequest, opts ...grpc.CallOption) (*TaskStatusResponse, error)
 	// StreamTaskOutput streams the output of a task by task ID
 	StreamTaskOutput(ctx context.Context, in *StreamTaskOutputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTaskOutputResponse], error)
+	// WatchMemoryEvents streams the memory events of a task by task ID until the task finishes
+	WatchMemoryEvents(ctx context.Context, in *WatchMemoryEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMemoryEventsResponse], error)
+	// WaitTasks blocks until all or any of the tasks have completed and returns their statuses
+	WaitTasks(ctx context.Context, in *WaitTasksRequest, opts ...grpc.CallOption) (*WaitTasksResponse, error)
+	// ListTasks lists the statuses of the tasks of the caller, or of all tasks for admins, matching a label selector
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
+	// StopTasks stops all tasks matching a selector and returns the result for each task
+	StopTasks(ctx context.Context, in *StopTasksRequest, opts ...grpc.CallOption) (*StopTasksResponse, error)
+	// SignalTasks sends a signal to all tasks matching a selector and returns the result for each task
+	SignalTasks(ctx context.Context, in *SignalTasksRequest, opts ...grpc.CallOption) (*SignalTasksResponse, error)
+	// GetQuota gets the usage of the caller, or of any client for admins, against the task quotas
+	GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error)
+	// CreateSchedule creates a schedule starting tasks from a template at the times of a cron expression
+	CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*CreateScheduleResponse, error)
+	// ListSchedules lists the schedules of the caller, or of all clients for admins
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error)
+	// DeleteSchedule deletes a schedule by schedule ID; tasks it started keep running
+	DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error)
+	// PauseSchedule pauses or resumes a schedule by schedule ID
+	PauseSchedule(ctx context.Context, in *PauseScheduleRequest, opts ...grpc.CallOption) (*PauseScheduleResponse, error)
 }
 
 type taskManagerClient struct {
@@ -69,6 +114,56 @@ func (c *taskManagerClient) StopTask(ctx context.Context, in *StopTaskRequest, o
 	return out, nil
 }
 
+func (c *taskManagerClient) DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTaskResponse)
+	err := c.cc.Invoke(ctx, TaskManager_DeleteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) SignalTask(ctx context.Context, in *SignalTaskRequest, opts ...grpc.CallOption) (*SignalTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignalTaskResponse)
+	err := c.cc.Invoke(ctx, TaskManager_SignalTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) PauseTask(ctx context.Context, in *PauseTaskRequest, opts ...grpc.CallOption) (*PauseTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseTaskResponse)
+	err := c.cc.Invoke(ctx, TaskManager_PauseTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) ResumeTask(ctx context.Context, in *ResumeTaskRequest, opts ...grpc.CallOption) (*ResumeTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeTaskResponse)
+	err := c.cc.Invoke(ctx, TaskManager_ResumeTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) UpdateTaskResources(ctx context.Context, in *UpdateTaskResourcesRequest, opts ...grpc.CallOption) (*UpdateTaskResourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTaskResourcesResponse)
+	err := c.cc.Invoke(ctx, TaskManager_UpdateTaskResources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *taskManagerClient) GetTaskStatus(ctx context.Context, in *TaskStatusRequest, opts ...grpc.CallOption) (*TaskStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TaskStatusResponse)
@@ -98,6 +193,115 @@ func (c *taskManagerClient) StreamTaskOutput(ctx context.Context, in *StreamTask
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type TaskManager_StreamTaskOutputClient = grpc.ServerStreamingClient[StreamTaskOutputResponse]
 
+func (c *taskManagerClient) WatchMemoryEvents(ctx context.Context, in *WatchMemoryEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMemoryEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskManager_ServiceDesc.Streams[1], TaskManager_WatchMemoryEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMemoryEventsRequest, WatchMemoryEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskManager_WatchMemoryEventsClient = grpc.ServerStreamingClient[WatchMemoryEventsResponse]
+
+func (c *taskManagerClient) WaitTasks(ctx context.Context, in *WaitTasksRequest, opts ...grpc.CallOption) (*WaitTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitTasksResponse)
+	err := c.cc.Invoke(ctx, TaskManager_WaitTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, TaskManager_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) StopTasks(ctx context.Context, in *StopTasksRequest, opts ...grpc.CallOption) (*StopTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopTasksResponse)
+	err := c.cc.Invoke(ctx, TaskManager_StopTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) SignalTasks(ctx context.Context, in *SignalTasksRequest, opts ...grpc.CallOption) (*SignalTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignalTasksResponse)
+	err := c.cc.Invoke(ctx, TaskManager_SignalTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaResponse)
+	err := c.cc.Invoke(ctx, TaskManager_GetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*CreateScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateScheduleResponse)
+	err := c.cc.Invoke(ctx, TaskManager_CreateSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSchedulesResponse)
+	err := c.cc.Invoke(ctx, TaskManager_ListSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteScheduleResponse)
+	err := c.cc.Invoke(ctx, TaskManager_DeleteSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) PauseSchedule(ctx context.Context, in *PauseScheduleRequest, opts ...grpc.CallOption) (*PauseScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseScheduleResponse)
+	err := c.cc.Invoke(ctx, TaskManager_PauseSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TaskManagerServer is the server API for TaskManager service.
 // All implementations must embed UnimplementedTaskManagerServer
 // for forward compatibility.
@@ -108,10 +312,40 @@ type TaskManagerServer interface {
 	StartTask(context.Context, *StartTaskRequest) (*StartTaskResponse, error)
 	// StopTask stops a running task by task ID
 	StopTask(context.Context, *StopTaskRequest) (*StopTaskResponse, error)
+	// DeleteTask removes a finished task and its output by task ID; later calls for the task return NOT_FOUND
+	DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error)
+	// SignalTask sends a signal such as SIGTERM to the process group of a running task by task ID
+	SignalTask(context.Context, *SignalTaskRequest) (*SignalTaskResponse, error)
+	// PauseTask freezes the processes of a running task by task ID until it is resumed
+	PauseTask(context.Context, *PauseTaskRequest) (*PauseTaskResponse, error)
+	// ResumeTask thaws the processes of a paused task by task ID
+	ResumeTask(context.Context, *ResumeTaskRequest) (*ResumeTaskResponse, error)
+	// UpdateTaskResources changes the cgroup limits of an unfinished task by task ID
+	UpdateTaskResources(context.Context, *UpdateTaskResourcesRequest) (*UpdateTaskResourcesResponse, error)
 	// GetTaskStatus gets the status of a task by task ID
 	GetTaskStatus(context.Context, *TaskStatusRequest) (*TaskStatusResponse, error)
 	// StreamTaskOutput streams the output of a task by task ID
 	StreamTaskOutput(*StreamTaskOutputRequest, grpc.ServerStreamingServer[StreamTaskOutputResponse]) error
+	// WatchMemoryEvents streams the memory events of a task by task ID until the task finishes
+	WatchMemoryEvents(*WatchMemoryEventsRequest, grpc.ServerStreamingServer[WatchMemoryEventsResponse]) error
+	// WaitTasks blocks until all or any of the tasks have completed and returns their statuses
+	WaitTasks(context.Context, *WaitTasksRequest) (*WaitTasksResponse, error)
+	// ListTasks lists the statuses of the tasks of the caller, or of all tasks for admins, matching a label selector
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+	// StopTasks stops all tasks matching a selector and returns the result for each task
+	StopTasks(context.Context, *StopTasksRequest) (*StopTasksResponse, error)
+	// SignalTasks sends a signal to all tasks matching a selector and returns the result for each task
+	SignalTasks(context.Context, *SignalTasksRequest) (*SignalTasksResponse, error)
+	// GetQuota gets the usage of the caller, or of any client for admins, against the task quotas
+	GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error)
+	// CreateSchedule creates a schedule starting tasks from a template at the times of a cron expression
+	CreateSchedule(context.Context, *CreateScheduleRequest) (*CreateScheduleResponse, error)
+	// ListSchedules lists the schedules of the caller, or of all clients for admins
+	ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	// DeleteSchedule deletes a schedule by schedule ID; tasks it started keep running
+	DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error)
+	// PauseSchedule pauses or resumes a schedule by schedule ID
+	PauseSchedule(context.Context, *PauseScheduleRequest) (*PauseScheduleResponse, error)
 	mustEmbedUnimplementedTaskManagerServer()
 }
 
@@ -128,12 +362,57 @@ func (UnimplementedTaskManagerServer) StartTask(context.Context, *StartTaskReque
 func (UnimplementedTaskManagerServer) StopTask(context.Context, *StopTaskRequest) (*StopTaskResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StopTask not implemented")
 }
+func (UnimplementedTaskManagerServer) DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTask not implemented")
+}
+func (UnimplementedTaskManagerServer) SignalTask(context.Context, *SignalTaskRequest) (*SignalTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignalTask not implemented")
+}
+func (UnimplementedTaskManagerServer) PauseTask(context.Context, *PauseTaskRequest) (*PauseTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseTask not implemented")
+}
+func (UnimplementedTaskManagerServer) ResumeTask(context.Context, *ResumeTaskRequest) (*ResumeTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeTask not implemented")
+}
+func (UnimplementedTaskManagerServer) UpdateTaskResources(context.Context, *UpdateTaskResourcesRequest) (*UpdateTaskResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTaskResources not implemented")
+}
 func (UnimplementedTaskManagerServer) GetTaskStatus(context.Context, *TaskStatusRequest) (*TaskStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTaskStatus not implemented")
 }
 func (UnimplementedTaskManagerServer) StreamTaskOutput(*StreamTaskOutputRequest, grpc.ServerStreamingServer[StreamTaskOutputResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamTaskOutput not implemented")
 }
+func (UnimplementedTaskManagerServer) WatchMemoryEvents(*WatchMemoryEventsRequest, grpc.ServerStreamingServer[WatchMemoryEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMemoryEvents not implemented")
+}
+func (UnimplementedTaskManagerServer) WaitTasks(context.Context, *WaitTasksRequest) (*WaitTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaitTasks not implemented")
+}
+func (UnimplementedTaskManagerServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedTaskManagerServer) StopTasks(context.Context, *StopTasksRequest) (*StopTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTasks not implemented")
+}
+func (UnimplementedTaskManagerServer) SignalTasks(context.Context, *SignalTasksRequest) (*SignalTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignalTasks not implemented")
+}
+func (UnimplementedTaskManagerServer) GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuota not implemented")
+}
+func (UnimplementedTaskManagerServer) CreateSchedule(context.Context, *CreateScheduleRequest) (*CreateScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSchedule not implemented")
+}
+func (UnimplementedTaskManagerServer) ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedTaskManagerServer) DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
+func (UnimplementedTaskManagerServer) PauseSchedule(context.Context, *PauseScheduleRequest) (*PauseScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseSchedule not implemented")
+}
 func (UnimplementedTaskManagerServer) mustEmbedUnimplementedTaskManagerServer() {}
 func (UnimplementedTaskManagerServer) testEmbeddedByValue()                     {}
 
@@ -191,6 +470,96 @@ func _TaskManager_StopTask_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskManager_DeleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).DeleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_DeleteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).DeleteTask(ctx, req.(*DeleteTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_SignalTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).SignalTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_SignalTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).SignalTask(ctx, req.(*SignalTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_PauseTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).PauseTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_PauseTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).PauseTask(ctx, req.(*PauseTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_ResumeTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).ResumeTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_ResumeTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).ResumeTask(ctx, req.(*ResumeTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_UpdateTaskResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTaskResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).UpdateTaskResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_UpdateTaskResources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).UpdateTaskResources(ctx, req.(*UpdateTaskResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TaskManager_GetTaskStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TaskStatusRequest)
 	if err := dec(in); err != nil {
@@ -220,6 +589,179 @@ func _TaskManager_StreamTaskOutput_Handler(srv interface{}, stream grpc.ServerSt
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type TaskManager_StreamTaskOutputServer = grpc.ServerStreamingServer[StreamTaskOutputResponse]
 
+func _TaskManager_WatchMemoryEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMemoryEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskManagerServer).WatchMemoryEvents(m, &grpc.GenericServerStream[WatchMemoryEventsRequest, WatchMemoryEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskManager_WatchMemoryEventsServer = grpc.ServerStreamingServer[WatchMemoryEventsResponse]
+
+func _TaskManager_WaitTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).WaitTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_WaitTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).WaitTasks(ctx, req.(*WaitTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_StopTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).StopTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_StopTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).StopTasks(ctx, req.(*StopTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_SignalTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).SignalTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_SignalTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).SignalTasks(ctx, req.(*SignalTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_GetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).GetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_GetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).GetQuota(ctx, req.(*GetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_CreateSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).CreateSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_CreateSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).CreateSchedule(ctx, req.(*CreateScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_ListSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_DeleteSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).DeleteSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_PauseSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).PauseSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_PauseSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).PauseSchedule(ctx, req.(*PauseScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TaskManager_ServiceDesc is the grpc.ServiceDesc for TaskManager service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -235,10 +777,66 @@ var TaskManager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopTask",
 			Handler:    _TaskManager_StopTask_Handler,
 		},
+		{
+			MethodName: "DeleteTask",
+			Handler:    _TaskManager_DeleteTask_Handler,
+		},
+		{
+			MethodName: "SignalTask",
+			Handler:    _TaskManager_SignalTask_Handler,
+		},
+		{
+			MethodName: "PauseTask",
+			Handler:    _TaskManager_PauseTask_Handler,
+		},
+		{
+			MethodName: "ResumeTask",
+			Handler:    _TaskManager_ResumeTask_Handler,
+		},
+		{
+			MethodName: "UpdateTaskResources",
+			Handler:    _TaskManager_UpdateTaskResources_Handler,
+		},
 		{
 			MethodName: "GetTaskStatus",
 			Handler:    _TaskManager_GetTaskStatus_Handler,
 		},
+		{
+			MethodName: "WaitTasks",
+			Handler:    _TaskManager_WaitTasks_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _TaskManager_ListTasks_Handler,
+		},
+		{
+			MethodName: "StopTasks",
+			Handler:    _TaskManager_StopTasks_Handler,
+		},
+		{
+			MethodName: "SignalTasks",
+			Handler:    _TaskManager_SignalTasks_Handler,
+		},
+		{
+			MethodName: "GetQuota",
+			Handler:    _TaskManager_GetQuota_Handler,
+		},
+		{
+			MethodName: "CreateSchedule",
+			Handler:    _TaskManager_CreateSchedule_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _TaskManager_ListSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _TaskManager_DeleteSchedule_Handler,
+		},
+		{
+			MethodName: "PauseSchedule",
+			Handler:    _TaskManager_PauseSchedule_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -246,6 +844,11 @@ var TaskManager_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _TaskManager_StreamTaskOutput_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchMemoryEvents",
+			Handler:       _TaskManager_WatchMemoryEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/task.proto",
 }
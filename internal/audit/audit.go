@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"log/slog"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Actions recorded in audit events
+const (
+	ActionStart       = "task.start"
+	ActionStop        = "task.stop"
+	ActionSignal      = "task.signal"
+	ActionPause       = "task.pause"
+	ActionResume      = "task.resume"
+	ActionResources   = "task.resources"
+	ActionStatus      = "task.status"
+	ActionWait        = "task.wait"
+	ActionList        = "task.list"
+	ActionStopBulk    = "task.stop.bulk"
+	ActionSignalBulk  = "task.signal.bulk"
+	ActionDelete      = "task.delete"
+	ActionEvict       = "task.evict"
+	ActionCancel      = "task.cancel"
+	ActionRetry       = "task.retry"
+	ActionRestart     = "task.restart"
+	ActionQuota       = "quota.get"
+	ActionStreamOpen  = "task.stream.open"
+	ActionStreamClose = "task.stream.close"
+	ActionExit        = "task.exit"
+	ActionAuthFailure = "auth.failure"
+)
+
+// Actions recorded in audit events of schedules
+const (
+	ActionScheduleCreate = "schedule.create"
+	ActionScheduleList   = "schedule.list"
+	ActionScheduleDelete = "schedule.delete"
+	ActionSchedulePause  = "schedule.pause"
+	ActionScheduleRun    = "schedule.run"
+)
+
+// Outcomes recorded in audit events
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// redactedValue replaces any redacted argument or argument fragment
+const redactedValue = "[REDACTED]"
+
+// Event is a single structured audit record. One event is written per line as JSON.
+type Event struct {
+	Time              time.Time `json:"time"`
+	Action            string    `json:"action"`
+	Method            string    `json:"method,omitempty"`
+	ClientID          string    `json:"client_id,omitempty"`
+	PeerAddr          string    `json:"peer_addr,omitempty"`
+	TaskID            string    `json:"task_id,omitempty"`
+	ScheduleID        string    `json:"schedule_id,omitempty"`
+	Command           string    `json:"command,omitempty"`
+	Args              []string  `json:"args,omitempty"`
+	ProcessID         int       `json:"process_id,omitempty"`
+	Signal            string    `json:"signal,omitempty"`
+	LabelSelector     string    `json:"label_selector,omitempty"`
+	DryRun            bool      `json:"dry_run,omitempty"`
+	ExitCode          *int32    `json:"exit_code,omitempty"`
+	TerminationSource string    `json:"termination_source,omitempty"`
+	Outcome           string    `json:"outcome"`
+	Code              string    `json:"code,omitempty"`
+	Message           string    `json:"message,omitempty"`
+}
+
+// Logger redacts and writes audit events to a sink. A nil *Logger is valid and discards all events
+// so callers do not need to check whether auditing is enabled.
+type Logger struct {
+	mu   sync.Mutex
+	sink Sink
+
+	redactArgs     bool
+	redactPatterns []*regexp.Regexp
+}
+
+// Option configures a Logger
+type Option func(*Logger)
+
+// WithRedactArgs replaces every task argument with a redaction marker
+func WithRedactArgs() Option {
+	return func(l *Logger) {
+		l.redactArgs = true
+	}
+}
+
+// WithRedactPatterns replaces any part of a task argument that matches one of the patterns
+func WithRedactPatterns(patterns ...*regexp.Regexp) Option {
+	return func(l *Logger) {
+		l.redactPatterns = append(l.redactPatterns, patterns...)
+	}
+}
+
+// New creates a new audit logger that writes to the given sink
+func New(sink Sink, opts ...Option) *Logger {
+	l := &Logger{sink: sink}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Log redacts the event and writes it to the sink. Failing to write an audit event
+// must not fail the request that produced it so errors are only logged.
+func (l *Logger) Log(ev Event) {
+	if l == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	ev.Args = l.redact(ev.Args)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.sink.Write(ev); err != nil {
+		slog.Error("failed to write audit event", "action", ev.Action, "error", err)
+	}
+}
+
+// Close closes the underlying sink
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.Close()
+}
+
+// redact returns a copy of args with sensitive values replaced
+func (l *Logger) redact(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	redacted := slices.Clone(args)
+	for i, arg := range redacted {
+		if l.redactArgs {
+			redacted[i] = redactedValue
+			continue
+		}
+		for _, re := range l.redactPatterns {
+			arg = re.ReplaceAllString(arg, redactedValue)
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	events []Event
+}
+
+func (m *memorySink) Write(ev Event) error {
+	m.events = append(m.events, ev)
+	return nil
+}
+
+func (m *memorySink) Close() error { return nil }
+
+func TestLoggerRedaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		args     []string
+		wantArgs []string
+	}{
+		{
+			desc:     "without redaction",
+			args:     []string{"-u", "admin", "--password=hunter2"},
+			wantArgs: []string{"-u", "admin", "--password=hunter2"},
+		},
+		{
+			desc:     "with all args redacted",
+			opts:     []Option{WithRedactArgs()},
+			args:     []string{"-u", "admin", "--password=hunter2"},
+			wantArgs: []string{redactedValue, redactedValue, redactedValue},
+		},
+		{
+			desc:     "with a redaction pattern",
+			opts:     []Option{WithRedactPatterns(regexp.MustCompile(`(?i)password=.*`))},
+			args:     []string{"-u", "admin", "--password=hunter2"},
+			wantArgs: []string{"-u", "admin", "--" + redactedValue},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			sink := &memorySink{}
+			l := New(sink, tt.opts...)
+			l.Log(Event{Action: ActionStart, Args: tt.args})
+
+			require.Len(t, sink.events, 1)
+			require.Equal(t, tt.wantArgs, sink.events[0].Args)
+			require.False(t, sink.events[0].Time.IsZero(), "expected event time to be set")
+		})
+	}
+}
+
+func TestNilLoggerDiscardsEvents(t *testing.T) {
+	t.Parallel()
+
+	var l *Logger
+	require.NotPanics(t, func() {
+		l.Log(Event{Action: ActionStart})
+	})
+	require.NoError(t, l.Close())
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path, 200, 2)
+	require.NoError(t, err)
+
+	l := New(sink)
+	for range 10 {
+		l.Log(Event{Action: ActionStatus, TaskID: "375b0522-72ed-4f3f-88d0-01d360d06b8c"})
+	}
+	require.NoError(t, l.Close())
+
+	require.FileExists(t, path)
+	require.FileExists(t, path+".1")
+	require.FileExists(t, path+".2")
+	require.NoFileExists(t, path+".3")
+
+	// every line of the current file must be a complete JSON event
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		require.Equal(t, ActionStatus, ev.Action)
+	}
+	require.NoError(t, scanner.Err())
+}
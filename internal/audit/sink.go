@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Sink is the destination audit events are written to
+type Sink interface {
+	Write(ev Event) error
+	Close() error
+}
+
+// make sure the sinks implement Sink
+var (
+	_ Sink = &WriterSink{}
+	_ Sink = &FileSink{}
+)
+
+// WriterSink writes JSON encoded events to an io.Writer such as os.Stdout
+type WriterSink struct {
+	enc *json.Encoder
+}
+
+// NewWriterSink creates a sink that writes one JSON event per line to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w)}
+}
+
+// Write encodes the event to the writer
+func (s *WriterSink) Write(ev Event) error {
+	return s.enc.Encode(ev)
+}
+
+// Close is a no-op; the caller owns the writer
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink writes JSON encoded events to a file and rotates it once it grows past maxSize.
+// Rotated files are renamed to <path>.1 through <path>.<maxBackups>, the oldest being dropped.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (or creates) the audit file at path for appending
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("audit file max size must be positive, got %d", maxSize)
+	}
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write encodes the event and appends it to the file, rotating first if needed
+func (s *FileSink) Write(ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+	line = append(line, '\n')
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current audit file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat audit file %s: %w", s.path, err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts the existing backups up by one and starts a new file
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file %s: %w", s.path, err)
+	}
+
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			src := fmt.Sprintf("%s.%d", s.path, i)
+			dst := fmt.Sprintf("%s.%d", s.path, i+1)
+			if err := os.Rename(src, dst); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate audit file %s: %w", src, err)
+			}
+		}
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate audit file %s: %w", s.path, err)
+		}
+	} else if err := os.Truncate(s.path, 0); err != nil {
+		return fmt.Errorf("failed to truncate audit file %s: %w", s.path, err)
+	}
+
+	return s.open()
+}
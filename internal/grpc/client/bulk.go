@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// TaskSelector selects the tasks of a bulk operation. All set fields must match and at least one must be set.
+type TaskSelector struct {
+	// LabelSelector is a comma-separated label selector e.g. "team=infra,env!=prod"
+	LabelSelector string
+	// Statuses of the tasks to select; the server selects only running tasks if empty
+	Statuses []pb.JobStatus
+	// Owner is the client ID owning the tasks; only admin can select the tasks of other clients
+	Owner string
+	// StartedBefore selects tasks started before this time if set
+	StartedBefore time.Time
+}
+
+func (s TaskSelector) toProto() *pb.TaskSelector {
+	selector := &pb.TaskSelector{
+		LabelSelector: s.LabelSelector,
+		Statuses:      s.Statuses,
+		Owner:         s.Owner,
+	}
+	if !s.StartedBefore.IsZero() {
+		selector.StartedBefore = timestamppb.New(s.StartedBefore)
+	}
+	return selector
+}
+
+// TaskResult is the outcome of a bulk operation on a single task
+type TaskResult struct {
+	TaskID  string
+	Code    codes.Code
+	Message string
+}
+
+func newTaskResults(pbResults []*pb.TaskResult) []TaskResult {
+	results := make([]TaskResult, 0, len(pbResults))
+	for _, r := range pbResults {
+		results = append(results, TaskResult{TaskID: r.TaskId, Code: codes.Code(r.Code), Message: r.Message})
+	}
+	return results
+}
+
+// StopTasks stops all tasks matching the selector. With dryRun the tasks that would be stopped are returned.
+func (m *Manager) StopTasks(ctx context.Context, selector TaskSelector, dryRun bool) ([]TaskResult, error) {
+	var header metadata.MD
+	resp, err := m.client.StopTasks(ctx, &pb.StopTasksRequest{
+		Selector: selector.toProto(),
+		DryRun:   dryRun,
+	}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error stopping tasks: %w", withRequestID(err, header))
+	}
+	return newTaskResults(resp.Results), nil
+}
+
+// SignalTasks sends the named signal such as "SIGTERM" to all tasks matching the selector. With dryRun the
+// tasks that would be signaled are returned.
+func (m *Manager) SignalTasks(ctx context.Context, selector TaskSelector, signal string, dryRun bool) ([]TaskResult, error) {
+	var header metadata.MD
+	resp, err := m.client.SignalTasks(ctx, &pb.SignalTasksRequest{
+		Selector: selector.toProto(),
+		Signal:   signal,
+		DryRun:   dryRun,
+	}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error signaling tasks: %w", withRequestID(err, header))
+	}
+	return newTaskResults(resp.Results), nil
+}
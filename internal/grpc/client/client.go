@@ -3,6 +3,7 @@ package client
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 
 	"google.golang.org/grpc"
@@ -12,16 +13,52 @@ import (
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
 )
 
-// New creates a new gRPC client with mTLS authentication
-func New(userID string, serverAddr string) (pb.TaskManagerClient, *grpc.ClientConn, error) {
-	cert, err := basegrpc.LoadTLSCert(userID)
+// Credentials selects the client certificate and CA used for mTLS. The certificate is read from
+// CertFile and KeyFile if set, otherwise the embedded certificate named UserID is used.
+// The CA is read from CAFile if set, otherwise the embedded CA is used.
+type Credentials struct {
+	UserID   string
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// load loads the client certificate and the CA certificate pool
+func (c Credentials) load() (tls.Certificate, *x509.CertPool, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case c.CertFile != "" || c.KeyFile != "":
+		if c.CertFile == "" || c.KeyFile == "" {
+			return tls.Certificate{}, nil, errors.New("both a client certificate and key file are required")
+		}
+		cert, err = basegrpc.LoadTLSCertFromFiles(c.CertFile, c.KeyFile)
+	case c.UserID != "":
+		cert, err = basegrpc.LoadTLSCert(c.UserID)
+	default:
+		return tls.Certificate{}, nil, errors.New("no client certificate configured: set --user-id or a context with a certificate and key")
+	}
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("loading client cert: %w", err)
+	}
+
+	var caPool *x509.CertPool
+	if c.CAFile != "" {
+		caPool, err = basegrpc.LoadCACertPoolFromFile(c.CAFile)
+	} else {
+		caPool, err = basegrpc.LoadCACertPool()
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("loading client cert: %w", err)
+		return tls.Certificate{}, nil, fmt.Errorf("loading CA cert: %w", err)
 	}
+	return cert, caPool, nil
+}
 
-	caPool, err := basegrpc.LoadCACertPool()
+// New creates a new gRPC client with mTLS authentication
+func New(creds Credentials, serverAddr string) (pb.TaskManagerClient, *grpc.ClientConn, error) {
+	cert, caPool, err := creds.load()
 	if err != nil {
-		return nil, nil, fmt.Errorf("loading CA cert: %w", err)
+		return nil, nil, err
 	}
 
 	conn, err := createConnection(serverAddr, cert, caPool)
@@ -6,10 +6,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	pb "github.com/mikewurtz/taskman/gen/proto"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
 )
 
 // Manager wraps the gRPC client operations
@@ -19,8 +26,8 @@ type Manager struct {
 }
 
 // NewManager sets up a new gRPC manager
-func NewManager(userID, serverAddr string) (*Manager, error) {
-	client, conn, err := New(userID, serverAddr)
+func NewManager(creds Credentials, serverAddr string) (*Manager, error) {
+	client, conn, err := New(creds, serverAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
@@ -36,37 +43,210 @@ func (m *Manager) Close() error {
 	return m.conn.Close()
 }
 
-// StartTask starts a new task with the given command and arguments
-func (m *Manager) StartTask(ctx context.Context, command string, args []string) (string, error) {
-	resp, err := m.client.StartTask(ctx, &pb.StartTaskRequest{
-		Command: command,
-		Args:    args,
-	})
-	if err != nil {
-		return "", fmt.Errorf("error starting task: %w", err)
+// StartOptions are the optional settings of a new task
+type StartOptions struct {
+	// Labels are attached to the task to select it later
+	Labels map[string]string
+	// IdempotencyKey identifies the start across retries; a random key is used if empty
+	IdempotencyKey string
+	// Priority orders the task if the server queues it; higher priorities start first
+	Priority int32
+	// Retry restarts the process of the task when an attempt fails; the task is not retried if nil
+	Retry *RetryPolicy
+	// Restart keeps the process of a long-running task up; the task is not restarted if nil
+	Restart *RestartPolicy
+	// DependsOn are the tasks that must finish with their condition before the task starts
+	DependsOn []Dependency
+	// Limits are the cgroup limits the task starts with; unset limits are the server defaults
+	Limits ResourceUpdate
+}
+
+// Dependency is a task that must finish with the condition before the dependent task starts
+type Dependency struct {
+	TaskID string
+	// Condition defaults to DEPENDENCY_CONDITION_SUCCESS if unspecified
+	Condition pb.DependencyCondition
+}
+
+// RetryPolicy restarts the process of a task when an attempt fails. Unset fields use the server defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one
+	MaxAttempts int32
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every retry
+	Multiplier float64
+	// RetryOn are the outcomes that are retried; only non-zero exit codes are retried if empty
+	RetryOn []pb.RetryOutcome
+}
+
+func (p *RetryPolicy) toProto() *pb.RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := &pb.RetryPolicy{
+		MaxAttempts:       p.MaxAttempts,
+		BackoffMultiplier: p.Multiplier,
+		RetryOn:           p.RetryOn,
+	}
+	if p.InitialBackoff > 0 {
+		policy.InitialBackoff = durationpb.New(p.InitialBackoff)
+	}
+	if p.MaxBackoff > 0 {
+		policy.MaxBackoff = durationpb.New(p.MaxBackoff)
+	}
+	return policy
+}
+
+// RestartPolicy keeps the process of a long-running task up. Unset fields use the server defaults.
+type RestartPolicy struct {
+	// Mode selects the exits that are restarted
+	Mode pb.RestartMode
+	// InitialBackoff is the delay before the first restart; it doubles on every restart up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxRestarts within Window end the task with the result of its last process
+	MaxRestarts int32
+	Window      time.Duration
+}
+
+func (p *RestartPolicy) toProto() *pb.RestartPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := &pb.RestartPolicy{
+		Mode:        p.Mode,
+		MaxRestarts: p.MaxRestarts,
+	}
+	if p.InitialBackoff > 0 {
+		policy.InitialBackoff = durationpb.New(p.InitialBackoff)
+	}
+	if p.MaxBackoff > 0 {
+		policy.MaxBackoff = durationpb.New(p.MaxBackoff)
+	}
+	if p.Window > 0 {
+		policy.RestartWindow = durationpb.New(p.Window)
+	}
+	return policy
+}
+
+const (
+	// maxStartAttempts is the number of times StartTask tries to start a task on transient errors
+	maxStartAttempts = 3
+	// startAttemptTimeout bounds a single start attempt so that a hanging call is retried
+	startAttemptTimeout = 10 * time.Second
+	// startRetryBackoff is the delay before the first retry; it doubles on every retry
+	startRetryBackoff = 200 * time.Millisecond
+)
+
+// StartTask starts a new task with the given command, arguments and options. The start is retried
+// if the server is unavailable or an attempt times out. Every attempt sends the same idempotency key
+// so that the server returns the task of an earlier attempt instead of starting a duplicate.
+func (m *Manager) StartTask(ctx context.Context, command string, args []string, opts StartOptions) (string, error) {
+	req := &pb.StartTaskRequest{
+		Command:        command,
+		Args:           args,
+		Labels:         opts.Labels,
+		IdempotencyKey: opts.IdempotencyKey,
+		Priority:       opts.Priority,
+		RetryPolicy:    opts.Retry.toProto(),
+		RestartPolicy:  opts.Restart.toProto(),
+		Limits:         opts.Limits.toProto(),
+	}
+	for _, dep := range opts.DependsOn {
+		req.DependsOn = append(req.DependsOn, &pb.TaskDependency{TaskId: dep.TaskID, Condition: dep.Condition})
+	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = uuid.New().String()
+	}
+
+	backoff := startRetryBackoff
+	for attempt := 1; ; attempt++ {
+		var header metadata.MD
+		attemptCtx, cancel := context.WithTimeout(ctx, startAttemptTimeout)
+		resp, err := m.client.StartTask(attemptCtx, req, grpc.Header(&header))
+		cancel()
+		if err == nil {
+			return resp.TaskId, nil
+		}
+		if attempt >= maxStartAttempts || !retryableStartError(ctx, err) {
+			return "", fmt.Errorf("error starting task: %w", withRequestID(err, header))
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return "", fmt.Errorf("error starting task: %w", withRequestID(err, header))
+		}
+		backoff *= 2
+	}
+}
+
+// retryableStartError reports whether a failed start attempt may be retried: the server was
+// unavailable or the attempt timed out while the context of the caller is still live
+func retryableStartError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
 	}
-	return resp.TaskId, nil
 }
 
 // GetTaskStatus gets the status of a task by its ID
 func (m *Manager) GetTaskStatus(ctx context.Context, taskID string) (*TaskStatus, error) {
-	pbStatus, err := m.client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: taskID})
+	var header metadata.MD
+	pbStatus, err := m.client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: taskID}, grpc.Header(&header))
 	if err != nil {
-		return nil, fmt.Errorf("error getting task status: %w", err)
+		return nil, fmt.Errorf("error getting task status: %w", withRequestID(err, header))
 	}
 
-	returnStatus := &TaskStatus{
-		TaskID:            pbStatus.TaskId,
-		Status:            pbStatus.Status.String(),
-		StartTime:         pbStatus.StartTime.AsTime(),
-		EndTime:           pbStatus.EndTime.AsTime(),
-		ExitCode:          pbStatus.ExitCode,
-		ProcessID:         pbStatus.ProcessId,
-		TerminationSignal: pbStatus.TerminationSignal,
-		TerminationSource: pbStatus.TerminationSource,
+	return newTaskStatus(pbStatus), nil
+}
+
+// ListTasks lists the statuses of the tasks matching the label selector; all tasks are listed if it is empty
+func (m *Manager) ListTasks(ctx context.Context, labelSelector string) ([]*TaskStatus, error) {
+	var header metadata.MD
+	resp, err := m.client.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: labelSelector}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error listing tasks: %w", withRequestID(err, header))
 	}
 
-	return returnStatus, nil
+	statuses := make([]*TaskStatus, 0, len(resp.Tasks))
+	for _, pbStatus := range resp.Tasks {
+		statuses = append(statuses, newTaskStatus(pbStatus))
+	}
+	return statuses, nil
+}
+
+// WaitTasks waits until all of the tasks, or any of them if waitAny is set, have completed and
+// returns their statuses. If timeout is positive the current statuses are returned once it expires
+// and completed is false if the tasks have not completed by then.
+func (m *Manager) WaitTasks(ctx context.Context, taskIDs []string, waitAny bool, timeout time.Duration) ([]*TaskStatus, bool, error) {
+	req := &pb.WaitTasksRequest{TaskIds: taskIDs}
+	if waitAny {
+		req.Mode = pb.WaitMode_WAIT_MODE_ANY
+	}
+	if timeout > 0 {
+		req.Timeout = durationpb.New(timeout)
+	}
+
+	var header metadata.MD
+	resp, err := m.client.WaitTasks(ctx, req, grpc.Header(&header))
+	if err != nil {
+		return nil, false, fmt.Errorf("error waiting for tasks: %w", withRequestID(err, header))
+	}
+
+	statuses := make([]*TaskStatus, 0, len(resp.Statuses))
+	for _, pbStatus := range resp.Statuses {
+		statuses = append(statuses, newTaskStatus(pbStatus))
+	}
+	return statuses, resp.Completed, nil
 }
 
 // StreamTaskOutput streams the output of a task by its ID
@@ -83,7 +263,9 @@ func (m *Manager) StreamTaskOutput(ctx context.Context, taskID string) error {
 			return nil
 		}
 		if err != nil {
-			return fmt.Errorf("error receiving stream: %w", err)
+			// the header has been received or the stream failed by the time Recv returns an error
+			header, _ := stream.Header()
+			return fmt.Errorf("error receiving stream: %w", withRequestID(err, header))
 		}
 
 		// Write raw bytes to stdout without UTF-8 conversion
@@ -95,11 +277,73 @@ func (m *Manager) StreamTaskOutput(ctx context.Context, taskID string) error {
 
 // StopTask stops a task by its ID
 func (m *Manager) StopTask(ctx context.Context, taskID string) error {
-	_, err := m.client.StopTask(ctx, &pb.StopTaskRequest{TaskId: taskID})
+	var header metadata.MD
+	_, err := m.client.StopTask(ctx, &pb.StopTaskRequest{TaskId: taskID}, grpc.Header(&header))
+	if err != nil {
+		return fmt.Errorf("error stopping task: %w", withRequestID(err, header))
+	}
+	return nil
+}
+
+// DeleteTask removes a finished task and its output by its ID
+func (m *Manager) DeleteTask(ctx context.Context, taskID string) error {
+	var header metadata.MD
+	_, err := m.client.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: taskID}, grpc.Header(&header))
+	if err != nil {
+		return fmt.Errorf("error deleting task: %w", withRequestID(err, header))
+	}
+	return nil
+}
+
+// SignalTask sends the named signal such as "SIGTERM" to a task by its ID
+func (m *Manager) SignalTask(ctx context.Context, taskID, signal string) error {
+	var header metadata.MD
+	_, err := m.client.SignalTask(ctx, &pb.SignalTaskRequest{TaskId: taskID, Signal: signal}, grpc.Header(&header))
+	if err != nil {
+		return fmt.Errorf("error signaling task: %w", withRequestID(err, header))
+	}
+	return nil
+}
+
+// PauseTask freezes the processes of a running task by its ID
+func (m *Manager) PauseTask(ctx context.Context, taskID string) error {
+	var header metadata.MD
+	_, err := m.client.PauseTask(ctx, &pb.PauseTaskRequest{TaskId: taskID}, grpc.Header(&header))
 	if err != nil {
-		return fmt.Errorf("error stopping task: %w", err)
+		return fmt.Errorf("error pausing task: %w", withRequestID(err, header))
 	}
+	return nil
+}
 
-	fmt.Printf("Task %s stopped successfully.\n", taskID)
+// ResumeTask thaws the processes of a paused task by its ID
+func (m *Manager) ResumeTask(ctx context.Context, taskID string) error {
+	var header metadata.MD
+	_, err := m.client.ResumeTask(ctx, &pb.ResumeTaskRequest{TaskId: taskID}, grpc.Header(&header))
+	if err != nil {
+		return fmt.Errorf("error resuming task: %w", withRequestID(err, header))
+	}
 	return nil
 }
+
+// RequestError wraps the error of a failed call with the request ID returned by the server so that
+// it can be matched against the server logs
+type RequestError struct {
+	Err       error
+	RequestID string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v (request id: %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// withRequestID wraps err with the request ID returned by the server if there is one
+func withRequestID(err error, header metadata.MD) error {
+	if ids := header.Get(basegrpc.RequestIDHeader); len(ids) > 0 {
+		return &RequestError{Err: err, RequestID: ids[0]}
+	}
+	return err
+}
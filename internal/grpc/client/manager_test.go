@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// fakeStartClient returns the queued errors from StartTask before succeeding and records every request
+type fakeStartClient struct {
+	pb.TaskManagerClient
+
+	mu       sync.Mutex
+	errs     []error
+	requests []*pb.StartTaskRequest
+}
+
+func (f *fakeStartClient) StartTask(ctx context.Context, req *pb.StartTaskRequest, opts ...grpc.CallOption) (*pb.StartTaskResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &pb.StartTaskResponse{TaskId: "task-1"}, nil
+}
+
+func TestManagerStartTaskRetries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc             string
+		errs             []error
+		key              string
+		expectedErr      codes.Code
+		expectedAttempts int
+	}{
+		{
+			desc:             "first attempt succeeds",
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "retries unavailable and deadline exceeded",
+			errs:             []error{status.Error(codes.Unavailable, "down"), status.Error(codes.DeadlineExceeded, "timeout")},
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "keeps the given key",
+			errs:             []error{status.Error(codes.Unavailable, "down")},
+			key:              "ci-job-42",
+			expectedAttempts: 2,
+		},
+		{
+			desc: "gives up after the last attempt",
+			errs: []error{
+				status.Error(codes.Unavailable, "down"),
+				status.Error(codes.Unavailable, "down"),
+				status.Error(codes.Unavailable, "down"),
+			},
+			expectedErr:      codes.Unavailable,
+			expectedAttempts: maxStartAttempts,
+		},
+		{
+			desc:             "does not retry other errors",
+			errs:             []error{status.Error(codes.AlreadyExists, "conflict")},
+			expectedErr:      codes.AlreadyExists,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeStartClient{errs: tt.errs}
+			m := &Manager{client: fake}
+
+			taskID, err := m.StartTask(context.Background(), "ls", []string{"-l"}, StartOptions{IdempotencyKey: tt.key})
+			if tt.expectedErr != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErr, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "task-1", taskID)
+			}
+
+			require.Len(t, fake.requests, tt.expectedAttempts)
+			key := fake.requests[0].IdempotencyKey
+			require.NotEmpty(t, key)
+			if tt.key != "" {
+				assert.Equal(t, tt.key, key)
+			}
+			for _, req := range fake.requests {
+				assert.Equal(t, key, req.IdempotencyKey, "every attempt must send the same key")
+			}
+		})
+	}
+}
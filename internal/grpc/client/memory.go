@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// MemoryStatus is the memory state of the process of a task
+type MemoryStatus struct {
+	// HighEvents, MaxEvents and OOMKillEvents are the memory.events counters: how often the processes
+	// were throttled above memory.high, the usage reached memory.max and processes were OOM killed
+	HighEvents    int64
+	MaxEvents     int64
+	OOMKillEvents int64
+	// SomePressure and FullPressure are the percentages of time some or all processes stalled on memory
+	// over the last 10 seconds; only set while the process runs
+	SomePressure float64
+	FullPressure float64
+}
+
+// MemoryEvent is a memory event of the process of a task
+type MemoryEvent struct {
+	Time time.Time
+	// Attempt is the attempt whose process the event occurred in
+	Attempt int32
+	// Kind is high, max, oom_kill, pressure or stop
+	Kind string
+	// Count is the memory.events counter of the kind after the event; 0 for pressure and stop events
+	Count        int64
+	SomePressure float64
+	FullPressure float64
+}
+
+func newMemoryStatus(m *pb.MemoryStatus) MemoryStatus {
+	return MemoryStatus{
+		HighEvents:    m.GetHighEvents(),
+		MaxEvents:     m.GetMaxEvents(),
+		OOMKillEvents: m.GetOomKillEvents(),
+		SomePressure:  m.GetSomePressure(),
+		FullPressure:  m.GetFullPressure(),
+	}
+}
+
+func newMemoryEvent(e *pb.MemoryEvent) MemoryEvent {
+	return MemoryEvent{
+		Time:         optionalTimestamp(e.Time),
+		Attempt:      e.Attempt,
+		Kind:         e.Kind,
+		Count:        e.Count,
+		SomePressure: e.SomePressure,
+		FullPressure: e.FullPressure,
+	}
+}
+
+// WatchMemoryEvents calls fn with the kept memory events of a task by its ID and then with each new
+// event until the task finishes
+func (m *Manager) WatchMemoryEvents(ctx context.Context, taskID string, fn func(MemoryEvent) error) error {
+	stream, err := m.client.WatchMemoryEvents(ctx, &pb.WatchMemoryEventsRequest{TaskId: taskID})
+	if err != nil {
+		return fmt.Errorf("error starting memory event stream: %w", err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			header, _ := stream.Header()
+			return fmt.Errorf("error receiving memory events: %w", withRequestID(err, header))
+		}
+		if err := fn(newMemoryEvent(resp.Event)); err != nil {
+			return err
+		}
+	}
+}
+
+// idle reports whether the process was never throttled or killed for its memory and does not stall on it
+func (m MemoryStatus) idle() bool {
+	return m == MemoryStatus{}
+}
+
+// String renders the memory state as "high=3 max=1 oom_kill=0 pressure=12.50%/8.02%" with the some and
+// full pressure
+func (m MemoryStatus) String() string {
+	return fmt.Sprintf("high=%d max=%d oom_kill=%d pressure=%.2f%%/%.2f%%", m.HighEvents, m.MaxEvents, m.OOMKillEvents,
+		m.SomePressure, m.FullPressure)
+}
+
+// String renders the event as a line such as "2025-01-02 15:04:05 attempt 1 max=2 pressure=12.50%/8.02%"
+func (e MemoryEvent) String() string {
+	return fmt.Sprintf("%s attempt %d %s pressure=%.2f%%/%.2f%%", formatTime(e.Time), e.Attempt, e.kindCount(),
+		e.SomePressure, e.FullPressure)
+}
+
+// kindCount renders the kind of the event with its counter, if any
+func (e MemoryEvent) kindCount() string {
+	if e.Count == 0 {
+		return e.Kind
+	}
+	return fmt.Sprintf("%s=%d", e.Kind, e.Count)
+}
+
+// FormatMemoryEvents renders memory events as a table with one row per event
+func FormatMemoryEvents(events []MemoryEvent) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"MEMORY EVENT AT", "ATTEMPT", "EVENT", "SOME PRESSURE", "FULL PRESSURE"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, e := range events {
+		table.Append([]string{
+			formatTime(e.Time),
+			fmt.Sprintf("%d", e.Attempt),
+			e.kindCount(),
+			fmt.Sprintf("%.2f%%", e.SomePressure),
+			fmt.Sprintf("%.2f%%", e.FullPressure),
+		})
+	}
+
+	table.Render()
+	return buf.String()
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// QuotaUsage is the usage of a single quota; a Limit of 0 is unlimited
+type QuotaUsage struct {
+	Used  int64
+	Limit int64
+}
+
+func newQuotaUsage(u *pb.QuotaUsage) QuotaUsage {
+	return QuotaUsage{Used: u.GetUsed(), Limit: u.GetLimit()}
+}
+
+// Quota is the usage of a client against the task quotas of the server
+type Quota struct {
+	ClientID string
+	// RunningTasks are the running tasks of the client
+	RunningTasks QuotaUsage
+	// GlobalRunningTasks are the running tasks of all clients
+	GlobalRunningTasks QuotaUsage
+	// StartsPerMinute are the tasks started by the client in the last minute
+	StartsPerMinute QuotaUsage
+	// QueuedTasks are the tasks of all clients waiting for a running task quota to free up
+	QueuedTasks QuotaUsage
+}
+
+// GetQuota gets the quota usage of the client; the caller's usage is returned if clientID is empty
+func (m *Manager) GetQuota(ctx context.Context, clientID string) (*Quota, error) {
+	var header metadata.MD
+	resp, err := m.client.GetQuota(ctx, &pb.GetQuotaRequest{ClientId: clientID}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error getting quota: %w", withRequestID(err, header))
+	}
+
+	return &Quota{
+		ClientID:           resp.ClientId,
+		RunningTasks:       newQuotaUsage(resp.RunningTasks),
+		GlobalRunningTasks: newQuotaUsage(resp.GlobalRunningTasks),
+		StartsPerMinute:    newQuotaUsage(resp.StartsPerMinute),
+		QueuedTasks:        newQuotaUsage(resp.QueuedTasks),
+	}, nil
+}
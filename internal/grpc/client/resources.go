@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// ResourceLimits are the cgroup limits of a task; a limit of 0 is unlimited
+type ResourceLimits struct {
+	// CPUMillis is the CPU time in thousandths of a CPU
+	CPUMillis int64
+	// MemoryMax and MemoryHigh are the memory.max and memory.high limits in bytes
+	MemoryMax  int64
+	MemoryHigh int64
+	// IOReadBPS and IOWriteBPS are the io.max limits in bytes per second
+	IOReadBPS  int64
+	IOWriteBPS int64
+	// PidsMax is the pids.max limit of processes and threads
+	PidsMax int64
+	// CPUs and Mems are the cpuset.cpus and cpuset.mems lists the task is pinned to; empty if it is not pinned
+	CPUs string
+	Mems string
+}
+
+// ResourceUpdate changes the limits of a task; nil limits are kept, 0 removes a limit and an empty list unpins the task
+type ResourceUpdate struct {
+	CPUMillis  *int64
+	MemoryMax  *int64
+	MemoryHigh *int64
+	IOReadBPS  *int64
+	IOWriteBPS *int64
+	PidsMax    *int64
+	CPUs       *string
+	Mems       *string
+}
+
+// ResourceChange is a change of the limits of a task
+type ResourceChange struct {
+	Time time.Time
+	// ClientID is the client that changed the limits
+	ClientID string
+	Previous ResourceLimits
+	Limits   ResourceLimits
+}
+
+func newResourceLimits(l *pb.ResourceLimits) ResourceLimits {
+	return ResourceLimits{
+		CPUMillis:  l.GetCpuMillis(),
+		MemoryMax:  l.GetMemoryMaxBytes(),
+		MemoryHigh: l.GetMemoryHighBytes(),
+		IOReadBPS:  l.GetIoReadBps(),
+		IOWriteBPS: l.GetIoWriteBps(),
+		PidsMax:    l.GetPidsMax(),
+		CPUs:       l.GetCpusetCpus(),
+		Mems:       l.GetCpusetMems(),
+	}
+}
+
+func (u ResourceUpdate) toProto() *pb.ResourceLimits {
+	return &pb.ResourceLimits{
+		CpuMillis:       u.CPUMillis,
+		MemoryMaxBytes:  u.MemoryMax,
+		MemoryHighBytes: u.MemoryHigh,
+		IoReadBps:       u.IOReadBPS,
+		IoWriteBps:      u.IOWriteBPS,
+		PidsMax:         u.PidsMax,
+		CpusetCpus:      u.CPUs,
+		CpusetMems:      u.Mems,
+	}
+}
+
+// UpdateTaskResources changes the cgroup limits of a task by its ID and returns its effective limits
+func (m *Manager) UpdateTaskResources(ctx context.Context, taskID string, update ResourceUpdate) (ResourceLimits, error) {
+	req := &pb.UpdateTaskResourcesRequest{TaskId: taskID, Limits: update.toProto()}
+
+	var header metadata.MD
+	resp, err := m.client.UpdateTaskResources(ctx, req, grpc.Header(&header))
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("error updating task resources: %w", withRequestID(err, header))
+	}
+	return newResourceLimits(resp.Limits), nil
+}
+
+// formatLimit renders a limit with its unit; 0 is "max"
+func formatLimit(limit int64, unit string) string {
+	if limit == 0 {
+		return "max"
+	}
+	return fmt.Sprintf("%d%s", limit, unit)
+}
+
+// String renders the limits as "cpu=200m memory.max=67108864 memory.high=max io=1048576/1048576 pids=512",
+// followed by " cpus=0-3 mems=0" if the task is pinned
+func (l ResourceLimits) String() string {
+	s := fmt.Sprintf("cpu=%s memory.max=%s memory.high=%s io=%s/%s pids=%s", formatLimit(l.CPUMillis, "m"),
+		formatLimit(l.MemoryMax, ""), formatLimit(l.MemoryHigh, ""), formatLimit(l.IOReadBPS, ""), formatLimit(l.IOWriteBPS, ""),
+		formatLimit(l.PidsMax, ""))
+	if l.CPUs != "" {
+		s += " cpus=" + l.CPUs
+	}
+	if l.Mems != "" {
+		s += " mems=" + l.Mems
+	}
+	return s
+}
+
+// FormatResourceChanges renders the resource limit changes of a task as a table with one row per change
+func FormatResourceChanges(t *TaskStatus) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"CHANGED AT", "CLIENT ID", "PREVIOUS LIMITS", "LIMITS"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, change := range t.ResourceChanges {
+		table.Append([]string{formatTime(change.Time), change.ClientID, change.Previous.String(), change.Limits.String()})
+	}
+
+	table.Render()
+	return buf.String()
+}
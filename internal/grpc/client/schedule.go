@@ -0,0 +1,173 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// ScheduleOptions are the settings of a new schedule
+type ScheduleOptions struct {
+	// Cron is a 5 field cron expression or a descriptor such as "@hourly" or "@every 10m"
+	Cron string
+	// Labels, Priority, Retry, Restart and Limits are applied to every task the schedule starts; unset
+	// limits are the server defaults
+	Labels   map[string]string
+	Priority int32
+	Retry    *RetryPolicy
+	Restart  *RestartPolicy
+	Limits   ResourceUpdate
+	// Concurrency decides what a run does while an earlier task of the schedule is unfinished;
+	// CONCURRENCY_POLICY_ALLOW if unspecified
+	Concurrency pb.ConcurrencyPolicy
+	// Paused creates the schedule without arming it
+	Paused bool
+}
+
+// Schedule is a cron schedule that starts a task from its template on every run
+type Schedule struct {
+	ScheduleID  string
+	ClientID    string
+	Cron        string
+	Command     string
+	Args        []string
+	Labels      map[string]string
+	Priority    int32
+	Concurrency pb.ConcurrencyPolicy
+	Paused      bool
+	CreateTime  time.Time
+	// NextRunTime is the zero time while the schedule is paused
+	NextRunTime time.Time
+	// LastRunTime is the zero time if the schedule has not run yet
+	LastRunTime time.Time
+	// LastTaskID is the task started by the last run; empty if it was skipped or failed
+	LastTaskID string
+	// ActiveTaskIDs are the unfinished tasks started by the schedule
+	ActiveTaskIDs []string
+}
+
+func newSchedule(s *pb.Schedule) *Schedule {
+	template := s.GetTemplate()
+	return &Schedule{
+		ScheduleID:    s.ScheduleId,
+		ClientID:      s.ClientId,
+		Cron:          s.CronExpression,
+		Command:       template.GetCommand(),
+		Args:          template.GetArgs(),
+		Labels:        template.GetLabels(),
+		Priority:      template.GetPriority(),
+		Concurrency:   s.ConcurrencyPolicy,
+		Paused:        s.Paused,
+		CreateTime:    s.CreateTime.AsTime(),
+		NextRunTime:   optionalTimestamp(s.NextRunTime),
+		LastRunTime:   optionalTimestamp(s.LastRunTime),
+		LastTaskID:    s.LastTaskId,
+		ActiveTaskIDs: s.ActiveTaskIds,
+	}
+}
+
+// CreateSchedule creates a schedule that starts the command with the arguments on every run
+func (m *Manager) CreateSchedule(ctx context.Context, command string, args []string, opts ScheduleOptions) (*Schedule, error) {
+	req := &pb.CreateScheduleRequest{
+		CronExpression: opts.Cron,
+		Template: &pb.TaskTemplate{
+			Command:       command,
+			Args:          args,
+			Labels:        opts.Labels,
+			Priority:      opts.Priority,
+			RetryPolicy:   opts.Retry.toProto(),
+			RestartPolicy: opts.Restart.toProto(),
+			Limits:        opts.Limits.toProto(),
+		},
+		ConcurrencyPolicy: opts.Concurrency,
+		Paused:            opts.Paused,
+	}
+
+	var header metadata.MD
+	resp, err := m.client.CreateSchedule(ctx, req, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error creating schedule: %w", withRequestID(err, header))
+	}
+	return newSchedule(resp.Schedule), nil
+}
+
+// ListSchedules lists the schedules of the caller, or of all clients for admins
+func (m *Manager) ListSchedules(ctx context.Context) ([]*Schedule, error) {
+	var header metadata.MD
+	resp, err := m.client.ListSchedules(ctx, &pb.ListSchedulesRequest{}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error listing schedules: %w", withRequestID(err, header))
+	}
+
+	schedules := make([]*Schedule, 0, len(resp.Schedules))
+	for _, s := range resp.Schedules {
+		schedules = append(schedules, newSchedule(s))
+	}
+	return schedules, nil
+}
+
+// DeleteSchedule deletes a schedule by its ID; the tasks it started keep running
+func (m *Manager) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	var header metadata.MD
+	_, err := m.client.DeleteSchedule(ctx, &pb.DeleteScheduleRequest{ScheduleId: scheduleID}, grpc.Header(&header))
+	if err != nil {
+		return fmt.Errorf("error deleting schedule: %w", withRequestID(err, header))
+	}
+	return nil
+}
+
+// PauseSchedule pauses a schedule by its ID, or resumes it if paused is false
+func (m *Manager) PauseSchedule(ctx context.Context, scheduleID string, paused bool) (*Schedule, error) {
+	var header metadata.MD
+	resp, err := m.client.PauseSchedule(ctx, &pb.PauseScheduleRequest{ScheduleId: scheduleID, Paused: paused}, grpc.Header(&header))
+	if err != nil {
+		return nil, fmt.Errorf("error pausing schedule: %w", withRequestID(err, header))
+	}
+	return newSchedule(resp.Schedule), nil
+}
+
+// concurrencyName returns the short name of a concurrency policy such as "forbid"
+func concurrencyName(policy pb.ConcurrencyPolicy) string {
+	if policy == pb.ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED {
+		return "allow"
+	}
+	return strings.ToLower(strings.TrimPrefix(policy.String(), "CONCURRENCY_POLICY_"))
+}
+
+// FormatSchedules renders the schedules as a table with one row per schedule
+func FormatSchedules(schedules []*Schedule) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{
+		"SCHEDULE ID", "CRON", "COMMAND", "CONCURRENCY", "PAUSED", "NEXT RUN", "LAST RUN", "LAST TASK ID", "ACTIVE",
+	})
+	table.SetAutoWrapText(true)
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, s := range schedules {
+		table.Append([]string{
+			s.ScheduleID,
+			s.Cron,
+			strings.Join(append([]string{s.Command}, s.Args...), " "),
+			concurrencyName(s.Concurrency),
+			fmt.Sprintf("%t", s.Paused),
+			formatTime(s.NextRunTime),
+			formatTime(s.LastRunTime),
+			formatString(s.LastTaskID),
+			fmt.Sprintf("%d", len(s.ActiveTaskIDs)),
+		})
+	}
+
+	table.Render()
+	return buf.String()
+}
@@ -3,9 +3,17 @@ package client
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"golang.org/x/sys/unix"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
 )
 
 // TaskStatus represents the status of the task
@@ -19,6 +27,167 @@ type TaskStatus struct {
 	ProcessID         int32
 	TerminationSignal string
 	TerminationSource string
+	Labels            map[string]string
+	Priority          int32
+	// QueuePosition is the 1-based position in the queue while the task is queued
+	QueuePosition int32
+	// Attempts are the runs of the process of the task; the last one is the current attempt
+	Attempts []TaskAttempt
+	// MaxAttempts is the maximum number of attempts of the retry policy; 1 if the task is not retried and
+	// 0 if it has a restart policy
+	MaxAttempts int32
+	// DependsOn are the tasks that must finish before the task starts
+	DependsOn []Dependency
+	// ScheduleID is the schedule that started the task, if any
+	ScheduleID string
+	// RestartCount is the number of times the restart policy restarted the process
+	RestartCount int32
+	// LastExitReason describes how the last process exited, e.g. "exit code 1"
+	LastExitReason string
+	// PausedDuration is the time the task spent paused, including a pause that has not ended yet
+	PausedDuration time.Duration
+	// Limits are the effective cgroup limits of the task; nil if the server did not report them
+	Limits *ResourceLimits
+	// ResourceChanges are the last changes of the limits, oldest first
+	ResourceChanges []ResourceChange
+	// PidsMaxEvents is how often a fork of the current or last process failed at pids.max
+	PidsMaxEvents int64
+	// Memory is the memory state of the current or last process; nil if the server did not report it
+	Memory *MemoryStatus
+	// MemoryEvents are the last memory events of the task, oldest first
+	MemoryEvents []MemoryEvent
+}
+
+// TaskAttempt is a single run of the process of a task
+type TaskAttempt struct {
+	Attempt           int32
+	ProcessID         int32
+	Status            string
+	StartTime         time.Time
+	EndTime           time.Time
+	ExitCode          *int32
+	TerminationSignal string
+	TerminationSource string
+	// OutputOffset is the offset of the first byte of the attempt in the output of the task
+	OutputOffset int64
+	// PidsMaxEvents is how often a fork of the attempt failed at pids.max
+	PidsMaxEvents int64
+}
+
+// newTaskStatus converts a status response to the TaskStatus shown to the caller
+func newTaskStatus(pbStatus *pb.TaskStatusResponse) *TaskStatus {
+	attempts := make([]TaskAttempt, 0, len(pbStatus.Attempts))
+	for _, a := range pbStatus.Attempts {
+		attempts = append(attempts, TaskAttempt{
+			Attempt:           a.Attempt,
+			ProcessID:         a.ProcessId,
+			Status:            a.Status.String(),
+			StartTime:         a.StartTime.AsTime(),
+			EndTime:           optionalTimestamp(a.EndTime),
+			ExitCode:          a.ExitCode,
+			TerminationSignal: a.TerminationSignal,
+			TerminationSource: a.TerminationSource,
+			OutputOffset:      a.OutputOffset,
+			PidsMaxEvents:     a.PidsMaxEvents,
+		})
+	}
+
+	var limits *ResourceLimits
+	if pbStatus.Limits != nil {
+		l := newResourceLimits(pbStatus.Limits)
+		limits = &l
+	}
+	var changes []ResourceChange
+	for _, change := range pbStatus.ResourceChanges {
+		changes = append(changes, ResourceChange{
+			Time:     change.Time.AsTime(),
+			ClientID: change.ClientId,
+			Previous: newResourceLimits(change.Previous),
+			Limits:   newResourceLimits(change.Limits),
+		})
+	}
+
+	var memory *MemoryStatus
+	if pbStatus.Memory != nil {
+		m := newMemoryStatus(pbStatus.Memory)
+		memory = &m
+	}
+	var memoryEvents []MemoryEvent
+	for _, event := range pbStatus.MemoryEvents {
+		memoryEvents = append(memoryEvents, newMemoryEvent(event))
+	}
+
+	var dependsOn []Dependency
+	for _, dep := range pbStatus.DependsOn {
+		dependsOn = append(dependsOn, Dependency{TaskID: dep.TaskId, Condition: dep.Condition})
+	}
+
+	return &TaskStatus{
+		TaskID:            pbStatus.TaskId,
+		Status:            pbStatus.Status.String(),
+		StartTime:         pbStatus.StartTime.AsTime(),
+		EndTime:           pbStatus.EndTime.AsTime(),
+		ExitCode:          pbStatus.ExitCode,
+		ProcessID:         pbStatus.ProcessId,
+		TerminationSignal: pbStatus.TerminationSignal,
+		TerminationSource: pbStatus.TerminationSource,
+		Labels:            pbStatus.Labels,
+		Priority:          pbStatus.Priority,
+		QueuePosition:     pbStatus.QueuePosition,
+		Attempts:          attempts,
+		MaxAttempts:       pbStatus.MaxAttempts,
+		DependsOn:         dependsOn,
+		ScheduleID:        pbStatus.ScheduleId,
+		RestartCount:      pbStatus.RestartCount,
+		LastExitReason:    pbStatus.LastExitReason,
+		PausedDuration:    pbStatus.PausedDuration.AsDuration(),
+		Limits:            limits,
+		ResourceChanges:   changes,
+		PidsMaxEvents:     pbStatus.PidsMaxEvents,
+		Memory:            memory,
+		MemoryEvents:      memoryEvents,
+	}
+}
+
+// optionalTimestamp converts a timestamp that may be unset; an unset timestamp is the zero time
+func optionalTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// ShellExitCode returns the exit code a shell would report for the task: the exit code of the
+// process, 128 plus the signal number if it was killed by a signal, 128 plus SIGTERM if it was
+// canceled before its process started or 1 if it failed to start or is unknown
+func (t *TaskStatus) ShellExitCode() int {
+	if t.ExitCode != nil {
+		return int(*t.ExitCode)
+	}
+	if t.Status == pb.JobStatus_JOB_STATUS_CANCELED.String() {
+		return 128 + int(syscall.SIGTERM)
+	}
+	if sig := signalNumber(t.TerminationSignal); sig != 0 {
+		return 128 + int(sig)
+	}
+	return 1
+}
+
+// signalNumber resolves a signal reported by the server, either a description such as "killed"
+// or a name such as "SIGKILL", to its number; returns 0 if it is unknown
+func signalNumber(name string) syscall.Signal {
+	if name == "" {
+		return 0
+	}
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig
+	}
+	for sig := syscall.Signal(1); sig < 65; sig++ {
+		if sig.String() == name {
+			return sig
+		}
+	}
+	return 0
 }
 
 func formatTime(t time.Time) string {
@@ -28,6 +197,14 @@ func formatTime(t time.Time) string {
 	return t.Format("2006-01-02 15:04:05")
 }
 
+// formatStatus renders the status with the queue position of a queued task
+func formatStatus(t *TaskStatus) string {
+	if t.QueuePosition > 0 {
+		return fmt.Sprintf("%s (#%d)", t.Status, t.QueuePosition)
+	}
+	return t.Status
+}
+
 func formatExitCode(code *int32) string {
 	if code == nil {
 		return "-"
@@ -42,29 +219,131 @@ func formatString(s string) string {
 	return s
 }
 
+// formatLabels renders labels as comma-separated key=value pairs sorted by key
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
 func (t *TaskStatus) String() string {
+	s := FormatTaskStatuses([]*TaskStatus{t})
+	if t.MaxAttempts > 1 || len(t.Attempts) > 1 {
+		s += FormatTaskAttempts(t)
+	}
+	if len(t.DependsOn) > 0 {
+		s += FormatTaskDependencies(t)
+	}
+	if t.RestartCount > 0 {
+		s += fmt.Sprintf("Restarts: %d, last exit: %s\n", t.RestartCount, formatString(t.LastExitReason))
+	}
+	if t.PausedDuration > 0 {
+		s += fmt.Sprintf("Paused for: %s\n", t.PausedDuration.Round(time.Millisecond))
+	}
+	if t.PidsMaxEvents > 0 {
+		s += fmt.Sprintf("Forks failed at pids.max: %d\n", t.PidsMaxEvents)
+	}
+	if t.Memory != nil && !t.Memory.idle() {
+		s += fmt.Sprintf("Memory: %s\n", t.Memory)
+	}
+	if len(t.MemoryEvents) > 0 {
+		s += FormatMemoryEvents(t.MemoryEvents)
+	}
+	// the limits are shown once they were changed so that the output of other tasks stays unchanged
+	if len(t.ResourceChanges) > 0 {
+		s += FormatResourceChanges(t)
+		if t.Limits != nil {
+			s += fmt.Sprintf("Limits: %s\n", t.Limits)
+		}
+	}
+	return s
+}
+
+// FormatTaskDependencies renders the dependencies of a task as a table with one row per dependency
+func FormatTaskDependencies(t *TaskStatus) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"DEPENDS ON", "CONDITION"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, dep := range t.DependsOn {
+		table.Append([]string{dep.TaskID, dep.Condition.String()})
+	}
+
+	table.Render()
+	return buf.String()
+}
+
+// FormatTaskAttempts renders the attempts of a task as a table with one row per attempt
+func FormatTaskAttempts(t *TaskStatus) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"ATTEMPT", "PID", "STATUS", "EXIT CODE", "SIGNAL", "STOP SOURCE", "START TIME", "END TIME", "OUTPUT OFFSET"})
+	table.SetBorder(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, a := range t.Attempts {
+		table.Append([]string{
+			formatAttempt(a.Attempt, t.MaxAttempts),
+			fmt.Sprintf("%d", a.ProcessID),
+			a.Status,
+			formatExitCode(a.ExitCode),
+			formatString(a.TerminationSignal),
+			formatString(a.TerminationSource),
+			formatTime(a.StartTime),
+			formatTime(a.EndTime),
+			fmt.Sprintf("%d", a.OutputOffset),
+		})
+	}
+
+	table.Render()
+	return buf.String()
+}
+
+// formatAttempt renders the number of an attempt out of the maximum attempts; restarted tasks have no maximum
+func formatAttempt(attempt, maxAttempts int32) string {
+	if maxAttempts == 0 {
+		return fmt.Sprintf("%d", attempt)
+	}
+	return fmt.Sprintf("%d/%d", attempt, maxAttempts)
+}
+
+// FormatTaskStatuses renders the statuses as a table with one row per task
+func FormatTaskStatuses(statuses []*TaskStatus) string {
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
 	table.SetHeader([]string{
-		"TASK ID", "START TIME", "PID", "STATUS", "EXIT CODE", "SIGNAL", "STOP SOURCE", "END TIME",
+		"TASK ID", "START TIME", "PID", "STATUS", "EXIT CODE", "SIGNAL", "STOP SOURCE", "END TIME", "LABELS",
 	})
 	table.SetAutoWrapText(true)
 	table.SetBorder(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_CENTER)
 	table.SetAlignment(tablewriter.ALIGN_CENTER)
 
-	row := []string{
-		t.TaskID,
-		t.StartTime.Format("2006-01-02 15:04:05"),
-		fmt.Sprintf("%d", t.ProcessID),
-		t.Status,
-		formatExitCode(t.ExitCode),
-		formatString(t.TerminationSignal),
-		formatString(t.TerminationSource),
-		formatTime(t.EndTime),
+	for _, t := range statuses {
+		row := []string{
+			t.TaskID,
+			formatTime(t.StartTime),
+			fmt.Sprintf("%d", t.ProcessID),
+			formatStatus(t),
+			formatExitCode(t.ExitCode),
+			formatString(t.TerminationSignal),
+			formatString(t.TerminationSource),
+			formatTime(t.EndTime),
+			formatLabels(t.Labels),
+		}
+		table.Append(row)
 	}
 
-	table.Append(row)
 	table.Render()
 	return buf.String()
 }
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestShellExitCode(t *testing.T) {
+	t.Parallel()
+
+	exitCode := func(code int32) *int32 {
+		return &code
+	}
+
+	tests := []struct {
+		desc     string
+		status   TaskStatus
+		expected int
+	}{
+		{
+			desc:     "exited ok",
+			status:   TaskStatus{ExitCode: exitCode(0)},
+			expected: 0,
+		},
+		{
+			desc:     "exited with error",
+			status:   TaskStatus{ExitCode: exitCode(2)},
+			expected: 2,
+		},
+		{
+			desc:     "killed by signal description",
+			status:   TaskStatus{TerminationSignal: "killed"},
+			expected: 137,
+		},
+		{
+			desc:     "terminated by signal name",
+			status:   TaskStatus{TerminationSignal: "SIGTERM"},
+			expected: 143,
+		},
+		{
+			desc:     "canceled before start",
+			status:   TaskStatus{Status: pb.JobStatus_JOB_STATUS_CANCELED.String()},
+			expected: 143,
+		},
+		{
+			desc:     "unknown exit",
+			status:   TaskStatus{},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, tt.status.ShellExitCode())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/mikewurtz/taskman/certs"
 )
@@ -42,3 +43,26 @@ func LoadTLSCert(certName string) (tls.Certificate, error) {
 
 	return cert, nil
 }
+
+// LoadCACertPoolFromFile loads the CA certificate pool from a PEM file
+func LoadCACertPoolFromFile(caFile string) (*x509.CertPool, error) {
+	caCert, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA cert: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("failed to append CA certificate")
+	}
+	return pool, nil
+}
+
+// LoadTLSCertFromFiles loads a TLS certificate from PEM certificate and key files
+func LoadTLSCertFromFiles(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load key pair: %w", err)
+	}
+	return cert, nil
+}
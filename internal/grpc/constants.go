@@ -5,3 +5,6 @@ type contextKey string
 const (
 	ClientIDKey = contextKey("clientCN")
 )
+
+// RequestIDHeader is the response header metadata key the server returns the request ID in
+const RequestIDHeader = "x-request-id"
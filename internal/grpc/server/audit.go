@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/audit"
+)
+
+// auditActions maps a gRPC method to the audit action recorded for it
+var auditActions = map[string]string{
+	pb.TaskManager_StartTask_FullMethodName:           audit.ActionStart,
+	pb.TaskManager_StopTask_FullMethodName:            audit.ActionStop,
+	pb.TaskManager_DeleteTask_FullMethodName:          audit.ActionDelete,
+	pb.TaskManager_SignalTask_FullMethodName:          audit.ActionSignal,
+	pb.TaskManager_PauseTask_FullMethodName:           audit.ActionPause,
+	pb.TaskManager_ResumeTask_FullMethodName:          audit.ActionResume,
+	pb.TaskManager_UpdateTaskResources_FullMethodName: audit.ActionResources,
+	pb.TaskManager_GetTaskStatus_FullMethodName:       audit.ActionStatus,
+	pb.TaskManager_StreamTaskOutput_FullMethodName:    audit.ActionStreamClose,
+	pb.TaskManager_WatchMemoryEvents_FullMethodName:   audit.ActionStreamClose,
+	pb.TaskManager_WaitTasks_FullMethodName:           audit.ActionWait,
+	pb.TaskManager_ListTasks_FullMethodName:           audit.ActionList,
+	pb.TaskManager_StopTasks_FullMethodName:           audit.ActionStopBulk,
+	pb.TaskManager_SignalTasks_FullMethodName:         audit.ActionSignalBulk,
+	pb.TaskManager_GetQuota_FullMethodName:            audit.ActionQuota,
+	pb.TaskManager_CreateSchedule_FullMethodName:      audit.ActionScheduleCreate,
+	pb.TaskManager_ListSchedules_FullMethodName:       audit.ActionScheduleList,
+	pb.TaskManager_DeleteSchedule_FullMethodName:      audit.ActionScheduleDelete,
+	pb.TaskManager_PauseSchedule_FullMethodName:       audit.ActionSchedulePause,
+}
+
+// AuditUnaryInterceptor records one audit event per unary call once the handler returns.
+// It must run before ExtractClientCNInterceptor so that authentication failures are recorded.
+// Calls to other services such as health checks and reflection are not audited.
+func AuditUnaryInterceptor(auditLog *audit.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isTaskManagerMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+
+		ev := newAuditEvent(ctx, info.FullMethod, err)
+		setAuditTaskFields(&ev, req)
+		setAuditTaskFields(&ev, resp)
+		auditLog.Log(ev)
+
+		return resp, err
+	}
+}
+
+// AuditStreamInterceptor records an audit event when a stream request is received and
+// another when the stream is closed. It must run before ExtractClientCNStreamInterceptor
+// so that authentication failures are recorded.
+func AuditStreamInterceptor(auditLog *audit.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !isTaskManagerMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		wrapped := &auditServerStream{ServerStream: ss}
+		wrapped.onRecv = func(req any) {
+			ev := newAuditEvent(ss.Context(), info.FullMethod, nil)
+			ev.Action = audit.ActionStreamOpen
+			setAuditTaskFields(&ev, req)
+			auditLog.Log(ev)
+		}
+
+		err := handler(srv, wrapped)
+
+		ev := newAuditEvent(ss.Context(), info.FullMethod, err)
+		setAuditTaskFields(&ev, wrapped.request())
+		auditLog.Log(ev)
+		return err
+	}
+}
+
+// isTaskManagerMethod reports whether the method belongs to the TaskManager service
+func isTaskManagerMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+pb.TaskManager_ServiceDesc.ServiceName+"/")
+}
+
+// newAuditEvent builds the common part of an audit event from the call context and result
+func newAuditEvent(ctx context.Context, method string, err error) audit.Event {
+	code := status.Code(err)
+	ev := audit.Event{
+		Action: auditActions[method],
+		Method: method,
+		Code:   code.String(),
+	}
+	if ev.Action == "" {
+		ev.Action = method
+	}
+
+	// the CN is read from the peer directly since the client ID is only injected further down the chain
+	if cn, cnErr := getClientCN(ctx); cnErr == nil {
+		ev.ClientID = cn
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ev.PeerAddr = p.Addr.String()
+	}
+
+	switch code {
+	case codes.OK:
+		ev.Outcome = audit.OutcomeSuccess
+	case codes.Unauthenticated:
+		ev.Action = audit.ActionAuthFailure
+		ev.Outcome = audit.OutcomeDenied
+	case codes.PermissionDenied:
+		ev.Outcome = audit.OutcomeDenied
+	default:
+		ev.Outcome = audit.OutcomeFailure
+	}
+	if err != nil {
+		ev.Message = status.Convert(err).Message()
+	}
+	return ev
+}
+
+// setAuditTaskFields copies the task ID, schedule ID, command, arguments, signal, label selector and dry run flag from a request or
+// response if present
+func setAuditTaskFields(ev *audit.Event, msg any) {
+	if m, ok := msg.(interface{ GetTaskId() string }); ok && m.GetTaskId() != "" {
+		ev.TaskID = m.GetTaskId()
+	}
+	if m, ok := msg.(interface{ GetTaskIds() []string }); ok && len(m.GetTaskIds()) > 0 {
+		ev.TaskID = strings.Join(m.GetTaskIds(), ",")
+	}
+	if m, ok := msg.(interface{ GetScheduleId() string }); ok && m.GetScheduleId() != "" {
+		ev.ScheduleID = m.GetScheduleId()
+	}
+	if m, ok := msg.(interface{ GetSchedule() *pb.Schedule }); ok && m.GetSchedule() != nil {
+		ev.ScheduleID = m.GetSchedule().GetScheduleId()
+	}
+	if m, ok := msg.(interface{ GetCommand() string }); ok {
+		ev.Command = m.GetCommand()
+	}
+	if m, ok := msg.(interface{ GetArgs() []string }); ok {
+		ev.Args = m.GetArgs()
+	}
+	if m, ok := msg.(interface{ GetTemplate() *pb.TaskTemplate }); ok && m.GetTemplate() != nil {
+		ev.Command = m.GetTemplate().GetCommand()
+		ev.Args = m.GetTemplate().GetArgs()
+	}
+	if m, ok := msg.(interface{ GetSignal() string }); ok {
+		ev.Signal = m.GetSignal()
+	}
+	if m, ok := msg.(interface{ GetLabelSelector() string }); ok {
+		ev.LabelSelector = m.GetLabelSelector()
+	}
+	if m, ok := msg.(interface{ GetSelector() *pb.TaskSelector }); ok {
+		ev.LabelSelector = m.GetSelector().GetLabelSelector()
+	}
+	if m, ok := msg.(interface{ GetDryRun() bool }); ok {
+		ev.DryRun = m.GetDryRun()
+	}
+}
+
+// auditServerStream captures the request message of a server streaming call
+type auditServerStream struct {
+	grpc.ServerStream
+	onRecv func(req any)
+
+	mu  sync.Mutex
+	req any
+}
+
+// RecvMsg records the received request and emits the stream open event
+func (s *auditServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	first := s.req == nil
+	s.req = m
+	s.mu.Unlock()
+	if first {
+		s.onRecv(m)
+	}
+	return nil
+}
+
+func (s *auditServerStream) request() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.req
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/task"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
+)
+
+// StopTasks stops all tasks matching the selector and returns the result for each task
+func (s *taskManagerServer) StopTasks(ctx context.Context, req *pb.StopTasksRequest) (*pb.StopTasksResponse, error) {
+	// queued, waiting and paused tasks are stopped too unless the selector asks for other statuses
+	defaultStatuses := []int{task.JobStatusStarted, task.JobStatusQueued, task.JobStatusWaiting, task.JobStatusPaused}
+	results, err := s.applyToTasks(ctx, req.Selector, defaultStatuses, req.DryRun, func(taskID string) error {
+		return s.taskManager.StopTask(ctx, taskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.StopTasksResponse{Results: results}, nil
+}
+
+// SignalTasks sends a signal to all tasks matching the selector and returns the result for each task
+func (s *taskManagerServer) SignalTasks(ctx context.Context, req *pb.SignalTasksRequest) (*pb.SignalTasksResponse, error) {
+	sig, err := task.ParseSignal(req.Signal)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	results, err := s.applyToTasks(ctx, req.Selector, []int{task.JobStatusStarted}, req.DryRun, func(taskID string) error {
+		return s.taskManager.SignalTask(ctx, taskID, sig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignalTasksResponse{Results: results}, nil
+}
+
+// applyToTasks runs op on every task matching the selector after checking that the caller may manage it.
+// A failure on one task is reported in its result and does not stop the operation on the others. With
+// dryRun the authorized tasks are reported as successful without running op. The default statuses are
+// selected if the selector has none.
+func (s *taskManagerServer) applyToTasks(ctx context.Context, selector *pb.TaskSelector, defaultStatuses []int, dryRun bool,
+	op func(taskID string) error) ([]*pb.TaskResult, error) {
+	filter, err := taskFilterFromSelector(selector, defaultStatuses)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	tasks := s.taskManager.ListTasks(ctx, filter)
+	results := make([]*pb.TaskResult, 0, len(tasks))
+	for _, taskObj := range tasks {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		err := s.checkAuthorization(ctx, caller, taskObj)
+		if err == nil && !dryRun {
+			if opErr := op(taskObj.GetID()); opErr != nil {
+				err = task.TaskErrorToGRPC(opErr)
+			}
+		}
+		sts := status.Convert(err)
+		results = append(results, &pb.TaskResult{
+			TaskId:  taskObj.GetID(),
+			Code:    int32(sts.Code()),
+			Message: sts.Message(),
+		})
+	}
+	return results, nil
+}
+
+// taskFilterFromSelector converts a selector to a task filter. At least one field must be set so that a
+// missing selector cannot stop every task; the default statuses are selected if no status is given.
+func taskFilterFromSelector(selector *pb.TaskSelector, defaultStatuses []int) (taskmanager.TaskFilter, error) {
+	var filter taskmanager.TaskFilter
+	if selector == nil || proto.Equal(selector, &pb.TaskSelector{}) {
+		return filter, task.NewTaskError(task.ErrInvalidArgument, "selector must not be empty")
+	}
+
+	labels, err := task.ParseSelector(selector.LabelSelector)
+	if err != nil {
+		return filter, err
+	}
+	filter.Labels = labels
+	filter.Owner = selector.Owner
+
+	if selector.StartedBefore != nil {
+		if err := selector.StartedBefore.CheckValid(); err != nil {
+			return filter, task.NewTaskError(task.ErrInvalidArgument, "invalid started_before: %v", err)
+		}
+		filter.StartedBefore = selector.StartedBefore.AsTime()
+	}
+
+	filter.Statuses = defaultStatuses
+	if len(selector.Statuses) > 0 {
+		filter.Statuses = make([]int, 0, len(selector.Statuses))
+		for _, pbStatus := range selector.Statuses {
+			internal, err := task.StatusFromProto(pbStatus)
+			if err != nil {
+				return filter, err
+			}
+			filter.Statuses = append(filter.Statuses, internal)
+		}
+	}
+	return filter, nil
+}
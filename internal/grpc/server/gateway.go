@@ -0,0 +1,549 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// maxGatewayBodySize limits the size of JSON request bodies accepted by the gateway
+const maxGatewayBodySize = 1 << 20
+
+// gatewayRoute maps an HTTP route to a TaskManager method. Path wildcards and query parameters
+// are bound to the request fields of the same name after the JSON body, if any, is decoded.
+type gatewayRoute struct {
+	pattern string
+	method  string
+}
+
+var gatewayRoutes = []gatewayRoute{
+	{pattern: "POST /v1/tasks", method: "StartTask"},
+	{pattern: "GET /v1/tasks", method: "ListTasks"},
+	{pattern: "POST /v1/tasks:wait", method: "WaitTasks"},
+	{pattern: "POST /v1/tasks:stop", method: "StopTasks"},
+	{pattern: "POST /v1/tasks:signal", method: "SignalTasks"},
+	{pattern: "GET /v1/tasks/{task_id}", method: "GetTaskStatus"},
+	{pattern: "DELETE /v1/tasks/{task_id}", method: "DeleteTask"},
+	{pattern: "POST /v1/tasks/{task_id}/stop", method: "StopTask"},
+	{pattern: "POST /v1/tasks/{task_id}/signal", method: "SignalTask"},
+	{pattern: "POST /v1/tasks/{task_id}/pause", method: "PauseTask"},
+	{pattern: "POST /v1/tasks/{task_id}/resume", method: "ResumeTask"},
+	{pattern: "PATCH /v1/tasks/{task_id}/resources", method: "UpdateTaskResources"},
+	{pattern: "GET /v1/tasks/{task_id}/output", method: "StreamTaskOutput"},
+	{pattern: "GET /v1/tasks/{task_id}/memory-events", method: "WatchMemoryEvents"},
+	{pattern: "GET /v1/quota", method: "GetQuota"},
+	{pattern: "POST /v1/schedules", method: "CreateSchedule"},
+	{pattern: "GET /v1/schedules", method: "ListSchedules"},
+	{pattern: "DELETE /v1/schedules/{schedule_id}", method: "DeleteSchedule"},
+	{pattern: "POST /v1/schedules/{schedule_id}/pause", method: "PauseSchedule"},
+}
+
+var (
+	gatewayMarshaler   = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	gatewayUnmarshaler = protojson.UnmarshalOptions{}
+)
+
+// gateway serves the TaskManager service over HTTP/JSON. Requests are dispatched through the
+// generated service handlers and the same interceptor chain as the gRPC server so authentication,
+// authorization, auditing, metrics and logging behave identically for both front ends.
+type gateway struct {
+	srv               any
+	serviceDesc       *grpc.ServiceDesc
+	unaryInterceptor  grpc.UnaryServerInterceptor
+	streamInterceptor grpc.StreamServerInterceptor
+}
+
+// newGatewayHandler builds the HTTP handler serving every gateway route
+func newGatewayHandler(
+	srv pb.TaskManagerServer,
+	unaryInterceptors []grpc.UnaryServerInterceptor,
+	streamInterceptors []grpc.StreamServerInterceptor,
+) (http.Handler, error) {
+	g := &gateway{
+		srv:               srv,
+		serviceDesc:       &pb.TaskManager_ServiceDesc,
+		unaryInterceptor:  chainUnaryInterceptors(unaryInterceptors),
+		streamInterceptor: chainStreamInterceptors(streamInterceptors),
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range gatewayRoutes {
+		handler, err := g.handlerFor(route.method)
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle(route.pattern, handler)
+	}
+	return mux, nil
+}
+
+func (g *gateway) handlerFor(method string) (http.Handler, error) {
+	fullMethod := "/" + g.serviceDesc.ServiceName + "/" + method
+	for _, desc := range g.serviceDesc.Methods {
+		if desc.MethodName == method {
+			return g.unaryHandler(fullMethod, desc), nil
+		}
+	}
+	for _, desc := range g.serviceDesc.Streams {
+		if desc.StreamName == method {
+			return g.streamHandler(fullMethod, desc), nil
+		}
+	}
+	return nil, fmt.Errorf("method %s not found in service %s", method, g.serviceDesc.ServiceName)
+}
+
+func (g *gateway) unaryHandler(fullMethod string, desc grpc.MethodDesc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transportStream := &gatewayTransportStream{method: fullMethod}
+		ctx := grpc.NewContextWithServerTransportStream(newGatewayContext(r), transportStream)
+
+		dec := func(m any) error {
+			return decodeGatewayRequest(r, m)
+		}
+		resp, err := desc.Handler(g.srv, ctx, dec, g.unaryInterceptor)
+
+		copyMetadataToHeader(w.Header(), transportStream.getHeader())
+		if err != nil {
+			writeGatewayError(w, err)
+			return
+		}
+		msg, ok := resp.(proto.Message)
+		if !ok {
+			writeGatewayError(w, status.Errorf(codes.Internal, "unexpected response type %T", resp))
+			return
+		}
+		writeGatewayJSON(w, http.StatusOK, msg)
+	})
+}
+
+func (g *gateway) streamHandler(fullMethod string, desc grpc.StreamDesc) http.Handler {
+	info := &grpc.StreamServerInfo{
+		FullMethod:     fullMethod,
+		IsClientStream: desc.ClientStreams,
+		IsServerStream: desc.ServerStreams,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream := &gatewayServerStream{
+			ctx:    newGatewayContext(r),
+			w:      w,
+			format: streamFormatFromAccept(r.Header.Get("Accept")),
+			decode: func(m any) error {
+				return decodeGatewayRequest(r, m)
+			},
+		}
+
+		err := g.streamInterceptor(g.srv, stream, info, desc.Handler)
+		stream.finish(err)
+	})
+}
+
+// newGatewayContext builds the call context from the HTTP request. The verified client certificate
+// is exposed as the gRPC peer auth info so the client CN is extracted exactly like for gRPC calls.
+func newGatewayContext(r *http.Request) context.Context {
+	p := &peer.Peer{}
+	if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		p.Addr = net.TCPAddrFromAddrPort(addrPort)
+	}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+	return peer.NewContext(r.Context(), p)
+}
+
+// decodeGatewayRequest decodes the JSON body into the request message and then binds
+// path wildcards and query parameters to the fields with the same name
+func decodeGatewayRequest(r *http.Request, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected request type %T", m)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGatewayBodySize))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := gatewayUnmarshaler.Unmarshal(body, msg); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+	}
+
+	reflectMsg := msg.ProtoReflect()
+	query := r.URL.Query()
+	fields := reflectMsg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+
+		var values []string
+		if v := r.PathValue(name); v != "" {
+			values = []string{v}
+		} else if v, ok := query[name]; ok {
+			values = v
+		} else {
+			continue
+		}
+
+		if err := setGatewayField(reflectMsg, fd, values); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid value for %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// setGatewayField sets a scalar, enum, well-known type or repeated field from its string values
+func setGatewayField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, values []string) error {
+	if fd.IsMap() {
+		return errors.New("map fields are not supported as parameters")
+	}
+	if fd.IsList() {
+		list := msg.Mutable(fd).List()
+		for _, v := range values {
+			value, err := parseGatewayValue(msg, fd, v)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+		return nil
+	}
+
+	value, err := parseGatewayValue(msg, fd, values[len(values)-1])
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, value)
+	return nil
+}
+
+func parseGatewayValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor, v string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(v), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(v)), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(v)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(v, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(v, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(v, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(v, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(v)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown enum value %q", v)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	case protoreflect.MessageKind:
+		// well-known types such as Timestamp and Duration have a JSON string representation
+		value := msg.NewField(fd)
+		if err := gatewayUnmarshaler.Unmarshal([]byte(strconv.Quote(v)), value.Message().Interface()); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return value, nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
+
+// httpStatusFromCode maps a gRPC status code to the HTTP status returned by the gateway
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		// non-standard status used by nginx and grpc-gateway for a client closed request
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeGatewayError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeGatewayJSON(w, httpStatusFromCode(st.Code()), st.Proto())
+}
+
+func writeGatewayJSON(w http.ResponseWriter, code int, msg proto.Message) {
+	body, err := gatewayMarshaler.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to marshal gateway response", "error", err)
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		slog.Debug("failed to write gateway response", "error", err)
+	}
+}
+
+// copyMetadataToHeader returns response metadata such as the request ID as HTTP headers
+func copyMetadataToHeader(h http.Header, md metadata.MD) {
+	for key, values := range md {
+		for _, v := range values {
+			h.Add(key, v)
+		}
+	}
+}
+
+// gatewayTransportStream collects the header metadata set by handlers and interceptors of unary calls
+type gatewayTransportStream struct {
+	method string
+
+	mu      sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+var _ grpc.ServerTransportStream = &gatewayTransportStream{}
+
+func (s *gatewayTransportStream) Method() string {
+	return s.method
+}
+
+func (s *gatewayTransportStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *gatewayTransportStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *gatewayTransportStream) SetTrailer(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func (s *gatewayTransportStream) getHeader() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+// streamFormat is how server streaming responses are written to the HTTP response
+type streamFormat int
+
+const (
+	// streamFormatNDJSON writes one JSON message per line using chunked transfer encoding
+	streamFormatNDJSON streamFormat = iota
+	// streamFormatSSE writes one Server-Sent Event per message
+	streamFormatSSE
+	// streamFormatRaw writes the raw task output bytes using chunked transfer encoding
+	streamFormatRaw
+)
+
+func streamFormatFromAccept(accept string) streamFormat {
+	switch {
+	case strings.Contains(accept, "text/event-stream"):
+		return streamFormatSSE
+	case strings.Contains(accept, "application/octet-stream"):
+		return streamFormatRaw
+	default:
+		return streamFormatNDJSON
+	}
+}
+
+// gatewayServerStream adapts an HTTP response to a server streaming gRPC call
+type gatewayServerStream struct {
+	ctx    context.Context
+	w      http.ResponseWriter
+	format streamFormat
+	decode func(any) error
+
+	received   bool
+	header     metadata.MD
+	sentHeader bool
+}
+
+var _ grpc.ServerStream = &gatewayServerStream{}
+
+func (s *gatewayServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *gatewayServerStream) SetHeader(md metadata.MD) error {
+	if s.sentHeader {
+		return errors.New("header already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *gatewayServerStream) SendHeader(md metadata.MD) error {
+	if err := s.SetHeader(md); err != nil {
+		return err
+	}
+	s.writeHeader()
+	return nil
+}
+
+func (s *gatewayServerStream) SetTrailer(metadata.MD) {}
+
+// RecvMsg decodes the single request message of a server streaming call
+func (s *gatewayServerStream) RecvMsg(m any) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	return s.decode(m)
+}
+
+// SendMsg writes the message in the negotiated format and flushes it to the client
+func (s *gatewayServerStream) SendMsg(m any) error {
+	s.writeHeader()
+
+	switch s.format {
+	case streamFormatRaw:
+		out, ok := m.(interface{ GetOutput() []byte })
+		if !ok {
+			return status.Errorf(codes.Internal, "message %T has no raw output", m)
+		}
+		if _, err := s.w.Write(out.GetOutput()); err != nil {
+			return err
+		}
+	default:
+		msg, ok := m.(proto.Message)
+		if !ok {
+			return status.Errorf(codes.Internal, "unexpected message type %T", m)
+		}
+		if err := s.writeMessage("", msg); err != nil {
+			return err
+		}
+	}
+
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func (s *gatewayServerStream) writeHeader() {
+	if s.sentHeader {
+		return
+	}
+	s.sentHeader = true
+
+	copyMetadataToHeader(s.w.Header(), s.header)
+	switch s.format {
+	case streamFormatSSE:
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+	case streamFormatRaw:
+		s.w.Header().Set("Content-Type", "application/octet-stream")
+	default:
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// writeMessage writes a JSON message as an NDJSON line or an SSE event
+func (s *gatewayServerStream) writeMessage(event string, msg proto.Message) error {
+	body, err := gatewayMarshaler.Marshal(msg)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal message: %v", err)
+	}
+	// protojson may add whitespace but never newlines without Multiline so one message is one line
+	if s.format == streamFormatSSE {
+		if event != "" {
+			_, err = fmt.Fprintf(s.w, "event: %s\n", event)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(s.w, "data: %s\n\n", body)
+		return err
+	}
+	_, err = s.w.Write(append(body, '\n'))
+	return err
+}
+
+// finish reports the result of the call. Errors before the first message are returned as a
+// regular JSON error response; later errors are written in-band for the JSON formats.
+func (s *gatewayServerStream) finish(err error) {
+	if !s.sentHeader {
+		if err == nil {
+			s.writeHeader()
+			return
+		}
+		copyMetadataToHeader(s.w.Header(), s.header)
+		writeGatewayError(s.w, err)
+		return
+	}
+	if err == nil || s.format == streamFormatRaw {
+		return
+	}
+
+	st := status.Convert(err).Proto()
+	var writeErr error
+	if s.format == streamFormatSSE {
+		writeErr = s.writeMessage("error", st)
+	} else {
+		writeErr = s.writeMessage("", st)
+	}
+	if writeErr != nil {
+		slog.Debug("failed to write gateway stream error", "error", writeErr)
+	}
+}
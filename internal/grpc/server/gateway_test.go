@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+// fakeTaskManagerServer echoes requests back so the gateway encoding can be verified
+type fakeTaskManagerServer struct {
+	pb.UnimplementedTaskManagerServer
+}
+
+func (f *fakeTaskManagerServer) StartTask(ctx context.Context, req *pb.StartTaskRequest) (*pb.StartTaskResponse, error) {
+	if req.GetCommand() == "" {
+		return nil, status.Error(codes.InvalidArgument, "command cannot be empty")
+	}
+	return &pb.StartTaskResponse{TaskId: req.GetCommand() + " " + strings.Join(req.GetArgs(), " ")}, nil
+}
+
+func (f *fakeTaskManagerServer) GetTaskStatus(ctx context.Context, req *pb.TaskStatusRequest) (*pb.TaskStatusResponse, error) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "req-1")); err != nil {
+		return nil, err
+	}
+	return nil, status.Errorf(codes.NotFound, "task with id %s not found", req.GetTaskId())
+}
+
+func (f *fakeTaskManagerServer) StreamTaskOutput(req *pb.StreamTaskOutputRequest, stream grpc.ServerStreamingServer[pb.StreamTaskOutputResponse]) error {
+	for _, line := range []string{"line 1\n", "line 2\n"} {
+		if err := stream.Send(&pb.StreamTaskOutputResponse{Output: []byte(req.GetTaskId() + " " + line)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestGateway(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	handler, err := newGatewayHandler(&fakeTaskManagerServer{}, nil, nil)
+	require.NoError(t, err)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGateway(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestGateway(t)
+
+	tests := []struct {
+		desc           string
+		method         string
+		path           string
+		body           string
+		accept         string
+		expectedCode   int
+		expectedType   string
+		expectedHeader http.Header
+		// expectedJSON holds the expected JSON messages of the body in order
+		expectedJSON []string
+		expectedRaw  string
+	}{
+		{
+			desc:         "unary request with body",
+			method:       http.MethodPost,
+			path:         "/v1/tasks",
+			body:         `{"command": "ls", "args": ["-l"]}`,
+			expectedCode: http.StatusOK,
+			expectedType: "application/json",
+			expectedJSON: []string{`{"task_id":"ls -l"}`},
+		},
+		{
+			desc:         "query parameters are bound to fields",
+			method:       http.MethodPost,
+			path:         "/v1/tasks?command=echo&args=a&args=b",
+			expectedCode: http.StatusOK,
+			expectedType: "application/json",
+			expectedJSON: []string{`{"task_id":"echo a b"}`},
+		},
+		{
+			desc:         "malformed body",
+			method:       http.MethodPost,
+			path:         "/v1/tasks",
+			body:         `{"command":`,
+			expectedCode: http.StatusBadRequest,
+			expectedType: "application/json",
+		},
+		{
+			desc:         "handler error is mapped with headers",
+			method:       http.MethodGet,
+			path:         "/v1/tasks/abc",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expectedHeader: http.Header{
+				"X-Request-Id": []string{"req-1"},
+			},
+			expectedJSON: []string{`{"code":5,"message":"task with id abc not found","details":[]}`},
+		},
+		{
+			desc:         "stream as ndjson",
+			method:       http.MethodGet,
+			path:         "/v1/tasks/abc/output",
+			expectedCode: http.StatusOK,
+			expectedType: "application/x-ndjson",
+			expectedJSON: []string{`{"output":"YWJjIGxpbmUgMQo="}`, `{"output":"YWJjIGxpbmUgMgo="}`},
+		},
+		{
+			desc:         "stream as server-sent events",
+			method:       http.MethodGet,
+			path:         "/v1/tasks/abc/output",
+			accept:       "text/event-stream",
+			expectedCode: http.StatusOK,
+			expectedType: "text/event-stream",
+			expectedJSON: []string{`{"output":"YWJjIGxpbmUgMQo="}`, `{"output":"YWJjIGxpbmUgMgo="}`},
+		},
+		{
+			desc:         "stream as raw bytes",
+			method:       http.MethodGet,
+			path:         "/v1/tasks/abc/output",
+			accept:       "application/octet-stream",
+			expectedCode: http.StatusOK,
+			expectedType: "application/octet-stream",
+			expectedRaw:  "abc line 1\nabc line 2\n",
+		},
+		{
+			desc:         "unimplemented method",
+			method:       http.MethodPost,
+			path:         "/v1/tasks/abc/stop",
+			expectedCode: http.StatusNotImplemented,
+			expectedType: "application/json",
+		},
+		{
+			desc:         "unknown route",
+			method:       http.MethodGet,
+			path:         "/v1/unknown",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := srv.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, resp.Header.Get("Content-Type"))
+			}
+			for k, v := range tt.expectedHeader {
+				assert.Equal(t, v, resp.Header.Values(k))
+			}
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if tt.expectedRaw != "" {
+				assert.Equal(t, tt.expectedRaw, string(body))
+			}
+			if tt.expectedJSON != nil {
+				actual := jsonMessages(string(body))
+				require.Len(t, actual, len(tt.expectedJSON))
+				for i := range actual {
+					assert.JSONEq(t, tt.expectedJSON[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+// jsonMessages splits an NDJSON or server-sent events body into its JSON messages
+func jsonMessages(body string) []string {
+	var messages []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimPrefix(line, "data: ")
+		if line != "" {
+			messages = append(messages, line)
+		}
+	}
+	return messages
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code     codes.Code
+		expected int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.FailedPrecondition, http.StatusConflict},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.Canceled, 499},
+		{codes.Unimplemented, http.StatusNotImplemented},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unknown, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, httpStatusFromCode(tt.code), tt.code.String())
+	}
+}
@@ -4,48 +4,84 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/logging"
 )
 
 // ExtractClientCNInterceptor extracts the client's Common Name and injects it into the context
-// for unary operations
+// for unary operations. It also injects a request-scoped logger carrying a generated request ID
+// which is returned to the client in the response header metadata.
 func ExtractClientCNInterceptor(
 	ctx context.Context,
 	req any,
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (any, error) {
+	var method string
+	if info != nil {
+		method = info.FullMethod
+	}
+	requestID := uuid.NewString()
+	logger := slog.Default().With("request_id", requestID, "method", method)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(basegrpc.RequestIDHeader, requestID)); err != nil {
+		logger.Debug("failed to set request ID header", "error", err)
+	}
+
 	commonName, err := getClientCN(ctx)
 	if err != nil || commonName == "" {
+		logger.Warn("failed to get client CN", "error", err)
 		return nil, status.Errorf(codes.Unauthenticated, "failed to get client CN")
 	}
+
+	logger = logger.With("client_id", commonName)
+	if r, ok := req.(interface{ GetTaskId() string }); ok && r.GetTaskId() != "" {
+		logger = logger.With("task_id", r.GetTaskId())
+	}
 	ctxWithCN := context.WithValue(ctx, basegrpc.ClientIDKey, commonName)
+	ctxWithCN = logging.WithLogger(ctxWithCN, logger)
 	respObj, err := handler(ctxWithCN, req)
 	return respObj, err
 }
 
 // ExtractClientCNStreamInterceptor extracts the client's Common Name and injects it into the context
-// for stream operations
+// for stream operations. It also injects a request-scoped logger carrying a generated request ID
+// which is returned to the client in the response header metadata.
 func ExtractClientCNStreamInterceptor(
 	srv any,
 	ss grpc.ServerStream,
 	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
+	var method string
+	if info != nil {
+		method = info.FullMethod
+	}
+	requestID := uuid.NewString()
+	logger := slog.Default().With("request_id", requestID, "method", method)
+	if err := ss.SetHeader(metadata.Pairs(basegrpc.RequestIDHeader, requestID)); err != nil {
+		logger.Debug("failed to set request ID header", "error", err)
+	}
+
 	ctx := ss.Context()
 	commonName, err := getClientCN(ctx)
 	if err != nil || commonName == "" {
+		logger.Warn("failed to get client CN", "error", err)
 		return status.Errorf(codes.Unauthenticated, "failed to get client CN")
 	}
 
+	logger = logger.With("client_id", commonName)
 	ctxWithCN := context.WithValue(ctx, basegrpc.ClientIDKey, commonName)
+	ctxWithCN = logging.WithLogger(ctxWithCN, logger)
 
 	wrappedStream := &wrappedServerStream{
 		ServerStream: ss,
@@ -88,3 +124,15 @@ type wrappedServerStream struct {
 func (w *wrappedServerStream) Context() context.Context {
 	return w.ctx
 }
+
+// RecvMsg receives the request and adds its task ID to the request-scoped logger
+// the request is always received before the handler reads the stream context
+func (w *wrappedServerStream) RecvMsg(m any) error {
+	if err := w.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if r, ok := m.(interface{ GetTaskId() string }); ok && r.GetTaskId() != "" {
+		w.ctx = logging.WithLogger(w.ctx, logging.FromContext(w.ctx).With("task_id", r.GetTaskId()))
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
@@ -130,13 +131,19 @@ func TestExtractClientCNInterceptor(t *testing.T) {
 
 type mockServerStream struct {
 	grpc.ServerStream
-	ctx context.Context
+	ctx    context.Context
+	header metadata.MD
 }
 
 func (m *mockServerStream) Context() context.Context {
 	return m.ctx
 }
 
+func (m *mockServerStream) SetHeader(md metadata.MD) error {
+	m.header = metadata.Join(m.header, md)
+	return nil
+}
+
 func TestExtractClientCNStreamInterceptor(t *testing.T) {
 	t.Parallel()
 
@@ -233,6 +240,7 @@ func TestExtractClientCNStreamInterceptor(t *testing.T) {
 
 			stream := &mockServerStream{ctx: tt.setUpTestCtx()}
 			err := ExtractClientCNStreamInterceptor(nil, stream, nil, handler)
+			require.Len(t, stream.header.Get(basegrpc.RequestIDHeader), 1, "Expected request ID header")
 			if tt.wantErrCode != codes.OK {
 				require.Error(t, err, "Expected error from stream interceptor")
 				require.Equal(t, tt.wantErrCode, status.Code(err), "Error code mismatch")
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
+)
+
+// WatchMemoryEvents streams the kept and new memory events of the task with the given ID until it finishes
+func (s *taskManagerServer) WatchMemoryEvents(req *pb.WatchMemoryEventsRequest, stream pb.TaskManager_WatchMemoryEventsServer) error {
+	ctx := stream.Context()
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return err
+	}
+
+	err = s.taskManager.WatchMemoryEvents(ctx, req.TaskId, func(event taskmanager.MemoryEvent) error {
+		return stream.Send(&pb.WatchMemoryEventsResponse{Event: memoryEventToProto(event)})
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case errors.Is(err, context.Canceled):
+		return task.TaskErrorToGRPC(task.NewTaskErrorWithErr(task.ErrCanceled, "server context canceled", err))
+	default:
+		return task.TaskErrorToGRPC(err)
+	}
+}
+
+// memoryStatusToProto converts the memory.events counters and the memory pressure of a task to their proto message
+func memoryStatusToProto(events cgroups.MemoryEvents, pressure cgroups.MemoryPressure) *pb.MemoryStatus {
+	return &pb.MemoryStatus{
+		HighEvents:    events.High,
+		MaxEvents:     events.Max,
+		OomKillEvents: events.OOMKill,
+		SomePressure:  pressure.Some,
+		FullPressure:  pressure.Full,
+	}
+}
+
+func memoryEventToProto(event taskmanager.MemoryEvent) *pb.MemoryEvent {
+	return &pb.MemoryEvent{
+		Time:         timestamppb.New(event.Time),
+		Attempt:      int32(event.Attempt),
+		Kind:         event.Kind,
+		Count:        event.Count,
+		SomePressure: event.Pressure.Some,
+		FullPressure: event.Pressure.Full,
+	}
+}
+
+// memoryEventsToProto converts the memory events of a task to their proto messages
+func memoryEventsToProto(events []taskmanager.MemoryEvent) []*pb.MemoryEvent {
+	pbEvents := make([]*pb.MemoryEvent, 0, len(events))
+	for _, event := range events {
+		pbEvents = append(pbEvents, memoryEventToProto(event))
+	}
+	return pbEvents
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
+)
+
+// MetricsUnaryInterceptor records the count and latency of unary calls by method and status code
+func MetricsUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// MetricsStreamInterceptor records the count and duration of stream calls by method and status code
+func MetricsStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+func observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metrics.RPCRequests.WithLabelValues(method, code).Inc()
+	metrics.RPCDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// handleHealthz is the liveness probe; it succeeds as long as the process can serve HTTP
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeProbeResponse(w, http.StatusOK, "ok")
+}
+
+// handleReadyz is the readiness probe; it succeeds when the gRPC server is serving and
+// new task cgroups can be created under the parent cgroup
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.healthServer.Check(r.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		writeProbeResponse(w, http.StatusServiceUnavailable, "not serving")
+		return
+	}
+
+	// the probe is unauthenticated, so the cgroup paths are only logged
+	if err := s.hierarchy.CheckWritable(); err != nil {
+		slog.Warn("readiness probe failed", "error", err)
+		writeProbeResponse(w, http.StatusServiceUnavailable, "cgroups not writable")
+		return
+	}
+
+	writeProbeResponse(w, http.StatusOK, "ok")
+}
+
+func writeProbeResponse(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	if _, err := fmt.Fprintln(w, msg); err != nil {
+		slog.Debug("failed to write probe response", "error", err)
+	}
+}
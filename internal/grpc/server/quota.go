@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/audit"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+)
+
+// GetQuota returns the usage of the caller against the task quotas; admins may ask for any client
+func (s *taskManagerServer) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	clientID := req.ClientId
+	if clientID == "" {
+		clientID = caller
+	}
+	if clientID != caller && caller != "admin" {
+		s.auditLog.Log(audit.Event{
+			Action:   audit.ActionAuthFailure,
+			ClientID: caller,
+			Outcome:  audit.OutcomeDenied,
+			Code:     codes.PermissionDenied.String(),
+			Message:  "caller may not read the quota of client " + clientID,
+		})
+		return nil, status.Error(codes.PermissionDenied, "only admins may get the quota of another client")
+	}
+
+	usage := s.taskManager.GetQuota(clientID)
+	return &pb.GetQuotaResponse{
+		ClientId: usage.ClientID,
+		RunningTasks: &pb.QuotaUsage{
+			Used:  int64(usage.Running),
+			Limit: int64(usage.MaxRunningPerClient),
+		},
+		GlobalRunningTasks: &pb.QuotaUsage{
+			Used:  int64(usage.GlobalRunning),
+			Limit: int64(usage.MaxRunning),
+		},
+		StartsPerMinute: &pb.QuotaUsage{
+			Used:  int64(usage.StartsLastMinute),
+			Limit: int64(usage.MaxStartsPerMinute),
+		},
+		QueuedTasks: &pb.QuotaUsage{
+			Used:  int64(usage.GlobalQueued),
+			Limit: int64(usage.MaxQueued),
+		},
+	}, nil
+}
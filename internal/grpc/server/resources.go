@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
+)
+
+// UpdateTaskResources changes the cgroup limits of the task with the given ID
+func (s *taskManagerServer) UpdateTaskResources(ctx context.Context, req *pb.UpdateTaskResourcesRequest) (*pb.UpdateTaskResourcesResponse, error) {
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return nil, err
+	}
+
+	// admins may change any task beyond the resource quotas of its owner
+	limitsResp, err := s.taskManager.UpdateTaskResources(ctx, req.TaskId, resourceUpdateFromProto(req.GetLimits()), caller != "admin")
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.UpdateTaskResourcesResponse{Limits: limitsToProto(limitsResp)}, nil
+}
+
+// resourceUpdateFromProto converts the set limits of a request; unset limits are left nil
+func resourceUpdateFromProto(limits *pb.ResourceLimits) taskmanager.ResourceUpdate {
+	if limits == nil {
+		return taskmanager.ResourceUpdate{}
+	}
+	return taskmanager.ResourceUpdate{
+		CPUMillis:  limits.CpuMillis,
+		MemoryMax:  limits.MemoryMaxBytes,
+		MemoryHigh: limits.MemoryHighBytes,
+		IOReadBPS:  limits.IoReadBps,
+		IOWriteBPS: limits.IoWriteBps,
+		PidsMax:    limits.PidsMax,
+		CPUs:       limits.CpusetCpus,
+		Mems:       limits.CpusetMems,
+	}
+}
+
+// resourceUpdateToProto converts an update with only its set limits; nil if no limit is set
+func resourceUpdateToProto(update taskmanager.ResourceUpdate) *pb.ResourceLimits {
+	if update == (taskmanager.ResourceUpdate{}) {
+		return nil
+	}
+	return &pb.ResourceLimits{
+		CpuMillis:       update.CPUMillis,
+		MemoryMaxBytes:  update.MemoryMax,
+		MemoryHighBytes: update.MemoryHigh,
+		IoReadBps:       update.IOReadBPS,
+		IoWriteBps:      update.IOWriteBPS,
+		PidsMax:         update.PidsMax,
+		CpusetCpus:      update.CPUs,
+		CpusetMems:      update.Mems,
+	}
+}
+
+// limitsToProto converts limits to their proto message with every limit set
+func limitsToProto(limits cgroups.Limits) *pb.ResourceLimits {
+	return &pb.ResourceLimits{
+		CpuMillis:       proto.Int64(limits.CPUMillis),
+		MemoryMaxBytes:  proto.Int64(limits.MemoryMax),
+		MemoryHighBytes: proto.Int64(limits.MemoryHigh),
+		IoReadBps:       proto.Int64(limits.IOReadBPS),
+		IoWriteBps:      proto.Int64(limits.IOWriteBPS),
+		PidsMax:         proto.Int64(limits.PidsMax),
+		CpusetCpus:      proto.String(limits.CPUs),
+		CpusetMems:      proto.String(limits.Mems),
+	}
+}
+
+// resourceChangesToProto converts the resource changes of a task to their proto messages
+func resourceChangesToProto(changes []taskmanager.ResourceChange) []*pb.ResourceChange {
+	pbChanges := make([]*pb.ResourceChange, 0, len(changes))
+	for _, change := range changes {
+		pbChanges = append(pbChanges, &pb.ResourceChange{
+			Time:     timestamppb.New(change.Time),
+			ClientId: change.ClientID,
+			Previous: limitsToProto(change.Previous),
+			Limits:   limitsToProto(change.Limits),
+		})
+	}
+	return pbChanges
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/task"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
+)
+
+// concurrencyPolicies maps the concurrency policies of a schedule request to those of the task manager
+var concurrencyPolicies = map[pb.ConcurrencyPolicy]taskmanager.ConcurrencyPolicy{
+	pb.ConcurrencyPolicy_CONCURRENCY_POLICY_UNSPECIFIED: taskmanager.ConcurrencyAllow,
+	pb.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW:       taskmanager.ConcurrencyAllow,
+	pb.ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID:      taskmanager.ConcurrencyForbid,
+	pb.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE:     taskmanager.ConcurrencyReplace,
+}
+
+// CreateSchedule creates a schedule owned by the caller
+func (s *taskManagerServer) CreateSchedule(ctx context.Context, req *pb.CreateScheduleRequest) (*pb.CreateScheduleResponse, error) {
+	concurrency, ok := concurrencyPolicies[req.ConcurrencyPolicy]
+	if !ok {
+		return nil, task.TaskErrorToGRPC(task.NewTaskError(task.ErrInvalidArgument, "invalid concurrency policy: %s", req.ConcurrencyPolicy))
+	}
+	template := req.Template
+	if template == nil {
+		return nil, task.TaskErrorToGRPC(task.NewTaskError(task.ErrInvalidArgument, "a task template is required"))
+	}
+	retry, err := retryPolicyFromProto(template.RetryPolicy)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	restart, err := restartPolicyFromProto(template.RestartPolicy)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+
+	schedule, err := s.taskManager.CreateSchedule(ctx, taskmanager.ScheduleSpec{
+		Cron: req.CronExpression,
+		Template: taskmanager.TaskSpec{
+			Command:  template.Command,
+			Args:     template.Args,
+			Labels:   template.Labels,
+			Priority: int(template.Priority),
+			Retry:    retry,
+			Restart:  restart,
+			Limits:   resourceUpdateFromProto(template.Limits),
+		},
+		Concurrency: concurrency,
+		Paused:      req.Paused,
+	})
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.CreateScheduleResponse{Schedule: scheduleToProto(schedule)}, nil
+}
+
+// ListSchedules lists the schedules of the caller, or of all clients for admins
+func (s *taskManagerServer) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	schedules := s.taskManager.ListSchedules(ctx)
+	resp := &pb.ListSchedulesResponse{Schedules: make([]*pb.Schedule, 0, len(schedules))}
+	for _, schedule := range schedules {
+		resp.Schedules = append(resp.Schedules, scheduleToProto(schedule))
+	}
+	return resp, nil
+}
+
+// DeleteSchedule deletes a schedule of the caller; schedules of other clients are reported as not found
+func (s *taskManagerServer) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.DeleteScheduleResponse, error) {
+	if err := s.taskManager.DeleteSchedule(ctx, req.ScheduleId); err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.DeleteScheduleResponse{}, nil
+}
+
+// PauseSchedule pauses or resumes a schedule of the caller
+func (s *taskManagerServer) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.PauseScheduleResponse, error) {
+	schedule, err := s.taskManager.PauseSchedule(ctx, req.ScheduleId, req.Paused)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.PauseScheduleResponse{Schedule: scheduleToProto(schedule)}, nil
+}
+
+// scheduleToProto converts a snapshot of a schedule
+func scheduleToProto(schedule taskmanager.Schedule) *pb.Schedule {
+	pbSchedule := &pb.Schedule{
+		ScheduleId:     schedule.ID,
+		ClientId:       schedule.ClientID,
+		CronExpression: schedule.Cron,
+		Template: &pb.TaskTemplate{
+			Command:       schedule.Template.Command,
+			Args:          schedule.Template.Args,
+			Labels:        schedule.Template.Labels,
+			Priority:      int32(schedule.Template.Priority),
+			RetryPolicy:   retryPolicyToProto(schedule.Template.Retry),
+			RestartPolicy: restartPolicyToProto(schedule.Template.Restart),
+			Limits:        resourceUpdateToProto(schedule.Template.Limits),
+		},
+		ConcurrencyPolicy: pb.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW,
+		Paused:            schedule.Paused,
+		CreateTime:        timestamppb.New(schedule.CreateTime),
+		LastTaskId:        schedule.LastTaskID,
+		ActiveTaskIds:     schedule.ActiveTaskIDs,
+	}
+	switch schedule.Concurrency {
+	case taskmanager.ConcurrencyForbid:
+		pbSchedule.ConcurrencyPolicy = pb.ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID
+	case taskmanager.ConcurrencyReplace:
+		pbSchedule.ConcurrencyPolicy = pb.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE
+	}
+	if !schedule.NextRunTime.IsZero() {
+		pbSchedule.NextRunTime = timestamppb.New(schedule.NextRunTime)
+	}
+	if !schedule.LastRunTime.IsZero() {
+		pbSchedule.LastRunTime = timestamppb.New(schedule.LastRunTime)
+	}
+	return pbSchedule
+}
@@ -3,30 +3,152 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	"github.com/mikewurtz/taskman/certs"
 	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/audit"
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/metrics"
 	"github.com/mikewurtz/taskman/internal/task/cgroups"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
 )
 
 // Wraps the grpcServer and listener together
 type Server struct {
-	grpcServer *grpc.Server
-	listener   net.Listener
-	taskServer *taskManagerServer
+	grpcServer   *grpc.Server
+	listener     net.Listener
+	taskServer   *taskManagerServer
+	healthServer *health.Server
+	// hierarchy is the cgroup tree the tasks run in
+	hierarchy *cgroups.Hierarchy
+
+	// metricsServer serves /metrics and the HTTP probes on its own listener; nil if metrics are disabled
+	metricsServer   *http.Server
+	metricsListener net.Listener
+
+	// gatewayServer serves the HTTP/JSON gateway with mTLS on its own listener; nil if the gateway is disabled
+	gatewayServer   *http.Server
+	gatewayListener net.Listener
+}
+
+// Option configures optional behavior of the Server
+type Option func(*options)
+
+type options struct {
+	auditLog         *audit.Logger
+	metricsAddress   string
+	gatewayAddress   string
+	enableReflection bool
+	idempotencyTTL   time.Duration
+	retention        taskmanager.RetentionPolicy
+	quota            taskmanager.QuotaPolicy
+	ceilings         cgroups.Limits
+	ioDevices        []string
+	hierarchy        *cgroups.Hierarchy
+	memoryPolicy     taskmanager.MemoryPressurePolicy
+}
+
+// WithAuditLogger records an audit event for every RPC and task lifecycle change
+func WithAuditLogger(auditLog *audit.Logger) Option {
+	return func(o *options) {
+		o.auditLog = auditLog
+	}
+}
+
+// WithMetricsAddress exposes Prometheus metrics at /metrics and the /healthz and /readyz probes
+// over plain HTTP on the given address
+func WithMetricsAddress(addr string) Option {
+	return func(o *options) {
+		o.metricsAddress = addr
+	}
+}
+
+// WithGatewayAddress serves the TaskManager API as HTTP/JSON on the given address. The gateway
+// requires the same client certificates as the gRPC server and shares its interceptors.
+func WithGatewayAddress(addr string) Option {
+	return func(o *options) {
+		o.gatewayAddress = addr
+	}
+}
+
+// WithReflection registers the gRPC server reflection service so tools such as grpcurl can
+// discover the API. Reflection calls are authenticated with mTLS like any other call.
+func WithReflection() Option {
+	return func(o *options) {
+		o.enableReflection = true
+	}
+}
+
+// WithIdempotencyTTL sets how long the idempotency keys of start requests are remembered
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.idempotencyTTL = ttl
+	}
+}
+
+// WithRetentionPolicy evicts finished tasks and their output according to the policy
+func WithRetentionPolicy(policy taskmanager.RetentionPolicy) Option {
+	return func(o *options) {
+		o.retention = policy
+	}
+}
+
+// WithQuotaPolicy rejects task starts that would exceed the quotas of the policy
+func WithQuotaPolicy(policy taskmanager.QuotaPolicy) Option {
+	return func(o *options) {
+		o.quota = policy
+	}
+}
+
+// WithResourceCeilings rejects resource updates that would raise a limit of a task above its ceiling
+func WithResourceCeilings(ceilings cgroups.Limits) Option {
+	return func(o *options) {
+		o.ceilings = ceilings
+	}
+}
+
+// WithIODevices applies the IO limits of the tasks to the block devices given by major:minor number,
+// as resolved by cgroups.ResolveIODevices
+func WithIODevices(ioDevices []string) Option {
+	return func(o *options) {
+		o.ioDevices = ioDevices
+	}
+}
+
+// WithMemoryPressurePolicy stops tasks under memory pressure as set by the policy
+func WithMemoryPressurePolicy(policy taskmanager.MemoryPressurePolicy) Option {
+	return func(o *options) {
+		o.memoryPolicy = policy
+	}
+}
+
+// WithCgroupHierarchy runs the tasks in the cgroup tree of the hierarchy instead of cgroups.DefaultHierarchy
+func WithCgroupHierarchy(hierarchy *cgroups.Hierarchy) Option {
+	return func(o *options) {
+		o.hierarchy = hierarchy
+	}
 }
 
 // New sets up the gRPC server and listener with mTLS authentication using TLS v1.3
-// Includes interceptors for injecting the client CN into the context for unary and stream calls
-func New(ctx context.Context, serverAddr string) (*Server, error) {
+// Includes interceptors for auditing calls and injecting the client CN into the context for unary and stream calls
+func New(ctx context.Context, serverAddr string, opts ...Option) (*Server, error) {
+	o := options{hierarchy: cgroups.DefaultHierarchy()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	cert, err := basegrpc.LoadTLSCert(certs.ServerCertName)
 	if err != nil {
 		return nil, fmt.Errorf("loading server cert: %w", err)
@@ -51,35 +173,138 @@ func New(ctx context.Context, serverAddr string) (*Server, error) {
 		},
 	}
 
+	// the metrics and audit interceptors run first so that they also see authentication failures
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		MetricsUnaryInterceptor,
+		AuditUnaryInterceptor(o.auditLog),
+		ExtractClientCNInterceptor,
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		MetricsStreamInterceptor,
+		AuditStreamInterceptor(o.auditLog),
+		ExtractClientCNStreamInterceptor,
+	}
 	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)),
-		grpc.ChainUnaryInterceptor(ExtractClientCNInterceptor),
-		grpc.ChainStreamInterceptor(ExtractClientCNStreamInterceptor))
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...))
 
-	taskServer := NewTaskManagerServer(ctx)
+	managerOpts := []taskmanager.Option{taskmanager.WithAuditLogger(o.auditLog)}
+	if o.idempotencyTTL > 0 {
+		managerOpts = append(managerOpts, taskmanager.WithIdempotencyTTL(o.idempotencyTTL))
+	}
+	managerOpts = append(managerOpts, taskmanager.WithRetentionPolicy(o.retention), taskmanager.WithQuotaPolicy(o.quota),
+		taskmanager.WithResourceCeilings(o.ceilings), taskmanager.WithIODevices(o.ioDevices),
+		taskmanager.WithCgroupHierarchy(o.hierarchy), taskmanager.WithMemoryPressurePolicy(o.memoryPolicy))
+	taskManager := taskmanager.NewTaskManager(ctx, managerOpts...)
+	taskServer := NewTaskManagerServer(taskManager, o.auditLog)
 	pb.RegisterTaskManagerServer(grpcServer, taskServer)
 
+	// the server reports NOT_SERVING until Start has verified the cgroup controllers
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(pb.TaskManager_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	if o.enableReflection {
+		reflection.Register(grpcServer)
+	}
+
 	lis, err := net.Listen("tcp", serverAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen: %w", err)
 	}
 
-	return &Server{
-		grpcServer: grpcServer,
-		listener:   lis,
-		taskServer: taskServer,
-	}, nil
+	srv := &Server{
+		grpcServer:   grpcServer,
+		listener:     lis,
+		taskServer:   taskServer,
+		healthServer: healthServer,
+		hierarchy:    o.hierarchy,
+	}
+
+	if o.metricsAddress != "" {
+		metricsLis, err := net.Listen("tcp", o.metricsAddress)
+		if err != nil {
+			_ = lis.Close()
+			return nil, fmt.Errorf("failed to listen on metrics address: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		mux.HandleFunc("/healthz", srv.handleHealthz)
+		mux.HandleFunc("/readyz", srv.handleReadyz)
+		srv.metricsListener = metricsLis
+		srv.metricsServer = &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	if o.gatewayAddress != "" {
+		handler, err := newGatewayHandler(taskServer, unaryInterceptors, streamInterceptors)
+		if err != nil {
+			srv.closeListeners()
+			return nil, fmt.Errorf("failed to create gateway: %w", err)
+		}
+		gatewayLis, err := net.Listen("tcp", o.gatewayAddress)
+		if err != nil {
+			srv.closeListeners()
+			return nil, fmt.Errorf("failed to listen on gateway address: %w", err)
+		}
+		srv.gatewayListener = gatewayLis
+		srv.gatewayServer = &http.Server{
+			Handler:           handler,
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	return srv, nil
 }
 
 // Start starts the gRPC server
 func (s *Server) Start() error {
-	// first check if the cgroup v2 controllers are enabled
-	err := cgroups.CheckAndEnableCgroupV2Controllers("/sys/fs/cgroup/cgroup.subtree_control", []string{"cpu", "memory", "io"})
+	// first check if the cgroup v2 controllers are enabled on the path to the parent cgroup of the tasks
+	slog.Info("running tasks in cgroup", "parent", s.hierarchy.Parent())
+	err := s.hierarchy.EnableControllers([]string{"cpu", "memory"})
 	if err != nil {
-		log.Printf("failed to check cgroup v2 controllers: %v", err)
+		slog.Error("failed to check cgroup v2 controllers", "error", err)
+		s.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
 		return err
 	}
+	// tasks run without IO limits when the io controller is unavailable, without a default pids.max
+	// and cannot be given one without the pids controller, and cannot be pinned to CPUs without the
+	// cpuset controller
+	if err := s.hierarchy.EnableControllers([]string{"io"}); err != nil {
+		slog.Warn("io cgroup controller unavailable: IO limits are not enforced", "error", err)
+	}
+	if err := s.hierarchy.EnableControllers([]string{"pids"}); err != nil {
+		slog.Warn("pids cgroup controller unavailable: the processes of tasks cannot be limited", "error", err)
+	}
+	if err := s.hierarchy.EnableControllers([]string{"cpuset"}); err != nil {
+		slog.Warn("cpuset cgroup controller unavailable: tasks cannot be pinned to CPUs or memory nodes", "error", err)
+	}
+	s.setServingStatus(healthpb.HealthCheckResponse_SERVING)
+
+	if s.metricsServer != nil {
+		go func() {
+			slog.Info("metrics listening", "address", s.metricsListener.Addr().String())
+			if err := s.metricsServer.Serve(s.metricsListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("metrics server exited with error", "error", err)
+			}
+		}()
+	}
+
+	if s.gatewayServer != nil {
+		go func() {
+			slog.Info("gateway listening", "address", s.gatewayListener.Addr().String())
+			// the certificates are already part of the TLS config
+			if err := s.gatewayServer.ServeTLS(s.gatewayListener, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("gateway server exited with error", "error", err)
+			}
+		}()
+	}
 
-	log.Printf("Server listening on %v (Ctrl+C to stop)", s.listener.Addr())
+	slog.Info("server listening (Ctrl+C to stop)", "address", s.listener.Addr().String())
 	return s.grpcServer.Serve(s.listener)
 }
 
@@ -90,29 +315,85 @@ func (s *Server) Addr() string {
 	return ""
 }
 
+// setServingStatus sets the health status of the server and the TaskManager service
+func (s *Server) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.healthServer.SetServingStatus("", status)
+	s.healthServer.SetServingStatus(pb.TaskManager_ServiceDesc.ServiceName, status)
+}
+
+// MetricsAddr returns the address metrics are served on or an empty string if metrics are disabled
+func (s *Server) MetricsAddr() string {
+	if s.metricsListener != nil {
+		return s.metricsListener.Addr().String()
+	}
+	return ""
+}
+
+// GatewayAddr returns the address the HTTP/JSON gateway is served on or an empty string if it is disabled
+func (s *Server) GatewayAddr() string {
+	if s.gatewayListener != nil {
+		return s.gatewayListener.Addr().String()
+	}
+	return ""
+}
+
+// closeListeners closes the listeners created so far when New fails part way through
+func (s *Server) closeListeners() {
+	_ = s.listener.Close()
+	if s.metricsListener != nil {
+		_ = s.metricsListener.Close()
+	}
+}
+
 // Shutdown shuts down the gRPC server and waits for all tasks to complete
 func (s *Server) Shutdown() {
-	log.Println("Shutting down gRPC server...")
+	slog.Info("shutting down gRPC server")
+
+	// report NOT_SERVING while draining so load balancers stop sending new requests
+	s.healthServer.Shutdown()
 
-	// GracefulStop with timeout
+	// GracefulStop with timeout; the gateway drains in parallel with the gRPC server
 	done := make(chan struct{})
 	go func() {
 		s.grpcServer.GracefulStop()
 		close(done)
 	}()
+	gatewayCtx, cancelGateway := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelGateway()
+	gatewayDone := make(chan struct{})
+	go func() {
+		defer close(gatewayDone)
+		if s.gatewayServer == nil {
+			return
+		}
+		if err := s.gatewayServer.Shutdown(gatewayCtx); err != nil {
+			slog.Warn("gateway shutdown timed out; forcing close", "error", err)
+			_ = s.gatewayServer.Close()
+		}
+	}()
 
 	// give the ongoing RPCs a chance to complete
 	// TODO: make this timeout configurable
 	select {
 	case <-done:
-		log.Println("gRPC server stopped gracefully.")
+		slog.Info("gRPC server stopped gracefully")
 	case <-time.After(30 * time.Second):
-		log.Println("GracefulStop timed out; forcing shutdown.")
+		slog.Warn("GracefulStop timed out; forcing shutdown")
 		s.grpcServer.Stop()
 	}
+	<-gatewayDone
 
-	log.Println("Waiting for all tasks to complete...")
+	slog.Info("waiting for all tasks to complete")
 	if err := s.taskServer.taskManager.WaitForTasks(); err != nil {
-		log.Printf("Error waiting for tasks to complete: %v", err)
+		slog.Error("error waiting for tasks to complete", "error", err)
+	}
+
+	// metrics are served until the end so the final task state can still be scraped
+	if s.metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			slog.Error("error shutting down metrics server", "error", err)
+		}
 	}
 }
@@ -4,23 +4,28 @@ import (
 	"context"
 	"errors"
 	"io"
-	"log"
 	"slices"
 
 	pb "github.com/mikewurtz/taskman/gen/proto"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/mikewurtz/taskman/internal/audit"
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/logging"
 	"github.com/mikewurtz/taskman/internal/task"
 	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
 )
 
-func NewTaskManagerServer(ctx context.Context) *taskManagerServer {
+func NewTaskManagerServer(taskManager *taskmanager.TaskManager, auditLog *audit.Logger) *taskManagerServer {
 	return &taskManagerServer{
-		taskManager: taskmanager.NewTaskManager(ctx),
+		taskManager: taskManager,
+		auditLog:    auditLog,
 	}
 }
 
@@ -29,17 +34,197 @@ type taskManagerServer struct {
 	// this gives us a forward compatible implementation to extend later
 	pb.UnimplementedTaskManagerServer
 	taskManager *taskmanager.TaskManager
+	auditLog    *audit.Logger
 }
 
 // StartTask starts a new task and returns the task ID
 func (s *taskManagerServer) StartTask(ctx context.Context, req *pb.StartTaskRequest) (*pb.StartTaskResponse, error) {
-	taskID, err := s.taskManager.StartTask(ctx, req.Command, req.Args)
+	retry, err := retryPolicyFromProto(req.RetryPolicy)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	restart, err := restartPolicyFromProto(req.RestartPolicy)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	dependsOn, err := dependenciesFromProto(req.DependsOn)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	taskID, err := s.taskManager.StartTask(ctx, taskmanager.TaskSpec{
+		Command:        req.Command,
+		Args:           req.Args,
+		Labels:         req.Labels,
+		IdempotencyKey: req.IdempotencyKey,
+		Priority:       int(req.Priority),
+		Retry:          retry,
+		Restart:        restart,
+		DependsOn:      dependsOn,
+		Limits:         resourceUpdateFromProto(req.GetLimits()),
+	})
 	if err != nil {
 		return nil, task.TaskErrorToGRPC(err)
 	}
 	return &pb.StartTaskResponse{TaskId: taskID}, nil
 }
 
+// retryPolicyFromProto converts the retry policy of a start request; a missing policy never retries
+func retryPolicyFromProto(policy *pb.RetryPolicy) (taskmanager.RetryPolicy, error) {
+	var retry taskmanager.RetryPolicy
+	if policy == nil {
+		return retry, nil
+	}
+	for _, d := range []*durationpb.Duration{policy.InitialBackoff, policy.MaxBackoff} {
+		if d == nil {
+			continue
+		}
+		if err := d.CheckValid(); err != nil {
+			return retry, task.NewTaskError(task.ErrInvalidArgument, "invalid retry backoff: %v", err)
+		}
+	}
+
+	retry.MaxAttempts = int(policy.MaxAttempts)
+	retry.InitialBackoff = policy.InitialBackoff.AsDuration()
+	retry.MaxBackoff = policy.MaxBackoff.AsDuration()
+	retry.Multiplier = policy.BackoffMultiplier
+	for _, outcome := range policy.RetryOn {
+		switch outcome {
+		case pb.RetryOutcome_RETRY_OUTCOME_EXIT_ERROR:
+			retry.RetryOn |= taskmanager.RetryOnExitError
+		case pb.RetryOutcome_RETRY_OUTCOME_OOM:
+			retry.RetryOn |= taskmanager.RetryOnOOM
+		case pb.RetryOutcome_RETRY_OUTCOME_SIGNAL:
+			retry.RetryOn |= taskmanager.RetryOnSignal
+		default:
+			return retry, task.NewTaskError(task.ErrInvalidArgument, "invalid retry outcome: %s", outcome)
+		}
+	}
+	return retry, nil
+}
+
+// restartModes maps the restart modes of a start request to those of the task manager
+var restartModes = map[pb.RestartMode]taskmanager.RestartMode{
+	pb.RestartMode_RESTART_MODE_UNSPECIFIED: taskmanager.RestartNever,
+	pb.RestartMode_RESTART_MODE_NEVER:       taskmanager.RestartNever,
+	pb.RestartMode_RESTART_MODE_ON_FAILURE:  taskmanager.RestartOnFailure,
+	pb.RestartMode_RESTART_MODE_ALWAYS:      taskmanager.RestartAlways,
+}
+
+// restartPolicyFromProto converts the restart policy of a start request; a missing policy never restarts
+func restartPolicyFromProto(policy *pb.RestartPolicy) (taskmanager.RestartPolicy, error) {
+	var restart taskmanager.RestartPolicy
+	if policy == nil {
+		return restart, nil
+	}
+	for _, d := range []*durationpb.Duration{policy.InitialBackoff, policy.MaxBackoff, policy.RestartWindow} {
+		if d == nil {
+			continue
+		}
+		if err := d.CheckValid(); err != nil {
+			return restart, task.NewTaskError(task.ErrInvalidArgument, "invalid restart duration: %v", err)
+		}
+	}
+	mode, ok := restartModes[policy.Mode]
+	if !ok {
+		return restart, task.NewTaskError(task.ErrInvalidArgument, "invalid restart mode: %s", policy.Mode)
+	}
+
+	restart.Mode = mode
+	restart.InitialBackoff = policy.InitialBackoff.AsDuration()
+	restart.MaxBackoff = policy.MaxBackoff.AsDuration()
+	restart.MaxRestarts = int(policy.MaxRestarts)
+	restart.Window = policy.RestartWindow.AsDuration()
+	return restart, nil
+}
+
+// retryPolicyToProto converts a retry policy as given by the client; nil if the task is not retried
+func retryPolicyToProto(retry taskmanager.RetryPolicy) *pb.RetryPolicy {
+	if retry.MaxAttempts == 0 {
+		return nil
+	}
+	policy := &pb.RetryPolicy{
+		MaxAttempts:       int32(retry.MaxAttempts),
+		BackoffMultiplier: retry.Multiplier,
+	}
+	if retry.InitialBackoff > 0 {
+		policy.InitialBackoff = durationpb.New(retry.InitialBackoff)
+	}
+	if retry.MaxBackoff > 0 {
+		policy.MaxBackoff = durationpb.New(retry.MaxBackoff)
+	}
+	for outcome, flag := range map[pb.RetryOutcome]taskmanager.RetryOutcome{
+		pb.RetryOutcome_RETRY_OUTCOME_EXIT_ERROR: taskmanager.RetryOnExitError,
+		pb.RetryOutcome_RETRY_OUTCOME_OOM:        taskmanager.RetryOnOOM,
+		pb.RetryOutcome_RETRY_OUTCOME_SIGNAL:     taskmanager.RetryOnSignal,
+	} {
+		if retry.RetryOn&flag != 0 {
+			policy.RetryOn = append(policy.RetryOn, outcome)
+		}
+	}
+	slices.Sort(policy.RetryOn)
+	return policy
+}
+
+// restartPolicyToProto converts a restart policy as given by the client; nil if the task is not restarted
+func restartPolicyToProto(restart taskmanager.RestartPolicy) *pb.RestartPolicy {
+	if restart.Mode == taskmanager.RestartNever {
+		return nil
+	}
+	policy := &pb.RestartPolicy{MaxRestarts: int32(restart.MaxRestarts)}
+	for pbMode, mode := range restartModes {
+		if mode == restart.Mode && pbMode != pb.RestartMode_RESTART_MODE_UNSPECIFIED {
+			policy.Mode = pbMode
+		}
+	}
+	if restart.InitialBackoff > 0 {
+		policy.InitialBackoff = durationpb.New(restart.InitialBackoff)
+	}
+	if restart.MaxBackoff > 0 {
+		policy.MaxBackoff = durationpb.New(restart.MaxBackoff)
+	}
+	if restart.Window > 0 {
+		policy.RestartWindow = durationpb.New(restart.Window)
+	}
+	return policy
+}
+
+// dependencyConditions maps the conditions of a start request to the conditions of the task manager
+var dependencyConditions = map[pb.DependencyCondition]taskmanager.DependencyCondition{
+	pb.DependencyCondition_DEPENDENCY_CONDITION_UNSPECIFIED: taskmanager.DependsOnSuccess,
+	pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS:     taskmanager.DependsOnSuccess,
+	pb.DependencyCondition_DEPENDENCY_CONDITION_COMPLETION:  taskmanager.DependsOnCompletion,
+	pb.DependencyCondition_DEPENDENCY_CONDITION_FAILURE:     taskmanager.DependsOnFailure,
+}
+
+// dependenciesFromProto converts the dependencies of a start request
+func dependenciesFromProto(deps []*pb.TaskDependency) ([]taskmanager.Dependency, error) {
+	dependsOn := make([]taskmanager.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		condition, ok := dependencyConditions[dep.Condition]
+		if !ok {
+			return nil, task.NewTaskError(task.ErrInvalidArgument, "invalid condition of dependency %s: %s", dep.TaskId, dep.Condition)
+		}
+		dependsOn = append(dependsOn, taskmanager.Dependency{TaskID: dep.TaskId, Condition: condition})
+	}
+	return dependsOn, nil
+}
+
+// dependenciesToProto converts the dependencies of a task to the status response
+func dependenciesToProto(deps []taskmanager.Dependency) []*pb.TaskDependency {
+	pbDeps := make([]*pb.TaskDependency, 0, len(deps))
+	for _, dep := range deps {
+		condition := pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS
+		switch dep.Condition {
+		case taskmanager.DependsOnCompletion:
+			condition = pb.DependencyCondition_DEPENDENCY_CONDITION_COMPLETION
+		case taskmanager.DependsOnFailure:
+			condition = pb.DependencyCondition_DEPENDENCY_CONDITION_FAILURE
+		}
+		pbDeps = append(pbDeps, &pb.TaskDependency{TaskId: dep.TaskID, Condition: condition})
+	}
+	return pbDeps
+}
+
 // StopTask stops the task with the given ID
 func (s *taskManagerServer) StopTask(ctx context.Context, req *pb.StopTaskRequest) (*pb.StopTaskResponse, error) {
 	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
@@ -47,7 +232,7 @@ func (s *taskManagerServer) StopTask(ctx context.Context, req *pb.StopTaskReques
 		return nil, task.TaskErrorToGRPC(err)
 	}
 	caller := ctx.Value(basegrpc.ClientIDKey).(string)
-	if err = checkAuthorization(caller, taskObj); err != nil {
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
 		return nil, err
 	}
 	if err := s.taskManager.StopTask(ctx, req.TaskId); err != nil {
@@ -56,6 +241,74 @@ func (s *taskManagerServer) StopTask(ctx context.Context, req *pb.StopTaskReques
 	return &pb.StopTaskResponse{}, nil
 }
 
+// DeleteTask removes the finished task with the given ID and its output
+func (s *taskManagerServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return nil, err
+	}
+	if err := s.taskManager.DeleteTask(ctx, req.TaskId); err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.DeleteTaskResponse{}, nil
+}
+
+// SignalTask sends a signal to the task with the given ID
+func (s *taskManagerServer) SignalTask(ctx context.Context, req *pb.SignalTaskRequest) (*pb.SignalTaskResponse, error) {
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return nil, err
+	}
+	sig, err := task.ParseSignal(req.Signal)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	if err := s.taskManager.SignalTask(ctx, req.TaskId, sig); err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.SignalTaskResponse{}, nil
+}
+
+// PauseTask freezes the processes of the task with the given ID
+func (s *taskManagerServer) PauseTask(ctx context.Context, req *pb.PauseTaskRequest) (*pb.PauseTaskResponse, error) {
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return nil, err
+	}
+	if err := s.taskManager.PauseTask(ctx, req.TaskId); err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.PauseTaskResponse{}, nil
+}
+
+// ResumeTask thaws the processes of the paused task with the given ID
+func (s *taskManagerServer) ResumeTask(ctx context.Context, req *pb.ResumeTaskRequest) (*pb.ResumeTaskResponse, error) {
+	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+		return nil, err
+	}
+	if err := s.taskManager.ResumeTask(ctx, req.TaskId); err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	return &pb.ResumeTaskResponse{}, nil
+}
+
 // GetTaskStatus returns the status of the task with the given ID
 func (s *taskManagerServer) GetTaskStatus(ctx context.Context, req *pb.TaskStatusRequest) (*pb.TaskStatusResponse, error) {
 	taskObj, err := s.taskManager.GetTask(ctx, req.TaskId)
@@ -63,15 +316,20 @@ func (s *taskManagerServer) GetTaskStatus(ctx context.Context, req *pb.TaskStatu
 		return nil, task.TaskErrorToGRPC(err)
 	}
 	caller := ctx.Value(basegrpc.ClientIDKey).(string)
-	if err = checkAuthorization(caller, taskObj); err != nil {
+	if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
 		return nil, err
 	}
-	status, err := task.StatusToProto(taskObj.GetStatus())
+	return s.taskStatusResponse(taskObj)
+}
+
+// taskStatusResponse converts a snapshot of the task to its status response
+func (s *taskManagerServer) taskStatusResponse(taskObj *taskmanager.Task) (*pb.TaskStatusResponse, error) {
+	snapshot := taskObj.Snapshot()
+	status, err := task.StatusToProto(snapshot.Status)
 	if err != nil {
 		return nil, task.TaskErrorToGRPC(err)
 	}
 
-	snapshot := taskObj.Snapshot()
 	returnStatus := &pb.TaskStatusResponse{
 		TaskId:            snapshot.ID,
 		ProcessId:         int32(snapshot.ProcessID),
@@ -81,13 +339,129 @@ func (s *taskManagerServer) GetTaskStatus(ctx context.Context, req *pb.TaskStatu
 		ExitCode:          snapshot.ExitCode,
 		TerminationSignal: snapshot.TerminationSignal,
 		TerminationSource: snapshot.TerminationSource,
+		Labels:            snapshot.Labels,
+		Priority:          int32(snapshot.Priority),
+		QueuePosition:     int32(snapshot.QueuePosition),
+		MaxAttempts:       int32(snapshot.MaxAttempts),
+		DependsOn:         dependenciesToProto(snapshot.Dependencies),
+		ScheduleId:        snapshot.ScheduleID,
+		RestartCount:      int32(snapshot.RestartCount),
+		LastExitReason:    snapshot.LastExitReason,
+		Limits:            limitsToProto(snapshot.Limits),
+		ResourceChanges:   resourceChangesToProto(snapshot.ResourceChanges),
+		PidsMaxEvents:     s.taskManager.PidsMaxEvents(taskObj),
+		Memory:            memoryStatusToProto(s.taskManager.MemoryStatus(taskObj)),
+		MemoryEvents:      memoryEventsToProto(snapshot.MemoryEvents),
+	}
+	if snapshot.PausedDuration > 0 {
+		returnStatus.PausedDuration = durationpb.New(snapshot.PausedDuration)
+	}
+	for _, attempt := range snapshot.Attempts {
+		attemptStatus, err := task.StatusToProto(attempt.Status)
+		if err != nil {
+			return nil, task.TaskErrorToGRPC(err)
+		}
+		pbAttempt := &pb.TaskAttempt{
+			Attempt:           int32(attempt.Number),
+			ProcessId:         int32(attempt.ProcessID),
+			Status:            attemptStatus,
+			StartTime:         timestamppb.New(attempt.StartTime),
+			ExitCode:          attempt.ExitCode,
+			TerminationSignal: attempt.TerminationSignal,
+			TerminationSource: attempt.TerminationSource,
+			OutputOffset:      attempt.OutputOffset,
+			PidsMaxEvents:     attempt.PidsMaxEvents,
+		}
+		if !attempt.EndTime.IsZero() {
+			pbAttempt.EndTime = timestamppb.New(attempt.EndTime)
+		}
+		returnStatus.Attempts = append(returnStatus.Attempts, pbAttempt)
 	}
 
 	return returnStatus, nil
 }
 
-func checkAuthorization(caller string, taskObj *taskmanager.Task) error {
+// maxWaitTasks limits the number of tasks of a single WaitTasks call
+const maxWaitTasks = 1000
+
+// WaitTasks waits for all or any of the tasks to complete and returns their statuses
+func (s *taskManagerServer) WaitTasks(ctx context.Context, req *pb.WaitTasksRequest) (*pb.WaitTasksResponse, error) {
+	if len(req.TaskIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one task ID is required")
+	}
+	if len(req.TaskIds) > maxWaitTasks {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot wait for more than %d tasks", maxWaitTasks)
+	}
+
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	for _, taskID := range req.TaskIds {
+		taskObj, err := s.taskManager.GetTask(ctx, taskID)
+		if err != nil {
+			return nil, task.TaskErrorToGRPC(err)
+		}
+		if err = s.checkAuthorization(ctx, caller, taskObj); err != nil {
+			return nil, err
+		}
+	}
+
+	waitCtx := ctx
+	if req.Timeout != nil {
+		if err := req.Timeout.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timeout: %v", err)
+		}
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, req.Timeout.AsDuration())
+		defer cancel()
+	}
+
+	tasks, err := s.taskManager.WaitTasks(waitCtx, req.TaskIds, req.Mode == pb.WaitMode_WAIT_MODE_ANY)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+	// the statuses are only returned when our own timeout expired, not when the call was canceled
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	resp := &pb.WaitTasksResponse{}
+	finished := 0
+	for _, taskObj := range tasks {
+		taskStatus, err := s.taskStatusResponse(taskObj)
+		if err != nil {
+			return nil, err
+		}
+		if !taskObj.GetEndTime().IsZero() {
+			finished++
+		}
+		resp.Statuses = append(resp.Statuses, taskStatus)
+	}
+	// completion is derived from the tasks themselves since they may finish just as the timeout expires
+	if req.Mode == pb.WaitMode_WAIT_MODE_ANY {
+		resp.Completed = finished > 0
+	} else {
+		resp.Completed = finished == len(tasks)
+	}
+	return resp, nil
+}
+
+// checkAuthorization returns NotFound when the caller does not own the task so that the existence
+// of other clients' tasks is not leaked. The denial is still recorded in the audit log with the method
+// and peer address of the call.
+func (s *taskManagerServer) checkAuthorization(ctx context.Context, caller string, taskObj *taskmanager.Task) error {
 	if taskObj.GetClientID() != caller && caller != "admin" {
+		ev := audit.Event{
+			Action:   audit.ActionAuthFailure,
+			ClientID: caller,
+			TaskID:   taskObj.GetID(),
+			Outcome:  audit.OutcomeDenied,
+			Code:     codes.NotFound.String(),
+			Message:  "caller does not own the task",
+		}
+		ev.Method, _ = grpc.Method(ctx)
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			ev.PeerAddr = p.Addr.String()
+		}
+		s.auditLog.Log(ev)
 		return status.Errorf(codes.NotFound, "task with id %s not found", taskObj.GetID())
 	}
 	return nil
@@ -100,8 +474,9 @@ func (s *taskManagerServer) StreamTaskOutput(req *pb.StreamTaskOutputRequest, st
 		return task.TaskErrorToGRPC(err)
 	}
 
+	logger := logging.FromContext(stream.Context())
 	caller := stream.Context().Value(basegrpc.ClientIDKey).(string)
-	if err = checkAuthorization(caller, taskObj); err != nil {
+	if err = s.checkAuthorization(stream.Context(), caller, taskObj); err != nil {
 		return err
 	}
 
@@ -112,7 +487,7 @@ func (s *taskManagerServer) StreamTaskOutput(req *pb.StreamTaskOutputRequest, st
 	}
 	context.AfterFunc(stream.Context(), func() {
 		if err := jobStreamer.Close(); err != nil {
-			log.Printf("Failed to close job streamer: %v", err)
+			logger.Error("failed to close job streamer", "error", err)
 		}
 	})
 
@@ -124,10 +499,10 @@ func (s *taskManagerServer) StreamTaskOutput(req *pb.StreamTaskOutputRequest, st
 			return nil
 		} else if err != nil {
 			if stream.Context().Err() != nil {
-				log.Printf("Client context canceled: %v", stream.Context().Err())
+				logger.Info("client context canceled", "error", stream.Context().Err())
 				return task.TaskErrorToGRPC(err)
 			} else if errors.Is(err, context.Canceled) {
-				log.Printf("Server context canceled: %v", err)
+				logger.Info("server context canceled", "error", err)
 				return task.TaskErrorToGRPC(task.NewTaskErrorWithErr(task.ErrCanceled, "server context canceled", err))
 			}
 			return task.TaskErrorToGRPC(task.NewTaskErrorWithErr(task.ErrInternal, "failed to read output", err))
@@ -141,3 +516,22 @@ func (s *taskManagerServer) StreamTaskOutput(req *pb.StreamTaskOutputRequest, st
 		}
 	}
 }
+
+// ListTasks returns the statuses of the caller's tasks matching the label selector; admins see all tasks
+func (s *taskManagerServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	selector, err := task.ParseSelector(req.LabelSelector)
+	if err != nil {
+		return nil, task.TaskErrorToGRPC(err)
+	}
+
+	tasks := s.taskManager.ListTasks(ctx, taskmanager.TaskFilter{Labels: selector})
+	resp := &pb.ListTasksResponse{Tasks: make([]*pb.TaskStatusResponse, 0, len(tasks))}
+	for _, taskObj := range tasks {
+		taskStatus, err := s.taskStatusResponse(taskObj)
+		if err != nil {
+			return nil, err
+		}
+		resp.Tasks = append(resp.Tasks, taskStatus)
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{desc: "with json format", format: FormatJSON, level: "info"},
+		{desc: "with text format", format: FormatText, level: "debug"},
+		{desc: "with unknown format", format: "xml", level: "info", wantErr: true},
+		{desc: "with unknown level", format: FormatJSON, level: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			logger, err := New(&bytes.Buffer{}, tt.format, tt.level)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, logger)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "info")
+	require.NoError(t, err)
+
+	// without a logger in the context the default logger is returned
+	require.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	ctx := WithLogger(context.Background(), logger.With("request_id", "abc"))
+	FromContext(ctx).Info("hello")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "abc", record["request_id"])
+	require.Equal(t, "hello", record["msg"])
+}
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "taskman"
+
+// TerminationSourceExited is the termination source label used for tasks that exited on their own
+const TerminationSourceExited = "exited"
+
+var (
+	// RPCRequests counts handled gRPC calls by full method name and status code
+	RPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Total number of gRPC requests handled by method and status code.",
+	}, []string{"method", "code"})
+
+	// RPCDuration observes the latency of gRPC calls by full method name and status code.
+	// For streams this is the lifetime of the stream.
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of gRPC requests by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// TasksStarted counts tasks whose process was started successfully
+	TasksStarted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_started_total",
+		Help:      "Total number of tasks started.",
+	})
+
+	// TasksDeduplicated counts start requests answered with an existing task because of a repeated idempotency key
+	TasksDeduplicated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_deduplicated_total",
+		Help:      "Total number of start requests that returned an existing task for a repeated idempotency key.",
+	})
+
+	// TasksFinished counts finished tasks by termination source (user, admin, oom, system, unknown or exited)
+	TasksFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_finished_total",
+		Help:      "Total number of finished tasks by termination source.",
+	}, []string{"termination_source"})
+
+	// TasksEvicted counts finished tasks removed by the retention policy or deleted by reason (age, count, output or delete)
+	TasksEvicted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_evicted_total",
+		Help:      "Total number of finished tasks evicted by the retention policy or deleted, by reason.",
+	}, []string{"reason"})
+
+	// QuotaRejections counts start requests rejected by a quota by quota (running_per_client, running, starts_per_minute or queued)
+	QuotaRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quota_rejections_total",
+		Help:      "Total number of start requests rejected by a quota, by quota.",
+	}, []string{"quota"})
+
+	// TaskRetries counts attempts of tasks started by a retry policy after a failed attempt
+	TaskRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_retries_total",
+		Help:      "Total number of task attempts started by a retry policy after a failed attempt.",
+	})
+
+	// TaskRestarts counts processes of tasks restarted by a restart policy
+	TaskRestarts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_restarts_total",
+		Help:      "Total number of task processes restarted by a restart policy.",
+	})
+
+	// Schedules is the number of schedules of all clients
+	Schedules = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "schedules",
+		Help:      "Number of schedules of all clients.",
+	})
+
+	// ScheduleRuns counts the runs of schedules by outcome: started, skipped, replaced or failed
+	ScheduleRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schedule_runs_total",
+		Help:      "Total number of schedule runs by outcome.",
+	}, []string{"outcome"})
+
+	// TasksQueued is the number of tasks waiting for a running task quota to free up
+	TasksQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tasks_queued",
+		Help:      "Number of tasks waiting in the queue for a running task quota to free up.",
+	})
+
+	// TasksWaiting is the number of tasks waiting for their dependencies to finish
+	TasksWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tasks_waiting",
+		Help:      "Number of tasks waiting for the tasks they depend on to finish.",
+	})
+
+	// TasksRunning is the number of tasks whose process has not exited yet
+	TasksRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tasks_running",
+		Help:      "Number of tasks currently running.",
+	})
+
+	// OutputBytesBuffered is the number of task output bytes held in memory
+	OutputBytesBuffered = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "output_buffered_bytes",
+		Help:      "Number of task output bytes buffered in memory.",
+	})
+
+	// ActiveStreamers is the number of open task output readers
+	ActiveStreamers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_streamers",
+		Help:      "Number of clients currently streaming task output.",
+	})
+
+	// CgroupCreateFailures counts failures to create or configure a task cgroup
+	CgroupCreateFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cgroup_create_failures_total",
+		Help:      "Total number of failures to create a task cgroup.",
+	})
+
+	// CgroupRemoveFailures counts failures to remove a task cgroup
+	CgroupRemoveFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cgroup_remove_failures_total",
+		Help:      "Total number of failures to remove a task cgroup.",
+	})
+
+	// CgroupRemoveDuration observes how long removing a task cgroup took including retries
+	CgroupRemoveDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "cgroup_remove_duration_seconds",
+		Help:      "Time spent removing a task cgroup including retries while it is busy.",
+		Buckets:   []float64{0.1, 0.2, 0.5, 1, 2, 3, 4, 5, 6},
+	})
+)
+
+// Registry holds all taskman collectors along with the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RPCRequests,
+		RPCDuration,
+		TasksStarted,
+		TasksDeduplicated,
+		TasksFinished,
+		TasksEvicted,
+		QuotaRejections,
+		TasksQueued,
+		TasksWaiting,
+		TaskRetries,
+		TaskRestarts,
+		Schedules,
+		ScheduleRuns,
+		TasksRunning,
+		OutputBytesBuffered,
+		ActiveStreamers,
+		CgroupCreateFailures,
+		CgroupRemoveFailures,
+		CgroupRemoveDuration,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
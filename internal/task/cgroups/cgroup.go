@@ -1,48 +1,60 @@
 package cgroups
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
-const baseCgroupPath = "/sys/fs/cgroup/"
+// cpuPeriodMicros is the period of cpu.max in microseconds
+const cpuPeriodMicros = 1000000
 
-// CreateCgroupForTask creates a cgroup for a task
-// In a real system, we would want the cgroup config to be configurable
-func CreateCgroupForTask(taskID string) (*os.File, error) {
-	cgroupPath := filepath.Join(baseCgroupPath, taskID)
+// Limits are the resource limits written to the cgroup of a task. A zero limit is unlimited ("max").
+type Limits struct {
+	// CPUMillis is the CPU time in thousandths of a CPU, e.g. 500 for half a CPU
+	CPUMillis int64
+	// MemoryMax is the memory in bytes above which the processes of the task are OOM killed
+	MemoryMax int64
+	// MemoryHigh is the memory in bytes above which the processes of the task are throttled and reclaimed
+	MemoryHigh int64
+	// IOReadBPS and IOWriteBPS are the bytes per second the task may read from and write to each IO device
+	IOReadBPS  int64
+	IOWriteBPS int64
+	// PidsMax is the number of processes and threads above which forks of the task fail
+	PidsMax int64
+	// CPUs and Mems are the CPUs and NUMA memory nodes the task is pinned to as a list such as "0-3,8";
+	// empty lists are those of the parent cgroup
+	CPUs string
+	Mems string
+}
 
-	if err := os.MkdirAll(cgroupPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cgroup directory %s: %w", cgroupPath, err)
-	}
+// DefaultLimits are the limits of a new task: 20% of a CPU, 64M of memory, 1 MB/s of IO and 512 processes
+var DefaultLimits = Limits{CPUMillis: 200, MemoryMax: 64 << 20, IOReadBPS: 1 << 20, IOWriteBPS: 1 << 20, PidsMax: 512}
 
-	// Configure CPU limits: Quota 200000 µs and Period 1000000 µs.
-	// this should cap the cpu usage to 20%
-	cpuMaxPath := filepath.Join(cgroupPath, "cpu.max")
-	cpuConfig := "200000 1000000"
-	if err := os.WriteFile(cpuMaxPath, []byte(cpuConfig), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write CPU config to %s: %w", cpuMaxPath, err)
+// limitValue formats a limit for a cgroup interface file; zero is "max"
+func limitValue(limit int64) string {
+	if limit == 0 {
+		return "max"
 	}
+	return strconv.FormatInt(limit, 10)
+}
 
-	// Configure memory limit: 64M.
-	memoryMaxPath := filepath.Join(cgroupPath, "memory.max")
-	memoryConfig := "64M"
-	if err := os.WriteFile(memoryMaxPath, []byte(memoryConfig), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write memory config to %s: %w", memoryMaxPath, err)
+// CreateCgroupForTask creates the cgroup of a task at cgroupPath with the limits, where the IO limits
+// apply to the ioDevices given by major:minor number, and returns it opened as a directory
+func CreateCgroupForTask(cgroupPath string, limits Limits, ioDevices []string) (*os.File, error) {
+	if err := os.MkdirAll(cgroupPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup directory %s: %w", cgroupPath, err)
 	}
 
-	// io is not always enabled on the system and can be enabled by:
-	// echo "+io" | sudo tee /sys/fs/cgroup/cgroup.subtree_control
-	// Configure IO limits: device "8:0" with max read and write bandwidth 1048576 (1 MB/s).
-	ioMaxPath := filepath.Join(cgroupPath, "io.max")
-	ioConfig := "8:0 rbps=1048576 wbps=1048576"
-	if err := os.WriteFile(ioMaxPath, []byte(ioConfig), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write IO config to %s: %w", ioMaxPath, err)
+	if err := SetCgroupLimits(cgroupPath, limits, ioDevices); err != nil {
+		return nil, err
 	}
 
 	// Open the cgroup directory as a file descriptor
@@ -54,9 +66,64 @@ func CreateCgroupForTask(taskID string) (*os.File, error) {
 	return cgFd, nil
 }
 
-// RemoveCgroupForTask removes the cgroup for a task
-func RemoveCgroupForTask(taskID string) error {
-	cgroupPath := filepath.Join(baseCgroupPath, taskID)
+// SetCgroupLimits writes the limits to the cgroup at cgroupPath. The processes in the cgroup are subject
+// to the new limits right away. The IO limits apply to each of the ioDevices and are skipped when the
+// io controller is not enabled for the cgroup, as is an unlimited pids.max without the pids controller.
+// The error wraps fs.ErrNotExist if the cgroup does not exist.
+func SetCgroupLimits(cgroupPath string, limits Limits, ioDevices []string) error {
+	// cpu.max is the quota of CPU time in every period, both in microseconds
+	cpuConfig := "max " + strconv.Itoa(cpuPeriodMicros)
+	if limits.CPUMillis > 0 {
+		cpuConfig = fmt.Sprintf("%d %d", limits.CPUMillis*cpuPeriodMicros/1000, cpuPeriodMicros)
+	}
+
+	type limitFile struct {
+		name   string
+		config string
+	}
+	files := []limitFile{
+		{name: "cpu.max", config: cpuConfig},
+		{name: "memory.max", config: limitValue(limits.MemoryMax)},
+		{name: "memory.high", config: limitValue(limits.MemoryHigh)},
+	}
+
+	// pids is optional like cpuset; a pids limit fails without it rather than leaving the task unlimited
+	if _, err := os.Stat(filepath.Join(cgroupPath, "pids.max")); err == nil {
+		files = append(files, limitFile{name: "pids.max", config: limitValue(limits.PidsMax)})
+	} else if limits.PidsMax > 0 {
+		return fmt.Errorf("failed to limit %s to %d processes: the pids controller is not enabled: %w",
+			cgroupPath, limits.PidsMax, err)
+	}
+
+	// cpuset is optional like io; pinning a task fails without it rather than leaving it unpinned
+	if _, err := os.Stat(filepath.Join(cgroupPath, "cpuset.cpus")); err == nil {
+		// an empty list is written as a newline since the kernel ignores empty writes
+		files = append(files, limitFile{name: "cpuset.cpus", config: limits.CPUs + "\n"}, limitFile{name: "cpuset.mems", config: limits.Mems + "\n"})
+	} else if limits.CPUs != "" || limits.Mems != "" {
+		return fmt.Errorf("failed to pin %s to cpus %q and mems %q: the cpuset controller is not enabled: %w",
+			cgroupPath, limits.CPUs, limits.Mems, err)
+	}
+
+	// io is not always enabled on the system and can be enabled by:
+	// echo "+io" | sudo tee /sys/fs/cgroup/cgroup.subtree_control, and on the path to the parent cgroup
+	// io.max only exists when it is, and takes one device per write
+	if _, err := os.Stat(filepath.Join(cgroupPath, "io.max")); err == nil {
+		for _, device := range ioDevices {
+			config := fmt.Sprintf("%s rbps=%s wbps=%s", device, limitValue(limits.IOReadBPS), limitValue(limits.IOWriteBPS))
+			files = append(files, limitFile{name: "io.max", config: config})
+		}
+	}
+	for _, f := range files {
+		path := filepath.Join(cgroupPath, f.name)
+		if err := os.WriteFile(path, []byte(f.config), 0644); err != nil {
+			return fmt.Errorf("failed to write %s to %s: %w", f.config, path, err)
+		}
+	}
+	return nil
+}
+
+// RemoveCgroupForTask removes the cgroup of a task at cgroupPath
+func RemoveCgroupForTask(cgroupPath string) error {
 	timeout := time.After(5 * time.Second)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
@@ -81,9 +148,8 @@ func RemoveCgroupForTask(taskID string) error {
 	}
 }
 
-// CheckIfOOMKilled checks if the task has been OOM killed
-func CheckIfOOMKilled(taskID string) (bool, error) {
-	cgroupPath := filepath.Join(baseCgroupPath, taskID)
+// CheckIfOOMKilled checks if the task of the cgroup at cgroupPath has been OOM killed
+func CheckIfOOMKilled(cgroupPath string) (bool, error) {
 	oomPath := filepath.Join(cgroupPath, "memory.events")
 
 	data, err := os.ReadFile(oomPath)
@@ -101,13 +167,102 @@ func CheckIfOOMKilled(taskID string) (bool, error) {
 	return false, nil
 }
 
-// CheckAndEnableCgroupV2Controllers checks if the cgroup v2 controllers are enabled
-// and enables them if they are not
+// freezePollInterval is how often cgroup.events is read while waiting for a freeze or thaw to complete
+const freezePollInterval = 10 * time.Millisecond
+
+// FreezeCgroupForTask freezes the processes of the task's cgroup at cgroupPath, or thaws them if frozen
+// is false, and waits until cgroup.events reports the new state or the context is done
+func FreezeCgroupForTask(ctx context.Context, cgroupPath string, frozen bool) error {
+	value := "0"
+	if frozen {
+		value = "1"
+	}
+	freezePath := filepath.Join(cgroupPath, "cgroup.freeze")
+	if err := os.WriteFile(freezePath, []byte(value), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", freezePath, err)
+	}
+
+	// the kernel sets frozen in cgroup.events once all processes of the cgroup are stopped
+	eventsPath := filepath.Join(cgroupPath, "cgroup.events")
+	ticker := time.NewTicker(freezePollInterval)
+	defer ticker.Stop()
+	for {
+		data, err := os.ReadFile(eventsPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", eventsPath, err)
+		}
+		if cgroupEventValue(string(data), "frozen") == value {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cgroup %s did not reach frozen %s: %w", cgroupPath, value, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// PidsMaxEvents returns how often a fork in the cgroup at cgroupPath failed because it reached pids.max
+func PidsMaxEvents(cgroupPath string) (int64, error) {
+	eventsPath := filepath.Join(cgroupPath, "pids.events")
+	data, err := os.ReadFile(eventsPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", eventsPath, err)
+	}
+	value := cgroupEventValue(string(data), "max")
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// ValidateCPUList checks a list of CPUs or memory nodes in the format of cpuset.cpus such as "0-3,8";
+// an empty list is valid
+func ValidateCPUList(list string) error {
+	if list == "" {
+		return nil
+	}
+	for entry := range strings.SplitSeq(list, ",") {
+		first, last, isRange := strings.Cut(entry, "-")
+		start, err := strconv.ParseUint(first, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid entry %q in list %q", entry, list)
+		}
+		if !isRange {
+			continue
+		}
+		end, err := strconv.ParseUint(last, 10, 16)
+		if err != nil || end < start {
+			return fmt.Errorf("invalid range %q in list %q", entry, list)
+		}
+	}
+	return nil
+}
+
+// cgroupEventValue returns the value of the key in the "key value" lines of a cgroup events file
+func cgroupEventValue(data, key string) string {
+	for line := range strings.SplitSeq(data, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == key {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// CheckAndEnableCgroupV2Controllers checks if the cgroup v2 controllers are enabled in the
+// cgroup.subtree_control file at path and enables them if they are not. A controller must be
+// available in the cgroup.controllers file next to it: pids and cpuset for instance are only
+// available below the root if they are enabled in every ancestor.
 func CheckAndEnableCgroupV2Controllers(path string, required []string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read %s: %w", path, err)
 	}
+	availablePath := filepath.Join(filepath.Dir(path), "cgroup.controllers")
+	available, err := os.ReadFile(availablePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", availablePath, err)
+	}
 
 	enabled := strings.Fields(string(data))
 	controllerSet := make(map[string]bool)
@@ -117,6 +272,9 @@ func CheckAndEnableCgroupV2Controllers(path string, required []string) error {
 
 	for _, ctrl := range required {
 		if !controllerSet[ctrl] {
+			if !slices.Contains(strings.Fields(string(available)), ctrl) {
+				return fmt.Errorf("cgroup controller %s is not available in %s", ctrl, filepath.Dir(path))
+			}
 
 			// Try to enable it
 			ctrlStr := "+" + ctrl
@@ -0,0 +1,99 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCPUList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		list        string
+		expectedErr bool
+	}{
+		{desc: "empty", list: ""},
+		{desc: "single", list: "0"},
+		{desc: "range", list: "0-3"},
+		{desc: "ranges and singles", list: "0-3,8,10-11"},
+		{desc: "descending range", list: "3-1", expectedErr: true},
+		{desc: "not a number", list: "a", expectedErr: true},
+		{desc: "empty entry", list: "0,,1", expectedErr: true},
+		{desc: "open range", list: "2-", expectedErr: true},
+		{desc: "negative", list: "-1", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateCPUList(tt.list)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPidsMaxEvents(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pids.events"), []byte("max 3\n"), 0644))
+	events, err := PidsMaxEvents(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), events)
+
+	_, err = PidsMaxEvents(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestCheckAndEnableCgroupV2Controllers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup.controllers"), []byte("cpuset cpu io memory pids\n"), 0644))
+	require.NoError(t, os.WriteFile(subtreeControl, []byte("cpu memory\n"), 0644))
+
+	// enabled controllers are not written again
+	require.NoError(t, CheckAndEnableCgroupV2Controllers(subtreeControl, []string{"cpu", "memory"}))
+	data, err := os.ReadFile(subtreeControl)
+	require.NoError(t, err)
+	assert.Equal(t, "cpu memory\n", string(data))
+
+	require.NoError(t, CheckAndEnableCgroupV2Controllers(subtreeControl, []string{"cpu", "pids"}))
+	data, err = os.ReadFile(subtreeControl)
+	require.NoError(t, err)
+	assert.Equal(t, "+pids", string(data))
+
+	// controllers that are not available cannot be enabled
+	assert.ErrorContains(t, CheckAndEnableCgroupV2Controllers(subtreeControl, []string{"hugetlb"}), "not available")
+}
+
+func TestSetCgroupLimitsWithoutPids(t *testing.T) {
+	t.Parallel()
+
+	// the cgroup of a task without the pids controller has no pids.max
+	dir := t.TempDir()
+	require.NoError(t, SetCgroupLimits(dir, Limits{CPUMillis: 500}, nil))
+	data, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "500000 1000000", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "pids.max"))
+
+	assert.Error(t, SetCgroupLimits(dir, Limits{PidsMax: 100}, nil))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pids.max"), []byte("max"), 0644))
+	require.NoError(t, SetCgroupLimits(dir, Limits{PidsMax: 100}, nil))
+	data, err = os.ReadFile(filepath.Join(dir, "pids.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "100", string(data))
+}
@@ -0,0 +1,241 @@
+package cgroups
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupRoot is the mount point of the cgroup v2 file system
+const cgroupRoot = "/sys/fs/cgroup"
+
+// DefaultParent is the parent cgroup of the client cgroups unless the server runs in a delegated cgroup
+const DefaultParent = cgroupRoot + "/taskman.slice"
+
+const (
+	// delegatedParent is the parent cgroup of the client cgroups below a delegated cgroup
+	delegatedParent = "tasks"
+	// serverLeaf is the cgroup the processes of a cgroup on the path to the parent are moved to, since
+	// controllers can only be enabled for the children of a cgroup without processes
+	serverLeaf = "taskman-server"
+	// procSelfCgroup lists the cgroup of the server process
+	procSelfCgroup = "/proc/self/cgroup"
+	// probePrefix is the name prefix of the scratch cgroups created by CheckWritable
+	probePrefix = "probe-"
+)
+
+// Hierarchy is the cgroup tree the tasks run in. Every client has an intermediate cgroup below the
+// parent, which holds the aggregate limits of its tasks, and every task a cgroup below its client:
+// <parent>/client-<client ID>/<task ID>
+type Hierarchy struct {
+	parent string
+	// clientLimits are the limits of every client cgroup; the IO limits are not applied
+	clientLimits Limits
+
+	// mu serializes the setup of client cgroups so that no task cgroup is created before the
+	// controllers of its client cgroup are enabled; clients are the client IDs set up by this server
+	mu      sync.Mutex
+	clients map[string]bool
+}
+
+// DefaultHierarchy returns the hierarchy below DefaultParent without client limits
+func DefaultHierarchy() *Hierarchy {
+	return &Hierarchy{parent: DefaultParent, clients: make(map[string]bool)}
+}
+
+// NewHierarchy returns the hierarchy below the parent cgroup, given as an absolute path or relative to
+// the cgroup mount. If parent is empty the server's own cgroup is used when systemd delegated it, e.g.
+// to a unit with Delegate=yes, and DefaultParent otherwise. The clientLimits are the aggregate limits of
+// the tasks of every client.
+func NewHierarchy(parent string, clientLimits Limits) (*Hierarchy, error) {
+	if parent == "" {
+		parent = DefaultParent
+		if own, err := ownCgroup(); err == nil && isDelegated(own) {
+			parent = filepath.Join(own, delegatedParent)
+		}
+	}
+	if !filepath.IsAbs(parent) {
+		parent = filepath.Join(cgroupRoot, parent)
+	}
+	parent = filepath.Clean(parent)
+	if rel, err := filepath.Rel(cgroupRoot, parent); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("cgroup parent %s must be below %s", parent, cgroupRoot)
+	}
+	return &Hierarchy{parent: parent, clientLimits: clientLimits, clients: make(map[string]bool)}, nil
+}
+
+// Parent returns the absolute path of the parent cgroup
+func (h *Hierarchy) Parent() string {
+	return h.parent
+}
+
+// ClientPath returns the absolute path of the cgroup of a client. The client ID is escaped so that
+// it is a single path element and the prefix keeps it apart from the interface files of the parent.
+func (h *Hierarchy) ClientPath(clientID string) string {
+	return filepath.Join(h.parent, "client-"+url.PathEscape(clientID))
+}
+
+// TaskPath returns the absolute path of the cgroup of a task
+func (h *Hierarchy) TaskPath(clientID, taskID string) string {
+	return filepath.Join(h.ClientPath(clientID), taskID)
+}
+
+// EnableControllers creates the parent cgroup and enables the controllers on the path from the cgroup
+// root down to the parent, so that they are available to the client and task cgroups. Controllers
+// already enabled, e.g. by systemd above a delegated cgroup, are left alone. The processes of the
+// server's own cgroup are moved to a leaf cgroup first if it is on the path.
+func (h *Hierarchy) EnableControllers(controllers []string) error {
+	if err := os.MkdirAll(h.parent, 0755); err != nil {
+		return fmt.Errorf("failed to create cgroup parent %s: %w", h.parent, err)
+	}
+	if err := h.vacateOwnCgroup(); err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(cgroupRoot, h.parent)
+	if err != nil {
+		return err
+	}
+	// the client cgroups enable the controllers for their tasks when they are created
+	path := cgroupRoot
+	for _, elem := range append([]string{""}, strings.Split(rel, string(filepath.Separator))...) {
+		path = filepath.Join(path, elem)
+		if err := CheckAndEnableCgroupV2Controllers(filepath.Join(path, "cgroup.subtree_control"), controllers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vacateOwnCgroup moves the processes of the server's own cgroup to a leaf cgroup if the own cgroup
+// is a non-root cgroup on the path to the parent, such as a delegated systemd unit or the cgroup of a
+// container
+func (h *Hierarchy) vacateOwnCgroup() error {
+	own, err := ownCgroup()
+	if err != nil {
+		return nil
+	}
+	if rel, err := filepath.Rel(own, h.parent); err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	// the root cgroup may have processes and has no cgroup.type
+	if _, err := os.Stat(filepath.Join(own, "cgroup.type")); err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(own, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("failed to read the processes of cgroup %s: %w", own, err)
+	}
+	pids := strings.Fields(string(data))
+	if len(pids) == 0 {
+		return nil
+	}
+	leaf := filepath.Join(own, serverLeaf)
+	if err := os.MkdirAll(leaf, 0755); err != nil {
+		return fmt.Errorf("failed to create cgroup %s: %w", leaf, err)
+	}
+	for _, pid := range pids {
+		// processes may exit while they are moved
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(pid), 0644); err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("failed to move process %s to cgroup %s: %w", pid, leaf, err)
+		}
+	}
+	return nil
+}
+
+// CreateClientCgroup creates the cgroup of a client, enables the controllers of the parent for its
+// tasks and writes the client limits. A client cgroup is set up once by the server; one left by a
+// previous server is set up again so that it gets the current client limits.
+func (h *Hierarchy) CreateClientCgroup(clientID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[clientID] {
+		return nil
+	}
+
+	clientPath := h.ClientPath(clientID)
+	if err := os.Mkdir(clientPath, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("failed to create client cgroup %s: %w", clientPath, err)
+	}
+	if err := h.setupClientCgroup(clientPath); err != nil {
+		return err
+	}
+	h.clients[clientID] = true
+	return nil
+}
+
+// setupClientCgroup enables the controllers of the parent in a client cgroup and writes the client limits
+func (h *Hierarchy) setupClientCgroup(clientPath string) error {
+	data, err := os.ReadFile(filepath.Join(h.parent, "cgroup.subtree_control"))
+	if err != nil {
+		return fmt.Errorf("failed to read the controllers of %s: %w", h.parent, err)
+	}
+	if err := CheckAndEnableCgroupV2Controllers(filepath.Join(clientPath, "cgroup.subtree_control"), strings.Fields(string(data))); err != nil {
+		return err
+	}
+	return SetCgroupLimits(clientPath, h.clientLimits, nil)
+}
+
+// ControllerEnabled reports whether the controller is enabled for the client cgroups, and so for the
+// task cgroups, below the parent
+func (h *Hierarchy) ControllerEnabled(controller string) bool {
+	data, err := os.ReadFile(filepath.Join(h.parent, "cgroup.subtree_control"))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(strings.Fields(string(data)), controller)
+}
+
+// CheckWritable checks that client and task cgroups can be created under the parent cgroup by
+// creating and removing a scratch cgroup; the probe prefix keeps it apart from the client cgroups
+func (h *Hierarchy) CheckWritable() error {
+	scratch, err := os.MkdirTemp(h.parent, probePrefix)
+	if err != nil {
+		return fmt.Errorf("failed to create a cgroup under %s: %w", h.parent, err)
+	}
+	if err := os.Remove(scratch); err != nil {
+		return fmt.Errorf("failed to remove cgroup %s: %w", scratch, err)
+	}
+	return nil
+}
+
+// ownCgroup returns the absolute path of the cgroup v2 of the server process
+func ownCgroup() (string, error) {
+	f, err := os.Open(procSelfCgroup)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// the cgroup v2 hierarchy has ID 0 and no controllers: 0::/system.slice/taskman.service
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return filepath.Join(cgroupRoot, path), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no cgroup v2 in %s", procSelfCgroup)
+}
+
+// isDelegated reports whether systemd delegated the cgroup, which it marks with a delegate extended attribute
+func isDelegated(path string) bool {
+	for _, attr := range []string{"trusted.delegate", "user.delegate"} {
+		buf := make([]byte, 8)
+		if n, err := unix.Getxattr(path, attr, buf); err == nil && string(buf[:n]) == "1" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHierarchy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		parent      string
+		expected    string
+		expectedErr bool
+	}{
+		{desc: "absolute", parent: "/sys/fs/cgroup/taskman.slice", expected: "/sys/fs/cgroup/taskman.slice"},
+		{desc: "relative to the mount", parent: "system.slice/taskman.service/tasks", expected: "/sys/fs/cgroup/system.slice/taskman.service/tasks"},
+		{desc: "trailing slash", parent: "/sys/fs/cgroup/taskman.slice/", expected: "/sys/fs/cgroup/taskman.slice"},
+		{desc: "cgroup root", parent: "/sys/fs/cgroup", expectedErr: true},
+		{desc: "outside the mount", parent: "/tmp/taskman", expectedErr: true},
+		{desc: "escapes the mount", parent: "../taskman", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			hierarchy, err := NewHierarchy(tt.parent, Limits{})
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hierarchy.Parent())
+		})
+	}
+}
+
+func TestHierarchyPaths(t *testing.T) {
+	t.Parallel()
+
+	hierarchy := DefaultHierarchy()
+	assert.Equal(t, "/sys/fs/cgroup/taskman.slice/client-client001", hierarchy.ClientPath("client001"))
+	assert.Equal(t, "/sys/fs/cgroup/taskman.slice/client-client001/a7da14c7", hierarchy.TaskPath("client001", "a7da14c7"))
+	// client IDs cannot leave the parent or name another cgroup
+	assert.Equal(t, "/sys/fs/cgroup/taskman.slice/client-..%2Fother", hierarchy.ClientPath("../other"))
+	assert.Equal(t, "/sys/fs/cgroup/taskman.slice/client-..", hierarchy.ClientPath(".."))
+}
+
+func TestControllerEnabled(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("cpu memory io\n"), 0644))
+	hierarchy := &Hierarchy{parent: parent, clients: make(map[string]bool)}
+	assert.True(t, hierarchy.ControllerEnabled("memory"))
+	assert.False(t, hierarchy.ControllerEnabled("pids"))
+
+	hierarchy = &Hierarchy{parent: filepath.Join(parent, "missing"), clients: make(map[string]bool)}
+	assert.False(t, hierarchy.ControllerEnabled("memory"))
+}
+
+func TestCheckWritable(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	hierarchy := &Hierarchy{parent: parent, clients: make(map[string]bool)}
+	require.NoError(t, hierarchy.CheckWritable())
+	// the scratch cgroup is removed again
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	hierarchy = &Hierarchy{parent: filepath.Join(parent, "missing"), clients: make(map[string]bool)}
+	assert.Error(t, hierarchy.CheckWritable())
+}
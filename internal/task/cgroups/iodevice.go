@@ -0,0 +1,168 @@
+package cgroups
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// IODeviceWorkDir selects the block device backing the working directory of the tasks, which is the
+// working directory of the server
+const IODeviceWorkDir = "workdir"
+
+const (
+	mountInfoPath   = "/proc/self/mountinfo"
+	sysBlockPath    = "/sys/block"
+	sysDevBlockPath = "/sys/dev/block"
+)
+
+// ResolveIODevices resolves block devices to the major:minor numbers that io.max limits. A device is
+// given by the path of a block device such as /dev/nvme0n1, by any other path such as a mount point,
+// which selects the device backing its file system, or by IODeviceWorkDir. Partitions resolve to their
+// disk since io.max only limits whole disks. Duplicates are removed.
+func ResolveIODevices(specs []string) ([]string, error) {
+	devices := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		device, err := resolveIODevice(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve io device %q: %w", spec, err)
+		}
+		if !slices.Contains(devices, device) {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+// resolveIODevice resolves a single device spec to the major:minor number of a disk
+func resolveIODevice(spec string) (string, error) {
+	path := spec
+	if spec == IODeviceWorkDir {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get the working directory: %w", err)
+		}
+		path = wd
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if st.Mode&unix.S_IFMT == unix.S_IFBLK {
+		return wholeDisk(fmt.Sprintf("%d:%d", unix.Major(st.Rdev), unix.Minor(st.Rdev)))
+	}
+
+	device, err := backingDevice(path)
+	if err != nil {
+		return "", err
+	}
+	return wholeDisk(device)
+}
+
+// mountEntry is a mount of /proc/self/mountinfo
+type mountEntry struct {
+	// device is the major:minor number of the file system, which is not a block device for file
+	// systems such as btrfs, overlay or tmpfs
+	device     string
+	mountPoint string
+	fsType     string
+	source     string
+}
+
+// findMount returns the mount of the mountinfo that contains the absolute path: the mount with the
+// longest mount point that is a prefix of the path, and the last one of those if it is mounted over
+func findMount(mountInfo io.Reader, path string) (mountEntry, error) {
+	var found mountEntry
+	ok := false
+	scanner := bufio.NewScanner(mountInfo)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(scanner.Text())
+		sep := slices.Index(fields, "-")
+		if sep < 5 || len(fields) < sep+3 {
+			continue
+		}
+		entry := mountEntry{
+			device:     fields[2],
+			mountPoint: unescapeMountInfo(fields[4]),
+			fsType:     fields[sep+1],
+			source:     unescapeMountInfo(fields[sep+2]),
+		}
+		contains := entry.mountPoint == "/" || path == entry.mountPoint || strings.HasPrefix(path, entry.mountPoint+"/")
+		if contains && (!ok || len(entry.mountPoint) >= len(found.mountPoint)) {
+			found, ok = entry, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return mountEntry{}, err
+	}
+	if !ok {
+		return mountEntry{}, fmt.Errorf("no mount contains %s", path)
+	}
+	return found, nil
+}
+
+// unescapeMountInfo replaces the octal escapes of spaces, tabs, newlines and backslashes in mountinfo
+func unescapeMountInfo(s string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(s)
+}
+
+// backingDevice returns the major:minor number of the block device backing the file system of the path
+func backingDevice(path string) (string, error) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", mountInfoPath, err)
+	}
+	defer f.Close()
+	mount, err := findMount(f, path)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filepath.Join(sysDevBlockPath, mount.device)); err == nil {
+		return mount.device, nil
+	}
+	// file systems such as btrfs report an anonymous device so the device is taken from the mount source
+	var st unix.Stat_t
+	if strings.HasPrefix(mount.source, "/dev/") && unix.Stat(mount.source, &st) == nil && st.Mode&unix.S_IFMT == unix.S_IFBLK {
+		return fmt.Sprintf("%d:%d", unix.Major(st.Rdev), unix.Minor(st.Rdev)), nil
+	}
+	return "", fmt.Errorf("no block device backs %s: it is on a %s file system mounted from %s at %s",
+		path, mount.fsType, mount.source, mount.mountPoint)
+}
+
+// wholeDisk returns the major:minor number of the disk of a block device, which is the device
+// itself unless it is a partition. The disk must be listed in /sys/block.
+func wholeDisk(device string) (string, error) {
+	sysPath, err := filepath.EvalSymlinks(filepath.Join(sysDevBlockPath, device))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a block device: %w", device, err)
+	}
+	if _, err := os.Stat(filepath.Join(sysPath, "partition")); err == nil {
+		sysPath = filepath.Dir(sysPath)
+	}
+	if _, err := os.Stat(filepath.Join(sysBlockPath, filepath.Base(sysPath))); err != nil {
+		return "", fmt.Errorf("block device %s is not a disk: %w", filepath.Base(sysPath), err)
+	}
+	data, err := os.ReadFile(filepath.Join(sysPath, "dev"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the device number of %s: %w", filepath.Base(sysPath), err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,52 @@
+package cgroups
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMountInfo = `22 1 254:0 / / rw,relatime shared:1 - ext4 /dev/vda rw
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:2 - proc proc rw
+24 22 0:22 / /tmp rw,nosuid,nodev shared:3 - tmpfs tmpfs rw
+25 22 259:2 / /data rw,relatime shared:4 - xfs /dev/nvme0n1p2 rw
+26 25 0:35 / /data/my\040disk rw,relatime shared:5 - btrfs /dev/sdb1 rw
+27 24 0:22 / /tmp rw,nosuid,nodev shared:6 - ext4 /dev/sdc rw
+`
+
+func TestFindMount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		path       string
+		mountPoint string
+		device     string
+		source     string
+	}{
+		{desc: "root", path: "/", mountPoint: "/", device: "254:0", source: "/dev/vda"},
+		{desc: "file on root", path: "/home/user/file", mountPoint: "/", device: "254:0", source: "/dev/vda"},
+		{desc: "mount point", path: "/data", mountPoint: "/data", device: "259:2", source: "/dev/nvme0n1p2"},
+		{desc: "below a mount point", path: "/data/tasks", mountPoint: "/data", device: "259:2", source: "/dev/nvme0n1p2"},
+		{desc: "prefix of a mount point", path: "/database", mountPoint: "/", device: "254:0", source: "/dev/vda"},
+		{desc: "escaped mount point", path: "/data/my disk/x", mountPoint: "/data/my disk", device: "0:35", source: "/dev/sdb1"},
+		{desc: "mounted over", path: "/tmp/x", mountPoint: "/tmp", device: "0:22", source: "/dev/sdc"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			mount, err := findMount(strings.NewReader(testMountInfo), tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.mountPoint, mount.mountPoint)
+			assert.Equal(t, tt.device, mount.device)
+			assert.Equal(t, tt.source, mount.source)
+		})
+	}
+
+	_, err := findMount(strings.NewReader(""), "/data")
+	assert.Error(t, err)
+}
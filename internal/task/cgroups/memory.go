@@ -0,0 +1,213 @@
+package cgroups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// memoryWatchInterval is how often a MemoryWatcher checks whether its context is done
+const memoryWatchInterval = 250 * time.Millisecond
+
+// MemoryEvents are the counters of memory.events of a cgroup
+type MemoryEvents struct {
+	// High is how often the processes were throttled and reclaimed because the usage exceeded memory.high
+	High int64
+	// Max is how often the usage reached memory.max
+	Max int64
+	// OOMKill is the number of processes killed by the OOM killer
+	OOMKill int64
+}
+
+// MemoryPressure is the pressure stall information (PSI) of memory.pressure: the percentage of time in
+// which some or all processes of a cgroup stalled waiting for memory over the last 10 seconds
+type MemoryPressure struct {
+	Some float64
+	Full float64
+}
+
+// PressureTrigger is a PSI trigger of memory.pressure that fires when some, or all if Full is set,
+// processes of a cgroup stall on memory for Stall within Window. The kernel accepts windows from 500ms
+// to 10s and fires a trigger at most once per window.
+type PressureTrigger struct {
+	Full   bool
+	Stall  time.Duration
+	Window time.Duration
+}
+
+// String renders the trigger as written to memory.pressure, e.g. "some 100000 1000000"
+func (t PressureTrigger) String() string {
+	kind := "some"
+	if t.Full {
+		kind = "full"
+	}
+	return fmt.Sprintf("%s %d %d", kind, t.Stall.Microseconds(), t.Window.Microseconds())
+}
+
+// ReadMemoryEvents reads the counters of memory.events of a cgroup
+func ReadMemoryEvents(cgroupPath string) (MemoryEvents, error) {
+	eventsPath := filepath.Join(cgroupPath, "memory.events")
+	data, err := os.ReadFile(eventsPath)
+	if err != nil {
+		return MemoryEvents{}, fmt.Errorf("failed to read %s: %w", eventsPath, err)
+	}
+	return parseMemoryEvents(string(data))
+}
+
+func parseMemoryEvents(data string) (MemoryEvents, error) {
+	var events MemoryEvents
+	for _, f := range []struct {
+		key   string
+		value *int64
+	}{
+		{"high", &events.High},
+		{"max", &events.Max},
+		{"oom_kill", &events.OOMKill},
+	} {
+		value := cgroupEventValue(data, f.key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return MemoryEvents{}, fmt.Errorf("invalid memory.events %s value %q", f.key, value)
+		}
+		*f.value = n
+	}
+	return events, nil
+}
+
+// ReadMemoryPressure reads the 10 second averages of memory.pressure of a cgroup
+func ReadMemoryPressure(cgroupPath string) (MemoryPressure, error) {
+	pressurePath := filepath.Join(cgroupPath, "memory.pressure")
+	data, err := os.ReadFile(pressurePath)
+	if err != nil {
+		return MemoryPressure{}, fmt.Errorf("failed to read %s: %w", pressurePath, err)
+	}
+	return parseMemoryPressure(string(data))
+}
+
+// parseMemoryPressure parses the avg10 values of lines such as "some avg10=1.50 avg60=0.30 avg300=0.06 total=1234"
+func parseMemoryPressure(data string) (MemoryPressure, error) {
+	var pressure MemoryPressure
+	for line := range strings.SplitSeq(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var target *float64
+		switch fields[0] {
+		case "some":
+			target = &pressure.Some
+		case "full":
+			target = &pressure.Full
+		default:
+			continue
+		}
+		value, ok := strings.CutPrefix(fields[1], "avg10=")
+		if !ok {
+			return MemoryPressure{}, fmt.Errorf("invalid memory.pressure line %q", line)
+		}
+		avg, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return MemoryPressure{}, fmt.Errorf("invalid memory.pressure line %q", line)
+		}
+		*target = avg
+	}
+	return pressure, nil
+}
+
+// MemoryWatcher waits for changes of the memory.events counters of a cgroup, which the kernel signals
+// to poll with POLLPRI, and for a PSI trigger of its memory.pressure
+type MemoryWatcher struct {
+	events   *os.File
+	pressure *os.File
+	buf      []byte
+}
+
+// NewMemoryWatcher opens memory.events of a cgroup
+func NewMemoryWatcher(cgroupPath string) (*MemoryWatcher, error) {
+	eventsPath := filepath.Join(cgroupPath, "memory.events")
+	events, err := os.Open(eventsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", eventsPath, err)
+	}
+	return &MemoryWatcher{events: events, buf: make([]byte, 512)}, nil
+}
+
+// AddPressureTrigger registers the trigger in memory.pressure of the cgroup. It fails if the kernel has
+// PSI disabled, in which case the watcher only watches memory.events.
+func (w *MemoryWatcher) AddPressureTrigger(cgroupPath string, trigger PressureTrigger) error {
+	pressurePath := filepath.Join(cgroupPath, "memory.pressure")
+	pressure, err := os.OpenFile(pressurePath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", pressurePath, err)
+	}
+	// the trigger lives as long as the file stays open
+	if _, err := pressure.WriteString(trigger.String()); err != nil {
+		pressure.Close()
+		return fmt.Errorf("failed to add trigger %q to %s: %w", trigger, pressurePath, err)
+	}
+	w.pressure = pressure
+	return nil
+}
+
+// Events reads the counters of memory.events. Reading them rearms the notification of the next change.
+func (w *MemoryWatcher) Events() (MemoryEvents, error) {
+	n, err := unix.Pread(int(w.events.Fd()), w.buf, 0)
+	if err != nil {
+		return MemoryEvents{}, fmt.Errorf("failed to read %s: %w", w.events.Name(), err)
+	}
+	return parseMemoryEvents(string(w.buf[:n]))
+}
+
+// Wait blocks until the counters of memory.events may have changed or the pressure trigger fired and
+// returns the counters and whether the trigger fired. It returns the error of ctx once ctx is done.
+func (w *MemoryWatcher) Wait(ctx context.Context) (MemoryEvents, bool, error) {
+	fds := []unix.PollFd{{Fd: int32(w.events.Fd()), Events: unix.POLLPRI}}
+	if w.pressure != nil {
+		fds = append(fds, unix.PollFd{Fd: int32(w.pressure.Fd()), Events: unix.POLLPRI})
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return MemoryEvents{}, false, err
+		}
+		n, err := unix.Poll(fds, int(memoryWatchInterval.Milliseconds()))
+		if errors.Is(err, unix.EINTR) || n == 0 {
+			continue
+		}
+		if err != nil {
+			return MemoryEvents{}, false, fmt.Errorf("failed to poll memory events: %w", err)
+		}
+		pressured := len(fds) > 1 && fds[1].Revents != 0
+		if pressured && fds[1].Revents&unix.POLLERR != 0 {
+			return MemoryEvents{}, false, fmt.Errorf("memory pressure trigger of %s failed", w.pressure.Name())
+		}
+		// kernfs reports a change as POLLPRI together with POLLERR
+		if fds[0].Revents == 0 && !pressured {
+			continue
+		}
+		events, err := w.Events()
+		if err != nil {
+			return MemoryEvents{}, false, err
+		}
+		return events, pressured, nil
+	}
+}
+
+// Close closes memory.events and memory.pressure, which removes the pressure trigger
+func (w *MemoryWatcher) Close() error {
+	var errs []error
+	if w.pressure != nil {
+		errs = append(errs, w.pressure.Close())
+	}
+	errs = append(errs, w.events.Close())
+	return errors.Join(errs...)
+}
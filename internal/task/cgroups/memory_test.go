@@ -0,0 +1,66 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMemoryEvents(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory.events"),
+		[]byte("low 0\nhigh 12\nmax 3\noom 1\noom_kill 1\noom_group_kill 0\n"), 0644))
+	events, err := ReadMemoryEvents(dir)
+	require.NoError(t, err)
+	assert.Equal(t, MemoryEvents{High: 12, Max: 3, OOMKill: 1}, events)
+
+	_, err = ReadMemoryEvents(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestParseMemoryPressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		data        string
+		expected    MemoryPressure
+		expectedErr bool
+	}{
+		{
+			desc:     "some and full",
+			data:     "some avg10=12.50 avg60=3.10 avg300=0.64 total=1532710\nfull avg10=8.02 avg60=1.97 avg300=0.40 total=1012345\n",
+			expected: MemoryPressure{Some: 12.5, Full: 8.02},
+		},
+		{desc: "no pressure", data: "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n", expected: MemoryPressure{}},
+		{desc: "malformed", data: "some total=0\n", expectedErr: true},
+		{desc: "not a number", data: "full avg10=high\n", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			pressure, err := parseMemoryPressure(tt.data)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pressure)
+		})
+	}
+}
+
+func TestPressureTriggerString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "some 100000 1000000", PressureTrigger{Stall: 100 * time.Millisecond, Window: time.Second}.String())
+	assert.Equal(t, "full 500000 2000000", PressureTrigger{Full: true, Stall: 500 * time.Millisecond, Window: 2 * time.Second}.String())
+}
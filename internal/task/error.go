@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -26,13 +27,27 @@ const (
 	ErrNotAvailable
 	// ErrCanceled indicates that the task was canceled
 	ErrCanceled
+	// ErrAlreadyExists indicates that a conflicting resource already exists
+	ErrAlreadyExists
+	// ErrResourceExhausted indicates that a quota or limit has been reached
+	ErrResourceExhausted
 )
 
+// QuotaViolation describes a quota that rejected a request
+type QuotaViolation struct {
+	// Subject is the scope of the quota e.g. "client:client001" or "global"
+	Subject string
+	// Description says which limit was reached
+	Description string
+}
+
 // TaskError represents an error that occurred during task management
 type TaskError struct {
 	Code    ErrorCode
 	Message string
 	Err     error
+	// Violations lists the quotas that were reached for ErrResourceExhausted errors
+	Violations []QuotaViolation
 }
 
 func (e *TaskError) Error() string {
@@ -63,6 +78,15 @@ func NewTaskErrorWithErr(code ErrorCode, format string, err error, args ...any)
 	}
 }
 
+// NewQuotaError creates an ErrResourceExhausted TaskError for the quota that was reached
+func NewQuotaError(violation QuotaViolation, format string, args ...any) *TaskError {
+	return &TaskError{
+		Code:       ErrResourceExhausted,
+		Message:    fmt.Sprintf(format, args...),
+		Violations: []QuotaViolation{violation},
+	}
+}
+
 // TaskErrorToGRPC converts a TaskError to a gRPC status error
 func TaskErrorToGRPC(err error) error {
 	var taskErr *TaskError
@@ -83,10 +107,28 @@ func TaskErrorToGRPC(err error) error {
 			code = codes.Unavailable
 		case ErrCanceled:
 			code = codes.Canceled
+		case ErrAlreadyExists:
+			code = codes.AlreadyExists
+		case ErrResourceExhausted:
+			code = codes.ResourceExhausted
 		default:
 			code = codes.Internal
 		}
-		return status.Error(code, taskErr.Error())
+		st := status.New(code, taskErr.Error())
+		if len(taskErr.Violations) > 0 {
+			failure := &errdetails.QuotaFailure{}
+			for _, v := range taskErr.Violations {
+				failure.Violations = append(failure.Violations, &errdetails.QuotaFailure_Violation{
+					Subject:     v.Subject,
+					Description: v.Description,
+				})
+			}
+			// the error is still returned without the details if they cannot be attached
+			if withDetails, err := st.WithDetails(failure); err == nil {
+				st = withDetails
+			}
+		}
+		return st.Err()
 	}
 	// If it's not a TaskError, return it as an internal error
 	return status.Error(codes.Internal, err.Error())
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Limits of the labels of a single task
+const (
+	MaxLabels           = 64
+	MaxLabelNameLength  = 63
+	MaxLabelPrefixLen   = 253
+	MaxLabelValueLength = 63
+	// MaxLabelsSize is the total size in bytes of all keys and values
+	MaxLabelsSize = 4096
+)
+
+var (
+	// labelNameRegexp matches a label name or value: alphanumerics, '-', '_' and '.' starting and ending with an alphanumeric
+	labelNameRegexp = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	// labelPrefixRegexp matches the optional DNS subdomain prefix of a label key e.g. "example.com"
+	labelPrefixRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateLabels checks the keys, values and size of task labels. Keys are a name with an optional
+// DNS prefix such as "team" or "example.com/build"; values follow the rules of names and may be empty.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return NewTaskError(ErrInvalidArgument, "too many labels: %d, at most %d are allowed", len(labels), MaxLabels)
+	}
+
+	size := 0
+	for key, value := range labels {
+		if err := validateLabelKey(key); err != nil {
+			return err
+		}
+		if value != "" && (len(value) > MaxLabelValueLength || !labelNameRegexp.MatchString(value)) {
+			return NewTaskError(ErrInvalidArgument,
+				"invalid value %q of label %q: must be at most %d alphanumeric, '-', '_' or '.' characters starting and ending with an alphanumeric",
+				value, key, MaxLabelValueLength)
+		}
+		size += len(key) + len(value)
+	}
+	if size > MaxLabelsSize {
+		return NewTaskError(ErrInvalidArgument, "labels are too large: %d bytes, at most %d are allowed", size, MaxLabelsSize)
+	}
+	return nil
+}
+
+// validateLabelKey checks a label key of the form [prefix/]name
+func validateLabelKey(key string) error {
+	name := key
+	prefix, suffix, hasPrefix := strings.Cut(key, "/")
+	if hasPrefix {
+		name = suffix
+	}
+	if hasPrefix && (prefix == "" || len(prefix) > MaxLabelPrefixLen || !labelPrefixRegexp.MatchString(prefix)) {
+		return NewTaskError(ErrInvalidArgument, "invalid prefix of label key %q: must be a DNS subdomain of at most %d characters",
+			key, MaxLabelPrefixLen)
+	}
+	if len(name) > MaxLabelNameLength || !labelNameRegexp.MatchString(name) {
+		return NewTaskError(ErrInvalidArgument,
+			"invalid label key %q: name must be 1-%d alphanumeric, '-', '_' or '.' characters starting and ending with an alphanumeric",
+			key, MaxLabelNameLength)
+	}
+	return nil
+}
+
+// selectorOperator is the comparison of a selector requirement
+type selectorOperator int
+
+const (
+	selectorEquals selectorOperator = iota
+	selectorNotEquals
+	selectorExists
+	selectorNotExists
+)
+
+// selectorRequirement is a single comma-separated term of a label selector
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	value    string
+}
+
+// Selector matches tasks by their labels. All requirements must match; the zero Selector matches every task.
+type Selector struct {
+	requirements []selectorRequirement
+}
+
+// ParseSelector parses a comma-separated label selector. Each requirement is one of "key=value",
+// "key==value", "key!=value", "key" (the label is set) or "!key" (the label is not set), e.g.
+// "team=infra,env!=prod". An empty selector matches every task.
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	if strings.TrimSpace(selector) == "" {
+		return s, nil
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var req selectorRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			req.key, req.value, _ = strings.Cut(term, "!=")
+			req.operator = selectorNotEquals
+		case strings.Contains(term, "=="):
+			req.key, req.value, _ = strings.Cut(term, "==")
+			req.operator = selectorEquals
+		case strings.Contains(term, "="):
+			req.key, req.value, _ = strings.Cut(term, "=")
+			req.operator = selectorEquals
+		case strings.HasPrefix(term, "!"):
+			req.key = strings.TrimPrefix(term, "!")
+			req.operator = selectorNotExists
+		default:
+			req.key = term
+			req.operator = selectorExists
+		}
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+
+		if err := validateLabelKey(req.key); err != nil {
+			return Selector{}, NewTaskError(ErrInvalidArgument, "invalid label selector %q: %s", selector, err.Error())
+		}
+		if req.value != "" && !labelNameRegexp.MatchString(req.value) {
+			return Selector{}, NewTaskError(ErrInvalidArgument, "invalid label selector %q: invalid value %q", selector, req.value)
+		}
+		s.requirements = append(s.requirements, req)
+	}
+	return s, nil
+}
+
+// Matches reports whether the labels satisfy all requirements of the selector
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s.requirements {
+		value, ok := labels[req.key]
+		switch req.operator {
+		case selectorEquals:
+			if !ok || value != req.value {
+				return false
+			}
+		case selectorNotEquals:
+			if ok && value == req.value {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Empty reports whether the selector has no requirements and so matches every task
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
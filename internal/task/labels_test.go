@@ -0,0 +1,106 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLabels(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make(map[string]string, MaxLabels+1)
+	for i := range MaxLabels + 1 {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	tooLarge := make(map[string]string)
+	for i := range MaxLabels {
+		tooLarge[strings.Repeat("k", 10)+string(rune('a'+i%26))+strings.Repeat("x", i/26)] = strings.Repeat("v", MaxLabelValueLength)
+	}
+
+	tests := []struct {
+		desc        string
+		labels      map[string]string
+		expectedErr string
+	}{
+		{desc: "no labels", labels: nil},
+		{desc: "valid labels", labels: map[string]string{"team": "infra", "example.com/build": "1234", "empty": ""}},
+		{desc: "empty key", labels: map[string]string{"": "v"}, expectedErr: "invalid label key"},
+		{desc: "invalid key characters", labels: map[string]string{"team name": "v"}, expectedErr: "invalid label key"},
+		{desc: "key too long", labels: map[string]string{strings.Repeat("k", MaxLabelNameLength+1): "v"}, expectedErr: "invalid label key"},
+		{desc: "invalid prefix", labels: map[string]string{"Example.com/build": "v"}, expectedErr: "invalid prefix"},
+		{desc: "empty prefix", labels: map[string]string{"/build": "v"}, expectedErr: "invalid prefix"},
+		{desc: "invalid value", labels: map[string]string{"team": "infra,prod"}, expectedErr: "invalid value"},
+		{desc: "value too long", labels: map[string]string{"team": strings.Repeat("v", MaxLabelValueLength+1)}, expectedErr: "invalid value"},
+		{desc: "too many labels", labels: tooMany, expectedErr: "too many labels"},
+		{desc: "labels too large", labels: tooLarge, expectedErr: "labels are too large"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateLabels(tt.labels)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+			var taskErr *TaskError
+			require.ErrorAs(t, err, &taskErr)
+			assert.Equal(t, ErrInvalidArgument, taskErr.Code)
+		})
+	}
+}
+
+func TestSelector(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{"team": "infra", "env": "staging", "example.com/build": "1234"}
+
+	tests := []struct {
+		desc     string
+		selector string
+		expected bool
+	}{
+		{desc: "empty selector", selector: "", expected: true},
+		{desc: "equals", selector: "team=infra", expected: true},
+		{desc: "double equals", selector: "team==infra", expected: true},
+		{desc: "equals mismatch", selector: "team=web", expected: false},
+		{desc: "equals missing label", selector: "owner=me", expected: false},
+		{desc: "not equals", selector: "env!=prod", expected: true},
+		{desc: "not equals mismatch", selector: "env!=staging", expected: false},
+		{desc: "not equals missing label", selector: "owner!=me", expected: true},
+		{desc: "exists", selector: "example.com/build", expected: true},
+		{desc: "exists missing label", selector: "owner", expected: false},
+		{desc: "not exists", selector: "!owner", expected: true},
+		{desc: "not exists mismatch", selector: "!team", expected: false},
+		{desc: "all requirements match", selector: "team=infra, env!=prod,example.com/build=1234", expected: true},
+		{desc: "one requirement does not match", selector: "team=infra,env=prod", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			selector, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selector.Matches(labels))
+		})
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, selector := range []string{"=infra", "team=in fra", "team=infra,", "!", "bad key=v"} {
+		_, err := ParseSelector(selector)
+		require.Error(t, err, selector)
+		var taskErr *TaskError
+		require.ErrorAs(t, err, &taskErr)
+		assert.Equal(t, ErrInvalidArgument, taskErr.Code)
+	}
+}
@@ -0,0 +1,23 @@
+package task
+
+import (
+	"context"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// DeleteTask removes a finished task and releases its output before the retention policy would.
+// Running tasks must be stopped first. The gRPC handler checks that the caller may access the task.
+func (tm *TaskManager) DeleteTask(_ context.Context, taskID string) error {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return err
+	}
+
+	if !task.finished() {
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task is still running; stop it before deleting it")
+	}
+
+	tm.evictTask(taskID, EvictReasonDelete)
+	return nil
+}
@@ -0,0 +1,30 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestDeleteTask(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	task := CreateNewTask("task", "client001", 4242, time.Now(), NewTaskWriter())
+	tm.addTask(task)
+
+	var taskErr *basetask.TaskError
+	require.ErrorAs(t, tm.DeleteTask(context.Background(), "task"), &taskErr)
+	assert.Equal(t, basetask.ErrFailedPrecondition, taskErr.Code)
+
+	// the manager deletes the task for any caller: the gRPC handler checks the access
+	close(task.done)
+	require.NoError(t, tm.DeleteTask(context.Background(), "task"))
+	require.ErrorAs(t, tm.DeleteTask(context.Background(), "task"), &taskErr)
+	assert.Equal(t, basetask.ErrNotFound, taskErr.Code)
+}
@@ -0,0 +1,176 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// MaxDependencies limits the number of tasks a task may depend on
+const MaxDependencies = 64
+
+// terminationSourceDependency is the termination source of a task whose dependency did not meet its condition
+const terminationSourceDependency = "dependency"
+
+// DependencyCondition is the outcome of a dependency that lets the dependent task start
+type DependencyCondition int
+
+const (
+	// DependsOnSuccess requires the dependency to exit with exit code 0
+	DependsOnSuccess DependencyCondition = iota
+	// DependsOnCompletion requires the dependency to finish with any outcome
+	DependsOnCompletion
+	// DependsOnFailure requires the dependency to finish with any outcome other than exit code 0
+	DependsOnFailure
+)
+
+// String returns the name of the condition
+func (c DependencyCondition) String() string {
+	switch c {
+	case DependsOnSuccess:
+		return "success"
+	case DependsOnCompletion:
+		return "completion"
+	case DependsOnFailure:
+		return "failure"
+	default:
+		return fmt.Sprintf("DependencyCondition(%d)", int(c))
+	}
+}
+
+// metBy reports whether a dependency that finished with the status meets the condition
+func (c DependencyCondition) metBy(status int) bool {
+	switch c {
+	case DependsOnCompletion:
+		return true
+	case DependsOnFailure:
+		return status != basetask.JobStatusExitedOK
+	default:
+		return status == basetask.JobStatusExitedOK
+	}
+}
+
+// Dependency is a task that must finish with the condition before the dependent task starts
+type Dependency struct {
+	TaskID    string
+	Condition DependencyCondition
+}
+
+// resolveDependencies checks the dependencies of a new task and returns the tasks they name. Every
+// dependency must be an existing task visible to the client, named at most once, and must not lead
+// back to taskID.
+func (tm *TaskManager) resolveDependencies(clientID, taskID string, dependsOn []Dependency) ([]*Task, error) {
+	if len(dependsOn) > MaxDependencies {
+		return nil, basetask.NewTaskError(basetask.ErrInvalidArgument, "a task may depend on at most %d tasks", MaxDependencies)
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	tasks := make([]*Task, 0, len(dependsOn))
+	for i, dep := range dependsOn {
+		if dep.Condition < DependsOnSuccess || dep.Condition > DependsOnFailure {
+			return nil, basetask.NewTaskError(basetask.ErrInvalidArgument, "unknown condition of dependency %s", dep.TaskID)
+		}
+		if slices.ContainsFunc(dependsOn[:i], func(d Dependency) bool { return d.TaskID == dep.TaskID }) {
+			return nil, basetask.NewTaskError(basetask.ErrInvalidArgument, "dependency %s is given more than once", dep.TaskID)
+		}
+		task, ok := tm.tasksMapByID[dep.TaskID]
+		// tasks of other clients are reported as unknown so that their IDs are not disclosed
+		if !ok || (task.GetClientID() != clientID && clientID != "admin") {
+			return nil, basetask.NewTaskError(basetask.ErrInvalidArgument, "unknown dependency %s", dep.TaskID)
+		}
+		tasks = append(tasks, task)
+	}
+
+	cycle := findDependencyCycle(taskID, dependsOn, func(id string) []Dependency {
+		if task, ok := tm.tasksMapByID[id]; ok {
+			return task.dependencies
+		}
+		return nil
+	})
+	if cycle != nil {
+		return nil, basetask.NewTaskError(basetask.ErrInvalidArgument, "dependency cycle: %v", cycle)
+	}
+	return tasks, nil
+}
+
+// findDependencyCycle returns a path of task IDs from taskID back to itself through the dependencies, or
+// nil if there is none. Dependencies must exist when a task is submitted so a new task cannot close a cycle
+// through its own ID; the check protects the graph walk of the waiting tasks from ever looping.
+func findDependencyCycle(taskID string, dependsOn []Dependency, dependenciesOf func(string) []Dependency) []string {
+	visited := make(map[string]bool)
+	var walk func(path []string, deps []Dependency) []string
+	walk = func(path []string, deps []Dependency) []string {
+		for _, dep := range deps {
+			if dep.TaskID == taskID {
+				return append(slices.Clone(path), dep.TaskID)
+			}
+			if visited[dep.TaskID] {
+				continue
+			}
+			visited[dep.TaskID] = true
+			if cycle := walk(append(path, dep.TaskID), dependenciesOf(dep.TaskID)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return walk([]string{taskID}, dependsOn)
+}
+
+// awaitDependencies waits until every dependency of the task has finished and then starts the task like a
+// new start. The task fails with the termination source "dependency" as soon as a dependency finishes
+// without meeting its condition, and is finished as stopped if it is stopped (canceled is closed) or the
+// manager shuts down while waiting.
+func (tm *TaskManager) awaitDependencies(logger *slog.Logger, task *Task, deps []*Task, canceled <-chan struct{}) {
+	defer metrics.TasksWaiting.Dec()
+
+	var unmet error
+	for i, dep := range deps {
+		select {
+		case <-dep.Done():
+		case <-canceled:
+		case <-tm.ctx.Done():
+		}
+		if tm.ctx.Err() != nil {
+			break
+		}
+		if dep.GetEndTime().IsZero() {
+			// the wait was canceled before the dependency finished
+			break
+		}
+		condition := task.dependencies[i].Condition
+		if status := dep.GetStatus(); !condition.metBy(status) {
+			protoStatus, _ := basetask.StatusToProto(status)
+			unmet = fmt.Errorf("dependency %s finished with %s and did not meet the condition %s", dep.GetID(), protoStatus, condition)
+			break
+		}
+	}
+
+	source, stopped := task.endWait()
+	switch {
+	case stopped || tm.ctx.Err() != nil:
+		if !stopped {
+			source = "system"
+		}
+		logger.Info("task stopped while waiting for its dependencies", "termination_source", source)
+		tm.finishUnstarted(task, basetask.JobStatusCanceled, source, audit.ActionCancel, audit.OutcomeSuccess)
+	case unmet != nil:
+		logger.Info("task not started", "reason", unmet)
+		if _, err := fmt.Fprintf(task.getWriter(), "--- taskman: %v ---\n", unmet); err != nil {
+			logger.Error("failed to write dependency failure", "error", err)
+		}
+		tm.finishUnstarted(task, basetask.JobStatusFailed, terminationSourceDependency, audit.ActionStart, audit.OutcomeFailure)
+	default:
+		logger.Info("dependencies of the task are met", "command", task.spec.Command, "args", task.spec.Args)
+		if err := tm.submit(logger, task); err != nil {
+			logger.Error("failed to start task after its dependencies", "error", err)
+			tm.finishUnstarted(task, basetask.JobStatusUnknown, terminationSourceStartFailed, audit.ActionStart, audit.OutcomeFailure)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestDependencyConditionMetBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		condition DependencyCondition
+		status    int
+		expected  bool
+	}{
+		{desc: "success met", condition: DependsOnSuccess, status: basetask.JobStatusExitedOK, expected: true},
+		{desc: "success not met", condition: DependsOnSuccess, status: basetask.JobStatusExitedError},
+		{desc: "success not met by a signal", condition: DependsOnSuccess, status: basetask.JobStatusSignaled},
+		{desc: "completion met by success", condition: DependsOnCompletion, status: basetask.JobStatusExitedOK, expected: true},
+		{desc: "completion met by a signal", condition: DependsOnCompletion, status: basetask.JobStatusSignaled, expected: true},
+		{desc: "failure met", condition: DependsOnFailure, status: basetask.JobStatusExitedError, expected: true},
+		{desc: "failure met by an unstarted task", condition: DependsOnFailure, status: basetask.JobStatusFailed, expected: true},
+		{desc: "failure met by a canceled task", condition: DependsOnFailure, status: basetask.JobStatusCanceled, expected: true},
+		{desc: "failure not met", condition: DependsOnFailure, status: basetask.JobStatusExitedOK},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.condition.metBy(tt.status))
+		})
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	t.Parallel()
+
+	graph := map[string][]Dependency{
+		"a": {{TaskID: "b"}},
+		"b": {{TaskID: "c"}, {TaskID: "d"}},
+		"c": {{TaskID: "d"}},
+		"d": nil,
+		"e": {{TaskID: "new"}},
+	}
+	dependenciesOf := func(id string) []Dependency { return graph[id] }
+
+	assert.Nil(t, findDependencyCycle("new", []Dependency{{TaskID: "a"}, {TaskID: "c"}}, dependenciesOf))
+	assert.Equal(t, []string{"new", "a", "b", "e", "new"},
+		findDependencyCycle("new", []Dependency{{TaskID: "a"}}, func(id string) []Dependency {
+			if id == "b" {
+				return []Dependency{{TaskID: "e"}}
+			}
+			return graph[id]
+		}))
+	assert.Equal(t, []string{"new", "new"}, findDependencyCycle("new", []Dependency{{TaskID: "new"}}, dependenciesOf))
+}
+
+func TestResolveDependencies(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	own := CreateNewTask("own", "client001", 0, time.Time{}, NewTaskWriter())
+	other := CreateNewTask("other", "client002", 0, time.Time{}, NewTaskWriter())
+	tm.addTask(own)
+	tm.addTask(other)
+
+	tooMany := make([]Dependency, MaxDependencies+1)
+	for i := range tooMany {
+		tooMany[i] = Dependency{TaskID: "own"}
+	}
+
+	tests := []struct {
+		desc        string
+		clientID    string
+		dependsOn   []Dependency
+		expected    []*Task
+		expectedErr bool
+	}{
+		{desc: "none", clientID: "client001", expected: []*Task{}},
+		{
+			desc:      "own task",
+			clientID:  "client001",
+			dependsOn: []Dependency{{TaskID: "own", Condition: DependsOnCompletion}},
+			expected:  []*Task{own},
+		},
+		{
+			desc:      "admin depends on any task",
+			clientID:  "admin",
+			dependsOn: []Dependency{{TaskID: "own"}, {TaskID: "other", Condition: DependsOnFailure}},
+			expected:  []*Task{own, other},
+		},
+		{desc: "unknown task", clientID: "client001", dependsOn: []Dependency{{TaskID: "missing"}}, expectedErr: true},
+		{desc: "task of another client", clientID: "client001", dependsOn: []Dependency{{TaskID: "other"}}, expectedErr: true},
+		{
+			desc:        "duplicate",
+			clientID:    "client001",
+			dependsOn:   []Dependency{{TaskID: "own"}, {TaskID: "own", Condition: DependsOnFailure}},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown condition",
+			clientID:    "client001",
+			dependsOn:   []Dependency{{TaskID: "own", Condition: DependencyCondition(7)}},
+			expectedErr: true,
+		},
+		{desc: "too many", clientID: "client001", dependsOn: tooMany, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tasks, err := tm.resolveDependencies(tt.clientID, "new", tt.dependsOn)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tasks)
+		})
+	}
+}
+
+func TestAwaitDependencies(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	newWaiting := func(id string, dep *Task, condition DependencyCondition) (*Task, <-chan struct{}) {
+		task := CreateNewTask(id, "client001", 0, time.Time{}, NewTaskWriter())
+		task.dependencies = []Dependency{{TaskID: dep.GetID(), Condition: condition}}
+		canceled := task.beginWait()
+		tm.addTask(task)
+		return task, canceled
+	}
+
+	dep := CreateNewTask("dep", "client001", 0, time.Time{}, NewTaskWriter())
+	tm.addTask(dep)
+
+	unmet, unmetCanceled := newWaiting("unmet", dep, DependsOnSuccess)
+	stopped, stoppedCanceled := newWaiting("stopped", dep, DependsOnCompletion)
+	go tm.awaitDependencies(slog.Default(), unmet, []*Task{dep}, unmetCanceled)
+	go tm.awaitDependencies(slog.Default(), stopped, []*Task{dep}, stoppedCanceled)
+
+	assert.Equal(t, basetask.JobStatusWaiting, stopped.GetStatus())
+	require.True(t, stopped.cancelWait("user"))
+	<-stopped.Done()
+	assert.Equal(t, basetask.JobStatusCanceled, stopped.GetStatus())
+	assert.Equal(t, "user", stopped.GetTerminationSource())
+
+	// the dependency is stopped before it started so a success condition can no longer be met
+	tm.finishUnstarted(dep, basetask.JobStatusCanceled, "user", audit.ActionCancel, audit.OutcomeSuccess)
+	<-unmet.Done()
+	assert.Equal(t, basetask.JobStatusFailed, unmet.GetStatus())
+	assert.Equal(t, terminationSourceDependency, unmet.GetTerminationSource())
+	assert.False(t, unmet.GetEndTime().IsZero())
+}
@@ -0,0 +1,172 @@
+package task
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long an idempotency key is remembered unless set with WithIdempotencyTTL
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength limits the size of client supplied idempotency keys
+	maxIdempotencyKeyLength = 256
+	// idempotencySweepInterval is the minimum time between two sweeps of expired keys
+	idempotencySweepInterval = time.Minute
+)
+
+// idempotencyKey scopes a client supplied key to the client identity
+type idempotencyKey struct {
+	clientID string
+	key      string
+}
+
+// idempotencyEntry remembers the parameters and the resulting task of a keyed start request.
+// ready is closed once the start has completed; taskID is empty if it failed.
+type idempotencyEntry struct {
+	command  string
+	args     []string
+	labels   map[string]string
+	priority int
+	retry    RetryPolicy
+	restart  RestartPolicy
+	// dependsOn is compared in order like args
+	dependsOn []Dependency
+	limits    ResourceUpdate
+
+	ready   chan struct{}
+	taskID  string
+	expires time.Time
+}
+
+// sameParameters reports whether the spec has the parameters the entry was created with
+func (e *idempotencyEntry) sameParameters(spec TaskSpec) bool {
+	return e.command == spec.Command && slices.Equal(e.args, spec.Args) && maps.Equal(e.labels, spec.Labels) &&
+		e.priority == spec.Priority && e.retry == spec.Retry && e.restart == spec.Restart &&
+		slices.Equal(e.dependsOn, spec.DependsOn) && e.limits.equal(spec.Limits)
+}
+
+// WithIdempotencyTTL sets how long idempotency keys of start requests are remembered
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(tm *TaskManager) {
+		tm.idempotencyTTL = ttl
+	}
+}
+
+// validateIdempotencyKey checks that a key is printable and not too long
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLength {
+		return basetask.NewTaskError(basetask.ErrInvalidArgument, "idempotency key must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return basetask.NewTaskError(basetask.ErrInvalidArgument, "idempotency key must only contain printable characters")
+		}
+	}
+	return nil
+}
+
+// startIdempotent starts the task at most once per idempotency key of the client. A concurrent request with
+// the same key waits for the first one to complete; if the first start failed the key is released and the
+// request starts the task itself.
+func (tm *TaskManager) startIdempotent(ctx context.Context, clientID string, spec TaskSpec) (string, error) {
+	if err := validateIdempotencyKey(spec.IdempotencyKey); err != nil {
+		return "", err
+	}
+	key := idempotencyKey{clientID: clientID, key: spec.IdempotencyKey}
+
+	for {
+		tm.idempotencyMu.Lock()
+		now := time.Now()
+		tm.sweepIdempotencyKeys(now)
+		entry, ok := tm.idempotencyKeys[key]
+		if ok && !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(tm.idempotencyKeys, key)
+			ok = false
+		}
+		if !ok {
+			entry = &idempotencyEntry{
+				command:   spec.Command,
+				args:      slices.Clone(spec.Args),
+				labels:    maps.Clone(spec.Labels),
+				priority:  spec.Priority,
+				retry:     spec.Retry,
+				restart:   spec.Restart,
+				dependsOn: slices.Clone(spec.DependsOn),
+				limits:    spec.Limits,
+				ready:     make(chan struct{}),
+			}
+			tm.idempotencyKeys[key] = entry
+			tm.idempotencyMu.Unlock()
+			return tm.startReserved(ctx, key, entry, spec)
+		}
+		tm.idempotencyMu.Unlock()
+
+		if !entry.sameParameters(spec) {
+			return "", basetask.NewTaskError(basetask.ErrAlreadyExists,
+				"idempotency key %q was already used with different parameters", spec.IdempotencyKey)
+		}
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return "", basetask.NewTaskErrorWithErr(basetask.ErrCanceled, "canceled while waiting for a start with the same idempotency key", ctx.Err())
+		}
+		tm.idempotencyMu.Lock()
+		taskID := entry.taskID
+		tm.idempotencyMu.Unlock()
+		if taskID != "" {
+			metrics.TasksDeduplicated.Inc()
+			return taskID, nil
+		}
+		// the first start failed and released the key so try again
+	}
+}
+
+// startReserved starts the task for a reserved idempotency key and records the result
+func (tm *TaskManager) startReserved(ctx context.Context, key idempotencyKey, entry *idempotencyEntry, spec TaskSpec) (string, error) {
+	taskID, err := tm.startTask(ctx, spec)
+
+	tm.idempotencyMu.Lock()
+	defer tm.idempotencyMu.Unlock()
+	if err != nil {
+		// a failed start is not remembered so that the request can be retried
+		delete(tm.idempotencyKeys, key)
+	} else {
+		entry.taskID = taskID
+		entry.expires = time.Now().Add(tm.idempotencyTTL)
+	}
+	close(entry.ready)
+	return taskID, err
+}
+
+// sweepIdempotencyKeys removes expired keys at most once per sweep interval. It must be called with
+// idempotencyMu held.
+func (tm *TaskManager) sweepIdempotencyKeys(now time.Time) {
+	if now.Sub(tm.idempotencySweptAt) < idempotencySweepInterval {
+		return
+	}
+	tm.idempotencySweptAt = now
+	for key, entry := range tm.idempotencyKeys {
+		if !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(tm.idempotencyKeys, key)
+		}
+	}
+}
+
+// forgetIdempotencyKeys removes the keys of an evicted task so that a retry starts a new task rather
+// than returning an ID that no longer exists
+func (tm *TaskManager) forgetIdempotencyKeys(taskID string) {
+	tm.idempotencyMu.Lock()
+	defer tm.idempotencyMu.Unlock()
+	for key, entry := range tm.idempotencyKeys {
+		if entry.taskID == taskID {
+			delete(tm.idempotencyKeys, key)
+		}
+	}
+}
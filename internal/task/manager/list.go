@@ -0,0 +1,64 @@
+package task
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// TaskFilter selects tasks by labels, status, owner and start time. Unset fields match every task.
+type TaskFilter struct {
+	Labels        basetask.Selector
+	Statuses      []int
+	Owner         string
+	StartedBefore time.Time
+}
+
+// matches reports whether the task satisfies all fields of the filter
+func (f TaskFilter) matches(task *Task) bool {
+	if f.Owner != "" && task.GetClientID() != f.Owner {
+		return false
+	}
+	if !f.StartedBefore.IsZero() {
+		// queued tasks have not started yet
+		startTime := task.GetStartTime()
+		if startTime.IsZero() || !startTime.Before(f.StartedBefore) {
+			return false
+		}
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.GetStatus()) {
+		return false
+	}
+	return f.Labels.Matches(task.labels)
+}
+
+// ListTasks returns the tasks matching the filter ordered by start time. Only the tasks of the
+// caller are returned unless the caller is admin.
+func (tm *TaskManager) ListTasks(ctx context.Context, filter TaskFilter) []*Task {
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+
+	tm.mu.RLock()
+	tasks := make([]*Task, 0, len(tm.tasksMapByID))
+	for _, task := range tm.tasksMapByID {
+		if task.GetClientID() != caller && caller != "admin" {
+			continue
+		}
+		if !filter.matches(task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	tm.mu.RUnlock()
+
+	slices.SortFunc(tasks, func(a, b *Task) int {
+		if c := a.GetStartTime().Compare(b.GetStartTime()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetID(), b.GetID())
+	})
+	return tasks
+}
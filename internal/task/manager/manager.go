@@ -6,7 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
 	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
 )
 
 type TaskManager struct {
@@ -14,19 +17,87 @@ type TaskManager struct {
 	// task map by task ID
 	tasksMapByID map[string]*Task
 	ctx          context.Context
+	auditLog     *audit.Logger
+
+	// idempotency keys of start requests by client; protected by idempotencyMu
+	idempotencyMu      sync.Mutex
+	idempotencyKeys    map[idempotencyKey]*idempotencyEntry
+	idempotencySweptAt time.Time
+	idempotencyTTL     time.Duration
+
+	retention RetentionPolicy
+
+	// running tasks and recent starts counted against the quota policy; protected by quotaMu
+	quotaMu         sync.Mutex
+	quota           QuotaPolicy
+	runningByClient map[string]int
+	runningTotal    int
+	startTimes      map[string][]time.Time
+	queue           []*queuedTask
+	queueSeq        uint64
+
+	// schedules by schedule ID; protected by schedulesMu
+	schedulesMu sync.Mutex
+	schedules   map[string]*schedule
+
+	// ceilings are the highest resource limits of a task; resourcesMu serializes resource updates
+	ceilings    cgroups.Limits
+	resourcesMu sync.Mutex
+	// ioDevices are the major:minor numbers of the block devices the IO limits apply to
+	ioDevices []string
+	// hierarchy is the cgroup tree the tasks run in
+	hierarchy *cgroups.Hierarchy
+	// memoryPolicy stops tasks under memory pressure; pressureWarning logs once that PSI is unavailable
+	memoryPolicy    MemoryPressurePolicy
+	pressureWarning sync.Once
+
+	// freezeCgroup freezes or thaws the cgroup of a task; replaced in tests
+	freezeCgroup func(ctx context.Context, cgroupPath string, frozen bool) error
+	// setCgroupLimits writes the resource limits to the cgroup of a task; replaced in tests
+	setCgroupLimits func(cgroupPath string, limits cgroups.Limits, ioDevices []string) error
 }
 
-func NewTaskManager(ctx context.Context) *TaskManager {
-	return &TaskManager{
-		tasksMapByID: make(map[string]*Task),
-		ctx:          ctx,
+// Option configures optional behavior of the TaskManager
+type Option func(*TaskManager)
+
+// WithAuditLogger records task lifecycle events such as signals and exits to the audit log
+func WithAuditLogger(auditLog *audit.Logger) Option {
+	return func(tm *TaskManager) {
+		tm.auditLog = auditLog
 	}
 }
 
+func NewTaskManager(ctx context.Context, opts ...Option) *TaskManager {
+	tm := &TaskManager{
+		tasksMapByID:    make(map[string]*Task),
+		ctx:             ctx,
+		idempotencyKeys: make(map[idempotencyKey]*idempotencyEntry),
+		idempotencyTTL:  DefaultIdempotencyTTL,
+		runningByClient: make(map[string]int),
+		startTimes:      make(map[string][]time.Time),
+		schedules:       make(map[string]*schedule),
+		hierarchy:       cgroups.DefaultHierarchy(),
+		freezeCgroup:    cgroups.FreezeCgroupForTask,
+		setCgroupLimits: cgroups.SetCgroupLimits,
+	}
+	for _, opt := range opts {
+		opt(tm)
+	}
+	if tm.retention.enabled() {
+		go tm.reap()
+	}
+	context.AfterFunc(ctx, tm.cancelQueue)
+	context.AfterFunc(ctx, tm.stopSchedules)
+	return tm
+}
+
 func (tm *TaskManager) addTask(task *Task) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
-	task.done = make(chan struct{})
+	// a waiting task is added again once it is started and keeps the channel its waiters hold
+	if task.done == nil {
+		task.done = make(chan struct{})
+	}
 	tm.tasksMapByID[task.GetID()] = task
 }
 
@@ -77,3 +148,26 @@ func (tm *TaskManager) getTaskFromMap(taskID string) (*Task, error) {
 	}
 	return task, nil
 }
+
+// WithCgroupHierarchy runs the tasks in the cgroup tree of the hierarchy instead of cgroups.DefaultHierarchy
+func WithCgroupHierarchy(hierarchy *cgroups.Hierarchy) Option {
+	return func(tm *TaskManager) {
+		tm.hierarchy = hierarchy
+	}
+}
+
+// cgroupPath returns the path of the cgroup of a task below the cgroup of its client
+func (tm *TaskManager) cgroupPath(task *Task) string {
+	return tm.hierarchy.TaskPath(task.GetClientID(), task.GetID())
+}
+
+// removeCgroup removes the cgroup of a task and records how long the removal took including retries
+func (tm *TaskManager) removeCgroup(task *Task) error {
+	start := time.Now()
+	err := cgroups.RemoveCgroupForTask(tm.cgroupPath(task))
+	metrics.CgroupRemoveDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.CgroupRemoveFailures.Inc()
+	}
+	return err
+}
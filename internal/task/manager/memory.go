@@ -0,0 +1,244 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"syscall"
+	"time"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+)
+
+// Kinds of memory events
+const (
+	// MemoryEventHigh is recorded when the processes were throttled above memory.high
+	MemoryEventHigh = "high"
+	// MemoryEventMax is recorded when the memory usage reached memory.max
+	MemoryEventMax = "max"
+	// MemoryEventOOMKill is recorded when the OOM killer killed a process
+	MemoryEventOOMKill = "oom_kill"
+	// MemoryEventPressure is recorded when processes stalled on memory for memoryPressureTrigger
+	MemoryEventPressure = "pressure"
+	// MemoryEventStop is recorded when the memory pressure policy stops the task
+	MemoryEventStop = "stop"
+)
+
+// terminationSourceMemoryPressure is the termination source of a task stopped by the memory pressure policy
+const terminationSourceMemoryPressure = "memory_pressure"
+
+// maxMemoryEvents bounds the memory events kept for the status of a task
+const maxMemoryEvents = 50
+
+// memoryPressureTrigger fires when some processes of a task stall on memory for a tenth of a second
+var memoryPressureTrigger = cgroups.PressureTrigger{Stall: 100 * time.Millisecond, Window: time.Second}
+
+// pressureEventInterval is the least time between two pressure events of a task
+const pressureEventInterval = 10 * time.Second
+
+// MemoryEvent is a memory event of the process of a task
+type MemoryEvent struct {
+	Time time.Time
+	// Attempt is the number of the attempt whose process the event occurred in
+	Attempt int
+	// Kind is one of the MemoryEvent kinds
+	Kind string
+	// Count is the memory.events counter of the kind after the event; 0 for pressure and stop events
+	Count int64
+	// Pressure is the memory pressure of the task when the event was recorded
+	Pressure cgroups.MemoryPressure
+}
+
+// MemoryPressurePolicy stops a task gracefully before the OOM killer kills it: once all processes of the
+// task stalled on memory for StopAbove percent of the last 10 seconds, they are sent SIGTERM and SIGKILL
+// if they are still running after GracePeriod. A task stopped by the policy may be retried or restarted.
+type MemoryPressurePolicy struct {
+	// StopAbove is the full memory pressure in percent at which a task is stopped; 0 disables the policy
+	StopAbove float64
+	// GracePeriod is how long the processes may take to exit after SIGTERM
+	GracePeriod time.Duration
+}
+
+// WithMemoryPressurePolicy stops tasks under memory pressure as set by the policy
+func WithMemoryPressurePolicy(policy MemoryPressurePolicy) Option {
+	return func(tm *TaskManager) {
+		tm.memoryPolicy = policy
+	}
+}
+
+// exceeded reports whether the policy stops a task under the pressure
+func (p MemoryPressurePolicy) exceeded(pressure cgroups.MemoryPressure) bool {
+	return p.StopAbove > 0 && pressure.Full >= p.StopAbove
+}
+
+// memoryEventsBetween returns an event for each memory.events counter that increased from last to current
+func memoryEventsBetween(now time.Time, attempt int, last, current cgroups.MemoryEvents, pressure cgroups.MemoryPressure) []MemoryEvent {
+	var events []MemoryEvent
+	for _, c := range []struct {
+		kind          string
+		last, current int64
+	}{
+		{MemoryEventHigh, last.High, current.High},
+		{MemoryEventMax, last.Max, current.Max},
+		{MemoryEventOOMKill, last.OOMKill, current.OOMKill},
+	} {
+		if c.current > c.last {
+			events = append(events, MemoryEvent{Time: now, Attempt: attempt, Kind: c.kind, Count: c.current, Pressure: pressure})
+		}
+	}
+	return events
+}
+
+// watchMemory records the memory events of the running process of a task until ctx is done and applies
+// the memory pressure policy. The returned channel is closed once it stopped watching.
+func (tm *TaskManager) watchMemory(ctx context.Context, logger *slog.Logger, taskID string) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		task, err := tm.getTaskFromMap(taskID)
+		if err != nil {
+			logger.Error("failed to get task", "error", err)
+			return
+		}
+		cgroupPath := tm.cgroupPath(task)
+		watcher, err := cgroups.NewMemoryWatcher(cgroupPath)
+		if err != nil {
+			logger.Warn("failed to watch memory events", "error", err)
+			return
+		}
+		defer func() {
+			if err := watcher.Close(); err != nil {
+				logger.Error("failed to close memory watcher", "error", err)
+			}
+		}()
+		if err := watcher.AddPressureTrigger(cgroupPath, memoryPressureTrigger); err != nil {
+			// without PSI, e.g. on a kernel booted with psi=0, this fails for every task
+			tm.pressureWarning.Do(func() {
+				logger.Warn("memory pressure events are unavailable", "error", err)
+			})
+		}
+		last, err := watcher.Events()
+		if err != nil {
+			logger.Warn("failed to watch memory events", "error", err)
+			return
+		}
+
+		attempt := task.currentAttempt()
+		stopping := false
+		for {
+			current, pressured, err := watcher.Wait(ctx)
+			if err != nil && ctx.Err() == nil {
+				logger.Warn("stopped watching memory events", "error", err)
+				return
+			}
+			if err != nil {
+				// the process exited; events such as its OOM kill may not have been seen yet
+				if current, err = watcher.Events(); err == nil {
+					task.addMemoryEvents(memoryEventsBetween(time.Now(), attempt, last, current, cgroups.MemoryPressure{})...)
+				}
+				return
+			}
+			now := time.Now()
+			pressure, err := cgroups.ReadMemoryPressure(cgroupPath)
+			if err != nil {
+				logger.Debug("failed to read memory pressure", "error", err)
+			}
+			events := memoryEventsBetween(now, attempt, last, current, pressure)
+			if pressured {
+				events = append(events, MemoryEvent{Time: now, Attempt: attempt, Kind: MemoryEventPressure, Pressure: pressure})
+			}
+			task.addMemoryEvents(events...)
+			last = current
+
+			// a stop that could not be sent is tried again on the next event
+			if !stopping && tm.memoryPolicy.exceeded(pressure) && tm.stopForMemoryPressure(ctx, logger, task, pressure) {
+				stopping = true
+				task.addMemoryEvents(MemoryEvent{Time: now, Attempt: attempt, Kind: MemoryEventStop, Pressure: pressure})
+			}
+		}
+	}()
+	return done
+}
+
+// stopForMemoryPressure sends SIGTERM to the process group of the task and SIGKILL if ctx is not done
+// after the grace period of the memory pressure policy. A paused task cannot handle SIGTERM, so it is
+// killed right away. It returns false if the task could not be signaled.
+func (tm *TaskManager) stopForMemoryPressure(ctx context.Context, logger *slog.Logger, task *Task, pressure cgroups.MemoryPressure) bool {
+	if task.GetStatus() == basetask.JobStatusPaused {
+		logger.Warn("killing paused task under memory pressure", "full_pressure", pressure.Full)
+		if err := tm.signalTask(task, task.GetClientID(), terminationSourceMemoryPressure, syscall.SIGKILL); err != nil {
+			logger.Error("failed to kill task under memory pressure", "error", err)
+			return false
+		}
+		return true
+	}
+
+	logger.Warn("stopping task under memory pressure", "full_pressure", pressure.Full, "grace_period", tm.memoryPolicy.GracePeriod)
+	if err := tm.signalTask(task, task.GetClientID(), terminationSourceMemoryPressure, syscall.SIGTERM); err != nil {
+		logger.Error("failed to stop task under memory pressure", "error", err)
+		return false
+	}
+	go func() {
+		timer := time.NewTimer(tm.memoryPolicy.GracePeriod)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		logger.Warn("killing task under memory pressure after the grace period")
+		if err := tm.signalTask(task, task.GetClientID(), terminationSourceMemoryPressure, syscall.SIGKILL); err != nil {
+			logger.Error("failed to kill task under memory pressure", "error", err)
+		}
+	}()
+	return true
+}
+
+// MemoryStatus returns the memory.events counters of the current or last process of the task and the
+// memory pressure while it runs
+func (tm *TaskManager) MemoryStatus(task *Task) (cgroups.MemoryEvents, cgroups.MemoryPressure) {
+	if !task.running() {
+		return task.lastMemoryEvents(), cgroups.MemoryPressure{}
+	}
+	cgroupPath := tm.cgroupPath(task)
+	// the process may exit and its cgroup be removed meanwhile
+	events, err := cgroups.ReadMemoryEvents(cgroupPath)
+	if err != nil {
+		return task.lastMemoryEvents(), cgroups.MemoryPressure{}
+	}
+	pressure, _ := cgroups.ReadMemoryPressure(cgroupPath)
+	return events, pressure
+}
+
+// WatchMemoryEvents calls send with the kept memory events of a task and then with each new event until
+// the task finishes, ctx or the server context is done or send fails
+func (tm *TaskManager) WatchMemoryEvents(ctx context.Context, taskID string, send func(MemoryEvent) error) error {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return err
+	}
+	mergedCtx, cancel := mergeCancelContexts(ctx, tm.ctx)
+	defer cancel()
+
+	next := 0
+	for {
+		// the events are read after the task is seen done so that none recorded before are missed
+		finished := task.finished()
+		events, count, changed := task.memoryEventsSince(next)
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		next = count
+		if finished {
+			return nil
+		}
+		select {
+		case <-mergedCtx.Done():
+			return mergedCtx.Err()
+		case <-changed:
+		case <-task.Done():
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+)
+
+func TestMemoryEventsBetween(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	pressure := cgroups.MemoryPressure{Some: 20, Full: 5}
+	tests := []struct {
+		desc     string
+		last     cgroups.MemoryEvents
+		current  cgroups.MemoryEvents
+		expected []MemoryEvent
+	}{
+		{desc: "unchanged", last: cgroups.MemoryEvents{High: 3}, current: cgroups.MemoryEvents{High: 3}},
+		{
+			desc:     "throttled",
+			last:     cgroups.MemoryEvents{High: 3},
+			current:  cgroups.MemoryEvents{High: 7},
+			expected: []MemoryEvent{{Time: now, Attempt: 2, Kind: MemoryEventHigh, Count: 7, Pressure: pressure}},
+		},
+		{
+			desc:    "OOM killed",
+			last:    cgroups.MemoryEvents{High: 3},
+			current: cgroups.MemoryEvents{High: 3, Max: 1, OOMKill: 1},
+			expected: []MemoryEvent{
+				{Time: now, Attempt: 2, Kind: MemoryEventMax, Count: 1, Pressure: pressure},
+				{Time: now, Attempt: 2, Kind: MemoryEventOOMKill, Count: 1, Pressure: pressure},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, memoryEventsBetween(now, 2, tt.last, tt.current, pressure))
+		})
+	}
+}
+
+func TestMemoryPressurePolicy(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, MemoryPressurePolicy{}.exceeded(cgroups.MemoryPressure{Some: 100, Full: 100}))
+	policy := MemoryPressurePolicy{StopAbove: 40}
+	assert.False(t, policy.exceeded(cgroups.MemoryPressure{Some: 90, Full: 39.9}))
+	assert.True(t, policy.exceeded(cgroups.MemoryPressure{Some: 90, Full: 40}))
+}
+
+func TestAddMemoryEvents(t *testing.T) {
+	t.Parallel()
+
+	task := CreateNewTask("task", "client001", 4242, time.Now(), NewTaskWriter())
+	start := time.Now()
+	// the pressure trigger fires every second while the pressure lasts
+	for i := range 20 {
+		task.addMemoryEvents(MemoryEvent{Time: start.Add(time.Duration(i) * time.Second), Kind: MemoryEventPressure})
+	}
+	events := task.Snapshot().MemoryEvents
+	require.Len(t, events, 2)
+	assert.Equal(t, start, events[0].Time)
+	assert.Equal(t, start.Add(pressureEventInterval), events[1].Time)
+
+	for i := range maxMemoryEvents {
+		task.addMemoryEvents(MemoryEvent{Time: start, Kind: MemoryEventHigh, Count: int64(i + 1)})
+	}
+	events = task.Snapshot().MemoryEvents
+	require.Len(t, events, maxMemoryEvents)
+	assert.Equal(t, int64(1), events[0].Count)
+	assert.Equal(t, int64(maxMemoryEvents), events[maxMemoryEvents-1].Count)
+}
+
+func TestWatchMemoryEvents(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	task := CreateNewTask("task", "client001", 4242, time.Now(), NewTaskWriter())
+	tm.addTask(task)
+	task.addMemoryEvents(MemoryEvent{Kind: MemoryEventHigh, Count: 1})
+
+	received := make(chan MemoryEvent, 10)
+	errC := make(chan error, 1)
+	go func() {
+		errC <- tm.WatchMemoryEvents(context.Background(), "task", func(event MemoryEvent) error {
+			received <- event
+			return nil
+		})
+	}()
+
+	// the kept events are sent first
+	assert.Equal(t, MemoryEventHigh, (<-received).Kind)
+	task.addMemoryEvents(MemoryEvent{Kind: MemoryEventMax, Count: 1}, MemoryEvent{Kind: MemoryEventOOMKill, Count: 1})
+	assert.Equal(t, MemoryEventMax, (<-received).Kind)
+	assert.Equal(t, MemoryEventOOMKill, (<-received).Kind)
+
+	// the watch ends when the task finishes
+	close(task.done)
+	select {
+	case err := <-errC:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not end when the task finished")
+	}
+	assert.Empty(t, received)
+
+	// a canceled watch ends with the error of its context
+	other := CreateNewTask("other", "client001", 4243, time.Now(), NewTaskWriter())
+	tm.addTask(other)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, tm.WatchMemoryEvents(ctx, "other", func(MemoryEvent) error { return nil }), context.Canceled)
+}
+
+func TestStopForMemoryPressurePaused(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithMemoryPressurePolicy(MemoryPressurePolicy{StopAbove: 50, GracePeriod: time.Hour}))
+	tm.freezeCgroup = (&freezeRecorder{}).freeze
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	// the process is not frozen by the stubbed freezer, so it would exit on SIGTERM as well
+	cmd := exec.Command("sleep", "60")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+	task := CreateNewTask("task", "client001", cmd.Process.Pid, time.Now(), NewTaskWriter())
+	tm.addTask(task)
+	require.NoError(t, tm.PauseTask(ctx, "task"))
+
+	pressure := cgroups.MemoryPressure{Full: 80}
+	require.True(t, tm.stopForMemoryPressure(ctx, slog.Default(), task, pressure))
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	status := exitErr.Sys().(syscall.WaitStatus)
+	require.True(t, status.Signaled())
+	assert.Equal(t, syscall.SIGKILL, status.Signal())
+	assert.Equal(t, terminationSourceMemoryPressure, task.GetTerminationSource())
+
+	// a task that cannot be signaled is stopped again on the next event
+	task.setResult(TaskAttempt{Status: basetask.JobStatusSignaled, TerminationSource: terminationSourceMemoryPressure, EndTime: time.Now()})
+	assert.False(t, tm.stopForMemoryPressure(ctx, slog.Default(), task, pressure))
+}
@@ -1,11 +1,17 @@
 package task
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os/exec"
 	"syscall"
 	"time"
 
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
 	"github.com/mikewurtz/taskman/internal/task/cgroups"
 
 	basetask "github.com/mikewurtz/taskman/internal/task"
@@ -13,6 +19,12 @@ import (
 
 // monitorProcess handles the process completion and status updates
 func (tm *TaskManager) monitorProcess(taskID string, cmd *exec.Cmd) {
+	logger := slog.Default().With("task_id", taskID)
+
+	// the memory events of the process are recorded until it exits
+	watchCtx, stopWatching := context.WithCancel(tm.ctx)
+	watchDone := tm.watchMemory(watchCtx, logger, taskID)
+
 	// Create a channel to receive the process completion
 	errC := make(chan error, 1)
 	go func() {
@@ -27,88 +39,167 @@ func (tm *TaskManager) monitorProcess(taskID string, cmd *exec.Cmd) {
 	case <-tm.ctx.Done():
 		// Server context was canceled, kill the entire process group
 		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
-			log.Printf("Failed to kill process group %d: %v", cmd.Process.Pid, err)
+			logger.Error("failed to kill process group", "pgid", cmd.Process.Pid, "error", err)
 		}
 		cmdErr = <-errC
 	}
+	stopWatching()
+	<-watchDone
 
 	finishTime := time.Now()
 
 	if cmdErr != nil {
-		log.Printf("Task %s exited with an error: %v", taskID, cmdErr)
+		logger.Info("task exited with an error", "error", cmdErr)
 	} else {
-		log.Printf("Task %s completed successfully", taskID)
+		logger.Info("task completed successfully")
 	}
 
 	var exitCode *int
 	var signal string
-	exitCode, signal = extractProcessExitInfo(cmdErr, cmd)
+	exitCode, signal = extractProcessExitInfo(logger, cmdErr, cmd)
 
 	task, err := tm.getTaskFromMap(taskID)
 	if err != nil {
-		log.Printf("Failed to get task %s: %v", taskID, err)
+		logger.Error("failed to get task", "error", err)
 		return
 	}
+	logger = logger.With("client_id", task.GetClientID())
+
+	result := TaskAttempt{
+		EndTime:           finishTime,
+		TerminationSignal: signal,
+		// set by SignalTask if a client sent the signal
+		TerminationSource: task.GetTerminationSource(),
+	}
+	if exitCode != nil {
+		ec := int32(*exitCode)
+		result.ExitCode = &ec
+	}
 
-	unknownStatus := false
 	if exitCode == nil && signal == "" {
 		// Unknown failure — ProcessState or WaitStatus was missing or corrupt
-		task.SetStatus(basetask.JobStatusUnknown)
-		task.SetTerminationSource("unknown")
-		log.Printf("Could not determine how task %s exited", task.GetID())
-		unknownStatus = true
+		result.Status = basetask.JobStatusUnknown
+		result.TerminationSource = "unknown"
+		logger.Warn("could not determine how task exited")
+	} else if oomKilled, err := cgroups.CheckIfOOMKilled(tm.cgroupPath(task)); err != nil {
+		logger.Error("failed to check if task was OOM killed", "error", err)
+		result.Status = exitStatus(exitCode)
+	} else if oomKilled {
+		// OOM kill overrides whatever status was previously inferred.
+		// Because we monitor the process group ID, the kernel may have killed a child
+		// process instead. In that case, the PGID process may exit with code 1,
+		// which would incorrectly appear as a regular failure.
+		// To reflect the true cause, we override the status and clear ExitCode.
+		logger.Info("task was OOM killed; overriding status to SIGKILL")
+		result.Status = basetask.JobStatusSignaled
+		result.TerminationSignal = syscall.SIGKILL.String()
+		result.TerminationSource = "oom"
+		result.ExitCode = nil
+	} else {
+		result.Status = exitStatus(exitCode)
+	}
+	// a process that fails or is killed after forks failed at pids.max most likely died of it, e.g. a
+	// shell that cannot fork exits with an error
+	// pids.events only exists with the pids controller
+	pidsMaxEvents, err := cgroups.PidsMaxEvents(tm.cgroupPath(task))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Error("failed to read pids.max events", "error", err)
+	}
+	result.PidsMaxEvents = pidsMaxEvents
+	if pidsMaxEvents > 0 && result.TerminationSource == "" &&
+		(result.Status == basetask.JobStatusExitedError || result.Status == basetask.JobStatusSignaled) {
+		logger.Info("task failed after reaching pids.max", "pids_max_events", pidsMaxEvents)
+		result.TerminationSource = "pids"
+	}
+	if memoryEvents, err := cgroups.ReadMemoryEvents(tm.cgroupPath(task)); err != nil {
+		logger.Error("failed to read memory events", "error", err)
+	} else {
+		result.MemoryEvents = memoryEvents
+	}
+	if result.Status == basetask.JobStatusSignaled && result.TerminationSource == "" {
+		result.TerminationSource = "system"
 	}
 
-	task.SetEndTime(finishTime)
-	if exitCode != nil {
-		ec := int32(*exitCode)
-		task.SetExitCode(&ec)
-	}
-	task.SetTerminationSignal(signal)
-
-	if !unknownStatus {
-		if oomKilled, err := cgroups.CheckIfOOMKilled(taskID); err != nil {
-			log.Printf("Failed to check if task %s was OOM killed: %v", taskID, err)
-		} else if oomKilled {
-			// OOM kill overrides whatever status was previously inferred.
-			// Because we monitor the process group ID, the kernel may have killed a child
-			// process instead. In that case, the PGID process may exit with code 1,
-			// which would incorrectly appear as a regular failure.
-			// To reflect the true cause, we override the status and clear ExitCode.
-			log.Printf("Task %s was OOM killed; overriding status to SIGKILL", task.GetID())
-			task.SetStatus(basetask.JobStatusSignaled)
-			task.SetTerminationSignal(syscall.SIGKILL.String())
-			task.SetTerminationSource("oom")
-			task.SetExitCode(nil)
-		} else if exitCode != nil {
-			if *exitCode == 0 {
-				task.SetStatus(basetask.JobStatusExitedOK)
-			} else {
-				task.SetStatus(basetask.JobStatusExitedError)
-			}
-		} else {
-			task.SetStatus(basetask.JobStatusSignaled)
-			if task.GetTerminationSource() == "" {
-				task.SetTerminationSource("system")
-			}
+	metrics.TasksRunning.Dec()
+
+	// Clean up cgroup after process completes; a retry runs in a fresh cgroup
+	if cleanupErr := tm.removeCgroup(task); cleanupErr != nil {
+		logger.Error("failed to clean up cgroup after process completion", "error", cleanupErr)
+	}
+
+	attempt, next := task.endAttempt(result, tm.ctx.Err() == nil)
+	switch {
+	case next.canceled != nil && next.restart:
+		tm.restartTask(logger, task, attempt, next.delay, next.canceled)
+		return
+	case next.canceled != nil:
+		tm.retryTask(logger, task, attempt, next.canceled)
+		return
+	case next.restartLimited:
+		logger.Warn("task not restarted: restart rate reached", "max_restarts", task.restart.MaxRestarts, "window", task.restart.Window)
+		if _, err := fmt.Fprintf(task.getWriter(), "--- taskman: not restarted after %s: %d restarts within %s ---\n",
+			exitReason(attempt), task.restart.MaxRestarts, task.restart.Window); err != nil {
+			logger.Error("failed to write restart marker", "error", err)
 		}
 	}
+	tm.finishTask(logger, task, result)
+}
+
+// exitStatus returns the status of a process that exited with the exit code or was killed by a signal if it is nil
+func exitStatus(exitCode *int) int {
+	switch {
+	case exitCode == nil:
+		return basetask.JobStatusSignaled
+	case *exitCode == 0:
+		return basetask.JobStatusExitedOK
+	default:
+		return basetask.JobStatusExitedError
+	}
+}
 
-	// Clean up cgroup after process completes
-	if cleanupErr := cgroups.RemoveCgroupForTask(taskID); cleanupErr != nil {
-		log.Printf("Failed to clean up cgroup after process completion: %v", cleanupErr)
+// finishTask sets the final status of the task from the result of its last attempt, closes its
+// output and gives back its running task
+func (tm *TaskManager) finishTask(logger *slog.Logger, task *Task, result TaskAttempt) {
+	task.setResult(result)
+	logger.Info("task finished", "status", result.Status, "termination_source", result.TerminationSource)
+
+	tm.releaseRunning(task.GetClientID())
+	terminationSource := result.TerminationSource
+	if terminationSource == "" {
+		terminationSource = metrics.TerminationSourceExited
 	}
+	metrics.TasksFinished.WithLabelValues(terminationSource).Inc()
 
 	task.closeWriter()
 
+	tm.auditTaskExit(task)
+
 	// Signal that this task is done
 	close(task.done)
+}
 
+// auditTaskExit records how the task terminated in the audit log
+func (tm *TaskManager) auditTaskExit(task *Task) {
+	snapshot := task.Snapshot()
+	outcome := audit.OutcomeFailure
+	if snapshot.Status == basetask.JobStatusExitedOK {
+		outcome = audit.OutcomeSuccess
+	}
+	tm.auditLog.Log(audit.Event{
+		Action:            audit.ActionExit,
+		ClientID:          snapshot.ClientID,
+		TaskID:            snapshot.ID,
+		ProcessID:         snapshot.ProcessID,
+		Signal:            snapshot.TerminationSignal,
+		ExitCode:          snapshot.ExitCode,
+		TerminationSource: snapshot.TerminationSource,
+		Outcome:           outcome,
+	})
 }
 
 // extractProcessExitInfo extracts the exit code and signal from the command error
 // or from the process state if the command terminated normally
-func extractProcessExitInfo(cmdErr error, cmd *exec.Cmd) (*int, string) {
+func extractProcessExitInfo(logger *slog.Logger, cmdErr error, cmd *exec.Cmd) (*int, string) {
 	var exitCode *int
 	var signal string
 	var ws syscall.WaitStatus
@@ -121,21 +212,21 @@ func extractProcessExitInfo(cmdErr error, cmd *exec.Cmd) (*int, string) {
 		case *exec.ExitError:
 			ws, ok = e.Sys().(syscall.WaitStatus)
 			if !ok {
-				log.Printf("Unexpected type in ExitError.Sys(): %T", e.Sys())
+				logger.Error("unexpected type in ExitError.Sys()", "type", fmt.Sprintf("%T", e.Sys()))
 				return nil, ""
 			}
 		default:
-			log.Printf("Unhandled command error type (%T): %v", cmdErr, cmdErr)
+			logger.Error("unhandled command error type", "type", fmt.Sprintf("%T", cmdErr), "error", cmdErr)
 			return nil, ""
 		}
 	default:
 		if cmd.ProcessState == nil {
-			log.Printf("Missing ProcessState for completed process, cannot extract exit info")
+			logger.Error("missing ProcessState for completed process, cannot extract exit info")
 			return nil, ""
 		}
 		ws, ok = cmd.ProcessState.Sys().(syscall.WaitStatus)
 		if !ok {
-			log.Printf("Unexpected type in ProcessState.Sys(): %T", cmd.ProcessState.Sys())
+			logger.Error("unexpected type in ProcessState.Sys()", "type", fmt.Sprintf("%T", cmd.ProcessState.Sys()))
 			return nil, ""
 		}
 	}
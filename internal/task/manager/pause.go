@@ -0,0 +1,90 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/logging"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// freezeTimeout bounds the wait for the processes of a task to freeze or thaw
+const freezeTimeout = 5 * time.Second
+
+// PauseTask freezes the processes of a running task through the freezer of its cgroup and returns once
+// they are frozen. The task keeps its running task quota while it is paused. Pausing a paused task does
+// nothing. The gRPC handler checks that the caller may access the task.
+func (tm *TaskManager) PauseTask(ctx context.Context, taskID string) error {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return err
+	}
+
+	task.pauseMu.Lock()
+	defer task.pauseMu.Unlock()
+
+	switch status := task.GetStatus(); {
+	case status == basetask.JobStatusPaused:
+		return nil
+	case status != basetask.JobStatusStarted || task.GetProcessID() <= 0 || !task.GetEndTime().IsZero():
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "only running tasks can be paused")
+	}
+
+	logger := logging.FromContext(ctx).With("task_id", taskID)
+	if err := tm.freeze(ctx, task, true); err != nil {
+		// the processes are thawed again so that a failed pause does not leave them partly frozen
+		if thawErr := tm.freeze(context.WithoutCancel(ctx), task, false); thawErr != nil {
+			logger.Error("failed to thaw task after a failed pause", "error", thawErr)
+		}
+		return err
+	}
+	if !task.setPaused(time.Now()) {
+		// the process exited or was restarted while it was frozen
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task is no longer running")
+	}
+	logger.Info("paused task")
+	return nil
+}
+
+// ResumeTask thaws the processes of a paused task and returns once they run again. Resuming a running
+// task does nothing. The gRPC handler checks that the caller may access the task.
+func (tm *TaskManager) ResumeTask(ctx context.Context, taskID string) error {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return err
+	}
+
+	task.pauseMu.Lock()
+	defer task.pauseMu.Unlock()
+
+	switch status := task.GetStatus(); {
+	case status == basetask.JobStatusStarted && task.GetProcessID() > 0 && task.GetEndTime().IsZero():
+		return nil
+	case status != basetask.JobStatusPaused:
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "only paused tasks can be resumed")
+	}
+
+	if err := tm.freeze(ctx, task, false); err != nil {
+		return err
+	}
+	pausedFor := task.setResumed(time.Now())
+	logging.FromContext(ctx).Info("resumed task", "task_id", taskID, "paused_for", pausedFor)
+	return nil
+}
+
+// freeze freezes or thaws the cgroup of the task within freezeTimeout
+func (tm *TaskManager) freeze(ctx context.Context, task *Task, frozen bool) error {
+	freezeCtx, cancel := context.WithTimeout(ctx, freezeTimeout)
+	defer cancel()
+	if err := tm.freezeCgroup(freezeCtx, tm.cgroupPath(task), frozen); err != nil {
+		if !task.GetEndTime().IsZero() || task.GetProcessID() <= 0 {
+			return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task is no longer running")
+		}
+		action := "freeze"
+		if !frozen {
+			action = "thaw"
+		}
+		return basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to %s task", err, action)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// freezeRecorder records the calls of a stubbed freezeCgroup and fails freezing if failFreeze is set
+type freezeRecorder struct {
+	mu         sync.Mutex
+	calls      []bool
+	failFreeze bool
+}
+
+func (r *freezeRecorder) freeze(_ context.Context, _ string, frozen bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, frozen)
+	if frozen && r.failFreeze {
+		return errors.New("cgroup did not freeze")
+	}
+	return nil
+}
+
+func (r *freezeRecorder) recorded() []bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]bool(nil), r.calls...)
+}
+
+func TestPauseAndResumeTask(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	recorder := &freezeRecorder{}
+	tm.freezeCgroup = recorder.freeze
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	task := CreateNewTask("task", "client001", 4242, time.Now(), NewTaskWriter())
+	tm.addTask(task)
+
+	require.NoError(t, tm.PauseTask(ctx, "task"))
+	assert.Equal(t, basetask.JobStatusPaused, task.GetStatus())
+	// pausing a paused task does not freeze it again
+	require.NoError(t, tm.PauseTask(ctx, "task"))
+	assert.Equal(t, []bool{true}, recorder.recorded())
+
+	// signals other than SIGKILL are rejected while paused
+	var taskErr *basetask.TaskError
+	require.ErrorAs(t, tm.SignalTask(ctx, "task", syscall.SIGTERM), &taskErr)
+	assert.Equal(t, basetask.ErrFailedPrecondition, taskErr.Code)
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, tm.ResumeTask(ctx, "task"))
+	assert.Equal(t, basetask.JobStatusStarted, task.GetStatus())
+	paused := task.Snapshot().PausedDuration
+	assert.GreaterOrEqual(t, paused, 10*time.Millisecond)
+
+	// resuming a running task does not thaw it again and the paused time stays
+	require.NoError(t, tm.ResumeTask(ctx, "task"))
+	assert.Equal(t, []bool{true, false}, recorder.recorded())
+	assert.Equal(t, paused, task.Snapshot().PausedDuration)
+}
+
+func TestPauseTaskFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc          string
+		pid           int
+		status        int
+		failFreeze    bool
+		expectedCode  basetask.ErrorCode
+		expectedCalls []bool
+	}{
+		{
+			desc:          "freeze fails",
+			pid:           4242,
+			status:        basetask.JobStatusStarted,
+			failFreeze:    true,
+			expectedCode:  basetask.ErrInternal,
+			expectedCalls: []bool{true, false},
+		},
+		{desc: "queued task", status: basetask.JobStatusQueued, expectedCode: basetask.ErrFailedPrecondition},
+		{desc: "finished task", pid: 4242, status: basetask.JobStatusExitedOK, expectedCode: basetask.ErrFailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tm := NewTaskManager(context.Background())
+			recorder := &freezeRecorder{failFreeze: tt.failFreeze}
+			tm.freezeCgroup = recorder.freeze
+			ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+			task := CreateNewTask("task", "client001", tt.pid, time.Now(), NewTaskWriter())
+			task.SetStatus(tt.status)
+			tm.addTask(task)
+
+			var taskErr *basetask.TaskError
+			require.ErrorAs(t, tm.PauseTask(ctx, "task"), &taskErr)
+			assert.Equal(t, tt.expectedCode, taskErr.Code)
+			assert.Equal(t, tt.expectedCalls, recorder.recorded())
+			assert.Equal(t, tt.status, task.GetStatus())
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package task
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// terminationSourceStartFailed is the termination source of a queued task whose process could not be started
+const terminationSourceStartFailed = "start_failed"
+
+// queuedTask is a task waiting for a running task quota to free up
+type queuedTask struct {
+	task *Task
+	spec TaskSpec
+	// seq orders tasks of the same priority and share by arrival
+	seq uint64
+}
+
+// orderQueue sorts the queue in the order the tasks are started: by priority, then by the share of the
+// client so that clients with fewer running and earlier queued tasks go first, then by arrival.
+// The share of a queued task is the number of running tasks of its client plus the number of tasks of
+// the client queued before it with the same priority, so clients take turns within a priority.
+func orderQueue(queue []*queuedTask, runningByClient map[string]int) {
+	slices.SortFunc(queue, func(a, b *queuedTask) int {
+		if c := cmp.Compare(b.task.priority, a.task.priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	type clientPriority struct {
+		clientID string
+		priority int
+	}
+	shares := make(map[*queuedTask]int, len(queue))
+	queuedBefore := make(map[clientPriority]int)
+	for _, entry := range queue {
+		key := clientPriority{entry.task.clientID, entry.task.priority}
+		shares[entry] = runningByClient[entry.task.clientID] + queuedBefore[key]
+		queuedBefore[key]++
+	}
+
+	slices.SortStableFunc(queue, func(a, b *queuedTask) int {
+		if c := cmp.Compare(b.task.priority, a.task.priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(shares[a], shares[b])
+	})
+}
+
+// enqueue adds the task to the queue and to the manager. It must be called with quotaMu held.
+func (tm *TaskManager) enqueue(entry *queuedTask) {
+	tm.queueSeq++
+	entry.seq = tm.queueSeq
+	entry.task.SetStatus(basetask.JobStatusQueued)
+	tm.addTask(entry.task)
+	tm.queue = append(tm.queue, entry)
+	metrics.TasksQueued.Inc()
+	tm.updateQueuePositions()
+}
+
+// updateQueuePositions orders the queue and updates the position of every queued task. It must be
+// called with quotaMu held.
+func (tm *TaskManager) updateQueuePositions() {
+	orderQueue(tm.queue, tm.runningByClient)
+	for i, entry := range tm.queue {
+		entry.task.setQueuePosition(i + 1)
+	}
+}
+
+// schedule starts queued tasks in queue order while the running task quotas allow. Queued tasks of
+// clients that reached their own quota are skipped so that they do not block other clients.
+func (tm *TaskManager) schedule() {
+	if tm.ctx.Err() != nil {
+		return
+	}
+	for _, entry := range tm.dequeue() {
+		tm.launchQueued(entry)
+	}
+}
+
+// dequeue takes the tasks that the running task quotas allow to start from the queue and marks them as
+// being started, so that a stop before their process runs cancels them
+func (tm *TaskManager) dequeue() []*queuedTask {
+	tm.quotaMu.Lock()
+	defer tm.quotaMu.Unlock()
+
+	var launches []*queuedTask
+	for len(tm.queue) > 0 {
+		orderQueue(tm.queue, tm.runningByClient)
+		i := slices.IndexFunc(tm.queue, func(entry *queuedTask) bool {
+			return !tm.runningQuotaReached(entry.task.clientID)
+		})
+		if i < 0 {
+			break
+		}
+		entry := tm.queue[i]
+		tm.queue = slices.Delete(tm.queue, i, i+1)
+		tm.runningByClient[entry.task.clientID]++
+		tm.runningTotal++
+		metrics.TasksQueued.Dec()
+		entry.task.beginLaunch()
+		launches = append(launches, entry)
+	}
+	tm.updateQueuePositions()
+	return launches
+}
+
+// launchQueued starts the process of a task taken from the queue. If it cannot be started the task
+// finishes with an unknown status, the failed start is audited and its running task is given back.
+// A task stopped since it was taken from the queue finishes as canceled instead, and a process started
+// while the stop came is killed.
+func (tm *TaskManager) launchQueued(entry *queuedTask) {
+	task := entry.task
+	logger := slog.Default().With("task_id", task.GetID(), "client_id", task.GetClientID())
+	if source, stopped := task.launchStopped(); stopped {
+		task.endLaunch()
+		logger.Info("queued task stopped before it started")
+		tm.finishUnstarted(task, basetask.JobStatusCanceled, source, audit.ActionCancel, audit.OutcomeSuccess)
+		tm.releaseRunning(task.GetClientID())
+		return
+	}
+	logger.Info("starting queued task", "command", entry.spec.Command, "args", entry.spec.Args, "priority", task.priority)
+
+	cmd, err := tm.launch(logger, task, entry.spec)
+	source, stopped := task.endLaunch()
+	if err != nil {
+		logger.Error("failed to start queued task", "error", err)
+		tm.finishUnstarted(task, basetask.JobStatusUnknown, terminationSourceStartFailed, audit.ActionStart, audit.OutcomeFailure)
+		tm.releaseRunning(task.GetClientID())
+		return
+	}
+	go tm.monitorProcess(task.GetID(), cmd)
+	if stopped {
+		task.SetTerminationSource(source)
+		if err := syscall.Kill(-task.GetProcessID(), syscall.SIGKILL); err != nil {
+			logger.Error("failed to kill queued task stopped while it started", "error", err)
+		}
+	}
+}
+
+// cancelQueued removes the task from the queue and finishes it as canceled by the source. It returns
+// false if the task is no longer queued.
+func (tm *TaskManager) cancelQueued(task *Task, source string) bool {
+	tm.quotaMu.Lock()
+	i := slices.IndexFunc(tm.queue, func(entry *queuedTask) bool {
+		return entry.task == task
+	})
+	if i < 0 {
+		tm.quotaMu.Unlock()
+		return false
+	}
+	tm.queue = slices.Delete(tm.queue, i, i+1)
+	metrics.TasksQueued.Dec()
+	tm.updateQueuePositions()
+	tm.quotaMu.Unlock()
+
+	tm.finishUnstarted(task, basetask.JobStatusCanceled, source, audit.ActionCancel, audit.OutcomeSuccess)
+	return true
+}
+
+// cancelQueue finishes every queued task when the manager shuts down
+func (tm *TaskManager) cancelQueue() {
+	tm.quotaMu.Lock()
+	queue := tm.queue
+	tm.queue = nil
+	metrics.TasksQueued.Sub(float64(len(queue)))
+	tm.quotaMu.Unlock()
+
+	for _, entry := range queue {
+		tm.finishUnstarted(entry.task, basetask.JobStatusCanceled, "system", audit.ActionCancel, audit.OutcomeSuccess)
+	}
+}
+
+// finishUnstarted completes a task whose process was never started and audits it with the action
+// and outcome
+func (tm *TaskManager) finishUnstarted(task *Task, status int, source, action, outcome string) {
+	task.setFinishedUnstarted(status, source, time.Now())
+	metrics.TasksFinished.WithLabelValues(source).Inc()
+	task.closeWriter()
+	tm.auditLog.Log(audit.Event{
+		Action:            action,
+		ClientID:          task.GetClientID(),
+		TaskID:            task.GetID(),
+		TerminationSource: source,
+		Outcome:           outcome,
+	})
+	close(task.done)
+}
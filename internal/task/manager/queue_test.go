@@ -0,0 +1,168 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestOrderQueue(t *testing.T) {
+	t.Parallel()
+
+	var seq uint64
+	entry := func(id, clientID string, priority int) *queuedTask {
+		seq++
+		return &queuedTask{task: &Task{id: id, clientID: clientID, priority: priority}, seq: seq}
+	}
+
+	tests := []struct {
+		desc     string
+		queue    []*queuedTask
+		running  map[string]int
+		expected []string
+	}{
+		{
+			desc: "arrival order",
+			queue: []*queuedTask{
+				entry("a", "client001", 0),
+				entry("b", "client002", 0),
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			desc: "higher priority first",
+			queue: []*queuedTask{
+				entry("low", "client001", -1),
+				entry("default", "client001", 0),
+				entry("high", "client001", 10),
+			},
+			expected: []string{"high", "default", "low"},
+		},
+		{
+			desc: "clients take turns",
+			queue: []*queuedTask{
+				entry("a1", "client001", 0),
+				entry("a2", "client001", 0),
+				entry("a3", "client001", 0),
+				entry("b1", "client002", 0),
+				entry("b2", "client002", 0),
+			},
+			expected: []string{"a1", "b1", "a2", "b2", "a3"},
+		},
+		{
+			desc: "clients with fewer running tasks first",
+			queue: []*queuedTask{
+				entry("a1", "client001", 0),
+				entry("b1", "client002", 0),
+				entry("b2", "client002", 0),
+			},
+			running:  map[string]int{"client001": 2},
+			expected: []string{"b1", "b2", "a1"},
+		},
+		{
+			desc: "priority before share",
+			queue: []*queuedTask{
+				entry("a1", "client001", 5),
+				entry("a2", "client001", 5),
+				entry("b1", "client002", 0),
+			},
+			expected: []string{"a1", "a2", "b1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			orderQueue(tt.queue, tt.running)
+			ids := make([]string, 0, len(tt.queue))
+			for _, entry := range tt.queue {
+				ids = append(ids, entry.task.id)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestAdmitStartQueue(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithQuotaPolicy(QuotaPolicy{MaxRunningPerClient: 1, MaxQueued: 2}))
+	newEntry := func(id string) *queuedTask {
+		return &queuedTask{task: CreateNewTask(id, "client001", 0, time.Time{}, NewTaskWriter())}
+	}
+
+	queued, err := tm.admitStart("client001", time.Now(), newEntry("running"))
+	require.NoError(t, err)
+	assert.False(t, queued)
+
+	first, second := newEntry("first"), newEntry("second")
+	for _, entry := range []*queuedTask{first, second} {
+		queued, err = tm.admitStart("client001", time.Now(), entry)
+		require.NoError(t, err)
+		assert.True(t, queued)
+		assert.Equal(t, basetask.JobStatusQueued, entry.task.GetStatus())
+	}
+	assert.Equal(t, 1, first.task.GetQueuePosition())
+	assert.Equal(t, 2, second.task.GetQueuePosition())
+	assert.Equal(t, 2, tm.GetQuota("client001").GlobalQueued)
+
+	// the queue is full
+	_, err = tm.admitStart("client001", time.Now(), newEntry("third"))
+	var taskErr *basetask.TaskError
+	require.True(t, errors.As(err, &taskErr))
+	assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+	assert.Equal(t, QuotaQueued, taskErr.Violations[0].Description)
+
+	// canceling a queued task moves the tasks behind it up
+	require.True(t, tm.cancelQueued(first.task, "user"))
+	assert.False(t, tm.cancelQueued(first.task, "user"))
+	<-first.task.Done()
+	snapshot := first.task.Snapshot()
+	assert.Equal(t, basetask.JobStatusCanceled, snapshot.Status)
+	assert.Equal(t, "user", snapshot.TerminationSource)
+	assert.Zero(t, snapshot.QueuePosition)
+	assert.Equal(t, 1, second.task.GetQueuePosition())
+}
+
+func TestStopTaskBetweenDequeueAndLaunch(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithQuotaPolicy(QuotaPolicy{MaxRunningPerClient: 1, MaxQueued: 1}))
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+	_, err := tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+	entry := &queuedTask{
+		task: CreateNewTask("queued", "client001", 0, time.Time{}, NewTaskWriter()),
+		spec: TaskSpec{Command: "/nonexistent"},
+	}
+	queued, err := tm.admitStart("client001", time.Now(), entry)
+	require.NoError(t, err)
+	require.True(t, queued)
+
+	// the running task finishes without starting the queued one, which is then taken from the queue
+	tm.quotaMu.Lock()
+	tm.runningByClient["client001"]--
+	tm.runningTotal--
+	tm.quotaMu.Unlock()
+	launches := tm.dequeue()
+	require.Equal(t, []*queuedTask{entry}, launches)
+	assert.Equal(t, basetask.JobStatusQueued, entry.task.GetStatus())
+
+	// the stop is kept for the launch, which finishes the task as canceled instead of starting it
+	require.NoError(t, tm.StopTask(ctx, "queued"))
+	tm.launchQueued(entry)
+	<-entry.task.Done()
+	snapshot := entry.task.Snapshot()
+	assert.Equal(t, basetask.JobStatusCanceled, snapshot.Status)
+	assert.Equal(t, "user", snapshot.TerminationSource)
+	assert.Zero(t, tm.GetQuota("client001").Running)
+}
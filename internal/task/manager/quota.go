@@ -0,0 +1,175 @@
+package task
+
+import (
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// Quotas recorded in rejections and metrics
+const (
+	QuotaRunningPerClient = "running_per_client"
+	QuotaRunning          = "running"
+	QuotaStartsPerMinute  = "starts_per_minute"
+	QuotaQueued           = "queued"
+	QuotaCPUPerClient     = "cpu_millis_per_client"
+	QuotaMemoryPerClient  = "memory_per_client"
+)
+
+// startRateWindow is the window of the starts per minute quota
+const startRateWindow = time.Minute
+
+// QuotaPolicy limits how many tasks clients may run and start. A zero limit disables that limit.
+type QuotaPolicy struct {
+	// MaxRunningPerClient is the maximum number of running tasks of a single client
+	MaxRunningPerClient int
+	// MaxRunning is the maximum number of running tasks of all clients
+	MaxRunning int
+	// MaxStartsPerMinute is the maximum number of tasks a single client may start in any minute
+	MaxStartsPerMinute int
+	// MaxQueued is the maximum number of tasks queued while a running task quota is reached.
+	// Starts are rejected instead of queued if it is zero.
+	MaxQueued int
+	// MaxCPUMillisPerClient and MaxMemoryPerClient bound the sum of the CPU and memory.max limits of the
+	// running tasks of a single client when the client updates the resource limits of its tasks
+	MaxCPUMillisPerClient int64
+	MaxMemoryPerClient    int64
+}
+
+// WithQuotaPolicy rejects starts that would exceed the quotas of the policy
+func WithQuotaPolicy(policy QuotaPolicy) Option {
+	return func(tm *TaskManager) {
+		tm.quota = policy
+	}
+}
+
+// QuotaUsage is the usage of a client against the quota policy. Limits of zero are unlimited.
+type QuotaUsage struct {
+	ClientID            string
+	Running             int
+	MaxRunningPerClient int
+	GlobalRunning       int
+	MaxRunning          int
+	StartsLastMinute    int
+	MaxStartsPerMinute  int
+	GlobalQueued        int
+	MaxQueued           int
+}
+
+// GetQuota returns the quota usage of the client
+func (tm *TaskManager) GetQuota(clientID string) QuotaUsage {
+	tm.quotaMu.Lock()
+	defer tm.quotaMu.Unlock()
+	return QuotaUsage{
+		ClientID:            clientID,
+		Running:             tm.runningByClient[clientID],
+		MaxRunningPerClient: tm.quota.MaxRunningPerClient,
+		GlobalRunning:       tm.runningTotal,
+		MaxRunning:          tm.quota.MaxRunning,
+		StartsLastMinute:    len(tm.recentStarts(clientID, time.Now())),
+		MaxStartsPerMinute:  tm.quota.MaxStartsPerMinute,
+		GlobalQueued:        len(tm.queue),
+		MaxQueued:           tm.quota.MaxQueued,
+	}
+}
+
+// admitStart reserves a running task and a start of the client. If a running task quota is reached
+// and queueing is enabled the entry is queued instead and true is returned. Otherwise an
+// ErrResourceExhausted error names the quota that was reached. The reservation must be given back
+// with releaseRunning if the start fails or once the task has exited; the start itself keeps counting
+// against the rate.
+func (tm *TaskManager) admitStart(clientID string, now time.Time, entry *queuedTask) (bool, error) {
+	tm.quotaMu.Lock()
+	defer tm.quotaMu.Unlock()
+
+	starts := tm.recentStarts(clientID, now)
+	if tm.quota.MaxStartsPerMinute > 0 && len(starts) >= tm.quota.MaxStartsPerMinute {
+		return false, quotaError(QuotaStartsPerMinute, "client:"+clientID,
+			"quota exceeded: client %s already started %d of at most %d tasks in the last minute",
+			clientID, len(starts), tm.quota.MaxStartsPerMinute)
+	}
+
+	if err := tm.checkRunning(clientID); err != nil {
+		if entry == nil || tm.quota.MaxQueued <= 0 {
+			return false, err
+		}
+		if len(tm.queue) >= tm.quota.MaxQueued {
+			return false, quotaError(QuotaQueued, "global",
+				"quota exceeded: %d of at most %d tasks are already queued", len(tm.queue), tm.quota.MaxQueued)
+		}
+		tm.recordStart(clientID, starts, now)
+		tm.enqueue(entry)
+		return true, nil
+	}
+
+	tm.runningByClient[clientID]++
+	tm.runningTotal++
+	tm.recordStart(clientID, starts, now)
+	return false, nil
+}
+
+// checkRunning returns an ErrResourceExhausted error if the client cannot run another task. It must be
+// called with quotaMu held.
+func (tm *TaskManager) checkRunning(clientID string) error {
+	if tm.quota.MaxRunningPerClient > 0 && tm.runningByClient[clientID] >= tm.quota.MaxRunningPerClient {
+		return quotaError(QuotaRunningPerClient, "client:"+clientID,
+			"quota exceeded: client %s already runs %d of at most %d tasks",
+			clientID, tm.runningByClient[clientID], tm.quota.MaxRunningPerClient)
+	}
+	if tm.quota.MaxRunning > 0 && tm.runningTotal >= tm.quota.MaxRunning {
+		return quotaError(QuotaRunning, "global",
+			"quota exceeded: the server already runs %d of at most %d tasks", tm.runningTotal, tm.quota.MaxRunning)
+	}
+	return nil
+}
+
+// runningQuotaReached reports whether the client cannot run another task. It must be called with quotaMu held.
+func (tm *TaskManager) runningQuotaReached(clientID string) bool {
+	return (tm.quota.MaxRunningPerClient > 0 && tm.runningByClient[clientID] >= tm.quota.MaxRunningPerClient) ||
+		(tm.quota.MaxRunning > 0 && tm.runningTotal >= tm.quota.MaxRunning)
+}
+
+// recordStart counts a start of the client against the rate. It must be called with quotaMu held.
+func (tm *TaskManager) recordStart(clientID string, starts []time.Time, now time.Time) {
+	if tm.quota.MaxStartsPerMinute > 0 {
+		tm.startTimes[clientID] = append(starts, now)
+	}
+}
+
+// quotaError records the rejection by the quota and returns an ErrResourceExhausted error for it
+func quotaError(quota, subject, format string, args ...any) error {
+	metrics.QuotaRejections.WithLabelValues(quota).Inc()
+	return basetask.NewQuotaError(basetask.QuotaViolation{Subject: subject, Description: quota}, format, args...)
+}
+
+// releaseRunning gives back the running task reserved by admitStart and starts the queued tasks
+// that fit into the freed quota
+func (tm *TaskManager) releaseRunning(clientID string) {
+	tm.quotaMu.Lock()
+	tm.runningByClient[clientID]--
+	if tm.runningByClient[clientID] <= 0 {
+		delete(tm.runningByClient, clientID)
+	}
+	tm.runningTotal--
+	tm.quotaMu.Unlock()
+
+	tm.schedule()
+}
+
+// recentStarts drops the starts of the client that are outside of the rate window and returns the
+// remaining ones. It must be called with quotaMu held.
+func (tm *TaskManager) recentStarts(clientID string, now time.Time) []time.Time {
+	starts := tm.startTimes[clientID]
+	i := 0
+	for i < len(starts) && now.Sub(starts[i]) >= startRateWindow {
+		i++
+	}
+	starts = starts[i:]
+	if len(starts) == 0 {
+		delete(tm.startTimes, clientID)
+		return nil
+	}
+	tm.startTimes[clientID] = starts
+	return starts
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestAdmitStart(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc string
+		// admitted are the clients of the starts admitted at now before the tested start
+		policy   QuotaPolicy
+		admitted []string
+		clientID string
+		at       time.Time
+		// expected is the quota reached or empty if the start is admitted
+		expected string
+	}{
+		{
+			desc:     "no limits",
+			admitted: []string{"client001", "client001", "client002"},
+			clientID: "client001",
+			at:       now,
+		},
+		{
+			desc:     "per client running limit",
+			policy:   QuotaPolicy{MaxRunningPerClient: 2},
+			admitted: []string{"client001", "client001"},
+			clientID: "client001",
+			at:       now,
+			expected: QuotaRunningPerClient,
+		},
+		{
+			desc:     "per client running limit of another client",
+			policy:   QuotaPolicy{MaxRunningPerClient: 2},
+			admitted: []string{"client001", "client001"},
+			clientID: "client002",
+			at:       now,
+		},
+		{
+			desc:     "global running limit",
+			policy:   QuotaPolicy{MaxRunningPerClient: 2, MaxRunning: 2},
+			admitted: []string{"client001", "client002"},
+			clientID: "client003",
+			at:       now,
+			expected: QuotaRunning,
+		},
+		{
+			desc:     "starts per minute",
+			policy:   QuotaPolicy{MaxStartsPerMinute: 2},
+			admitted: []string{"client001", "client001"},
+			clientID: "client001",
+			at:       now.Add(59 * time.Second),
+			expected: QuotaStartsPerMinute,
+		},
+		{
+			desc:     "starts per minute after the window",
+			policy:   QuotaPolicy{MaxStartsPerMinute: 2},
+			admitted: []string{"client001", "client001"},
+			clientID: "client001",
+			at:       now.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tm := NewTaskManager(context.Background(), WithQuotaPolicy(tt.policy))
+			for _, clientID := range tt.admitted {
+				_, err := tm.admitStart(clientID, now, nil)
+				require.NoError(t, err)
+			}
+
+			queued, err := tm.admitStart(tt.clientID, tt.at, nil)
+			assert.False(t, queued)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var taskErr *basetask.TaskError
+			require.True(t, errors.As(err, &taskErr))
+			assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+			require.Len(t, taskErr.Violations, 1)
+			assert.Equal(t, tt.expected, taskErr.Violations[0].Description)
+		})
+	}
+}
+
+func TestReleaseRunning(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithQuotaPolicy(QuotaPolicy{MaxRunningPerClient: 1, MaxStartsPerMinute: 5}))
+	_, err := tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+	_, err = tm.admitStart("client001", time.Now(), nil)
+	require.Error(t, err)
+
+	tm.releaseRunning("client001")
+	usage := tm.GetQuota("client001")
+	assert.Equal(t, 0, usage.Running)
+	assert.Equal(t, 0, usage.GlobalRunning)
+	// released tasks still count against the start rate
+	assert.Equal(t, 1, usage.StartsLastMinute)
+	_, err = tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,304 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/logging"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+)
+
+// maxResourceChanges bounds the resource limit changes kept for the status of a task
+const maxResourceChanges = 20
+
+// ResourceUpdate changes the resource limits of a task. Nil fields keep their current limit and zero
+// removes the limit.
+type ResourceUpdate struct {
+	CPUMillis  *int64
+	MemoryMax  *int64
+	MemoryHigh *int64
+	IOReadBPS  *int64
+	IOWriteBPS *int64
+	PidsMax    *int64
+	// CPUs and Mems pin the task to CPUs and NUMA memory nodes; an empty list unpins it
+	CPUs *string
+	Mems *string
+}
+
+// ResourceChange is a change of the resource limits of a task
+type ResourceChange struct {
+	Time time.Time
+	// ClientID is the client that changed the limits
+	ClientID string
+	Previous cgroups.Limits
+	Limits   cgroups.Limits
+}
+
+// WithResourceCeilings rejects resource updates that would remove or raise a limit above its ceiling.
+// A zero ceiling does not restrict that limit.
+func WithResourceCeilings(ceilings cgroups.Limits) Option {
+	return func(tm *TaskManager) {
+		tm.ceilings = ceilings
+	}
+}
+
+// WithIODevices applies the IO limits of the tasks to the block devices given by major:minor number.
+// Without IO devices the IO limits are recorded but not enforced.
+func WithIODevices(ioDevices []string) Option {
+	return func(tm *TaskManager) {
+		tm.ioDevices = ioDevices
+	}
+}
+
+// apply returns the limits with the update applied
+func (u ResourceUpdate) apply(limits cgroups.Limits) cgroups.Limits {
+	for _, f := range []struct {
+		value *int64
+		limit *int64
+	}{
+		{u.CPUMillis, &limits.CPUMillis},
+		{u.MemoryMax, &limits.MemoryMax},
+		{u.MemoryHigh, &limits.MemoryHigh},
+		{u.IOReadBPS, &limits.IOReadBPS},
+		{u.IOWriteBPS, &limits.IOWriteBPS},
+		{u.PidsMax, &limits.PidsMax},
+	} {
+		if f.value != nil {
+			*f.limit = *f.value
+		}
+	}
+	if u.CPUs != nil {
+		limits.CPUs = *u.CPUs
+	}
+	if u.Mems != nil {
+		limits.Mems = *u.Mems
+	}
+	return limits
+}
+
+// equal reports whether both updates set the same limits
+func (u ResourceUpdate) equal(other ResourceUpdate) bool {
+	return equalLimit(u.CPUMillis, other.CPUMillis) && equalLimit(u.MemoryMax, other.MemoryMax) &&
+		equalLimit(u.MemoryHigh, other.MemoryHigh) && equalLimit(u.IOReadBPS, other.IOReadBPS) &&
+		equalLimit(u.IOWriteBPS, other.IOWriteBPS) && equalLimit(u.PidsMax, other.PidsMax) &&
+		equalLimit(u.CPUs, other.CPUs) && equalLimit(u.Mems, other.Mems)
+}
+
+// equalLimit reports whether both limits are unset or set to the same value
+func equalLimit[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// empty reports whether the update changes no limit
+func (u ResourceUpdate) empty() bool {
+	return u.CPUMillis == nil && u.MemoryMax == nil && u.MemoryHigh == nil && u.IOReadBPS == nil && u.IOWriteBPS == nil &&
+		u.PidsMax == nil && u.CPUs == nil && u.Mems == nil
+}
+
+// specLimits returns the default limits with the limits of the spec applied, checked against the ceilings
+func (tm *TaskManager) specLimits(spec TaskSpec) (cgroups.Limits, error) {
+	if spec.Limits.empty() {
+		return tm.defaultLimits(), nil
+	}
+	limits := spec.Limits.apply(tm.defaultLimits())
+	if err := tm.checkLimits(limits); err != nil {
+		return cgroups.Limits{}, err
+	}
+	return limits, nil
+}
+
+// defaultLimits are the limits of a new task, lowered to the ceilings below them so that no task starts
+// above a limit that an update could not set. Tasks only get the default pids.max where the pids
+// controller is enabled.
+func (tm *TaskManager) defaultLimits() cgroups.Limits {
+	limits := cgroups.DefaultLimits
+	for _, l := range []struct {
+		limit   *int64
+		ceiling int64
+	}{
+		{&limits.CPUMillis, tm.ceilings.CPUMillis},
+		{&limits.MemoryMax, tm.ceilings.MemoryMax},
+		{&limits.MemoryHigh, tm.ceilings.MemoryHigh},
+		{&limits.IOReadBPS, tm.ceilings.IOReadBPS},
+		{&limits.IOWriteBPS, tm.ceilings.IOWriteBPS},
+		{&limits.PidsMax, tm.ceilings.PidsMax},
+	} {
+		if l.ceiling > 0 && (*l.limit == 0 || *l.limit > l.ceiling) {
+			*l.limit = l.ceiling
+		}
+	}
+	if !tm.hierarchy.ControllerEnabled("pids") {
+		limits.PidsMax = 0
+	}
+	return limits
+}
+
+// checkLimits checks the limits against the ceilings. Without the pids controller a pids limit is
+// rejected and the pids ceiling does not apply; without the cpuset controller pinning is rejected, so
+// that a task is not started or left without the limits it was given.
+func (tm *TaskManager) checkLimits(limits cgroups.Limits) error {
+	if (limits.CPUs != "" || limits.Mems != "") && !tm.hierarchy.ControllerEnabled("cpuset") {
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "pinning requires the cpuset cgroup controller")
+	}
+	ceilings := tm.ceilings
+	if !tm.hierarchy.ControllerEnabled("pids") {
+		if limits.PidsMax > 0 {
+			return basetask.NewTaskError(basetask.ErrFailedPrecondition, "pids limit requires the pids cgroup controller")
+		}
+		ceilings.PidsMax = 0
+	}
+	return validateLimits(limits, ceilings)
+}
+
+// validateLimits checks the limits against the ceilings
+func validateLimits(limits, ceilings cgroups.Limits) error {
+	for _, l := range []struct {
+		name    string
+		limit   int64
+		ceiling int64
+	}{
+		{"cpu", limits.CPUMillis, ceilings.CPUMillis},
+		{"memory.max", limits.MemoryMax, ceilings.MemoryMax},
+		{"memory.high", limits.MemoryHigh, ceilings.MemoryHigh},
+		{"io read", limits.IOReadBPS, ceilings.IOReadBPS},
+		{"io write", limits.IOWriteBPS, ceilings.IOWriteBPS},
+		{"pids", limits.PidsMax, ceilings.PidsMax},
+	} {
+		switch {
+		case l.limit < 0:
+			return basetask.NewTaskError(basetask.ErrInvalidArgument, "%s limit must not be negative", l.name)
+		case l.ceiling > 0 && l.limit == 0:
+			return basetask.NewTaskError(basetask.ErrInvalidArgument, "%s cannot be unlimited: the server ceiling is %d", l.name, l.ceiling)
+		case l.ceiling > 0 && l.limit > l.ceiling:
+			return basetask.NewTaskError(basetask.ErrInvalidArgument, "%s limit %d exceeds the server ceiling of %d", l.name, l.limit, l.ceiling)
+		}
+	}
+	if limits.MemoryMax > 0 && limits.MemoryHigh > limits.MemoryMax {
+		return basetask.NewTaskError(basetask.ErrInvalidArgument, "memory.high must not exceed memory.max")
+	}
+	if err := cgroups.ValidateCPUList(limits.CPUs); err != nil {
+		return basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid cpuset cpus", err)
+	}
+	if err := cgroups.ValidateCPUList(limits.Mems); err != nil {
+		return basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid cpuset mems", err)
+	}
+	return nil
+}
+
+// UpdateTaskResources changes the resource limits of an unfinished task and returns its effective limits.
+// The limits of a running process are rewritten in its cgroup right away; a queued or waiting task and
+// restarted or retried processes start with them. Raised limits are checked against the resource quotas
+// of the owner if enforceQuota is set; the gRPC handler checks that the caller may access the task and
+// exempts admins from the quotas. Every change is recorded in the status of the task with the caller.
+func (tm *TaskManager) UpdateTaskResources(ctx context.Context, taskID string, update ResourceUpdate, enforceQuota bool) (cgroups.Limits, error) {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return cgroups.Limits{}, err
+	}
+
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	if update.empty() {
+		return cgroups.Limits{}, basetask.NewTaskError(basetask.ErrInvalidArgument, "no resource limit to update")
+	}
+
+	// updates are serialized so that the resource quota of a client is checked against its current limits
+	tm.resourcesMu.Lock()
+	defer tm.resourcesMu.Unlock()
+	task.limitsMu.Lock()
+	defer task.limitsMu.Unlock()
+
+	if !task.GetEndTime().IsZero() {
+		return cgroups.Limits{}, basetask.NewTaskError(basetask.ErrFailedPrecondition, "task has already completed")
+	}
+	previous := task.getLimits()
+	limits := update.apply(previous)
+	if err := tm.checkLimits(limits); err != nil {
+		return cgroups.Limits{}, err
+	}
+	if enforceQuota {
+		if err := tm.checkResourceQuota(task.GetClientID(), taskID, previous, limits); err != nil {
+			return cgroups.Limits{}, err
+		}
+	}
+
+	// the cgroup only exists while a process runs; otherwise the limits apply to the next process
+	if err := tm.setCgroupLimits(tm.cgroupPath(task), limits, tm.ioDevices); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cgroups.Limits{}, basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to update the cgroup limits", err)
+	}
+	task.setLimits(ResourceChange{Time: time.Now(), ClientID: caller, Previous: previous, Limits: limits})
+	logging.FromContext(ctx).Info("updated task resources", "task_id", taskID, "previous", previous, "limits", limits)
+	return limits, nil
+}
+
+// PidsMaxEvents returns how often a fork of the current or last process of the task failed because
+// the task reached its pids.max. It is read from the cgroup while the process runs.
+func (tm *TaskManager) PidsMaxEvents(task *Task) int64 {
+	if task.running() {
+		if events, err := cgroups.PidsMaxEvents(tm.cgroupPath(task)); err == nil {
+			return events
+		}
+	}
+	return task.lastPidsMaxEvents()
+}
+
+// checkStartResourceQuota checks the limits of a new task against the resource quotas of the client like
+// an update raising the default limits to them
+func (tm *TaskManager) checkStartResourceQuota(clientID string, limits cgroups.Limits) error {
+	tm.resourcesMu.Lock()
+	defer tm.resourcesMu.Unlock()
+	return tm.checkResourceQuota(clientID, "", tm.defaultLimits(), limits)
+}
+
+// raised reports whether the limit is higher than the previous one; unlimited is the highest limit
+func raised(previous, limit int64) bool {
+	return previous != 0 && (limit == 0 || limit > previous)
+}
+
+// checkResourceQuota returns an ErrResourceExhausted error if the task raises a limit and its limits
+// together with the limits of the other running tasks of the client exceed the resource quotas. An
+// unlimited limit exceeds any quota. Lowering a limit is always allowed.
+func (tm *TaskManager) checkResourceQuota(clientID, taskID string, previous, limits cgroups.Limits) error {
+	checkCPU := tm.quota.MaxCPUMillisPerClient > 0 && raised(previous.CPUMillis, limits.CPUMillis)
+	checkMemory := tm.quota.MaxMemoryPerClient > 0 && raised(previous.MemoryMax, limits.MemoryMax)
+	if !checkCPU && !checkMemory {
+		return nil
+	}
+
+	cpu, memory := limits.CPUMillis, limits.MemoryMax
+	unlimitedCPU, unlimitedMemory := cpu == 0, memory == 0
+	tm.mu.RLock()
+	for _, task := range tm.tasksMapByID {
+		if task.GetID() == taskID || task.GetClientID() != clientID || !task.running() {
+			continue
+		}
+		other := task.getLimits()
+		cpu += other.CPUMillis
+		memory += other.MemoryMax
+		unlimitedCPU = unlimitedCPU || other.CPUMillis == 0
+		unlimitedMemory = unlimitedMemory || other.MemoryMax == 0
+	}
+	tm.mu.RUnlock()
+
+	if limit := tm.quota.MaxCPUMillisPerClient; checkCPU && unlimitedCPU {
+		return quotaError(QuotaCPUPerClient, "client:"+clientID,
+			"quota exceeded: client %s may use at most %d CPU millis so its running tasks need a CPU limit", clientID, limit)
+	} else if checkCPU && cpu > limit {
+		return quotaError(QuotaCPUPerClient, "client:"+clientID,
+			"quota exceeded: the running tasks of client %s would use %d of at most %d CPU millis", clientID, cpu, limit)
+	}
+	if limit := tm.quota.MaxMemoryPerClient; checkMemory && unlimitedMemory {
+		return quotaError(QuotaMemoryPerClient, "client:"+clientID,
+			"quota exceeded: client %s may use at most %d bytes of memory so its running tasks need a memory.max", clientID, limit)
+	} else if checkMemory && memory > limit {
+		return quotaError(QuotaMemoryPerClient, "client:"+clientID,
+			"quota exceeded: the running tasks of client %s would use %d of at most %d bytes of memory", clientID, memory, limit)
+	}
+	return nil
+}
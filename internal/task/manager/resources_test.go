@@ -0,0 +1,250 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+)
+
+// limitsRecorder records the limits written by a stubbed setCgroupLimits by cgroup path
+type limitsRecorder struct {
+	mu      sync.Mutex
+	written map[string]cgroups.Limits
+}
+
+func (r *limitsRecorder) set(cgroupPath string, limits cgroups.Limits, _ []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if filepath.Base(cgroupPath) == "queued" {
+		return fmt.Errorf("failed to write cpu.max: %w", fs.ErrNotExist)
+	}
+	r.written[cgroupPath] = limits
+	return nil
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestValidateLimits(t *testing.T) {
+	t.Parallel()
+
+	ceilings := cgroups.Limits{CPUMillis: 1000, MemoryMax: 1 << 30, IOReadBPS: 10 << 20, PidsMax: 1024}
+	tests := []struct {
+		desc        string
+		limits      cgroups.Limits
+		expectedErr bool
+	}{
+		{desc: "default limits", limits: cgroups.DefaultLimits},
+		{desc: "at the ceilings", limits: cgroups.Limits{CPUMillis: 1000, MemoryMax: 1 << 30, IOReadBPS: 10 << 20, PidsMax: 1024}},
+		{desc: "unlimited without a ceiling", limits: cgroups.Limits{CPUMillis: 1000, MemoryMax: 1 << 30, IOReadBPS: 1, PidsMax: 1}},
+		{desc: "above a ceiling", limits: cgroups.Limits{CPUMillis: 1001, MemoryMax: 1 << 20, IOReadBPS: 1, PidsMax: 1}, expectedErr: true},
+		{desc: "pids above the ceiling", limits: cgroups.Limits{CPUMillis: 100, MemoryMax: 1 << 20, IOReadBPS: 1, PidsMax: 1025}, expectedErr: true},
+		{desc: "unlimited with a ceiling", limits: cgroups.Limits{CPUMillis: 100, IOReadBPS: 1, PidsMax: 1}, expectedErr: true},
+		{desc: "negative", limits: cgroups.Limits{CPUMillis: 100, MemoryMax: 1, IOReadBPS: 1, IOWriteBPS: -1, PidsMax: 1}, expectedErr: true},
+		{desc: "pinned", limits: cgroups.Limits{CPUMillis: 100, MemoryMax: 1, IOReadBPS: 1, PidsMax: 1, CPUs: "0-3,8", Mems: "0"}},
+		{
+			desc:        "invalid cpu list",
+			limits:      cgroups.Limits{CPUMillis: 100, MemoryMax: 1, IOReadBPS: 1, PidsMax: 1, CPUs: "3-1"},
+			expectedErr: true,
+		},
+		{
+			desc:        "memory.high above memory.max",
+			limits:      cgroups.Limits{CPUMillis: 100, MemoryMax: 1 << 20, MemoryHigh: 2 << 20, IOReadBPS: 1, PidsMax: 1},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateLimits(tt.limits, ceilings)
+			if tt.expectedErr {
+				var taskErr *basetask.TaskError
+				require.ErrorAs(t, err, &taskErr)
+				assert.Equal(t, basetask.ErrInvalidArgument, taskErr.Code)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSpecLimits(t *testing.T) {
+	t.Parallel()
+
+	// the parent cgroup does not exist, so the pids controller is not enabled
+	hierarchy, err := cgroups.NewHierarchy("taskman-test-missing.slice", cgroups.Limits{})
+	require.NoError(t, err)
+	tm := NewTaskManager(context.Background(), WithCgroupHierarchy(hierarchy),
+		WithResourceCeilings(cgroups.Limits{MemoryMax: 1 << 30, PidsMax: 4096}))
+
+	// tasks get no default pids.max without the pids controller
+	limits, err := tm.specLimits(TaskSpec{Command: "true"})
+	require.NoError(t, err)
+	expected := cgroups.DefaultLimits
+	expected.PidsMax = 0
+	assert.Equal(t, expected, limits)
+
+	// unset limits keep the defaults
+	limits, err = tm.specLimits(TaskSpec{Command: "true", Limits: ResourceUpdate{MemoryMax: int64Ptr(256 << 20)}})
+	require.NoError(t, err)
+	expected.MemoryMax = 256 << 20
+	assert.Equal(t, expected, limits)
+
+	var taskErr *basetask.TaskError
+	_, err = tm.specLimits(TaskSpec{Command: "true", Limits: ResourceUpdate{MemoryMax: int64Ptr(0)}})
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrInvalidArgument, taskErr.Code)
+
+	_, err = tm.specLimits(TaskSpec{Command: "true", Limits: ResourceUpdate{PidsMax: int64Ptr(100)}})
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrFailedPrecondition, taskErr.Code)
+
+	// pinning a task without the cpuset controller is rejected before its cgroup is created
+	cpus := "0-1"
+	_, err = tm.specLimits(TaskSpec{Command: "true", Limits: ResourceUpdate{CPUs: &cpus}})
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrFailedPrecondition, taskErr.Code)
+	empty := ""
+	_, err = tm.specLimits(TaskSpec{Command: "true", Limits: ResourceUpdate{CPUs: &empty}})
+	require.NoError(t, err)
+}
+
+func TestResourceUpdateEqual(t *testing.T) {
+	t.Parallel()
+
+	cpus := "0-1"
+	update := ResourceUpdate{MemoryHigh: int64Ptr(100 << 20), CPUs: &cpus}
+	assert.True(t, ResourceUpdate{}.equal(ResourceUpdate{}))
+	assert.True(t, update.equal(ResourceUpdate{MemoryHigh: int64Ptr(100 << 20), CPUs: &cpus}))
+	assert.False(t, update.equal(ResourceUpdate{MemoryHigh: int64Ptr(200 << 20), CPUs: &cpus}))
+	// an unset limit differs from a removed one
+	assert.False(t, update.equal(ResourceUpdate{MemoryHigh: int64Ptr(100 << 20), CPUs: &cpus, PidsMax: int64Ptr(0)}))
+}
+
+func TestCheckStartResourceQuota(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithQuotaPolicy(QuotaPolicy{MaxMemoryPerClient: 256 << 20}))
+	running := CreateNewTask("running", "client001", 4242, time.Now(), NewTaskWriter())
+	running.limits = cgroups.Limits{MemoryMax: 192 << 20}
+	tm.addTask(running)
+
+	// the default limits and lower ones always pass
+	require.NoError(t, tm.checkStartResourceQuota("client001", tm.defaultLimits()))
+	limits := tm.defaultLimits()
+	limits.MemoryMax = 32 << 20
+	require.NoError(t, tm.checkStartResourceQuota("client001", limits))
+
+	// 128M together with the 192M of the running task exceed the memory quota of the client
+	limits.MemoryMax = 128 << 20
+	var taskErr *basetask.TaskError
+	require.ErrorAs(t, tm.checkStartResourceQuota("client001", limits), &taskErr)
+	assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+	require.NoError(t, tm.checkStartResourceQuota("client002", limits))
+}
+
+func TestDefaultLimitsWithinCeilings(t *testing.T) {
+	t.Parallel()
+
+	hierarchy, err := cgroups.NewHierarchy("taskman-test-missing.slice", cgroups.Limits{})
+	require.NoError(t, err)
+	tm := NewTaskManager(context.Background(), WithCgroupHierarchy(hierarchy),
+		WithResourceCeilings(cgroups.Limits{CPUMillis: 2000, MemoryMax: 32 << 20, IOReadBPS: 512 << 10, IOWriteBPS: 512 << 10}))
+
+	// defaults above a ceiling are lowered to it so that new tasks pass the checks of an update
+	limits := tm.defaultLimits()
+	expected := cgroups.DefaultLimits
+	expected.MemoryMax = 32 << 20
+	expected.IOReadBPS = 512 << 10
+	expected.IOWriteBPS = 512 << 10
+	expected.PidsMax = 0
+	assert.Equal(t, expected, limits)
+	require.NoError(t, tm.checkLimits(limits))
+}
+
+func TestUpdateTaskResources(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(),
+		WithResourceCeilings(cgroups.Limits{CPUMillis: 2000, MemoryMax: 1 << 30}),
+		WithQuotaPolicy(QuotaPolicy{MaxMemoryPerClient: 256 << 20}))
+	recorder := &limitsRecorder{written: make(map[string]cgroups.Limits)}
+	tm.setCgroupLimits = recorder.set
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+	adminCtx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "admin")
+
+	running := CreateNewTask("running", "client001", 4242, time.Now(), NewTaskWriter())
+	other := CreateNewTask("other", "client001", 4343, time.Now(), NewTaskWriter())
+	queued := CreateNewTask("queued", "client001", 0, time.Time{}, NewTaskWriter())
+	queued.SetStatus(basetask.JobStatusQueued)
+	for _, task := range []*Task{running, other, queued} {
+		// tasks start with the default limits of the manager, as in startTask
+		task.limits = tm.defaultLimits()
+		tm.addTask(task)
+	}
+
+	limits, err := tm.UpdateTaskResources(ctx, "running", ResourceUpdate{MemoryMax: int64Ptr(128 << 20), MemoryHigh: int64Ptr(100 << 20)}, true)
+	require.NoError(t, err)
+	expected := tm.defaultLimits()
+	expected.MemoryMax = 128 << 20
+	expected.MemoryHigh = 100 << 20
+	assert.Equal(t, expected, limits)
+	assert.Equal(t, expected, recorder.written[tm.hierarchy.TaskPath("client001", "running")])
+
+	snapshot := running.Snapshot()
+	assert.Equal(t, expected, snapshot.Limits)
+	require.Len(t, snapshot.ResourceChanges, 1)
+	assert.Equal(t, "client001", snapshot.ResourceChanges[0].ClientID)
+	assert.Equal(t, tm.defaultLimits(), snapshot.ResourceChanges[0].Previous)
+
+	// 256M together with the 64M of the other running task exceed the memory quota of the client
+	var taskErr *basetask.TaskError
+	_, err = tm.UpdateTaskResources(ctx, "running", ResourceUpdate{MemoryMax: int64Ptr(256 << 20)}, true)
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+
+	// updates without the quota, as the handler makes them for admins, are only bound by the ceilings
+	_, err = tm.UpdateTaskResources(adminCtx, "running", ResourceUpdate{MemoryMax: int64Ptr(256 << 20)}, false)
+	require.NoError(t, err)
+	_, err = tm.UpdateTaskResources(adminCtx, "running", ResourceUpdate{CPUMillis: int64Ptr(0)}, false)
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrInvalidArgument, taskErr.Code)
+	assert.Len(t, running.Snapshot().ResourceChanges, 2)
+
+	// the owner may lower limits while the client exceeds its quota but not raise them
+	_, err = tm.UpdateTaskResources(ctx, "other", ResourceUpdate{MemoryMax: int64Ptr(128 << 20)}, true)
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+	_, err = tm.UpdateTaskResources(ctx, "other", ResourceUpdate{MemoryMax: int64Ptr(32 << 20)}, true)
+	require.NoError(t, err)
+
+	// a task without a cgroup keeps the limits for its process
+	limits, err = tm.UpdateTaskResources(ctx, "queued", ResourceUpdate{CPUMillis: int64Ptr(500)}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), limits.CPUMillis)
+	assert.Equal(t, limits, queued.getLimits())
+
+	_, err = tm.UpdateTaskResources(ctx, "running", ResourceUpdate{}, true)
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrInvalidArgument, taskErr.Code)
+
+	running.SetEndTime(time.Now())
+	_, err = tm.UpdateTaskResources(ctx, "running", ResourceUpdate{CPUMillis: int64Ptr(500)}, true)
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrFailedPrecondition, taskErr.Code)
+}
@@ -0,0 +1,197 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// RestartMode selects the exits of a process that are restarted
+type RestartMode int
+
+const (
+	// RestartNever never restarts the process
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts the process if it fails: a non-zero exit code, an OOM kill or a signal
+	// that was not sent through the manager
+	RestartOnFailure
+	// RestartAlways restarts the process whenever it exits unless it was stopped or signaled through the manager
+	RestartAlways
+)
+
+// String returns the name of the mode
+func (m RestartMode) String() string {
+	switch m {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("RestartMode(%d)", int(m))
+	}
+}
+
+// Defaults of restart policies
+const (
+	DefaultRestartBackoff    = time.Second
+	DefaultRestartMaxBackoff = 5 * time.Minute
+	DefaultMaxRestarts       = 10
+	DefaultRestartWindow     = 10 * time.Minute
+)
+
+// RestartPolicy keeps the process of a long-running task up. A restart waits for a crash-loop backoff
+// that doubles after every restart and resets once a process has run for longer than MaxBackoff. The task
+// finishes with the result of its last process once MaxRestarts restarts happened within Window. The zero
+// RestartPolicy never restarts.
+type RestartPolicy struct {
+	Mode           RestartMode
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxRestarts    int
+	Window         time.Duration
+}
+
+// validateRestartPolicy checks the policy and returns it with the defaults of unset fields applied
+func validateRestartPolicy(policy RestartPolicy) (RestartPolicy, error) {
+	if policy.Mode < RestartNever || policy.Mode > RestartAlways {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "unknown restart mode")
+	}
+	if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 || policy.Window < 0 {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "restart backoff and window must not be negative")
+	}
+	if policy.MaxRestarts < 0 {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "max restarts must not be negative")
+	}
+	if policy.Mode == RestartNever {
+		return RestartPolicy{}, nil
+	}
+
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = DefaultRestartBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = max(DefaultRestartMaxBackoff, policy.InitialBackoff)
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "max backoff must not be less than the initial backoff")
+	}
+	if policy.MaxRestarts == 0 {
+		policy.MaxRestarts = DefaultMaxRestarts
+	}
+	if policy.Window == 0 {
+		policy.Window = DefaultRestartWindow
+	}
+	return policy, nil
+}
+
+// restarts reports whether the policy restarts the process of the ended attempt
+func (p RestartPolicy) restarts(attempt TaskAttempt) bool {
+	if attempt.TerminationSource == "user" || attempt.TerminationSource == "admin" {
+		return false
+	}
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return attempt.Status != basetask.JobStatusExitedOK
+	default:
+		return false
+	}
+}
+
+// restartState tracks the restarts of a task for the crash-loop backoff and the restart rate
+type restartState struct {
+	// count is the number of restarts of the task
+	count int
+	// consecutive is the number of restarts since a process last ran for longer than the max backoff
+	consecutive int
+	// recent are the times of the restarts within the window of the policy
+	recent []time.Time
+}
+
+// next records a restart after the attempt ended and returns its backoff. It returns false without
+// recording a restart if the restart rate of the policy is reached.
+func (s *restartState) next(p RestartPolicy, attempt TaskAttempt) (time.Duration, bool) {
+	now := attempt.EndTime
+	s.recent = slices.DeleteFunc(s.recent, func(t time.Time) bool {
+		return now.Sub(t) >= p.Window
+	})
+	if len(s.recent) >= p.MaxRestarts {
+		return 0, false
+	}
+	s.recent = append(s.recent, now)
+	s.count++
+
+	if attempt.EndTime.Sub(attempt.StartTime) > p.MaxBackoff {
+		s.consecutive = 0
+	}
+	delay := p.InitialBackoff << min(s.consecutive, 30)
+	s.consecutive++
+	if delay <= 0 || delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay, true
+}
+
+// exitReason describes how the process of an attempt exited, e.g. "exit code 1" or "signal SIGKILL (oom)"
+func exitReason(attempt TaskAttempt) string {
+	var reason string
+	switch {
+	case attempt.ExitCode != nil:
+		reason = fmt.Sprintf("exit code %d", *attempt.ExitCode)
+	case attempt.TerminationSignal != "":
+		reason = "signal " + attempt.TerminationSignal
+	default:
+		reason = "unknown"
+	}
+	if source := attempt.TerminationSource; source != "" && source != "unknown" {
+		reason += " (" + source + ")"
+	}
+	return reason
+}
+
+// restartTask waits for the backoff and restarts the process of the task in a fresh cgroup. The task is
+// finished instead if it is stopped or the manager shuts down while waiting, or if the process cannot
+// be started again.
+func (tm *TaskManager) restartTask(logger *slog.Logger, task *Task, previous TaskAttempt, delay time.Duration, canceled <-chan struct{}) {
+	restart := previous.Number
+	logger.Info("restarting task", "restart", restart, "reason", exitReason(previous), "backoff", delay)
+	metrics.TaskRestarts.Inc()
+	tm.auditLog.Log(audit.Event{
+		Action:            audit.ActionRestart,
+		ClientID:          task.GetClientID(),
+		TaskID:            task.GetID(),
+		ProcessID:         previous.ProcessID,
+		Signal:            previous.TerminationSignal,
+		ExitCode:          previous.ExitCode,
+		TerminationSource: previous.TerminationSource,
+		Outcome:           audit.OutcomeFailure,
+		Message:           fmt.Sprintf("process exited with %s, restart %d in %s", exitReason(previous), restart, delay),
+	})
+
+	if !tm.awaitNextAttempt(logger, task, delay, canceled, "restart") {
+		return
+	}
+
+	if _, err := fmt.Fprintf(task.getWriter(), "--- taskman: restart %d after %s ---\n", restart, exitReason(previous)); err != nil {
+		logger.Error("failed to write restart marker", "error", err)
+	}
+	cmd, err := tm.launch(logger, task, task.spec)
+	if err != nil {
+		logger.Error("failed to restart the process of the task", "error", err)
+		tm.finishTask(logger, task, TaskAttempt{
+			Status:            basetask.JobStatusUnknown,
+			TerminationSource: terminationSourceStartFailed,
+			EndTime:           time.Now(),
+		})
+		return
+	}
+	go tm.monitorProcess(task.GetID(), cmd)
+}
@@ -0,0 +1,244 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestValidateRestartPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		policy      RestartPolicy
+		expected    RestartPolicy
+		expectedErr bool
+	}{
+		{desc: "zero policy", policy: RestartPolicy{}, expected: RestartPolicy{}},
+		{desc: "never", policy: RestartPolicy{Mode: RestartNever, MaxRestarts: 3}, expected: RestartPolicy{}},
+		{
+			desc:   "defaults",
+			policy: RestartPolicy{Mode: RestartAlways},
+			expected: RestartPolicy{
+				Mode:           RestartAlways,
+				InitialBackoff: DefaultRestartBackoff,
+				MaxBackoff:     DefaultRestartMaxBackoff,
+				MaxRestarts:    DefaultMaxRestarts,
+				Window:         DefaultRestartWindow,
+			},
+		},
+		{
+			desc:   "initial backoff above the default max backoff",
+			policy: RestartPolicy{Mode: RestartOnFailure, InitialBackoff: 10 * time.Minute, MaxRestarts: 2, Window: time.Hour},
+			expected: RestartPolicy{
+				Mode:           RestartOnFailure,
+				InitialBackoff: 10 * time.Minute,
+				MaxBackoff:     10 * time.Minute,
+				MaxRestarts:    2,
+				Window:         time.Hour,
+			},
+		},
+		{desc: "unknown mode", policy: RestartPolicy{Mode: RestartMode(7)}, expectedErr: true},
+		{desc: "negative backoff", policy: RestartPolicy{Mode: RestartAlways, InitialBackoff: -time.Second}, expectedErr: true},
+		{desc: "negative window", policy: RestartPolicy{Mode: RestartAlways, Window: -time.Second}, expectedErr: true},
+		{desc: "negative max restarts", policy: RestartPolicy{Mode: RestartAlways, MaxRestarts: -1}, expectedErr: true},
+		{
+			desc:        "max backoff below initial backoff",
+			policy:      RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Minute, MaxBackoff: time.Second},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := validateRestartPolicy(tt.policy)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestValidateSpecRetryAndRestart(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := validateSpec(TaskSpec{
+		Command: "true",
+		Retry:   RetryPolicy{MaxAttempts: 3},
+		Restart: RestartPolicy{Mode: RestartAlways},
+	})
+	require.Error(t, err)
+
+	_, restart, err := validateSpec(TaskSpec{Command: "true", Retry: RetryPolicy{MaxAttempts: 1}, Restart: RestartPolicy{Mode: RestartAlways}})
+	require.NoError(t, err)
+	assert.Equal(t, RestartAlways, restart.Mode)
+}
+
+func TestRestartPolicyRestarts(t *testing.T) {
+	t.Parallel()
+
+	exitOK := TaskAttempt{Status: basetask.JobStatusExitedOK}
+	exitError := TaskAttempt{Status: basetask.JobStatusExitedError}
+	oom := TaskAttempt{Status: basetask.JobStatusSignaled, TerminationSource: "oom"}
+	stopped := TaskAttempt{Status: basetask.JobStatusSignaled, TerminationSource: "user"}
+
+	tests := []struct {
+		desc     string
+		mode     RestartMode
+		attempt  TaskAttempt
+		expected bool
+	}{
+		{desc: "never", mode: RestartNever, attempt: exitError},
+		{desc: "on-failure exit ok", mode: RestartOnFailure, attempt: exitOK},
+		{desc: "on-failure exit error", mode: RestartOnFailure, attempt: exitError, expected: true},
+		{desc: "on-failure oom", mode: RestartOnFailure, attempt: oom, expected: true},
+		{desc: "on-failure stopped", mode: RestartOnFailure, attempt: stopped},
+		{desc: "always exit ok", mode: RestartAlways, attempt: exitOK, expected: true},
+		{desc: "always stopped", mode: RestartAlways, attempt: stopped},
+		{
+			desc:    "always stopped by admin",
+			mode:    RestartAlways,
+			attempt: TaskAttempt{Status: basetask.JobStatusSignaled, TerminationSource: "admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, RestartPolicy{Mode: tt.mode}.restarts(tt.attempt))
+		})
+	}
+}
+
+func TestRestartStateNext(t *testing.T) {
+	t.Parallel()
+
+	policy := RestartPolicy{
+		Mode:           RestartAlways,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		MaxRestarts:    4,
+		Window:         time.Minute,
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	crash := func(at time.Duration) TaskAttempt {
+		return TaskAttempt{StartTime: start.Add(at - time.Second), EndTime: start.Add(at)}
+	}
+
+	var state restartState
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		delay, ok := state.next(policy, crash(time.Duration(i)*time.Second))
+		require.True(t, ok)
+		assert.Equal(t, expected, delay)
+	}
+	assert.Equal(t, 4, state.count)
+
+	// the restart rate is reached within the window
+	_, ok := state.next(policy, crash(10*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 4, state.count)
+
+	// restarts leave the window and a process that ran longer than the max backoff resets the backoff
+	delay, ok := state.next(policy, TaskAttempt{StartTime: start.Add(50 * time.Second), EndTime: start.Add(2 * time.Minute)})
+	require.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+	assert.Equal(t, 5, state.count)
+}
+
+func TestExitReason(t *testing.T) {
+	t.Parallel()
+
+	code := int32(3)
+	assert.Equal(t, "exit code 3", exitReason(TaskAttempt{ExitCode: &code}))
+	assert.Equal(t, "signal killed (oom)", exitReason(TaskAttempt{TerminationSignal: "killed", TerminationSource: "oom"}))
+	assert.Equal(t, "unknown", exitReason(TaskAttempt{TerminationSource: "unknown"}))
+}
+
+func TestEndAttemptRestart(t *testing.T) {
+	t.Parallel()
+
+	newTask := func() *Task {
+		task := CreateNewTask("task", "client001", 0, time.Time{}, NewTaskWriter())
+		task.restart = RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Second, MaxBackoff: time.Minute, MaxRestarts: 1, Window: time.Hour}
+		task.setStarted(100, time.Now(), 0)
+		return task
+	}
+	code := int32(0)
+	result := TaskAttempt{Status: basetask.JobStatusExitedOK, ExitCode: &code, EndTime: time.Now()}
+
+	task := newTask()
+	attempt, next := task.endAttempt(result, true)
+	assert.Equal(t, 1, attempt.Number)
+	require.NotNil(t, next.canceled)
+	assert.True(t, next.restart)
+	assert.Equal(t, time.Second, next.delay)
+	assert.Zero(t, task.GetProcessID())
+	assert.Equal(t, "exit code 0", task.Snapshot().LastExitReason)
+	assert.Equal(t, 1, task.Snapshot().RestartCount)
+	assert.Zero(t, task.Snapshot().MaxAttempts)
+
+	// the second exit reaches the restart rate
+	_, _ = task.endWait()
+	task.setStarted(101, time.Now(), 0)
+	_, next = task.endAttempt(result, true)
+	assert.Nil(t, next.canceled)
+	assert.True(t, next.restartLimited)
+
+	// a stop disables restarts before the process is killed
+	task = newTask()
+	task.setStopping()
+	_, next = task.endAttempt(result, true)
+	assert.Equal(t, nextAttempt{}, next)
+}
+
+func TestAttemptHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+
+	task := CreateNewTask("task", "client001", 0, time.Time{}, NewTaskWriter())
+	for i := range maxAttemptHistory + 50 {
+		task.setStarted(i+1, time.Now(), 0)
+	}
+
+	attempts := task.Snapshot().Attempts
+	require.Len(t, attempts, maxAttemptHistory)
+	assert.Equal(t, 51, attempts[0].Number)
+	assert.Equal(t, maxAttemptHistory+50, attempts[len(attempts)-1].Number)
+}
+
+func TestRestartTaskStoppedDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	task := CreateNewTask("task", "client001", 0, time.Time{}, NewTaskWriter())
+	task.restart = RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Hour, MaxBackoff: time.Hour, MaxRestarts: 3, Window: time.Hour}
+	task.setStarted(100, time.Now(), 0)
+	tm.addTask(task)
+	_, err := tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+
+	code := int32(0)
+	attempt, next := task.endAttempt(TaskAttempt{Status: basetask.JobStatusExitedOK, ExitCode: &code, EndTime: time.Now()}, true)
+	require.True(t, next.restart)
+	go tm.restartTask(slog.Default(), task, attempt, next.delay, next.canceled)
+
+	require.NoError(t, tm.stopTask(task, "client001", "admin"))
+	<-task.Done()
+	assert.Equal(t, basetask.JobStatusCanceled, task.GetStatus())
+	assert.Equal(t, "admin", task.GetTerminationSource())
+	assert.Zero(t, tm.runningTotal)
+}
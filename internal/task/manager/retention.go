@@ -0,0 +1,185 @@
+package task
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
+)
+
+// Reasons recorded when a finished task is evicted
+const (
+	EvictReasonAge    = "age"
+	EvictReasonCount  = "count"
+	EvictReasonOutput = "output"
+	EvictReasonDelete = "delete"
+)
+
+// defaultReapInterval is how often the reaper applies the retention policy unless set in the policy
+const defaultReapInterval = 30 * time.Second
+
+// RetentionPolicy limits how many finished tasks and how much of their output the manager keeps.
+// Running tasks are never evicted. A zero limit disables that limit.
+type RetentionPolicy struct {
+	// MaxAge evicts tasks that finished longer ago than this
+	MaxAge time.Duration
+	// MaxTasksPerClient keeps at most this many finished tasks per client, evicting the oldest first
+	MaxTasksPerClient int
+	// MaxOutputBytes evicts the oldest finished tasks while the output of all tasks exceeds this size
+	MaxOutputBytes int64
+	// Interval is how often the policy is applied; defaults to 30 seconds
+	Interval time.Duration
+}
+
+// enabled reports whether any limit is set
+func (p RetentionPolicy) enabled() bool {
+	return p.MaxAge > 0 || p.MaxTasksPerClient > 0 || p.MaxOutputBytes > 0
+}
+
+// WithRetentionPolicy evicts finished tasks and their output in the background according to the policy
+func WithRetentionPolicy(policy RetentionPolicy) Option {
+	return func(tm *TaskManager) {
+		tm.retention = policy
+	}
+}
+
+// retentionCandidate is a task considered by the retention policy
+type retentionCandidate struct {
+	id         string
+	clientID   string
+	finished   bool
+	endTime    time.Time
+	outputSize int64
+}
+
+// selectEvictions returns the IDs of the finished tasks to evict with the reason for each. Tasks are
+// first evicted by age, then beyond the per-client count and last by total output size, oldest first.
+func selectEvictions(candidates []retentionCandidate, policy RetentionPolicy, now time.Time) map[string]string {
+	evictions := make(map[string]string)
+
+	// oldest finished tasks first so that every limit evicts the oldest tasks
+	finished := make([]retentionCandidate, 0, len(candidates))
+	var totalOutput int64
+	for _, c := range candidates {
+		totalOutput += c.outputSize
+		if c.finished {
+			finished = append(finished, c)
+		}
+	}
+	slices.SortFunc(finished, func(a, b retentionCandidate) int {
+		return a.endTime.Compare(b.endTime)
+	})
+
+	evict := func(c retentionCandidate, reason string) {
+		if _, ok := evictions[c.id]; ok {
+			return
+		}
+		evictions[c.id] = reason
+		totalOutput -= c.outputSize
+	}
+
+	if policy.MaxAge > 0 {
+		for _, c := range finished {
+			if now.Sub(c.endTime) > policy.MaxAge {
+				evict(c, EvictReasonAge)
+			}
+		}
+	}
+
+	if policy.MaxTasksPerClient > 0 {
+		kept := make(map[string]int)
+		// newest first so that the newest tasks of each client are kept
+		for i := len(finished) - 1; i >= 0; i-- {
+			c := finished[i]
+			if _, ok := evictions[c.id]; ok {
+				continue
+			}
+			kept[c.clientID]++
+			if kept[c.clientID] > policy.MaxTasksPerClient {
+				evict(c, EvictReasonCount)
+			}
+		}
+	}
+
+	if policy.MaxOutputBytes > 0 {
+		for _, c := range finished {
+			if totalOutput <= policy.MaxOutputBytes {
+				break
+			}
+			evict(c, EvictReasonOutput)
+		}
+	}
+
+	return evictions
+}
+
+// reap runs the retention policy periodically until the manager context is done
+func (tm *TaskManager) reap() {
+	interval := tm.retention.Interval
+	if interval <= 0 {
+		interval = defaultReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tm.ctx.Done():
+			return
+		case now := <-ticker.C:
+			tm.applyRetention(now)
+		}
+	}
+}
+
+// applyRetention evicts the finished tasks selected by the retention policy
+func (tm *TaskManager) applyRetention(now time.Time) {
+	tm.mu.RLock()
+	candidates := make([]retentionCandidate, 0, len(tm.tasksMapByID))
+	for _, task := range tm.tasksMapByID {
+		candidates = append(candidates, retentionCandidate{
+			id:         task.GetID(),
+			clientID:   task.GetClientID(),
+			finished:   task.finished(),
+			endTime:    task.GetEndTime(),
+			outputSize: task.getWriter().Size(),
+		})
+	}
+	tm.mu.RUnlock()
+
+	for taskID, reason := range selectEvictions(candidates, tm.retention, now) {
+		tm.evictTask(taskID, reason)
+	}
+}
+
+// evictTask removes a finished task from the manager and releases its output. Later calls for the
+// task ID return NotFound.
+func (tm *TaskManager) evictTask(taskID, reason string) {
+	tm.mu.Lock()
+	task, ok := tm.tasksMapByID[taskID]
+	if ok {
+		delete(tm.tasksMapByID, taskID)
+	}
+	tm.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	task.getWriter().release()
+	tm.forgetIdempotencyKeys(taskID)
+	metrics.TasksEvicted.WithLabelValues(reason).Inc()
+	slog.Default().Info("evicted finished task", "task_id", taskID, "client_id", task.GetClientID(), "reason", reason)
+
+	// deletions are audited as the DeleteTask call itself
+	if reason != EvictReasonDelete {
+		tm.auditLog.Log(audit.Event{
+			Action:   audit.ActionEvict,
+			ClientID: task.GetClientID(),
+			TaskID:   taskID,
+			Outcome:  audit.OutcomeSuccess,
+			Message:  "evicted by the " + reason + " retention limit",
+		})
+	}
+}
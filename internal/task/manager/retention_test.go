@@ -0,0 +1,95 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectEvictions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	finished := func(id, clientID string, age time.Duration, size int64) retentionCandidate {
+		return retentionCandidate{id: id, clientID: clientID, finished: true, endTime: now.Add(-age), outputSize: size}
+	}
+	running := func(id, clientID string, size int64) retentionCandidate {
+		return retentionCandidate{id: id, clientID: clientID, outputSize: size}
+	}
+
+	tests := []struct {
+		desc       string
+		candidates []retentionCandidate
+		policy     RetentionPolicy
+		expected   map[string]string
+	}{
+		{
+			desc: "no limits",
+			candidates: []retentionCandidate{
+				finished("a", "client001", 48*time.Hour, 100),
+			},
+			expected: map[string]string{},
+		},
+		{
+			desc: "max age",
+			candidates: []retentionCandidate{
+				finished("old", "client001", 2*time.Hour, 10),
+				finished("new", "client001", time.Minute, 10),
+				running("running", "client001", 10),
+			},
+			policy:   RetentionPolicy{MaxAge: time.Hour},
+			expected: map[string]string{"old": EvictReasonAge},
+		},
+		{
+			desc: "max tasks per client keeps the newest",
+			candidates: []retentionCandidate{
+				finished("c1-oldest", "client001", 3*time.Minute, 10),
+				finished("c1-older", "client001", 2*time.Minute, 10),
+				finished("c1-newest", "client001", time.Minute, 10),
+				finished("c2", "client002", 5*time.Minute, 10),
+				running("c1-running", "client001", 10),
+			},
+			policy:   RetentionPolicy{MaxTasksPerClient: 2},
+			expected: map[string]string{"c1-oldest": EvictReasonCount},
+		},
+		{
+			desc: "max output evicts the oldest finished tasks",
+			candidates: []retentionCandidate{
+				finished("oldest", "client001", 3*time.Minute, 40),
+				finished("older", "client002", 2*time.Minute, 40),
+				finished("newest", "client001", time.Minute, 40),
+				running("running", "client001", 50),
+			},
+			policy:   RetentionPolicy{MaxOutputBytes: 100},
+			expected: map[string]string{"oldest": EvictReasonOutput, "older": EvictReasonOutput},
+		},
+		{
+			desc: "running tasks are never evicted",
+			candidates: []retentionCandidate{
+				running("running", "client001", 1000),
+			},
+			policy:   RetentionPolicy{MaxAge: time.Nanosecond, MaxTasksPerClient: 1, MaxOutputBytes: 1},
+			expected: map[string]string{},
+		},
+		{
+			desc: "earlier limits count towards later ones",
+			candidates: []retentionCandidate{
+				finished("old", "client001", 2*time.Hour, 60),
+				finished("c1", "client001", 3*time.Minute, 30),
+				finished("c1-newer", "client001", 2*time.Minute, 30),
+				finished("c1-newest", "client001", time.Minute, 30),
+			},
+			policy:   RetentionPolicy{MaxAge: time.Hour, MaxTasksPerClient: 2, MaxOutputBytes: 60},
+			expected: map[string]string{"old": EvictReasonAge, "c1": EvictReasonCount},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, selectEvictions(tt.candidates, tt.policy, now))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// RetryOutcome is a set of attempt outcomes that are retried
+type RetryOutcome int
+
+// Outcomes of an attempt that can be retried
+const (
+	// RetryOnExitError retries attempts that exited with a non-zero exit code
+	RetryOnExitError RetryOutcome = 1 << iota
+	// RetryOnOOM retries attempts killed by the OOM killer
+	RetryOnOOM
+	// RetryOnSignal retries attempts killed by a signal that was not sent through the manager
+	RetryOnSignal
+)
+
+// Limits and defaults of retry policies
+const (
+	MaxRetryAttempts      = 100
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultBackoffFactor  = 2.0
+)
+
+// RetryPolicy restarts the process of a task whose attempt failed with one of the RetryOn outcomes.
+// Attempts stopped or signaled by a client are never retried. The zero RetryPolicy never retries.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every retry
+	Multiplier float64
+	// RetryOn is the set of outcomes that are retried
+	RetryOn RetryOutcome
+}
+
+// validateRetryPolicy checks the policy and returns it with the defaults of unset fields applied
+func validateRetryPolicy(policy RetryPolicy) (RetryPolicy, error) {
+	if policy.MaxAttempts < 0 || policy.MaxAttempts > MaxRetryAttempts {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "max attempts must be between 0 and %d", MaxRetryAttempts)
+	}
+	if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "retry backoff must not be negative")
+	}
+	if policy.Multiplier != 0 && (policy.Multiplier < 1 || math.IsInf(policy.Multiplier, 0) || math.IsNaN(policy.Multiplier)) {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "backoff multiplier must be at least 1")
+	}
+	if policy.MaxAttempts <= 1 {
+		return RetryPolicy{}, nil
+	}
+
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = DefaultInitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = max(DefaultMaxBackoff, policy.InitialBackoff)
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return policy, basetask.NewTaskError(basetask.ErrInvalidArgument, "max backoff must not be less than the initial backoff")
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = DefaultBackoffFactor
+	}
+	if policy.RetryOn == 0 {
+		policy.RetryOn = RetryOnExitError
+	}
+	return policy, nil
+}
+
+// backoff returns the delay before the given retry, 1 being the first retry
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1))
+	if delay > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// retries reports whether the policy retries the ended attempt
+func (p RetryPolicy) retries(attempt TaskAttempt) bool {
+	if attempt.Number >= p.MaxAttempts {
+		return false
+	}
+	switch {
+	case attempt.TerminationSource == "user" || attempt.TerminationSource == "admin":
+		return false
+	case attempt.TerminationSource == "oom":
+		return p.RetryOn&RetryOnOOM != 0
+	case attempt.Status == basetask.JobStatusExitedError:
+		return p.RetryOn&RetryOnExitError != 0
+	case attempt.Status == basetask.JobStatusSignaled:
+		return p.RetryOn&RetryOnSignal != 0
+	default:
+		return false
+	}
+}
+
+// retryTask waits for the backoff of the policy and starts the next attempt of the task. The task
+// is finished instead if it is stopped or the manager shuts down while waiting, or if the next attempt
+// cannot be started.
+func (tm *TaskManager) retryTask(logger *slog.Logger, task *Task, previous TaskAttempt, canceled <-chan struct{}) {
+	delay := task.retry.backoff(previous.Number)
+	next := previous.Number + 1
+	logger.Info("retrying task", "attempt", next, "max_attempts", task.retry.MaxAttempts, "backoff", delay)
+	metrics.TaskRetries.Inc()
+	tm.auditLog.Log(audit.Event{
+		Action:            audit.ActionRetry,
+		ClientID:          task.GetClientID(),
+		TaskID:            task.GetID(),
+		ProcessID:         previous.ProcessID,
+		Signal:            previous.TerminationSignal,
+		ExitCode:          previous.ExitCode,
+		TerminationSource: previous.TerminationSource,
+		Outcome:           audit.OutcomeFailure,
+		Message:           fmt.Sprintf("attempt %d failed, starting attempt %d of %d in %s", previous.Number, next, task.retry.MaxAttempts, delay),
+	})
+
+	if !tm.awaitNextAttempt(logger, task, delay, canceled, "retry") {
+		return
+	}
+
+	if _, err := fmt.Fprintf(task.getWriter(), "--- taskman: attempt %d of %d ---\n", next, task.retry.MaxAttempts); err != nil {
+		logger.Error("failed to write attempt marker", "error", err)
+	}
+	cmd, err := tm.launch(logger, task, task.spec)
+	if err != nil {
+		logger.Error("failed to start the next attempt of the task", "error", err)
+		tm.finishTask(logger, task, TaskAttempt{
+			Status:            basetask.JobStatusUnknown,
+			TerminationSource: terminationSourceStartFailed,
+			EndTime:           time.Now(),
+		})
+		return
+	}
+	go tm.monitorProcess(task.GetID(), cmd)
+}
+
+// awaitNextAttempt waits for the delay before the next attempt of the task, a retry or a restart as
+// named by kind. It returns false once the task is finished as canceled because it was stopped
+// (canceled is closed) or the manager shut down while waiting; no process ran meanwhile that a
+// signal could have terminated.
+func (tm *TaskManager) awaitNextAttempt(logger *slog.Logger, task *Task, delay time.Duration, canceled <-chan struct{}, kind string) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-canceled:
+	case <-tm.ctx.Done():
+	}
+
+	source, stopped := task.endWait()
+	if !stopped && tm.ctx.Err() == nil {
+		return true
+	}
+	if !stopped {
+		source = "system"
+	}
+	logger.Info("task stopped while waiting to "+kind, "termination_source", source)
+	tm.finishTask(logger, task, TaskAttempt{
+		Status:            basetask.JobStatusCanceled,
+		TerminationSource: source,
+		EndTime:           time.Now(),
+	})
+	return false
+}
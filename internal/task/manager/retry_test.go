@@ -0,0 +1,163 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+func TestValidateRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		policy      RetryPolicy
+		expected    RetryPolicy
+		expectedErr bool
+	}{
+		{desc: "zero policy", policy: RetryPolicy{}, expected: RetryPolicy{}},
+		{desc: "single attempt", policy: RetryPolicy{MaxAttempts: 1, InitialBackoff: time.Hour}, expected: RetryPolicy{}},
+		{
+			desc:   "defaults",
+			policy: RetryPolicy{MaxAttempts: 3},
+			expected: RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: DefaultInitialBackoff,
+				MaxBackoff:     DefaultMaxBackoff,
+				Multiplier:     DefaultBackoffFactor,
+				RetryOn:        RetryOnExitError,
+			},
+		},
+		{
+			desc:   "initial backoff above the default max backoff",
+			policy: RetryPolicy{MaxAttempts: 2, InitialBackoff: 5 * time.Minute, RetryOn: RetryOnOOM | RetryOnSignal},
+			expected: RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: 5 * time.Minute,
+				MaxBackoff:     5 * time.Minute,
+				Multiplier:     DefaultBackoffFactor,
+				RetryOn:        RetryOnOOM | RetryOnSignal,
+			},
+		},
+		{desc: "negative attempts", policy: RetryPolicy{MaxAttempts: -1}, expectedErr: true},
+		{desc: "too many attempts", policy: RetryPolicy{MaxAttempts: MaxRetryAttempts + 1}, expectedErr: true},
+		{desc: "negative backoff", policy: RetryPolicy{MaxAttempts: 2, InitialBackoff: -time.Second}, expectedErr: true},
+		{desc: "multiplier below one", policy: RetryPolicy{MaxAttempts: 2, Multiplier: 0.5}, expectedErr: true},
+		{
+			desc:        "max backoff below initial backoff",
+			policy:      RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute, MaxBackoff: time.Second},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := validateRetryPolicy(tt.policy)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 8*time.Second, policy.backoff(4))
+	assert.Equal(t, 10*time.Second, policy.backoff(5))
+	assert.Equal(t, 10*time.Second, policy.backoff(50))
+}
+
+func TestRetryPolicyRetries(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnExitError | RetryOnOOM}
+
+	tests := []struct {
+		desc     string
+		policy   RetryPolicy
+		attempt  TaskAttempt
+		expected bool
+	}{
+		{desc: "exit error", policy: policy, attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusExitedError}, expected: true},
+		{desc: "exit ok", policy: policy, attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusExitedOK}},
+		{desc: "last attempt", policy: policy, attempt: TaskAttempt{Number: 3, Status: basetask.JobStatusExitedError}},
+		{
+			desc:     "oom",
+			policy:   policy,
+			attempt:  TaskAttempt{Number: 2, Status: basetask.JobStatusSignaled, TerminationSource: "oom"},
+			expected: true,
+		},
+		{
+			desc:    "signal not retried",
+			policy:  policy,
+			attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusSignaled, TerminationSource: "system"},
+		},
+		{
+			desc:     "signal retried",
+			policy:   RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnSignal},
+			attempt:  TaskAttempt{Number: 1, Status: basetask.JobStatusSignaled, TerminationSource: "system"},
+			expected: true,
+		},
+		{
+			desc:    "stopped by user",
+			policy:  RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnSignal},
+			attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusSignaled, TerminationSource: "user"},
+		},
+		{
+			desc:    "stopped by admin",
+			policy:  RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnSignal},
+			attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusSignaled, TerminationSource: "admin"},
+		},
+		{desc: "zero policy", attempt: TaskAttempt{Number: 1, Status: basetask.JobStatusExitedError}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.policy.retries(tt.attempt))
+		})
+	}
+}
+
+func TestRetryTaskStoppedDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	task := CreateNewTask("task", "client001", 0, time.Time{}, NewTaskWriter())
+	task.retry = RetryPolicy{MaxAttempts: 3, RetryOn: RetryOnExitError, InitialBackoff: time.Hour, MaxBackoff: time.Hour, Multiplier: 2}
+	task.setStarted(100, time.Now(), 0)
+	tm.addTask(task)
+	_, err := tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+
+	code := int32(1)
+	attempt, next := task.endAttempt(TaskAttempt{Status: basetask.JobStatusExitedError, ExitCode: &code, EndTime: time.Now()}, true)
+	require.NotNil(t, next.canceled)
+	go tm.retryTask(slog.Default(), task, attempt, next.canceled)
+
+	// no process runs during the backoff so the stop cancels the task instead of signaling it
+	require.NoError(t, tm.stopTask(task, "client001", "user"))
+	<-task.Done()
+	assert.Equal(t, basetask.JobStatusCanceled, task.GetStatus())
+	assert.Equal(t, "user", task.GetTerminationSource())
+	assert.Empty(t, task.Snapshot().TerminationSignal)
+	assert.Zero(t, tm.runningTotal)
+}
@@ -0,0 +1,408 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os/exec"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/logging"
+	"github.com/mikewurtz/taskman/internal/metrics"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+)
+
+// MaxSchedulesPerClient limits the number of schedules a client may create
+const MaxSchedulesPerClient = 100
+
+// terminationSourceSchedule is the termination source of a task stopped because a new run of its schedule replaced it
+const terminationSourceSchedule = "schedule"
+
+// replaceTimeout bounds the wait of a replacing run for the stopped tasks of earlier runs to exit
+const replaceTimeout = 10 * time.Second
+
+// Outcomes of a schedule run recorded in metrics
+const (
+	ScheduleRunStarted  = "started"
+	ScheduleRunSkipped  = "skipped"
+	ScheduleRunReplaced = "replaced"
+	ScheduleRunFailed   = "failed"
+)
+
+// ConcurrencyPolicy decides what a schedule does when a run is due while a task of an earlier run is unfinished
+type ConcurrencyPolicy int
+
+const (
+	// ConcurrencyAllow starts a new task next to the unfinished tasks
+	ConcurrencyAllow ConcurrencyPolicy = iota
+	// ConcurrencyForbid skips the run
+	ConcurrencyForbid
+	// ConcurrencyReplace stops the unfinished tasks and starts a new task
+	ConcurrencyReplace
+)
+
+// cronParser parses standard 5 field cron expressions and descriptors such as "@hourly" or "@every 10m"
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ScheduleSpec describes a schedule to create
+type ScheduleSpec struct {
+	// Cron is the cron expression giving the times of the runs
+	Cron string
+	// Template is the task started by every run; the idempotency key and dependencies are not used
+	Template TaskSpec
+	// Concurrency decides what a run does if a task of an earlier run is unfinished
+	Concurrency ConcurrencyPolicy
+	// Paused creates the schedule without running it until it is resumed
+	Paused bool
+}
+
+// Schedule is a snapshot of a schedule
+type Schedule struct {
+	ID          string
+	ClientID    string
+	Cron        string
+	Template    TaskSpec
+	Concurrency ConcurrencyPolicy
+	Paused      bool
+	CreateTime  time.Time
+	// NextRunTime is zero while the schedule is paused
+	NextRunTime time.Time
+	// LastRunTime is zero if the schedule has not run yet
+	LastRunTime time.Time
+	// LastTaskID is empty if the last run did not start a task
+	LastTaskID string
+	// ActiveTaskIDs are the unfinished tasks started by the schedule
+	ActiveTaskIDs []string
+}
+
+// schedule starts a task from its template at the times of its cron expression. All fields are
+// protected by the schedulesMu mutex of the TaskManager.
+type schedule struct {
+	id          string
+	clientID    string
+	expr        string
+	cron        cron.Schedule
+	template    TaskSpec
+	concurrency ConcurrencyPolicy
+	paused      bool
+	createTime  time.Time
+	nextRun     time.Time
+	lastRun     time.Time
+	lastTaskID  string
+	// active are the tasks started by the schedule that were unfinished when last checked
+	active []*Task
+	// timer fires at nextRun; nil while paused or after the schedule is deleted
+	timer *time.Timer
+	// generation counts the times the timer was armed or disarmed. A timer that fired before it was
+	// stopped runs with an outdated generation and does nothing.
+	generation uint64
+}
+
+// snapshot returns a copy of the schedule. It must be called with schedulesMu held.
+func (s *schedule) snapshot() Schedule {
+	s.pruneActive()
+	activeIDs := make([]string, 0, len(s.active))
+	for _, task := range s.active {
+		activeIDs = append(activeIDs, task.GetID())
+	}
+	template := s.template
+	template.Args = slices.Clone(s.template.Args)
+	template.Labels = maps.Clone(s.template.Labels)
+	return Schedule{
+		ID:            s.id,
+		ClientID:      s.clientID,
+		Cron:          s.expr,
+		Template:      template,
+		Concurrency:   s.concurrency,
+		Paused:        s.paused,
+		CreateTime:    s.createTime,
+		NextRunTime:   s.nextRun,
+		LastRunTime:   s.lastRun,
+		LastTaskID:    s.lastTaskID,
+		ActiveTaskIDs: activeIDs,
+	}
+}
+
+// pruneActive forgets the tasks that have finished. It must be called with schedulesMu held.
+func (s *schedule) pruneActive() {
+	s.active = slices.DeleteFunc(s.active, func(task *Task) bool {
+		return !task.GetEndTime().IsZero()
+	})
+}
+
+// CreateSchedule creates a schedule owned by the caller and arms it unless it is paused
+func (tm *TaskManager) CreateSchedule(ctx context.Context, spec ScheduleSpec) (Schedule, error) {
+	clientID := ctx.Value(basegrpc.ClientIDKey).(string)
+
+	parsed, err := cronParser.Parse(spec.Cron)
+	if err != nil {
+		return Schedule{}, basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid cron expression", err)
+	}
+	if spec.Concurrency < ConcurrencyAllow || spec.Concurrency > ConcurrencyReplace {
+		return Schedule{}, basetask.NewTaskError(basetask.ErrInvalidArgument, "unknown concurrency policy")
+	}
+	template := spec.Template
+	template.IdempotencyKey = ""
+	template.DependsOn = nil
+	if _, _, err := validateSpec(template); err != nil {
+		return Schedule{}, err
+	}
+	if _, err := tm.specLimits(template); err != nil {
+		return Schedule{}, err
+	}
+	// the tasks are started later so an invalid command must be rejected now
+	if _, err := exec.LookPath(template.Command); err != nil {
+		return Schedule{}, basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid command", err)
+	}
+	template.Args = slices.Clone(template.Args)
+	template.Labels = maps.Clone(template.Labels)
+
+	s := &schedule{
+		id:          uuid.New().String(),
+		clientID:    clientID,
+		expr:        spec.Cron,
+		cron:        parsed,
+		template:    template,
+		concurrency: spec.Concurrency,
+		paused:      spec.Paused,
+		createTime:  time.Now(),
+	}
+	template.ScheduleID = s.id
+	s.template = template
+
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	owned := 0
+	for _, other := range tm.schedules {
+		if other.clientID == clientID {
+			owned++
+		}
+	}
+	if owned >= MaxSchedulesPerClient {
+		return Schedule{}, basetask.NewTaskError(basetask.ErrResourceExhausted, "a client may create at most %d schedules", MaxSchedulesPerClient)
+	}
+	tm.schedules[s.id] = s
+	metrics.Schedules.Inc()
+	if !s.paused {
+		tm.armSchedule(s, s.createTime)
+	}
+
+	logging.FromContext(ctx).Info("created schedule", "schedule_id", s.id, "cron", s.expr, "command", template.Command,
+		"args", template.Args, "paused", s.paused)
+	return s.snapshot(), nil
+}
+
+// ListSchedules returns the schedules visible to the caller ordered by creation time: its own
+// schedules, or all schedules for admins
+func (tm *TaskManager) ListSchedules(ctx context.Context) []Schedule {
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	schedules := make([]Schedule, 0, len(tm.schedules))
+	for _, s := range tm.schedules {
+		if s.clientID == caller || caller == "admin" {
+			schedules = append(schedules, s.snapshot())
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreateTime.Before(schedules[j].CreateTime)
+	})
+	return schedules
+}
+
+// DeleteSchedule deletes a schedule of the caller. Tasks it started are not stopped.
+func (tm *TaskManager) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	s, err := tm.getSchedule(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	tm.disarmSchedule(s)
+	delete(tm.schedules, scheduleID)
+	metrics.Schedules.Dec()
+	logging.FromContext(ctx).Info("deleted schedule", "schedule_id", scheduleID)
+	return nil
+}
+
+// PauseSchedule pauses a schedule of the caller, or resumes it if paused is false. A resumed schedule
+// runs next at the first time of its cron expression after now; runs missed while paused are skipped.
+func (tm *TaskManager) PauseSchedule(ctx context.Context, scheduleID string, paused bool) (Schedule, error) {
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	s, err := tm.getSchedule(ctx, scheduleID)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if s.paused != paused {
+		s.paused = paused
+		if paused {
+			tm.disarmSchedule(s)
+		} else {
+			tm.armSchedule(s, time.Now())
+		}
+		logging.FromContext(ctx).Info("paused schedule", "schedule_id", scheduleID, "paused", paused)
+	}
+	return s.snapshot(), nil
+}
+
+// getSchedule returns a schedule visible to the caller. It must be called with schedulesMu held.
+func (tm *TaskManager) getSchedule(ctx context.Context, scheduleID string) (*schedule, error) {
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	s, ok := tm.schedules[scheduleID]
+	if !ok || (s.clientID != caller && caller != "admin") {
+		return nil, basetask.NewTaskError(basetask.ErrNotFound, "schedule with id %s not found", scheduleID)
+	}
+	return s, nil
+}
+
+// armSchedule sets the timer of the schedule to its next run after the given time. It must be called with
+// schedulesMu held.
+func (tm *TaskManager) armSchedule(s *schedule, after time.Time) {
+	s.nextRun = s.cron.Next(after)
+	if s.nextRun.IsZero() {
+		// the expression has no time left, e.g. a date that does not exist
+		return
+	}
+	s.generation++
+	id, generation := s.id, s.generation
+	s.timer = time.AfterFunc(time.Until(s.nextRun), func() {
+		tm.runSchedule(id, generation)
+	})
+}
+
+// disarmSchedule stops the timer of the schedule. It must be called with schedulesMu held.
+func (tm *TaskManager) disarmSchedule(s *schedule) {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.generation++
+	s.nextRun = time.Time{}
+}
+
+// stopSchedules stops the timers of all schedules when the manager shuts down
+func (tm *TaskManager) stopSchedules() {
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	for _, s := range tm.schedules {
+		tm.disarmSchedule(s)
+	}
+}
+
+// runSchedule is called by the timer of a schedule armed as the generation. It arms the next run and
+// starts a task from the template as the owner of the schedule, applying the concurrency policy to the
+// unfinished tasks of earlier runs. It does nothing if the schedule was rearmed or disarmed since.
+func (tm *TaskManager) runSchedule(scheduleID string, generation uint64) {
+	if tm.ctx.Err() != nil {
+		return
+	}
+
+	tm.schedulesMu.Lock()
+	s, ok := tm.schedules[scheduleID]
+	if !ok || s.paused || s.generation != generation {
+		tm.schedulesMu.Unlock()
+		return
+	}
+	now := time.Now()
+	s.lastRun = now
+	s.lastTaskID = ""
+	s.pruneActive()
+	active := slices.Clone(s.active)
+	clientID, concurrency, template := s.clientID, s.concurrency, s.template
+	// a timer firing slightly early must not arm the run it is running again
+	after := now
+	if s.nextRun.After(now) {
+		after = s.nextRun
+	}
+	tm.armSchedule(s, after)
+	tm.schedulesMu.Unlock()
+
+	logger := slog.Default().With("schedule_id", scheduleID, "client_id", clientID)
+	if concurrency == ConcurrencyForbid && len(active) > 0 {
+		logger.Info("skipped schedule run", "reason", "a task of an earlier run is unfinished", "active_tasks", len(active))
+		metrics.ScheduleRuns.WithLabelValues(ScheduleRunSkipped).Inc()
+		tm.auditScheduleRun(clientID, scheduleID, "", audit.OutcomeFailure, "skipped: a task of an earlier run is unfinished")
+		return
+	}
+	outcome := ScheduleRunStarted
+	if concurrency == ConcurrencyReplace && len(active) > 0 {
+		outcome = ScheduleRunReplaced
+		for _, task := range active {
+			if err := tm.stopTask(task, clientID, terminationSourceSchedule); err != nil {
+				logger.Warn("failed to stop the task of an earlier run", "task_id", task.GetID(), "error", err)
+			}
+		}
+		// the stopped tasks hold their running quota until they exited
+		if !tm.awaitReplaced(logger, active) {
+			return
+		}
+	}
+
+	ctx := context.WithValue(tm.ctx, basegrpc.ClientIDKey, clientID)
+	ctx = logging.WithLogger(ctx, logger)
+	taskID, err := tm.startTask(ctx, template)
+	if err != nil {
+		logger.Error("failed to start scheduled task", "error", err)
+		metrics.ScheduleRuns.WithLabelValues(ScheduleRunFailed).Inc()
+		tm.auditScheduleRun(clientID, scheduleID, "", audit.OutcomeFailure, err.Error())
+		return
+	}
+	metrics.ScheduleRuns.WithLabelValues(outcome).Inc()
+	var message string
+	if outcome == ScheduleRunReplaced {
+		message = fmt.Sprintf("replaced %d unfinished tasks", len(active))
+	}
+	tm.auditScheduleRun(clientID, scheduleID, taskID, audit.OutcomeSuccess, message)
+
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return
+	}
+	tm.schedulesMu.Lock()
+	defer tm.schedulesMu.Unlock()
+	// the schedule may have been deleted while the task was started
+	if s, ok := tm.schedules[scheduleID]; ok {
+		s.lastTaskID = taskID
+		s.active = append(s.active, task)
+	}
+}
+
+// awaitReplaced waits up to replaceTimeout for the stopped tasks to finish. It returns false if the
+// manager shuts down meanwhile.
+func (tm *TaskManager) awaitReplaced(logger *slog.Logger, stopped []*Task) bool {
+	timeout := time.NewTimer(replaceTimeout)
+	defer timeout.Stop()
+	for _, task := range stopped {
+		select {
+		case <-task.Done():
+		case <-timeout.C:
+			logger.Warn("stopped task of an earlier run did not exit in time", "task_id", task.GetID(), "timeout", replaceTimeout)
+			return true
+		case <-tm.ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// auditScheduleRun records the outcome of a run of a schedule
+func (tm *TaskManager) auditScheduleRun(clientID, scheduleID, taskID, outcome, message string) {
+	tm.auditLog.Log(audit.Event{
+		Action:     audit.ActionScheduleRun,
+		ClientID:   clientID,
+		ScheduleID: scheduleID,
+		TaskID:     taskID,
+		Outcome:    outcome,
+		Message:    message,
+	})
+}
@@ -0,0 +1,282 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikewurtz/taskman/internal/audit"
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
+)
+
+func TestCreateSchedule(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background(), WithResourceCeilings(cgroups.Limits{MemoryMax: 1 << 30}))
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	tests := []struct {
+		desc        string
+		spec        ScheduleSpec
+		expectedErr bool
+	}{
+		{desc: "cron expression", spec: ScheduleSpec{Cron: "*/5 * * * *", Template: TaskSpec{Command: "true"}}},
+		{desc: "descriptor", spec: ScheduleSpec{Cron: "@every 1h", Template: TaskSpec{Command: "true"}}},
+		{
+			desc:        "invalid cron expression",
+			spec:        ScheduleSpec{Cron: "* * *", Template: TaskSpec{Command: "true"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "seconds field",
+			spec:        ScheduleSpec{Cron: "0 */5 * * * *", Template: TaskSpec{Command: "true"}},
+			expectedErr: true,
+		},
+		{desc: "empty command", spec: ScheduleSpec{Cron: "@hourly"}, expectedErr: true},
+		{
+			desc:        "unknown command",
+			spec:        ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "no-such-command-taskman"}},
+			expectedErr: true,
+		},
+		{
+			desc: "limits and restart policy",
+			spec: ScheduleSpec{Cron: "@hourly", Template: TaskSpec{
+				Command: "true",
+				Limits:  ResourceUpdate{MemoryMax: int64Ptr(256 << 20)},
+				Restart: RestartPolicy{Mode: RestartOnFailure},
+			}},
+		},
+		{
+			desc:        "limit above the ceiling",
+			spec:        ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "true", Limits: ResourceUpdate{MemoryMax: int64Ptr(2 << 30)}}},
+			expectedErr: true,
+		},
+		{
+			desc: "retry and restart policy",
+			spec: ScheduleSpec{Cron: "@hourly", Template: TaskSpec{
+				Command: "true",
+				Retry:   RetryPolicy{MaxAttempts: 3},
+				Restart: RestartPolicy{Mode: RestartAlways},
+			}},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown concurrency policy",
+			spec:        ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "true"}, Concurrency: ConcurrencyPolicy(9)},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := tm.CreateSchedule(ctx, tt.spec)
+			if tt.expectedErr {
+				var taskErr *basetask.TaskError
+				require.ErrorAs(t, err, &taskErr)
+				assert.Equal(t, basetask.ErrInvalidArgument, taskErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, s.ID)
+			assert.Equal(t, "client001", s.ClientID)
+			assert.Equal(t, s.ID, s.Template.ScheduleID)
+			assert.True(t, s.NextRunTime.After(s.CreateTime))
+		})
+	}
+}
+
+func TestCreateScheduleLimit(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+	spec := ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "true"}, Paused: true}
+	for range MaxSchedulesPerClient {
+		_, err := tm.CreateSchedule(ctx, spec)
+		require.NoError(t, err)
+	}
+
+	_, err := tm.CreateSchedule(ctx, spec)
+	var taskErr *basetask.TaskError
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, basetask.ErrResourceExhausted, taskErr.Code)
+
+	// the limit is per client
+	otherCtx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client002")
+	_, err = tm.CreateSchedule(otherCtx, spec)
+	require.NoError(t, err)
+}
+
+func TestPauseAndDeleteSchedule(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+	otherCtx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client002")
+	adminCtx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "admin")
+
+	s, err := tm.CreateSchedule(ctx, ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "true"}, Paused: true})
+	require.NoError(t, err)
+	assert.True(t, s.Paused)
+	assert.True(t, s.NextRunTime.IsZero())
+
+	s, err = tm.PauseSchedule(ctx, s.ID, false)
+	require.NoError(t, err)
+	assert.False(t, s.Paused)
+	assert.False(t, s.NextRunTime.IsZero())
+
+	s, err = tm.PauseSchedule(ctx, s.ID, true)
+	require.NoError(t, err)
+	assert.True(t, s.NextRunTime.IsZero())
+
+	// schedules of other clients are not found, except for admin
+	_, err = tm.PauseSchedule(otherCtx, s.ID, false)
+	require.Error(t, err)
+	require.Error(t, tm.DeleteSchedule(otherCtx, s.ID))
+	assert.Empty(t, tm.ListSchedules(otherCtx))
+	assert.Len(t, tm.ListSchedules(adminCtx), 1)
+
+	require.NoError(t, tm.DeleteSchedule(ctx, s.ID))
+	assert.Empty(t, tm.ListSchedules(ctx))
+	var taskErr *basetask.TaskError
+	require.ErrorAs(t, tm.DeleteSchedule(ctx, s.ID), &taskErr)
+	assert.Equal(t, basetask.ErrNotFound, taskErr.Code)
+}
+
+func TestRunScheduleForbid(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	s, err := tm.CreateSchedule(ctx, ScheduleSpec{
+		Cron:        "@hourly",
+		Template:    TaskSpec{Command: "true"},
+		Concurrency: ConcurrencyForbid,
+	})
+	require.NoError(t, err)
+
+	unfinished := CreateNewTask("unfinished", "client001", 0, time.Time{}, NewTaskWriter())
+	tm.schedulesMu.Lock()
+	tm.schedules[s.ID].active = []*Task{unfinished}
+	generation := tm.schedules[s.ID].generation
+	tm.schedulesMu.Unlock()
+
+	tm.runSchedule(s.ID, generation)
+
+	schedules := tm.ListSchedules(ctx)
+	require.Len(t, schedules, 1)
+	assert.False(t, schedules[0].LastRunTime.IsZero())
+	assert.Empty(t, schedules[0].LastTaskID)
+	assert.Equal(t, []string{"unfinished"}, schedules[0].ActiveTaskIDs)
+	assert.True(t, schedules[0].NextRunTime.After(schedules[0].LastRunTime))
+}
+
+func TestRunScheduleOutdatedTimer(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	s, err := tm.CreateSchedule(ctx, ScheduleSpec{Cron: "@hourly", Template: TaskSpec{Command: "true"}})
+	require.NoError(t, err)
+	tm.schedulesMu.Lock()
+	generation := tm.schedules[s.ID].generation
+	tm.schedulesMu.Unlock()
+
+	// the timer fired while the schedule was paused and resumed, which armed a new timer
+	_, err = tm.PauseSchedule(ctx, s.ID, true)
+	require.NoError(t, err)
+	resumed, err := tm.PauseSchedule(ctx, s.ID, false)
+	require.NoError(t, err)
+	tm.runSchedule(s.ID, generation)
+
+	schedules := tm.ListSchedules(ctx)
+	require.Len(t, schedules, 1)
+	assert.True(t, schedules[0].LastRunTime.IsZero())
+	assert.Equal(t, resumed.NextRunTime, schedules[0].NextRunTime)
+}
+
+func TestRunScheduleReplace(t *testing.T) {
+	t.Parallel()
+
+	// the parent cgroup does not exist, so the start of the new task fails after the quota admitted it
+	hierarchy, err := cgroups.NewHierarchy("taskman-test-missing.slice", cgroups.Limits{})
+	require.NoError(t, err)
+	var events bytes.Buffer
+	tm := NewTaskManager(context.Background(), WithCgroupHierarchy(hierarchy),
+		WithQuotaPolicy(QuotaPolicy{MaxRunningPerClient: 1}), WithAuditLogger(audit.New(audit.NewWriterSink(&events))))
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	s, err := tm.CreateSchedule(ctx, ScheduleSpec{
+		Cron:        "@hourly",
+		Template:    TaskSpec{Command: "true"},
+		Concurrency: ConcurrencyReplace,
+	})
+	require.NoError(t, err)
+
+	// the task of the earlier run holds the only running task of the client and exits a while after it is stopped
+	unfinished := CreateNewTask("unfinished", "client001", 0, time.Time{}, NewTaskWriter())
+	canceled := unfinished.beginWait()
+	tm.addTask(unfinished)
+	_, err = tm.admitStart("client001", time.Now(), nil)
+	require.NoError(t, err)
+	go func() {
+		<-canceled
+		time.Sleep(100 * time.Millisecond)
+		source, _ := unfinished.endWait()
+		tm.finishTask(slog.Default(), unfinished, TaskAttempt{
+			Status:            basetask.JobStatusCanceled,
+			TerminationSource: source,
+			EndTime:           time.Now(),
+		})
+	}()
+
+	tm.schedulesMu.Lock()
+	tm.schedules[s.ID].active = []*Task{unfinished}
+	generation := tm.schedules[s.ID].generation
+	tm.schedulesMu.Unlock()
+
+	tm.runSchedule(s.ID, generation)
+
+	assert.Equal(t, basetask.JobStatusCanceled, unfinished.GetStatus())
+	assert.Equal(t, terminationSourceSchedule, unfinished.GetTerminationSource())
+	assert.Contains(t, events.String(), "failed to create client cgroup")
+	assert.NotContains(t, events.String(), "quota exceeded")
+}
+
+func TestRunScheduleEarlyTimer(t *testing.T) {
+	t.Parallel()
+
+	tm := NewTaskManager(context.Background())
+	ctx := context.WithValue(context.Background(), basegrpc.ClientIDKey, "client001")
+
+	s, err := tm.CreateSchedule(ctx, ScheduleSpec{
+		Cron:        "@hourly",
+		Template:    TaskSpec{Command: "true"},
+		Concurrency: ConcurrencyForbid,
+	})
+	require.NoError(t, err)
+	unfinished := CreateNewTask("unfinished", "client001", 0, time.Time{}, NewTaskWriter())
+	tm.schedulesMu.Lock()
+	tm.schedules[s.ID].active = []*Task{unfinished}
+	generation := tm.schedules[s.ID].generation
+	tm.schedulesMu.Unlock()
+
+	// the timer of the run fires before its time
+	tm.runSchedule(s.ID, generation)
+
+	schedules := tm.ListSchedules(ctx)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, s.NextRunTime.Add(time.Hour), schedules[0].NextRunTime)
+}
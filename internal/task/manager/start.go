@@ -2,46 +2,193 @@ package task
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
+	"github.com/mikewurtz/taskman/internal/logging"
+	"github.com/mikewurtz/taskman/internal/metrics"
 	basetask "github.com/mikewurtz/taskman/internal/task"
 	"github.com/mikewurtz/taskman/internal/task/cgroups"
 )
 
-// StartTask starts a new task with the given command and arguments
-func (tm *TaskManager) StartTask(ctx context.Context, command string, args []string) (string, error) {
-	clientID := ctx.Value(basegrpc.ClientIDKey)
-	log.Printf("Starting task for client %s: %s %v", clientID, command, args)
+// TaskSpec describes the task to start
+type TaskSpec struct {
+	// Command is a full path or a binary in the PATH
+	Command string
+	Args    []string
+	// Labels are user-supplied metadata used to select tasks
+	Labels map[string]string
+	// IdempotencyKey makes retried starts of the same client return the task of the first start
+	IdempotencyKey string
+	// Priority orders queued tasks; higher priorities start first
+	Priority int
+	// Retry restarts the process if an attempt fails
+	Retry RetryPolicy
+	// Restart keeps the process of a long-running task up; it may not be combined with Retry
+	Restart RestartPolicy
+	// DependsOn are the tasks that must finish before the task starts
+	DependsOn []Dependency
+	// Limits override the default resource limits of the task; unset limits keep the defaults
+	Limits ResourceUpdate
+	// ScheduleID is the schedule that started the task; set by the scheduler only
+	ScheduleID string
+}
 
-	if command == "" {
-		return "", basetask.NewTaskError(basetask.ErrInvalidArgument, "command cannot be empty")
+// StartTask starts a new task with the command, arguments and labels of the spec. If the spec has an
+// idempotency key already used by the client the task started for it is returned instead.
+func (tm *TaskManager) StartTask(ctx context.Context, spec TaskSpec) (string, error) {
+	if spec.IdempotencyKey != "" {
+		return tm.startIdempotent(ctx, ctx.Value(basegrpc.ClientIDKey).(string), spec)
+	}
+	return tm.startTask(ctx, spec)
+}
+
+// startTask starts the process of a new task, queues the task if a running task quota is reached, or
+// lets it wait for its dependencies
+func (tm *TaskManager) startTask(ctx context.Context, spec TaskSpec) (string, error) {
+	clientID := ctx.Value(basegrpc.ClientIDKey).(string)
+	logger := logging.FromContext(ctx)
+
+	retry, restart, err := validateSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	limits, err := tm.specLimits(spec)
+	if err != nil {
+		return "", err
+	}
+	if err := tm.checkStartResourceQuota(clientID, limits); err != nil {
+		return "", err
+	}
+	if tm.quota.MaxQueued > 0 {
+		// a queued task is started later so an invalid command must be rejected now
+		if _, err := exec.LookPath(spec.Command); err != nil {
+			return "", basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid command", err)
+		}
 	}
 
 	taskID := uuid.New().String()
+	logger = logger.With("task_id", taskID)
 
-	// Create cgroup and get file descriptor
-	cgroupFd, err := cgroups.CreateCgroupForTask(taskID)
+	deps, err := tm.resolveDependencies(clientID, taskID, spec.DependsOn)
+	if err != nil {
+		return "", err
+	}
+
+	task := CreateNewTask(taskID, clientID, 0, time.Time{}, NewTaskWriter())
+	task.labels = maps.Clone(spec.Labels)
+	task.priority = spec.Priority
+	task.spec = spec
+	task.retry = retry
+	task.restart = restart
+	task.limits = limits
+	task.dependencies = slices.Clone(spec.DependsOn)
+
+	if len(deps) > 0 {
+		canceled := task.beginWait()
+		tm.addTask(task)
+		metrics.TasksWaiting.Inc()
+		logger.Info("task waiting for its dependencies", "command", spec.Command, "args", spec.Args,
+			"labels", spec.Labels, "depends_on", spec.DependsOn)
+		go tm.awaitDependencies(logger, task, deps, canceled)
+		return taskID, nil
+	}
+
+	if err := tm.submit(logger, task); err != nil {
+		return "", err
+	}
+	return taskID, nil
+}
+
+// validateSpec checks the command, labels, retry and restart policy of the spec and returns the policies
+// with their defaults applied
+func validateSpec(spec TaskSpec) (RetryPolicy, RestartPolicy, error) {
+	if spec.Command == "" {
+		return RetryPolicy{}, RestartPolicy{}, basetask.NewTaskError(basetask.ErrInvalidArgument, "command cannot be empty")
+	}
+	if err := basetask.ValidateLabels(spec.Labels); err != nil {
+		return RetryPolicy{}, RestartPolicy{}, err
+	}
+	retry, err := validateRetryPolicy(spec.Retry)
+	if err != nil {
+		return RetryPolicy{}, RestartPolicy{}, err
+	}
+	restart, err := validateRestartPolicy(spec.Restart)
 	if err != nil {
+		return RetryPolicy{}, RestartPolicy{}, err
+	}
+	if retry.MaxAttempts > 1 && restart.Mode != RestartNever {
+		return RetryPolicy{}, RestartPolicy{}, basetask.NewTaskError(basetask.ErrInvalidArgument,
+			"a task may have a retry policy or a restart policy but not both")
+	}
+	return retry, restart, nil
+}
+
+// submit starts the process of the task or queues it if a running task quota is reached. The task is
+// added to the manager unless it fails to start.
+func (tm *TaskManager) submit(logger *slog.Logger, task *Task) error {
+	clientID := task.GetClientID()
+	spec := task.spec
+
+	queued, err := tm.admitStart(clientID, time.Now(), &queuedTask{task: task, spec: spec})
+	if err != nil {
+		return err
+	}
+	if queued {
+		logger.Info("queued task", "command", spec.Command, "args", spec.Args, "labels", spec.Labels,
+			"priority", spec.Priority, "queue_position", task.GetQueuePosition())
+		return nil
+	}
+
+	logger.Info("starting task", "command", spec.Command, "args", spec.Args, "labels", spec.Labels)
+	cmd, err := tm.launch(logger, task, spec)
+	if err != nil {
+		tm.releaseRunning(clientID)
+		return err
+	}
+	tm.addTask(task)
+
+	// Start monitoring the process
+	go tm.monitorProcess(task.GetID(), cmd)
+
+	return nil
+}
+
+// launch creates the cgroup and starts the process of the task. The caller must have reserved a
+// running task with admitStart and monitor the returned command.
+func (tm *TaskManager) launch(logger *slog.Logger, task *Task, spec TaskSpec) (*exec.Cmd, error) {
+	// the cgroup of the task is created below the cgroup of its client, which holds the client limits
+	if err := tm.hierarchy.CreateClientCgroup(task.GetClientID()); err != nil {
+		metrics.CgroupCreateFailures.Inc()
+		return nil, basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to create client cgroup", err)
+	}
+
+	// Create cgroup and get file descriptor; a resource update waits so that it is not lost
+	task.limitsMu.Lock()
+	cgroupFd, err := cgroups.CreateCgroupForTask(tm.cgroupPath(task), task.getLimits(), tm.ioDevices)
+	task.limitsMu.Unlock()
+	if err != nil {
+		metrics.CgroupCreateFailures.Inc()
 		// if we fail to create the cgroup, try to remove it
-		err = cgroups.RemoveCgroupForTask(taskID)
-		if err != nil {
-			log.Printf("failed to remove cgroup %s: %v", taskID, err)
+		if rmErr := tm.removeCgroup(task); rmErr != nil {
+			logger.Error("failed to remove cgroup", "error", rmErr)
 		}
-		return "", basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to create cgroup", err)
+		return nil, basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to create cgroup", err)
 	}
 
 	// exec.CommandContext() calls cmd.Process.Kill() on context cancelation which kills just the first process
 	// and not the entire process group. We want the whole process group to be killed on context cancelation.
 	// So we later call syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) to kill the entire process group in the event
 	// of a context cancelation.
-	cmd := exec.Command(command, args...)
+	cmd := exec.Command(spec.Command, spec.Args...)
 
 	// Set process attributes. We set the cgroup fields so the process starts in the cgroup rather than having to move it later
 	// We want the pgid so we can kill the entire process group later
@@ -51,27 +198,28 @@ func (tm *TaskManager) StartTask(ctx context.Context, command string, args []str
 		CgroupFD:    int(cgroupFd.Fd()),
 	}
 
-	writer := NewTaskWriter()
+	writer := task.getWriter()
 	// Set up output capture; we use a single writer for both stdout and stderr
 	cmd.Stdout = writer
 	cmd.Stderr = writer
+	outputOffset := writer.Size()
 
 	// Start the process
 	if err := cmd.Start(); err != nil {
 		if err := cgroupFd.Close(); err != nil {
-			log.Printf("Failed to close cgroup file descriptor after process start failure: %v", err)
+			logger.Error("failed to close cgroup file descriptor after process start failure", "error", err)
 		}
 		// clean up the cgroup so it doesn't leak
-		if cleanupErr := cgroups.RemoveCgroupForTask(taskID); cleanupErr != nil {
-			log.Printf("Failed to clean up cgroup after process start failure: %v", cleanupErr)
+		if cleanupErr := tm.removeCgroup(task); cleanupErr != nil {
+			logger.Error("failed to clean up cgroup after process start failure", "error", cleanupErr)
 		}
 		switch e := err.(type) {
 		case *exec.Error:
-			return "", basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid command", e)
+			return nil, basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "invalid command", e)
 		case *os.PathError:
-			return "", basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "command not found or not executable", e)
+			return nil, basetask.NewTaskErrorWithErr(basetask.ErrInvalidArgument, "command not found or not executable", e)
 		default:
-			return "", basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to start process", err)
+			return nil, basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to start process", err)
 		}
 	}
 
@@ -85,15 +233,12 @@ func (tm *TaskManager) StartTask(ctx context.Context, command string, args []str
 	// we can now safely close the CgroupFD
 	if err := cgroupFd.Close(); err != nil {
 		// if we fail to close the cgroup FD log the error but continue as task is already started
-		log.Printf("Failed to close cgroup file descriptor after process start: %v", err)
+		logger.Error("failed to close cgroup file descriptor after process start", "error", err)
 	}
 
-	// Create the new task and add it to the task manager
-	task := CreateNewTask(taskID, clientID.(string), pgid, startTime, writer)
-	tm.addTask(task)
-
-	// Start monitoring the process
-	go tm.monitorProcess(taskID, cmd)
+	task.setStarted(pgid, startTime, outputOffset)
+	metrics.TasksStarted.Inc()
+	metrics.TasksRunning.Inc()
 
-	return taskID, nil
+	return cmd, nil
 }
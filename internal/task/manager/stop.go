@@ -4,11 +4,17 @@ import (
 	"context"
 	"syscall"
 
+	"golang.org/x/sys/unix"
+
+	"github.com/mikewurtz/taskman/internal/audit"
 	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
 	basetask "github.com/mikewurtz/taskman/internal/task"
 )
 
-// StopTask stops a task by sending a SIGKILL to the process group
+// StopTask stops a task by sending a SIGKILL to the process group. A queued task is removed from the
+// queue instead and never started, a task waiting for its dependencies is never started and a task
+// waiting to retry or restart is not started again. A killed process is neither retried nor restarted.
+// The gRPC handler checks that the caller may access the task.
 func (tm *TaskManager) StopTask(ctx context.Context, taskID string) error {
 	task, err := tm.getTaskFromMap(taskID)
 	if err != nil {
@@ -16,25 +22,73 @@ func (tm *TaskManager) StopTask(ctx context.Context, taskID string) error {
 	}
 
 	caller := ctx.Value(basegrpc.ClientIDKey).(string)
-	if task.GetClientID() != caller && caller != "admin" {
-		return basetask.NewTaskError(basetask.ErrNotFound, "task with id %s not found", taskID)
+	return tm.stopTask(task, caller, callerSource(caller))
+}
 
+// stopTask stops the task on behalf of the caller and sets the termination source of the task to source
+func (tm *TaskManager) stopTask(task *Task, caller, source string) error {
+	if task.GetStatus() == basetask.JobStatusQueued && (tm.cancelQueued(task, source) || task.cancelLaunch(source)) {
+		return nil
+	}
+	if task.cancelWait(source) {
+		return nil
 	}
+	// restarts are disabled before the kill so that a process exiting meanwhile is not started again
+	task.setStopping()
+	return tm.signalTask(task, caller, source, syscall.SIGKILL)
+}
 
+// SignalTask sends the signal to the process group of a running task. The termination source
+// is set to the caller in case the signal terminates the task. The gRPC handler checks that the
+// caller may access the task.
+func (tm *TaskManager) SignalTask(ctx context.Context, taskID string, sig syscall.Signal) error {
+	task, err := tm.getTaskFromMap(taskID)
+	if err != nil {
+		return err
+	}
+
+	caller := ctx.Value(basegrpc.ClientIDKey).(string)
+	return tm.signalTask(task, caller, callerSource(caller), sig)
+}
+
+// callerSource returns the termination source of a task stopped or signaled by the caller
+func callerSource(caller string) string {
+	if caller == "admin" {
+		return "admin"
+	}
+	return "user"
+}
+
+// signalTask sends the signal to the process group of the task on behalf of the caller
+func (tm *TaskManager) signalTask(task *Task, caller, source string, sig syscall.Signal) error {
 	alreadyCompleted := !task.GetEndTime().IsZero()
 
 	if alreadyCompleted {
 		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task has already completed")
 	}
-
-	if err := syscall.Kill(-task.GetProcessID(), syscall.SIGKILL); err != nil {
-		return basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to send SIGKILL to process group", err)
+	// a queued or waiting task has no process group and -0 would signal our own process group
+	if task.GetProcessID() <= 0 {
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task has no running process: it is queued or waiting for its dependencies, to retry or to restart")
+	}
+	// other signals stay pending in frozen processes while SIGKILL is delivered, so stop works while paused
+	if sig != syscall.SIGKILL && task.GetStatus() == basetask.JobStatusPaused {
+		return basetask.NewTaskError(basetask.ErrFailedPrecondition, "task is paused: resume it before sending %s", unix.SignalName(sig))
 	}
 
-	task.SetTerminationSource("user")
-	if caller == "admin" {
-		task.SetTerminationSource("admin")
+	if err := syscall.Kill(-task.GetProcessID(), sig); err != nil {
+		return basetask.NewTaskErrorWithErr(basetask.ErrInternal, "failed to send %s to process group", err, unix.SignalName(sig))
 	}
 
+	task.SetTerminationSource(source)
+
+	tm.auditLog.Log(audit.Event{
+		Action:    audit.ActionSignal,
+		ClientID:  caller,
+		TaskID:    task.GetID(),
+		ProcessID: task.GetProcessID(),
+		Signal:    sig.String(),
+		Outcome:   audit.OutcomeSuccess,
+	})
+
 	return nil
 }
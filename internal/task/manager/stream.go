@@ -3,6 +3,8 @@ package task
 import (
 	"context"
 	"io"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
 )
 
 // mergeCancelContexts merges two contexts and cancels when either is done
@@ -30,6 +32,7 @@ func (tm *TaskManager) GetStreamer(ctx context.Context, taskID string) (io.ReadC
 
 	reader := taskObj.newOutputReader(mergedCtx)
 	reader.cancel = cancel
+	metrics.ActiveStreamers.Inc()
 	return reader, nil
 }
 
@@ -1,10 +1,13 @@
 package task
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	basetask "github.com/mikewurtz/taskman/internal/task"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
 )
 
 // Task represents a managed process. All fields are protected by the mu mutex.
@@ -22,6 +25,54 @@ type Task struct {
 	terminationSource string
 	endTime           time.Time
 	done              chan struct{}
+	// labels and priority are set when the task is created and never modified
+	labels   map[string]string
+	priority int
+	// queuePosition is the 1-based position in the queue while the task is queued
+	queuePosition int
+	// spec, retry and restart are set when the task is created and never modified
+	spec    TaskSpec
+	retry   RetryPolicy
+	restart RestartPolicy
+	// attempts are the runs of the process; the last one is the current attempt. Only the last
+	// maxAttemptHistory attempts are kept.
+	attempts []TaskAttempt
+	// attemptCount is the number of attempts started
+	attemptCount int
+	// restarts tracks the restarts of the restart policy
+	restarts restartState
+	// lastExitReason describes how the process of the last ended attempt exited
+	lastExitReason string
+	// stopping is set by a stop before the process is killed so that the attempt is neither retried nor restarted
+	stopping bool
+	// dependencies are set when the task is created and never modified
+	dependencies []Dependency
+	// waitCanceled is closed to stop the task while it waits for its dependencies, to retry or to restart; nil otherwise
+	waitCanceled chan struct{}
+	// waitStopSource is the termination source of a stop while waiting
+	waitStopSource string
+	// launching is set while a task taken from the queue is started; a stop meanwhile sets stopping and
+	// launchStopSource for the launch instead of signaling the process
+	launching        bool
+	launchStopSource string
+	// pausedAt is when the task was paused while its status is paused
+	pausedAt time.Time
+	// pausedTotal is the time the task spent paused before pausedAt
+	pausedTotal time.Duration
+	// pauseMu serializes pausing and resuming the task; it is not held with mu
+	pauseMu sync.Mutex
+	// limits are the resource limits of the cgroup of the task and resourceChanges the last changes of them
+	limits          cgroups.Limits
+	resourceChanges []ResourceChange
+	// limitsMu serializes resource updates with the creation of the cgroup; it is not held with mu
+	limitsMu sync.Mutex
+	// memoryEvents are the last memory events of the task, memoryEventCount the number of events recorded
+	// and lastPressureEvent the time of the last pressure event. memoryEventsChanged is closed when the
+	// next event is recorded; nil if nobody waits for it.
+	memoryEvents        []MemoryEvent
+	memoryEventCount    int
+	lastPressureEvent   time.Time
+	memoryEventsChanged chan struct{}
 
 	writer *TaskWriter
 }
@@ -38,6 +89,53 @@ type TaskSnapshot struct {
 	ExitCode          *int32
 	TerminationSignal string
 	TerminationSource string
+	Labels            map[string]string
+	Priority          int
+	QueuePosition     int
+	Attempts          []TaskAttempt
+	MaxAttempts       int
+	Dependencies      []Dependency
+	ScheduleID        string
+	RestartCount      int
+	LastExitReason    string
+	// PausedDuration is the time the task spent paused, including a pause that has not ended yet
+	PausedDuration time.Duration
+	// Limits are the effective resource limits and ResourceChanges the last changes of them
+	Limits          cgroups.Limits
+	ResourceChanges []ResourceChange
+	// MemoryEvents are the last memory events of the task, oldest first
+	MemoryEvents []MemoryEvent
+}
+
+// maxAttemptHistory bounds the attempts kept for the status of a task that is restarted indefinitely
+const maxAttemptHistory = MaxRetryAttempts
+
+// TaskAttempt is a single run of the process of a task
+type TaskAttempt struct {
+	// Number is the 1-based number of the attempt
+	Number            int
+	ProcessID         int
+	Status            int
+	StartTime         time.Time
+	EndTime           time.Time
+	ExitCode          *int32
+	TerminationSignal string
+	TerminationSource string
+	// OutputOffset is the offset of the first byte of the attempt in the output of the task
+	OutputOffset int64
+	// PidsMaxEvents is how often a fork failed because the attempt reached its pids.max
+	PidsMaxEvents int64
+	// MemoryEvents are the memory.events counters of the attempt when it ended
+	MemoryEvents cgroups.MemoryEvents
+}
+
+// clone returns a copy of the attempt that does not share the exit code
+func (a TaskAttempt) clone() TaskAttempt {
+	if a.ExitCode != nil {
+		code := *a.ExitCode
+		a.ExitCode = &code
+	}
+	return a
 }
 
 // CreateNewTask creates a new task with a writer
@@ -50,6 +148,7 @@ func CreateNewTask(id, clientID string, pid int, startTime time.Time, writer *Ta
 		status:    basetask.JobStatusStarted,
 		done:      make(chan struct{}),
 		writer:    writer,
+		limits:    cgroups.DefaultLimits,
 	}
 }
 
@@ -68,11 +167,23 @@ func (t *Task) GetClientID() string {
 	return t.clientID
 }
 
-// GetProcessID returns the process group ID
+// GetProcessID returns the process group ID; 0 while the task is queued
 func (t *Task) GetProcessID() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.processID
 }
 
+// GetLabels returns a copy of the task labels
+func (t *Task) GetLabels() map[string]string {
+	return maps.Clone(t.labels)
+}
+
+// GetDependencies returns the tasks the task depends on.
+func (t *Task) GetDependencies() []Dependency {
+	return slices.Clone(t.dependencies)
+}
+
 // GetStatus returns the task status.
 func (t *Task) GetStatus() int {
 	t.mu.RLock()
@@ -80,11 +191,25 @@ func (t *Task) GetStatus() int {
 	return t.status
 }
 
-// GetStartTime returns the task's start time
+// GetStartTime returns the task's start time; zero while the task is queued
 func (t *Task) GetStartTime() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.startTime
 }
 
+// GetPriority returns the priority the task was started with
+func (t *Task) GetPriority() int {
+	return t.priority
+}
+
+// GetQueuePosition returns the 1-based position of a queued task in the queue or 0 if it is not queued
+func (t *Task) GetQueuePosition() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.queuePosition
+}
+
 // GetEndTime returns the task's end time
 func (t *Task) GetEndTime() time.Time {
 	t.mu.RLock()
@@ -124,6 +249,317 @@ func (t *Task) SetStatus(status int) {
 	t.status = status
 }
 
+// setStarted records the process of a new attempt once it has been started. The start time of
+// the task is the start time of its first attempt.
+func (t *Task) setStarted(pid int, startTime time.Time, outputOffset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.processID = pid
+	if t.startTime.IsZero() {
+		t.startTime = startTime
+	}
+	t.status = basetask.JobStatusStarted
+	t.queuePosition = 0
+	t.attemptCount++
+	if len(t.attempts) >= maxAttemptHistory {
+		t.attempts = slices.Delete(t.attempts, 0, len(t.attempts)-maxAttemptHistory+1)
+	}
+	t.attempts = append(t.attempts, TaskAttempt{
+		Number:       t.attemptCount,
+		ProcessID:    pid,
+		Status:       basetask.JobStatusStarted,
+		StartTime:    startTime,
+		OutputOffset: outputOffset,
+	})
+}
+
+// nextAttempt is how a task continues after an attempt ended
+type nextAttempt struct {
+	// canceled is closed if the task is stopped while it waits for the next attempt; nil if there is none
+	canceled <-chan struct{}
+	// restart is set if the next attempt is a restart of the restart policy that begins after delay
+	restart bool
+	delay   time.Duration
+	// restartLimited is set if the restart policy would restart the attempt but its restart rate is reached
+	restartLimited bool
+}
+
+// endAttempt records the result of the current attempt and returns it. If canRetry is set and the retry
+// or restart policy starts another attempt, the task starts waiting for it and the returned canceled
+// channel is closed if the task is stopped meanwhile.
+func (t *Task) endAttempt(result TaskAttempt, canRetry bool) (TaskAttempt, nextAttempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.attempts) == 0 {
+		return result, nextAttempt{}
+	}
+	current := &t.attempts[len(t.attempts)-1]
+	current.Status = result.Status
+	current.EndTime = result.EndTime
+	current.ExitCode = result.ExitCode
+	current.TerminationSignal = result.TerminationSignal
+	current.TerminationSource = result.TerminationSource
+	current.PidsMaxEvents = result.PidsMaxEvents
+	current.MemoryEvents = result.MemoryEvents
+	t.lastExitReason = exitReason(*current)
+	// a process killed while frozen ends the pause
+	t.endPause(result.EndTime)
+	if !canRetry || t.stopping {
+		return current.clone(), nextAttempt{}
+	}
+
+	var next nextAttempt
+	switch {
+	case t.restart.restarts(*current):
+		delay, ok := t.restarts.next(t.restart, *current)
+		if !ok {
+			return current.clone(), nextAttempt{restartLimited: true}
+		}
+		next = nextAttempt{restart: true, delay: delay}
+	case t.retry.retries(*current):
+	default:
+		return current.clone(), nextAttempt{}
+	}
+
+	// the backoff begins under the same lock so that a stop never sees the exited process
+	t.processID = 0
+	t.terminationSource = ""
+	t.waitCanceled = make(chan struct{})
+	next.canceled = t.waitCanceled
+	return current.clone(), next
+}
+
+// setStopping marks the task as stopped before its process is killed so that it is not started again
+func (t *Task) setStopping() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopping = true
+}
+
+// setPaused marks a running task as paused; it returns false if the task has no running process anymore
+func (t *Task) setPaused(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status != basetask.JobStatusStarted || t.processID <= 0 || !t.endTime.IsZero() {
+		return false
+	}
+	t.status = basetask.JobStatusPaused
+	t.pausedAt = now
+	return true
+}
+
+// setResumed marks a paused task as running again and returns how long it was paused
+func (t *Task) setResumed(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status != basetask.JobStatusPaused {
+		return 0
+	}
+	pausedFor := now.Sub(t.pausedAt)
+	t.endPause(now)
+	return pausedFor
+}
+
+// endPause adds the current pause to the paused time and marks the task as running. It must be called
+// with mu held.
+func (t *Task) endPause(now time.Time) {
+	if t.status != basetask.JobStatusPaused {
+		return
+	}
+	t.pausedTotal += now.Sub(t.pausedAt)
+	t.pausedAt = time.Time{}
+	t.status = basetask.JobStatusStarted
+}
+
+// running reports whether the process of the task is running or paused
+func (t *Task) running() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return (t.status == basetask.JobStatusStarted || t.status == basetask.JobStatusPaused) && t.processID > 0 && t.endTime.IsZero()
+}
+
+// lastPidsMaxEvents returns the pids.max events of the last ended attempt
+func (t *Task) lastPidsMaxEvents() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.attempts) == 0 {
+		return 0
+	}
+	return t.attempts[len(t.attempts)-1].PidsMaxEvents
+}
+
+// lastMemoryEvents returns the memory.events counters of the last ended attempt
+func (t *Task) lastMemoryEvents() cgroups.MemoryEvents {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.attempts) == 0 {
+		return cgroups.MemoryEvents{}
+	}
+	return t.attempts[len(t.attempts)-1].MemoryEvents
+}
+
+// currentAttempt returns the number of the current attempt; 0 before the first one started
+func (t *Task) currentAttempt() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.attemptCount
+}
+
+// addMemoryEvents records memory events of the task and wakes those waiting for them. A pressure event
+// within pressureEventInterval of the last one is dropped since the trigger fires every window while the
+// pressure lasts.
+func (t *Task) addMemoryEvents(events ...MemoryEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	added := false
+	for _, event := range events {
+		if event.Kind == MemoryEventPressure {
+			if event.Time.Sub(t.lastPressureEvent) < pressureEventInterval {
+				continue
+			}
+			t.lastPressureEvent = event.Time
+		}
+		if len(t.memoryEvents) >= maxMemoryEvents {
+			t.memoryEvents = slices.Delete(t.memoryEvents, 0, len(t.memoryEvents)-maxMemoryEvents+1)
+		}
+		t.memoryEvents = append(t.memoryEvents, event)
+		t.memoryEventCount++
+		added = true
+	}
+	if added && t.memoryEventsChanged != nil {
+		close(t.memoryEventsChanged)
+		t.memoryEventsChanged = nil
+	}
+}
+
+// memoryEventsSince returns the kept memory events recorded after the first next events, the number of
+// events recorded and a channel that is closed when the next event is recorded
+func (t *Task) memoryEventsSince(next int) ([]MemoryEvent, int, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.memoryEventsChanged == nil {
+		t.memoryEventsChanged = make(chan struct{})
+	}
+	// the events before the first kept event were dropped
+	start := max(next-(t.memoryEventCount-len(t.memoryEvents)), 0)
+	return slices.Clone(t.memoryEvents[start:]), t.memoryEventCount, t.memoryEventsChanged
+}
+
+// getLimits returns the resource limits of the task
+func (t *Task) getLimits() cgroups.Limits {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.limits
+}
+
+// setLimits sets the resource limits of the task and records the change
+func (t *Task) setLimits(change ResourceChange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits = change.Limits
+	if len(t.resourceChanges) >= maxResourceChanges {
+		t.resourceChanges = slices.Delete(t.resourceChanges, 0, len(t.resourceChanges)-maxResourceChanges+1)
+	}
+	t.resourceChanges = append(t.resourceChanges, change)
+}
+
+// setResult sets the final status of the task from the result of its last attempt
+func (t *Task) setResult(result TaskAttempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = result.Status
+	t.endTime = result.EndTime
+	t.exitCode = result.ExitCode
+	t.terminationSignal = result.TerminationSignal
+	t.terminationSource = result.TerminationSource
+}
+
+// beginWait marks the task as waiting for its dependencies. The returned channel is closed if the task is stopped.
+func (t *Task) beginWait() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = basetask.JobStatusWaiting
+	t.waitCanceled = make(chan struct{})
+	return t.waitCanceled
+}
+
+// endWait ends the wait and returns the termination source if the task was stopped meanwhile
+func (t *Task) endWait() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.waitCanceled == nil {
+		return t.waitStopSource, true
+	}
+	t.waitCanceled = nil
+	return "", false
+}
+
+// cancelWait stops a task waiting for its dependencies, to retry or to restart; it returns false if the task is not waiting
+func (t *Task) cancelWait(source string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.waitCanceled == nil {
+		return false
+	}
+	t.waitStopSource = source
+	close(t.waitCanceled)
+	t.waitCanceled = nil
+	return true
+}
+
+// beginLaunch marks a task taken from the queue as being started
+func (t *Task) beginLaunch() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.launching = true
+}
+
+// cancelLaunch stops a task taken from the queue before its process runs; it returns false if the task
+// is not being started
+func (t *Task) cancelLaunch(source string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.launching {
+		return false
+	}
+	t.stopping = true
+	t.launchStopSource = source
+	return true
+}
+
+// launchStopped returns the termination source if the task was stopped while it is being started
+func (t *Task) launchStopped() (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.launchStopSource, t.launching && t.launchStopSource != ""
+}
+
+// endLaunch ends the launch and returns the termination source if the task was stopped meanwhile
+func (t *Task) endLaunch() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stopped := t.launching && t.launchStopSource != ""
+	t.launching = false
+	return t.launchStopSource, stopped
+}
+
+// setQueuePosition sets the 1-based position of a queued task in the queue
+func (t *Task) setQueuePosition(position int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queuePosition = position
+}
+
+// setFinishedUnstarted completes a task whose process was never started
+func (t *Task) setFinishedUnstarted(status int, source string, endTime time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+	t.terminationSource = source
+	t.endTime = endTime
+	t.queuePosition = 0
+}
+
 // SetExitCode sets the exit code.
 func (t *Task) SetExitCode(code *int32) {
 	t.mu.Lock()
@@ -159,6 +595,16 @@ func (t *Task) Done() <-chan struct{} {
 	return t.done
 }
 
+// finished reports whether the task has completed and its final status is set
+func (t *Task) finished() bool {
+	select {
+	case <-t.Done():
+		return true
+	default:
+		return false
+	}
+}
+
 // Snapshot returns a snapshot of the task's state
 // used to reduce the number of lock calls
 func (t *Task) Snapshot() TaskSnapshot {
@@ -171,6 +617,16 @@ func (t *Task) Snapshot() TaskSnapshot {
 		exitCodeCopy = &val
 	}
 
+	attempts := make([]TaskAttempt, 0, len(t.attempts))
+	for _, attempt := range t.attempts {
+		attempts = append(attempts, attempt.clone())
+	}
+
+	paused := t.pausedTotal
+	if t.status == basetask.JobStatusPaused {
+		paused += time.Since(t.pausedAt)
+	}
+
 	return TaskSnapshot{
 		ID:                t.id,
 		ClientID:          t.clientID,
@@ -181,5 +637,27 @@ func (t *Task) Snapshot() TaskSnapshot {
 		ExitCode:          exitCodeCopy,
 		TerminationSignal: t.terminationSignal,
 		TerminationSource: t.terminationSource,
+		Labels:            maps.Clone(t.labels),
+		Priority:          t.priority,
+		QueuePosition:     t.queuePosition,
+		Attempts:          attempts,
+		MaxAttempts:       t.maxAttempts(),
+		Dependencies:      slices.Clone(t.dependencies),
+		ScheduleID:        t.spec.ScheduleID,
+		RestartCount:      t.restarts.count,
+		LastExitReason:    t.lastExitReason,
+		PausedDuration:    paused,
+		Limits:            t.limits,
+		ResourceChanges:   slices.Clone(t.resourceChanges),
+		MemoryEvents:      slices.Clone(t.memoryEvents),
+	}
+}
+
+// maxAttempts returns the maximum number of attempts: 1 if the task is not retried and 0 if it is
+// restarted without a limit
+func (t *Task) maxAttempts() int {
+	if t.restart.Mode != RestartNever {
+		return 0
 	}
+	return max(1, t.retry.MaxAttempts)
 }
@@ -3,6 +3,9 @@ package task
 import (
 	"context"
 	"io"
+	"sync"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
 )
 
 // make sure TaskReader implements io.Reader
@@ -14,6 +17,7 @@ type TaskReader struct {
 	ctx    context.Context
 	offset int64
 	cancel context.CancelFunc
+	once   sync.Once
 }
 
 // Read reads the output of the task
@@ -28,9 +32,13 @@ func (tr *TaskReader) Read(p []byte) (int, error) {
 }
 
 // Close should now cancel the underlying context to stop further reads.
+// Close is safe to call more than once.
 func (tr *TaskReader) Close() error {
-	if tr.cancel != nil {
-		tr.cancel()
-	}
+	tr.once.Do(func() {
+		if tr.cancel != nil {
+			tr.cancel()
+		}
+		metrics.ActiveStreamers.Dec()
+	})
 	return nil
 }
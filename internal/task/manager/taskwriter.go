@@ -3,9 +3,11 @@ package task
 import (
 	"context"
 	"io"
-	"log"
+	"log/slog"
 	"slices"
 	"sync"
+
+	"github.com/mikewurtz/taskman/internal/metrics"
 )
 
 // make sure TaskWriter implements io.Writer
@@ -42,6 +44,7 @@ func (tw *TaskWriter) Write(p []byte) (n int, err error) {
 	tw.output = append(tw.output, p...)
 	tw.cond.Broadcast()
 	tw.mu.Unlock()
+	metrics.OutputBytesBuffered.Add(float64(len(p)))
 	return len(p), nil
 }
 
@@ -92,7 +95,7 @@ func (tw *TaskWriter) ReadOutput(ctx context.Context, offset int64) ([]byte, int
 
 		select {
 		case <-ctx.Done():
-			log.Printf("TaskWriter context canceled returning error: %v", ctx.Err())
+			slog.Debug("TaskWriter context canceled returning error", "error", ctx.Err())
 			return nil, offset, ctx.Err()
 		case <-tw.done:
 			if offset >= int64(len(tw.output)) {
@@ -114,3 +117,22 @@ func (tw *TaskWriter) Close() {
 		tw.cond.Broadcast()
 	})
 }
+
+// Size returns the number of output bytes held by the writer
+func (tw *TaskWriter) Size() int64 {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	return int64(len(tw.output))
+}
+
+// release drops the buffered output of a closed writer so that it can be garbage collected.
+// Readers still open on the writer reach EOF.
+func (tw *TaskWriter) release() {
+	tw.Close()
+	tw.mu.Lock()
+	released := len(tw.output)
+	tw.output = nil
+	tw.cond.Broadcast()
+	tw.mu.Unlock()
+	metrics.OutputBytesBuffered.Sub(float64(released))
+}
@@ -0,0 +1,47 @@
+package task
+
+import "context"
+
+// WaitTasks blocks until all of the tasks, or any of them if waitAny is set, have completed or the
+// context is done. It returns the tasks in the order of taskIDs; tasks may still be running if the
+// context is done first. Waiting uses the done channel of each task rather than polling. The gRPC handler
+// checks that the caller may access the tasks.
+func (tm *TaskManager) WaitTasks(ctx context.Context, taskIDs []string, waitAny bool) ([]*Task, error) {
+	tasks := make([]*Task, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		task, err := tm.getTaskFromMap(taskID)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// each task reports its completion on the shared channel; the buffer lets every goroutine exit
+	// without blocking once we stop receiving
+	doneC := make(chan struct{}, len(tasks))
+	for _, task := range tasks {
+		go func(t *Task) {
+			select {
+			case <-t.Done():
+				doneC <- struct{}{}
+			case <-waitCtx.Done():
+			}
+		}(task)
+	}
+
+	remaining := len(tasks)
+	if waitAny && remaining > 0 {
+		remaining = 1
+	}
+	for ; remaining > 0; remaining-- {
+		select {
+		case <-doneC:
+		case <-waitCtx.Done():
+			return tasks, nil
+		}
+	}
+	return tasks, nil
+}
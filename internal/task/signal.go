@@ -0,0 +1,36 @@
+package task
+
+import (
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// allowedSignals are the signals clients may send to a task. Job control signals such as SIGSTOP
+// are not allowed since a stopped process would still be reported as running.
+var allowedSignals = map[syscall.Signal]bool{
+	syscall.SIGHUP:  true,
+	syscall.SIGINT:  true,
+	syscall.SIGQUIT: true,
+	syscall.SIGKILL: true,
+	syscall.SIGTERM: true,
+	syscall.SIGUSR1: true,
+	syscall.SIGUSR2: true,
+}
+
+// ParseSignal parses a signal name such as "SIGTERM" or "term" and checks that clients may send it
+func ParseSignal(name string) (syscall.Signal, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(upper, "SIG") {
+		upper = "SIG" + upper
+	}
+	sig := unix.SignalNum(upper)
+	if sig == 0 {
+		return 0, NewTaskError(ErrInvalidArgument, "unknown signal %q", name)
+	}
+	if !allowedSignals[sig] {
+		return 0, NewTaskError(ErrInvalidArgument, "signal %s is not allowed", upper)
+	}
+	return sig, nil
+}
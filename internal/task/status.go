@@ -11,6 +11,11 @@ const (
 	JobStatusSignaled
 	JobStatusExitedOK
 	JobStatusExitedError
+	JobStatusQueued
+	JobStatusWaiting
+	JobStatusPaused
+	JobStatusCanceled
+	JobStatusFailed
 )
 
 // StatusToProto converts internal status strings to proto JobStatus enum
@@ -26,7 +31,45 @@ func StatusToProto(internal int) (pb.JobStatus, error) {
 		return pb.JobStatus_JOB_STATUS_EXITED_OK, nil
 	case JobStatusExitedError:
 		return pb.JobStatus_JOB_STATUS_EXITED_ERROR, nil
+	case JobStatusQueued:
+		return pb.JobStatus_JOB_STATUS_QUEUED, nil
+	case JobStatusWaiting:
+		return pb.JobStatus_JOB_STATUS_WAITING, nil
+	case JobStatusPaused:
+		return pb.JobStatus_JOB_STATUS_PAUSED, nil
+	case JobStatusCanceled:
+		return pb.JobStatus_JOB_STATUS_CANCELED, nil
+	case JobStatusFailed:
+		return pb.JobStatus_JOB_STATUS_FAILED, nil
 	default:
 		return pb.JobStatus_JOB_STATUS_UNKNOWN, NewTaskError(ErrInternal, "unknown internal job status: %q", internal)
 	}
 }
+
+// StatusFromProto converts a proto JobStatus enum to the internal status
+func StatusFromProto(status pb.JobStatus) (int, error) {
+	switch status {
+	case pb.JobStatus_JOB_STATUS_UNKNOWN:
+		return JobStatusUnknown, nil
+	case pb.JobStatus_JOB_STATUS_STARTED:
+		return JobStatusStarted, nil
+	case pb.JobStatus_JOB_STATUS_SIGNALED:
+		return JobStatusSignaled, nil
+	case pb.JobStatus_JOB_STATUS_EXITED_OK:
+		return JobStatusExitedOK, nil
+	case pb.JobStatus_JOB_STATUS_EXITED_ERROR:
+		return JobStatusExitedError, nil
+	case pb.JobStatus_JOB_STATUS_QUEUED:
+		return JobStatusQueued, nil
+	case pb.JobStatus_JOB_STATUS_WAITING:
+		return JobStatusWaiting, nil
+	case pb.JobStatus_JOB_STATUS_PAUSED:
+		return JobStatusPaused, nil
+	case pb.JobStatus_JOB_STATUS_CANCELED:
+		return JobStatusCanceled, nil
+	case pb.JobStatus_JOB_STATUS_FAILED:
+		return JobStatusFailed, nil
+	default:
+		return JobStatusUnknown, NewTaskError(ErrInvalidArgument, "unknown job status: %d", status)
+	}
+}
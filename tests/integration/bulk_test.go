@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestIntegration_StopTasksBySelector(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// a unique pipeline label keeps the selection independent of tasks started by other tests
+	pipeline := uuid.New().String()
+	var taskIDs []string
+	for range 2 {
+		resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+			Command: "sleep",
+			Args:    []string{"30"},
+			Labels:  map[string]string{"pipeline": pipeline},
+		})
+		require.NoError(t, err)
+		taskIDs = append(taskIDs, resp.TaskId)
+	}
+	selector := &pb.TaskSelector{LabelSelector: "pipeline=" + pipeline}
+
+	// other clients never select the tasks even when naming the owner
+	otherResp, err := otherClient.StopTasks(ctx, &pb.StopTasksRequest{
+		Selector: &pb.TaskSelector{LabelSelector: "pipeline=" + pipeline, Owner: "client001"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, otherResp.Results)
+
+	dryRunResp, err := client.StopTasks(ctx, &pb.StopTasksRequest{Selector: selector, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, dryRunResp.Results, 2)
+	for i, result := range dryRunResp.Results {
+		assert.Equal(t, taskIDs[i], result.TaskId)
+		assert.Equal(t, int32(codes.OK), result.Code)
+
+		statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: result.TaskId})
+		require.NoError(t, err)
+		assert.Equal(t, pb.JobStatus_JOB_STATUS_STARTED, statusResp.Status)
+	}
+
+	stopResp, err := client.StopTasks(ctx, &pb.StopTasksRequest{Selector: selector})
+	require.NoError(t, err)
+	require.Len(t, stopResp.Results, 2)
+	for _, result := range stopResp.Results {
+		assert.Equal(t, int32(codes.OK), result.Code, result.Message)
+	}
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: taskIDs})
+	require.NoError(t, err)
+	for _, taskStatus := range waitResp.Statuses {
+		assert.Equal(t, pb.JobStatus_JOB_STATUS_SIGNALED, taskStatus.Status)
+		assert.Equal(t, "user", taskStatus.TerminationSource)
+	}
+
+	// the stopped tasks are no longer running so they are not selected again
+	stopResp, err = client.StopTasks(ctx, &pb.StopTasksRequest{Selector: selector})
+	require.NoError(t, err)
+	assert.Empty(t, stopResp.Results)
+
+	// selecting the stopped tasks by status reports a failure for each of them
+	stopResp, err = client.StopTasks(ctx, &pb.StopTasksRequest{Selector: &pb.TaskSelector{
+		LabelSelector: "pipeline=" + pipeline,
+		Statuses:      []pb.JobStatus{pb.JobStatus_JOB_STATUS_SIGNALED},
+		StartedBefore: timestamppb.Now(),
+	}})
+	require.NoError(t, err)
+	require.Len(t, stopResp.Results, 2)
+	for _, result := range stopResp.Results {
+		assert.Equal(t, int32(codes.FailedPrecondition), result.Code)
+		assert.NotEmpty(t, result.Message)
+	}
+}
+
+func TestIntegration_SignalTasksBySelector(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	pipeline := uuid.New().String()
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "sleep",
+		Args:    []string{"30"},
+		Labels:  map[string]string{"pipeline": pipeline},
+	})
+	require.NoError(t, err)
+	selector := &pb.TaskSelector{LabelSelector: "pipeline=" + pipeline}
+
+	_, err = client.SignalTasks(ctx, &pb.SignalTasksRequest{Selector: selector, Signal: "SIGSTOP"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	signalResp, err := client.SignalTasks(ctx, &pb.SignalTasksRequest{Selector: selector, Signal: "TERM"})
+	require.NoError(t, err)
+	require.Len(t, signalResp.Results, 1)
+	assert.Equal(t, resp.TaskId, signalResp.Results[0].TaskId)
+	assert.Equal(t, int32(codes.OK), signalResp.Results[0].Code)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{resp.TaskId}})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_SIGNALED, waitResp.Statuses[0].Status)
+}
+
+func TestIntegration_StopTasksEmptySelector(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, err := client.StopTasks(ctx, &pb.StopTasksRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.StopTasks(ctx, &pb.StopTasksRequest{Selector: &pb.TaskSelector{}})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
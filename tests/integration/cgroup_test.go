@@ -2,6 +2,8 @@ package integration
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -77,16 +79,20 @@ func TestIntegration_StartTaskOOMKilledPerl(t *testing.T) {
 
 func TestIntegration_StartTaskIOThrottled(t *testing.T) {
 	t.Parallel()
+	if len(testIODevices) == 0 {
+		t.Skip("no block device backs the working directory")
+	}
 
 	client := createTestClient(t, "client001")
 
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 
+	// read directly from the device the IO limits apply to; udev links it by its major:minor number
 	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
 		Command: "dd",
 		Args: []string{
-			"if=/dev/sda",
+			"if=/dev/block/" + testIODevices[0],
 			"of=/dev/null",
 			"bs=1M",
 			"count=5",
@@ -99,7 +105,7 @@ func TestIntegration_StartTaskIOThrottled(t *testing.T) {
 
 	require.Eventually(t, func() bool {
 
-		ioStatPath := filepath.Join("/sys/fs/cgroup", resp.TaskId, "io.stat")
+		ioStatPath := filepath.Join(testHierarchy.TaskPath("client001", resp.TaskId), "io.stat")
 		data, readErr := os.ReadFile(ioStatPath)
 		if readErr != nil {
 			t.Logf("waiting for io.stat: %v", readErr)
@@ -174,7 +180,7 @@ func TestIntegration_CPUThrottled_BashLoop(t *testing.T) {
 		}
 
 		// Try reading cpu.stat while task is running
-		cpuStatPath := filepath.Join("/sys/fs/cgroup", resp.TaskId, "cpu.stat")
+		cpuStatPath := filepath.Join(testHierarchy.TaskPath("client001", resp.TaskId), "cpu.stat")
 		data, readErr := os.ReadFile(cpuStatPath)
 		if readErr != nil {
 			t.Logf("cpu.stat not ready yet: %v", readErr)
@@ -194,3 +200,42 @@ func TestIntegration_CPUThrottled_BashLoop(t *testing.T) {
 	assert.True(t, ok)
 	assert.True(t, sts.Code() == codes.OK || sts.Code() == codes.FailedPrecondition)
 }
+
+func TestIntegration_WatchMemoryEvents(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// the watch starts before the task allocates more than its memory.max of 64M
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "perl",
+		Args:    []string{"-e", "sleep 1; my $x = \"A\" x (128 * 1024 * 1024); sleep 5;"},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.WatchMemoryEvents(ctx, &pb.WatchMemoryEventsRequest{TaskId: resp.TaskId})
+	require.NoError(t, err)
+	kinds := make(map[string]bool)
+	for {
+		eventResp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), eventResp.Event.Attempt)
+		kinds[eventResp.Event.Kind] = true
+	}
+	// the stream ends once the task was OOM killed
+	assert.True(t, kinds["max"], "expected a max event, got %v", kinds)
+	assert.True(t, kinds["oom_kill"], "expected an oom_kill event, got %v", kinds)
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: resp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, "oom", statusResp.TerminationSource)
+	assert.Positive(t, statusResp.Memory.GetMaxEvents())
+	assert.Positive(t, statusResp.Memory.GetOomKillEvents())
+	assert.NotEmpty(t, statusResp.MemoryEvents)
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntegration_DeleteTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sh",
+		Args:    []string{"-c", "echo hello; sleep 0.5"},
+	})
+	require.NoError(t, err)
+
+	// running tasks must be stopped first
+	_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: resp.TaskId})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{resp.TaskId}})
+	require.NoError(t, err)
+
+	// other clients cannot delete the task
+	_, err = otherClient.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: resp.TaskId})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: resp.TaskId})
+	require.NoError(t, err)
+
+	// the deleted task is gone
+	_, err = client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: resp.TaskId})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.DeleteTask(ctx, &pb.DeleteTaskRequest{TaskId: resp.TaskId})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
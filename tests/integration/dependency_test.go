@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_TaskDependencies(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	start := func(req *pb.StartTaskRequest) string {
+		resp, err := client.StartTask(ctx, req)
+		require.NoError(t, err)
+		return resp.TaskId
+	}
+
+	failing := start(&pb.StartTaskRequest{Command: "/bin/sh", Args: []string{"-c", "sleep 0.5; exit 1"}})
+	onSuccess := start(&pb.StartTaskRequest{
+		Command:   "/bin/true",
+		DependsOn: []*pb.TaskDependency{{TaskId: failing}},
+	})
+	onFailure := start(&pb.StartTaskRequest{
+		Command: "/bin/true",
+		DependsOn: []*pb.TaskDependency{
+			{TaskId: failing, Condition: pb.DependencyCondition_DEPENDENCY_CONDITION_FAILURE},
+		},
+	})
+	// the second step waits for the first one and fails with it
+	chained := start(&pb.StartTaskRequest{
+		Command:   "/bin/true",
+		DependsOn: []*pb.TaskDependency{{TaskId: onSuccess}},
+	})
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: onSuccess})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_WAITING, statusResp.Status)
+	require.Len(t, statusResp.DependsOn, 1)
+	assert.Equal(t, failing, statusResp.DependsOn[0].TaskId)
+	assert.Equal(t, pb.DependencyCondition_DEPENDENCY_CONDITION_SUCCESS, statusResp.DependsOn[0].Condition)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{onSuccess, onFailure, chained}})
+	require.NoError(t, err)
+	require.Len(t, waitResp.Statuses, 3)
+
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_FAILED, waitResp.Statuses[0].Status)
+	assert.Equal(t, "dependency", waitResp.Statuses[0].TerminationSource)
+	assert.Zero(t, waitResp.Statuses[0].ProcessId)
+
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_OK, waitResp.Statuses[1].Status)
+
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_FAILED, waitResp.Statuses[2].Status)
+	assert.Equal(t, "dependency", waitResp.Statuses[2].TerminationSource)
+}
+
+func TestIntegration_TaskDependenciesRejected(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	otherResp, err := otherClient.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/true"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc      string
+		dependsOn []*pb.TaskDependency
+	}{
+		{desc: "unknown task", dependsOn: []*pb.TaskDependency{{TaskId: "123e4567-e89b-12d3-a456-426614174000"}}},
+		{desc: "task of another client", dependsOn: []*pb.TaskDependency{{TaskId: otherResp.TaskId}}},
+		{
+			desc:      "invalid condition",
+			dependsOn: []*pb.TaskDependency{{TaskId: otherResp.TaskId, Condition: pb.DependencyCondition(42)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/true", DependsOn: tt.dependsOn})
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestIntegration_StopWaitingTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sleeping, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sleep", Args: []string{"10"}})
+	require.NoError(t, err)
+	waiting, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command:   "/bin/true",
+		DependsOn: []*pb.TaskDependency{{TaskId: sleeping.TaskId}},
+	})
+	require.NoError(t, err)
+
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: waiting.TaskId})
+	require.NoError(t, err)
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: sleeping.TaskId})
+	require.NoError(t, err)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{waiting.TaskId}})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_CANCELED, waitResp.Statuses[0].Status)
+	assert.Equal(t, "user", waitResp.Statuses[0].TerminationSource)
+}
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createGatewayClient returns an HTTPS client authenticating to the gateway with the user's certificate
+func createGatewayClient(t *testing.T, userID string) *http.Client {
+	t.Helper()
+
+	tlsConfig, err := loadClientTLSConfig(userID)
+	require.NoError(t, err, "failed to load client TLS config")
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	t.Cleanup(transport.CloseIdleConnections)
+	return &http.Client{Transport: transport, Timeout: streamTestTimeout}
+}
+
+func gatewayRequest(t *testing.T, client *http.Client, method, path, body string, header http.Header) *http.Response {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamTestTimeout)
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, method, "https://"+testGatewayAddr+path, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp
+}
+
+func TestIntegration_GatewayStartStatusAndOutput(t *testing.T) {
+	t.Parallel()
+
+	client := createGatewayClient(t, "client001")
+
+	resp := gatewayRequest(t, client, http.MethodPost, "/v1/tasks",
+		`{"command": "sh", "args": ["-c", "echo hello"]}`, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("X-Request-Id"))
+
+	var started struct {
+		TaskID string `json:"task_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&started))
+	require.NotEmpty(t, started.TaskID)
+
+	// raw output blocks until the task has exited
+	resp = gatewayRequest(t, client, http.MethodGet, "/v1/tasks/"+started.TaskID+"/output", "",
+		http.Header{"Accept": []string{"application/octet-stream"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	output, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(output))
+
+	resp = gatewayRequest(t, client, http.MethodGet, "/v1/tasks/"+started.TaskID, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var taskStatus struct {
+		TaskID string `json:"task_id"`
+		Status string `json:"status"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&taskStatus))
+	assert.Equal(t, started.TaskID, taskStatus.TaskID)
+	assert.Equal(t, "JOB_STATUS_EXITED_OK", taskStatus.Status)
+}
+
+func TestIntegration_GatewayErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc         string
+		userID       string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			desc:         "task not found",
+			userID:       "client001",
+			method:       http.MethodGet,
+			path:         "/v1/tasks/375b0522-72ed-4f3f-88d0-01d360d06b8c",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "empty command",
+			userID:       "client001",
+			method:       http.MethodPost,
+			path:         "/v1/tasks",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "malformed body",
+			userID:       "client001",
+			method:       http.MethodPost,
+			path:         "/v1/tasks",
+			body:         `{"command":`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := createGatewayClient(t, tt.userID)
+			resp := gatewayRequest(t, client, tt.method, tt.path, tt.body, nil)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			var st struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+			assert.NotZero(t, st.Code)
+			assert.NotEmpty(t, st.Message)
+		})
+	}
+}
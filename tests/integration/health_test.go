@@ -0,0 +1,32 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_HealthCheckServing(t *testing.T) {
+	t.Parallel()
+
+	_, conn, err := createClient(testUserID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close(), "failed to clean up gRPC connection")
+	})
+	healthClient := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, service := range []string{"", pb.TaskManager_ServiceDesc.ServiceName} {
+		resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, "service %q", service)
+	}
+}
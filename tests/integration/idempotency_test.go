@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntegration_StartTaskIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	key := uuid.New().String()
+	req := &pb.StartTaskRequest{
+		Command:        "sleep",
+		Args:           []string{"0.1"},
+		Labels:         map[string]string{"team": "infra"},
+		IdempotencyKey: key,
+	}
+
+	first, err := client.StartTask(ctx, req)
+	require.NoError(t, err)
+
+	// a retry with the same key and parameters returns the original task
+	retry, err := client.StartTask(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.TaskId, retry.TaskId)
+
+	// the same key with different parameters is a conflict
+	_, err = client.StartTask(ctx, &pb.StartTaskRequest{
+		Command:        "sleep",
+		Args:           []string{"0.2"},
+		IdempotencyKey: key,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// keys are scoped per client
+	other, err := otherClient.StartTask(ctx, req)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.TaskId, other.TaskId)
+}
+
+func TestIntegration_StartTaskIdempotencyKeyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	req := &pb.StartTaskRequest{
+		Command:        "sleep",
+		Args:           []string{"0.1"},
+		IdempotencyKey: uuid.New().String(),
+	}
+
+	const concurrency = 5
+	taskIDs := make([]string, concurrency)
+	errs := make([]error, concurrency)
+	var wg sync.WaitGroup
+	for i := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.StartTask(ctx, req)
+			errs[i] = err
+			if err == nil {
+				taskIDs[i] = resp.TaskId
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range concurrency {
+		require.NoError(t, errs[i])
+		assert.Equal(t, taskIDs[0], taskIDs[i])
+	}
+}
+
+func TestIntegration_StartTaskIdempotencyKeyFailedStart(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	key := uuid.New().String()
+	_, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/does/not/exist", IdempotencyKey: key})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// a failed start does not keep the key
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "sleep", Args: []string{"0.1"}, IdempotencyKey: key})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.TaskId)
+}
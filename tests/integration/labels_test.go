@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntegration_LabelsAndListTasks(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+	adminClient := createTestClient(t, "admin")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// a unique build label keeps the listing independent of tasks started by other tests
+	build := uuid.New().String()
+	infra, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "sleep",
+		Args:    []string{"0.1"},
+		Labels:  map[string]string{"team": "infra", "build": build},
+	})
+	require.NoError(t, err)
+	web, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "sleep",
+		Args:    []string{"0.1"},
+		Labels:  map[string]string{"team": "web", "build": build},
+	})
+	require.NoError(t, err)
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: infra.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "infra", "build": build}, statusResp.Labels)
+
+	listResp, err := client.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: "build=" + build})
+	require.NoError(t, err)
+	require.Len(t, listResp.Tasks, 2)
+	assert.Equal(t, infra.TaskId, listResp.Tasks[0].TaskId)
+	assert.Equal(t, web.TaskId, listResp.Tasks[1].TaskId)
+
+	listResp, err = client.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: "build=" + build + ",team!=infra"})
+	require.NoError(t, err)
+	require.Len(t, listResp.Tasks, 1)
+	assert.Equal(t, web.TaskId, listResp.Tasks[0].TaskId)
+
+	// other clients do not see the tasks but the admin does
+	listResp, err = otherClient.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: "build=" + build})
+	require.NoError(t, err)
+	assert.Empty(t, listResp.Tasks)
+
+	listResp, err = adminClient.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: "build=" + build})
+	require.NoError(t, err)
+	assert.Len(t, listResp.Tasks, 2)
+}
+
+func TestIntegration_LabelsInvalid(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "sleep",
+		Args:    []string{"0.1"},
+		Labels:  map[string]string{"team name": "infra"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListTasks(ctx, &pb.ListTasksRequest{LabelSelector: "team=in fra"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
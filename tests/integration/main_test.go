@@ -12,6 +12,7 @@ import (
 
 	"github.com/mikewurtz/taskman/certs"
 	"github.com/mikewurtz/taskman/internal/grpc/server"
+	"github.com/mikewurtz/taskman/internal/task/cgroups"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -29,7 +30,12 @@ const (
 )
 
 var (
-	testServerAddr string
+	testServerAddr  string
+	testGatewayAddr string
+	// testIODevices are the major:minor numbers of the block devices the IO limits apply to
+	testIODevices []string
+	// testHierarchy is the cgroup tree the tasks of the test server run in
+	testHierarchy = cgroups.DefaultHierarchy()
 )
 
 func TestMain(m *testing.M) {
@@ -48,8 +54,16 @@ func TestMain(m *testing.M) {
 // startTestServer starts the test server and returns a function to stop it
 // will only be called once
 func startTestServer() (func(), error) {
+	ioDevices, err := cgroups.ResolveIODevices([]string{cgroups.IODeviceWorkDir})
+	if err != nil {
+		fmt.Println("IO limits are not enforced:", err)
+	}
+	testIODevices = ioDevices
+
 	ctx, cancel := context.WithCancel(context.Background())
-	srv, err := server.New(ctx, "localhost:0")
+	srv, err := server.New(ctx, "localhost:0", server.WithGatewayAddress("localhost:0"),
+		server.WithResourceCeilings(cgroups.Limits{MemoryMax: 1 << 30}), server.WithIODevices(ioDevices),
+		server.WithCgroupHierarchy(testHierarchy))
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create test server: %w", err)
@@ -67,6 +81,7 @@ func startTestServer() (func(), error) {
 	}
 
 	testServerAddr = srv.Addr()
+	testGatewayAddr = srv.GatewayAddr()
 	client, conn, err := createClient(testUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
@@ -100,6 +115,29 @@ func startTestServer() (func(), error) {
 }
 
 func createClient(userID string) (pb.TaskManagerClient, *grpc.ClientConn, error) {
+	return createClientForAddr(testServerAddr, userID)
+}
+
+// createClientForAddr creates a client of the user for a server other than the shared test server
+func createClientForAddr(serverAddr, userID string) (pb.TaskManagerClient, *grpc.ClientConn, error) {
+	tlsConfig, err := loadClientTLSConfig(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := grpc.NewClient(
+		serverAddr,
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up gRPC client: %w", err)
+	}
+
+	return pb.NewTaskManagerClient(conn), conn, nil
+}
+
+// loadClientTLSConfig loads the embedded client certificate of the user and the CA
+func loadClientTLSConfig(userID string) (*tls.Config, error) {
 	if userID == "" {
 		userID = testUserID
 	}
@@ -109,43 +147,33 @@ func createClient(userID string) (pb.TaskManagerClient, *grpc.ClientConn, error)
 
 	certPEM, err := certs.CertFiles.ReadFile(certPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read embedded client cert: %w", err)
+		return nil, fmt.Errorf("failed to read embedded client cert: %w", err)
 	}
 
 	keyPEM, err := certs.CertFiles.ReadFile(keyPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read embedded client key: %w", err)
+		return nil, fmt.Errorf("failed to read embedded client key: %w", err)
 	}
 
 	cert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse key pair: %w", err)
+		return nil, fmt.Errorf("failed to parse key pair: %w", err)
 	}
 
 	caCert, err := certs.CertFiles.ReadFile("ca.crt")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read embedded CA cert: %w", err)
+		return nil, fmt.Errorf("failed to read embedded CA cert: %w", err)
 	}
 
 	caPool := x509.NewCertPool()
 	if !caPool.AppendCertsFromPEM(caCert) {
-		return nil, nil, errors.New("failed to append CA cert to pool")
+		return nil, errors.New("failed to append CA cert to pool")
 	}
 
-	tlsConfig := &tls.Config{
+	return &tls.Config{
 		Certificates: []tls.Certificate{cert},
 		RootCAs:      caPool,
-	}
-
-	conn, err := grpc.NewClient(
-		testServerAddr,
-		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to set up gRPC client: %w", err)
-	}
-
-	return pb.NewTaskManagerClient(conn), conn, nil
+	}, nil
 }
 
 func createTestClient(t *testing.T, userID string) pb.TaskManagerClient {
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_PauseAndResumeTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sleep", Args: []string{"60"}})
+	require.NoError(t, err)
+
+	_, err = client.PauseTask(ctx, &pb.PauseTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_PAUSED, statusResp.Status)
+
+	// signals stay pending in frozen processes so they are rejected until the task is resumed
+	_, err = client.SignalTask(ctx, &pb.SignalTaskRequest{TaskId: startResp.TaskId, Signal: "SIGTERM"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// other clients cannot resume the task
+	otherClient := createTestClient(t, "client002")
+	_, err = otherClient.ResumeTask(ctx, &pb.ResumeTaskRequest{TaskId: startResp.TaskId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	time.Sleep(50 * time.Millisecond)
+	_, err = client.ResumeTask(ctx, &pb.ResumeTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	statusResp, err = client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_STARTED, statusResp.Status)
+	assert.GreaterOrEqual(t, statusResp.PausedDuration.AsDuration(), 50*time.Millisecond)
+
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+}
+
+func TestIntegration_StopPausedTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sleep", Args: []string{"60"}})
+	require.NoError(t, err)
+	_, err = client.PauseTask(ctx, &pb.PauseTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+
+	// SIGKILL is delivered to frozen processes
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{startResp.TaskId}})
+	require.NoError(t, err)
+	require.Len(t, waitResp.Statuses, 1)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_SIGNALED, waitResp.Statuses[0].Status)
+	assert.Equal(t, "user", waitResp.Statuses[0].TerminationSource)
+
+	_, err = client.ResumeTask(ctx, &pb.ResumeTaskRequest{TaskId: startResp.TaskId})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/mikewurtz/taskman/internal/grpc/server"
+	taskmanager "github.com/mikewurtz/taskman/internal/task/manager"
+)
+
+// startQuotaServer starts a server of its own with the quota policy so that the quotas do not
+// affect the tests using the shared test server
+func startQuotaServer(t *testing.T, policy taskmanager.QuotaPolicy) string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv, err := server.New(ctx, "localhost:0", server.WithQuotaPolicy(policy))
+	require.NoError(t, err)
+	go func() {
+		if err := srv.Start(); err != nil {
+			t.Logf("quota server error: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		srv.Shutdown()
+	})
+	return srv.Addr()
+}
+
+func createQuotaClient(t *testing.T, serverAddr, userID string) pb.TaskManagerClient {
+	t.Helper()
+
+	client, conn, err := createClientForAddr(serverAddr, userID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+	return client
+}
+
+func TestIntegration_QueueTasks(t *testing.T) {
+	t.Parallel()
+
+	addr := startQuotaServer(t, taskmanager.QuotaPolicy{MaxRunningPerClient: 1, MaxQueued: 2})
+	client := createQuotaClient(t, addr, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	start := func(priority int32, args ...string) string {
+		resp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sh", Args: args, Priority: priority})
+		require.NoError(t, err)
+		return resp.TaskId
+	}
+
+	running := start(0, "-c", "sleep 1")
+	low := start(0, "-c", "echo low")
+	high := start(5, "-c", "echo high")
+
+	// the queue is full
+	_, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/true"})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the task with the higher priority is first in the queue
+	highStatus, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: high})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_QUEUED, highStatus.Status)
+	assert.Equal(t, int32(1), highStatus.QueuePosition)
+	lowStatus, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: low})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), lowStatus.QueuePosition)
+
+	// stopping a queued task removes it from the queue
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: low})
+	require.NoError(t, err)
+	lowStatus, err = client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: low})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_CANCELED, lowStatus.Status)
+	assert.Equal(t, "user", lowStatus.TerminationSource)
+	assert.Zero(t, lowStatus.ProcessId)
+
+	// the queued task starts once the running task has exited
+	resp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{running, high}})
+	require.NoError(t, err)
+	require.True(t, resp.Completed)
+	for _, s := range resp.Statuses {
+		assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_OK, s.Status)
+	}
+	runningStatus, highStatus := resp.Statuses[0], resp.Statuses[1]
+	assert.Zero(t, highStatus.QueuePosition)
+	assert.False(t, highStatus.StartTime.AsTime().Before(runningStatus.EndTime.AsTime()))
+}
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntegration_GetQuota(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client002")
+	adminClient := createTestClient(t, "admin")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sleep",
+		Args:    []string{"2"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = client.StopTask(context.Background(), &pb.StopTaskRequest{TaskId: resp.TaskId})
+	})
+
+	quota, err := client.GetQuota(ctx, &pb.GetQuotaRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "client002", quota.ClientId)
+	assert.GreaterOrEqual(t, quota.RunningTasks.Used, int64(1))
+	assert.GreaterOrEqual(t, quota.GlobalRunningTasks.Used, quota.RunningTasks.Used)
+	// the test server runs without quotas
+	assert.Zero(t, quota.RunningTasks.Limit)
+	assert.Zero(t, quota.GlobalRunningTasks.Limit)
+	assert.Zero(t, quota.StartsPerMinute.Limit)
+
+	// admins can get the usage of any client
+	adminQuota, err := adminClient.GetQuota(ctx, &pb.GetQuotaRequest{ClientId: "client002"})
+	require.NoError(t, err)
+	assert.Equal(t, "client002", adminQuota.ClientId)
+	assert.GreaterOrEqual(t, adminQuota.RunningTasks.Used, int64(1))
+
+	// other clients cannot
+	_, err = client.GetQuota(ctx, &pb.GetQuotaRequest{ClientId: "client001"})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
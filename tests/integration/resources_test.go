@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_UpdateTaskResources(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sleep", Args: []string{"60"}})
+	require.NoError(t, err)
+	defer func() {
+		_, err := client.StopTask(context.Background(), &pb.StopTaskRequest{TaskId: startResp.TaskId})
+		assert.NoError(t, err)
+	}()
+
+	updateResp, err := client.UpdateTaskResources(ctx, &pb.UpdateTaskResourcesRequest{
+		TaskId: startResp.TaskId,
+		Limits: &pb.ResourceLimits{MemoryMaxBytes: proto.Int64(128 << 20), MemoryHighBytes: proto.Int64(100 << 20)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(128<<20), updateResp.Limits.GetMemoryMaxBytes())
+	assert.Equal(t, int64(100<<20), updateResp.Limits.GetMemoryHighBytes())
+	assert.Equal(t, int64(200), updateResp.Limits.GetCpuMillis())
+
+	// the live cgroup has the new limits
+	for file, expected := range map[string]string{"memory.max": "134217728", "memory.high": "104857600"} {
+		data, err := os.ReadFile(filepath.Join(testHierarchy.TaskPath("client001", startResp.TaskId), file))
+		require.NoError(t, err)
+		assert.Equal(t, expected, strings.TrimSpace(string(data)), file)
+	}
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(128<<20), statusResp.Limits.GetMemoryMaxBytes())
+	require.Len(t, statusResp.ResourceChanges, 1)
+	assert.Equal(t, "client001", statusResp.ResourceChanges[0].ClientId)
+	assert.Equal(t, int64(64<<20), statusResp.ResourceChanges[0].Previous.GetMemoryMaxBytes())
+
+	// limits above the server ceilings are rejected
+	_, err = client.UpdateTaskResources(ctx, &pb.UpdateTaskResourcesRequest{
+		TaskId: startResp.TaskId,
+		Limits: &pb.ResourceLimits{MemoryMaxBytes: proto.Int64(0)},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// other clients cannot update the task
+	otherClient := createTestClient(t, "client002")
+	_, err = otherClient.UpdateTaskResources(ctx, &pb.UpdateTaskResourcesRequest{
+		TaskId: startResp.TaskId,
+		Limits: &pb.ResourceLimits{CpuMillis: proto.Int64(100)},
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestIntegration_StartTaskWithLimits(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sleep",
+		Args:    []string{"60"},
+		Limits:  &pb.ResourceLimits{MemoryMaxBytes: proto.Int64(128 << 20), MemoryHighBytes: proto.Int64(100 << 20)},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_, err := client.StopTask(context.Background(), &pb.StopTaskRequest{TaskId: startResp.TaskId})
+		assert.NoError(t, err)
+	}()
+
+	// the cgroup is created with the limits of the request and the defaults for the others
+	for file, expected := range map[string]string{"memory.max": "134217728", "memory.high": "104857600", "cpu.max": "200000 1000000"} {
+		data, err := os.ReadFile(filepath.Join(testHierarchy.TaskPath("client001", startResp.TaskId), file))
+		require.NoError(t, err)
+		assert.Equal(t, expected, strings.TrimSpace(string(data)), file)
+	}
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100<<20), statusResp.Limits.GetMemoryHighBytes())
+	assert.Empty(t, statusResp.ResourceChanges)
+
+	// limits above the server ceilings are rejected
+	_, err = client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/true",
+		Limits:  &pb.ResourceLimits{MemoryMaxBytes: proto.Int64(0)},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestIntegration_StartTaskWithPidsMaxAndPinning(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	limits := &pb.ResourceLimits{PidsMax: proto.Int64(16)}
+	// pinning requires the cpuset controller, which the server only enables when it is available
+	pinned := testHierarchy.ControllerEnabled("cpuset")
+	if pinned {
+		limits.CpusetCpus = proto.String("0")
+		limits.CpusetMems = proto.String("0")
+	}
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "/bin/sleep", Args: []string{"60"}, Limits: limits})
+	require.NoError(t, err)
+	defer func() {
+		_, err := client.StopTask(context.Background(), &pb.StopTaskRequest{TaskId: startResp.TaskId})
+		assert.NoError(t, err)
+	}()
+
+	// the process runs under the limits from its start
+	expected := map[string]string{"pids.max": "16"}
+	if pinned {
+		expected["cpuset.cpus"] = "0"
+		expected["cpuset.mems"] = "0"
+	}
+	for file, value := range expected {
+		data, err := os.ReadFile(filepath.Join(testHierarchy.TaskPath("client001", startResp.TaskId), file))
+		require.NoError(t, err)
+		assert.Equal(t, value, strings.TrimSpace(string(data)), file)
+	}
+}
+
+func TestIntegration_PidsMax(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*testTimeout)
+	defer cancel()
+
+	// the shell fails once it cannot fork another sleep
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sh",
+		Args:    []string{"-c", "sleep 1; for i in 1 2 3 4 5 6 7 8; do sleep 2 & done; wait"},
+	})
+	require.NoError(t, err)
+
+	updateResp, err := client.UpdateTaskResources(ctx, &pb.UpdateTaskResourcesRequest{
+		TaskId: startResp.TaskId,
+		Limits: &pb.ResourceLimits{PidsMax: proto.Int64(4)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), updateResp.Limits.GetPidsMax())
+
+	data, err := os.ReadFile(filepath.Join(testHierarchy.TaskPath("client001", startResp.TaskId), "pids.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "4", strings.TrimSpace(string(data)))
+
+	var statusResp *pb.TaskStatusResponse
+	require.Eventually(t, func() bool {
+		statusResp, err = client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+		return err == nil && statusResp.Status != pb.JobStatus_JOB_STATUS_STARTED
+	}, 2*testTimeout, pollInterval)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_ERROR, statusResp.Status)
+	assert.Equal(t, "pids", statusResp.TerminationSource)
+	assert.Positive(t, statusResp.PidsMaxEvents)
+}
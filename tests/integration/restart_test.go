@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_RestartTaskUntilRateReached(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sh",
+		Args:    []string{"-c", "echo up; exit 2"},
+		RestartPolicy: &pb.RestartPolicy{
+			Mode:           pb.RestartMode_RESTART_MODE_ON_FAILURE,
+			InitialBackoff: durationpb.New(50 * time.Millisecond),
+			MaxRestarts:    2,
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.StreamTaskOutput(ctx, &pb.StreamTaskOutputRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	var output []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		output = append(output, resp.Output...)
+	}
+	assert.Equal(t, "up\n--- taskman: restart 1 after exit code 2 ---\nup\n--- taskman: restart 2 after exit code 2 ---\nup\n"+
+		"--- taskman: not restarted after exit code 2: 2 restarts within 10m0s ---\n", string(output))
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_ERROR, statusResp.Status)
+	assert.Equal(t, int32(2), statusResp.RestartCount)
+	assert.Equal(t, "exit code 2", statusResp.LastExitReason)
+	assert.Zero(t, statusResp.MaxAttempts)
+	assert.Len(t, statusResp.Attempts, 3)
+}
+
+func TestIntegration_StopRestartedTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sleep",
+		Args:    []string{"60"},
+		RestartPolicy: &pb.RestartPolicy{
+			Mode:           pb.RestartMode_RESTART_MODE_ALWAYS,
+			InitialBackoff: durationpb.New(10 * time.Millisecond),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{startResp.TaskId}})
+	require.NoError(t, err)
+	require.Len(t, waitResp.Statuses, 1)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_SIGNALED, waitResp.Statuses[0].Status)
+	assert.Equal(t, "user", waitResp.Statuses[0].TerminationSource)
+	assert.Zero(t, waitResp.Statuses[0].RestartCount)
+	assert.Len(t, waitResp.Statuses[0].Attempts, 1)
+}
+
+func TestIntegration_RestartPolicyWithRetryPolicyRejected(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command:       "/bin/true",
+		RetryPolicy:   &pb.RetryPolicy{MaxAttempts: 3},
+		RestartPolicy: &pb.RestartPolicy{Mode: pb.RestartMode_RESTART_MODE_ALWAYS},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_RetryTask(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/sh",
+		Args:    []string{"-c", "echo failing; exit 3"},
+		RetryPolicy: &pb.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: durationpb.New(50 * time.Millisecond),
+		},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.StreamTaskOutput(ctx, &pb.StreamTaskOutputRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	var output []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		output = append(output, resp.Output...)
+	}
+	assert.Equal(t, "failing\n--- taskman: attempt 2 of 3 ---\nfailing\n--- taskman: attempt 3 of 3 ---\nfailing\n", string(output))
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_ERROR, statusResp.Status)
+	assert.Equal(t, int32(3), statusResp.GetExitCode())
+	assert.Equal(t, int32(3), statusResp.MaxAttempts)
+	require.Len(t, statusResp.Attempts, 3)
+	for i, attempt := range statusResp.Attempts {
+		assert.Equal(t, int32(i+1), attempt.Attempt)
+		assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_ERROR, attempt.Status)
+		assert.NotZero(t, attempt.ProcessId)
+	}
+	assert.Equal(t, int64(0), statusResp.Attempts[0].OutputOffset)
+	assert.Equal(t, int64(len("failing\n")), statusResp.Attempts[1].OutputOffset)
+}
+
+func TestIntegration_StopTaskWhileWaitingToRetry(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	startResp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "/bin/false",
+		RetryPolicy: &pb.RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: durationpb.New(time.Minute),
+		},
+	})
+	require.NoError(t, err)
+
+	// wait until the first attempt failed and the task waits to retry
+	require.Eventually(t, func() bool {
+		statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: startResp.TaskId})
+		return err == nil && len(statusResp.Attempts) == 1 && statusResp.Attempts[0].EndTime != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, err = client.StopTask(ctx, &pb.StopTaskRequest{TaskId: startResp.TaskId})
+	require.NoError(t, err)
+
+	waitResp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{startResp.TaskId}})
+	require.NoError(t, err)
+	require.Len(t, waitResp.Statuses, 1)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_SIGNALED, waitResp.Statuses[0].Status)
+	assert.Equal(t, "user", waitResp.Statuses[0].TerminationSource)
+	assert.Len(t, waitResp.Statuses[0].Attempts, 1)
+}
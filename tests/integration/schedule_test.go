@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+)
+
+func TestIntegration_Schedule(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	createResp, err := client.CreateSchedule(ctx, &pb.CreateScheduleRequest{
+		CronExpression: "@every 1s",
+		Template: &pb.TaskTemplate{
+			Command: "/bin/true",
+			Labels:  map[string]string{"schedule-test": "true"},
+		},
+		ConcurrencyPolicy: pb.ConcurrencyPolicy_CONCURRENCY_POLICY_FORBID,
+	})
+	require.NoError(t, err)
+	schedule := createResp.Schedule
+	assert.Equal(t, "client001", schedule.ClientId)
+	assert.NotNil(t, schedule.NextRunTime)
+
+	// schedules of other clients are not visible
+	listResp, err := otherClient.ListSchedules(ctx, &pb.ListSchedulesRequest{})
+	require.NoError(t, err)
+	for _, s := range listResp.Schedules {
+		assert.NotEqual(t, schedule.ScheduleId, s.ScheduleId)
+	}
+	_, err = otherClient.DeleteSchedule(ctx, &pb.DeleteScheduleRequest{ScheduleId: schedule.ScheduleId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	var taskID string
+	require.Eventually(t, func() bool {
+		listResp, err := client.ListSchedules(ctx, &pb.ListSchedulesRequest{})
+		require.NoError(t, err)
+		for _, s := range listResp.Schedules {
+			if s.ScheduleId == schedule.ScheduleId && s.LastTaskId != "" {
+				taskID = s.LastTaskId
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 100*time.Millisecond)
+
+	statusResp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{TaskId: taskID})
+	require.NoError(t, err)
+	assert.Equal(t, schedule.ScheduleId, statusResp.ScheduleId)
+	assert.Equal(t, "true", statusResp.Labels["schedule-test"])
+
+	pauseResp, err := client.PauseSchedule(ctx, &pb.PauseScheduleRequest{ScheduleId: schedule.ScheduleId, Paused: true})
+	require.NoError(t, err)
+	assert.True(t, pauseResp.Schedule.Paused)
+	assert.Nil(t, pauseResp.Schedule.NextRunTime)
+
+	_, err = client.DeleteSchedule(ctx, &pb.DeleteScheduleRequest{ScheduleId: schedule.ScheduleId})
+	require.NoError(t, err)
+	_, err = client.PauseSchedule(ctx, &pb.PauseScheduleRequest{ScheduleId: schedule.ScheduleId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestIntegration_ScheduleRejected(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	tests := []struct {
+		desc string
+		req  *pb.CreateScheduleRequest
+	}{
+		{desc: "invalid cron expression", req: &pb.CreateScheduleRequest{CronExpression: "every minute", Template: &pb.TaskTemplate{Command: "/bin/true"}}},
+		{desc: "missing template", req: &pb.CreateScheduleRequest{CronExpression: "@hourly"}},
+		{desc: "unknown command", req: &pb.CreateScheduleRequest{CronExpression: "@hourly", Template: &pb.TaskTemplate{Command: "/no/such/command"}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.CreateSchedule(ctx, tt.req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
@@ -7,8 +7,12 @@ import (
 	pb "github.com/mikewurtz/taskman/gen/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+
+	basegrpc "github.com/mikewurtz/taskman/internal/grpc"
 )
 
 func TestIntegration_GetTaskStatusContextTimeout(t *testing.T) {
@@ -64,3 +68,25 @@ func TestIntegration_GetTaskStatusDoesNotExist(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, codes.NotFound, sts.Code())
 }
+
+func TestIntegration_GetTaskStatusReturnsRequestID(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	var header metadata.MD
+	resp, err := client.GetTaskStatus(ctx, &pb.TaskStatusRequest{
+		TaskId: "375b0522-72ed-4f3f-88d0-01d360d06b8c",
+	}, grpc.Header(&header))
+	require.Nil(t, resp)
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// the request ID is returned even when the call fails so it can be matched against the server logs
+	requestIDs := header.Get(basegrpc.RequestIDHeader)
+	require.Len(t, requestIDs, 1)
+	assert.NotEmpty(t, requestIDs[0])
+}
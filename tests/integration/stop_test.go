@@ -2,7 +2,9 @@ package integration
 
 import (
 	"context"
+	"syscall"
 	"testing"
+	"time"
 
 	pb "github.com/mikewurtz/taskman/gen/proto"
 	"github.com/stretchr/testify/assert"
@@ -46,3 +48,44 @@ func TestIntegration_StopTaskDoesNotExist(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, codes.NotFound, sts.Code())
 }
+
+func TestIntegration_SignalTaskTerm(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+		Command: "sleep",
+		Args:    []string{"5"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.TaskId)
+
+	_, err = client.SignalTask(ctx, &pb.SignalTaskRequest{
+		TaskId: resp.TaskId,
+		Signal: "SIGSTOP",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.SignalTask(ctx, &pb.SignalTaskRequest{
+		TaskId: resp.TaskId,
+		Signal: "term",
+	})
+	require.NoError(t, err)
+
+	var statusResp *pb.TaskStatusResponse
+	require.Eventually(t, func() bool {
+		statusResp, err = client.GetTaskStatus(ctx, &pb.TaskStatusRequest{
+			TaskId: resp.TaskId,
+		})
+		return err == nil && statusResp != nil && statusResp.Status == pb.JobStatus_JOB_STATUS_SIGNALED
+	}, 3*time.Second, pollInterval, "expected task to be terminated")
+
+	assert.Equal(t, "user", statusResp.TerminationSource)
+	assert.Equal(t, syscall.SIGTERM.String(), statusResp.TerminationSignal)
+	assert.Nil(t, statusResp.ExitCode)
+}
@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/mikewurtz/taskman/gen/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestIntegration_WaitTasks(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	var taskIDs []string
+	for _, args := range [][]string{{"-c", "sleep 0.2"}, {"-c", "sleep 0.5; exit 3"}} {
+		resp, err := client.StartTask(ctx, &pb.StartTaskRequest{
+			Command: "/bin/sh",
+			Args:    args,
+		})
+		require.NoError(t, err)
+		taskIDs = append(taskIDs, resp.TaskId)
+	}
+
+	resp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{
+		TaskIds: taskIDs,
+		Mode:    pb.WaitMode_WAIT_MODE_ALL,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Completed)
+	require.Len(t, resp.Statuses, 2)
+
+	assert.Equal(t, taskIDs[0], resp.Statuses[0].TaskId)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_OK, resp.Statuses[0].Status)
+	assert.Equal(t, int32(0), resp.Statuses[0].GetExitCode())
+
+	assert.Equal(t, taskIDs[1], resp.Statuses[1].TaskId)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_ERROR, resp.Statuses[1].Status)
+	assert.Equal(t, int32(3), resp.Statuses[1].GetExitCode())
+}
+
+func TestIntegration_WaitTasksAnyAndTimeout(t *testing.T) {
+	t.Parallel()
+
+	client := createTestClient(t, "client001")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	fast, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "sleep", Args: []string{"0.2"}})
+	require.NoError(t, err)
+	slow, err := client.StartTask(ctx, &pb.StartTaskRequest{Command: "sleep", Args: []string{"30"}})
+	require.NoError(t, err)
+	defer func() {
+		_, _ = client.StopTask(context.Background(), &pb.StopTaskRequest{TaskId: slow.TaskId})
+	}()
+
+	resp, err := client.WaitTasks(ctx, &pb.WaitTasksRequest{
+		TaskIds: []string{slow.TaskId, fast.TaskId},
+		Mode:    pb.WaitMode_WAIT_MODE_ANY,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Completed)
+	require.Len(t, resp.Statuses, 2)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_STARTED, resp.Statuses[0].Status)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_EXITED_OK, resp.Statuses[1].Status)
+
+	start := time.Now()
+	resp, err = client.WaitTasks(ctx, &pb.WaitTasksRequest{
+		TaskIds: []string{slow.TaskId},
+		Timeout: durationpb.New(200 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Completed)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.Len(t, resp.Statuses, 1)
+	assert.Equal(t, pb.JobStatus_JOB_STATUS_STARTED, resp.Statuses[0].Status)
+}
+
+func TestIntegration_WaitTasksNotFound(t *testing.T) {
+	t.Parallel()
+
+	ownerClient := createTestClient(t, "client001")
+	otherClient := createTestClient(t, "client002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	resp, err := ownerClient.StartTask(ctx, &pb.StartTaskRequest{Command: "sleep", Args: []string{"0.1"}})
+	require.NoError(t, err)
+
+	_, err = otherClient.WaitTasks(ctx, &pb.WaitTasksRequest{TaskIds: []string{resp.TaskId}})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ownerClient.WaitTasks(ctx, &pb.WaitTasksRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}